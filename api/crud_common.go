@@ -105,6 +105,13 @@ func (c *CrudCommon[T]) createCrudRecord(route Route[T], request Request[T]) (an
 	body := request.Body
 	body.SetOwner(request.Token.UserId)
 
+	// reset any fields that only the record's own workflow may set
+	if p, ok := any(body).(database.PreCreateRequest); ok {
+		if err := p.PreCreateRequest(request.Context, c.DatabaseProvider, request.Token.UserId); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+
 	// Enforce the model's EditableBy on the create path. After stamping the
 	// caller as owner, verify that the caller may edit a record with these field
 	// values. For owner-based models this always passes (the caller just became
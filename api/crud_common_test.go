@@ -241,6 +241,57 @@ func TestCrudCommonCreateSuccess(t *testing.T) {
 	}
 }
 
+// workflowCrudRecord has a field that only its own workflow may set, which
+// the generic create route must reset via PreCreateRequest.
+type workflowCrudRecord struct {
+	testCrudRecord
+	Approved bool
+}
+
+func newWorkflowCrudRecord() *workflowCrudRecord {
+	return &workflowCrudRecord{testCrudRecord: *newTestCrudRecord()}
+}
+
+func (t *workflowCrudRecord) PreCreateRequest(_ context.Context, _ database.Provider, userId database.UserId) error {
+	t.Approved = false
+	return nil
+}
+
+func (t *workflowCrudRecord) NewRecord() database.CrudRecord {
+	return new(workflowCrudRecord)
+}
+
+func TestCrudCommonCreateResetsWorkflowFields(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	cc := NewCrudCommon(newWorkflowCrudRecord, false, db)
+
+	route := genericApiRoute[*workflowCrudRecord]{
+		requestBody:    newWorkflowCrudRecord,
+		useRequestBody: true,
+		handle:         cc.createCrudRecord,
+	}
+
+	body := newWorkflowCrudRecord()
+	body.Approved = true
+	req := Request[*workflowCrudRecord]{
+		Context:          context.Background(),
+		DatabaseProvider: db,
+		Token:            &AuthToken{UserId: database.UserId(database.NewRecordId())},
+		Body:             body,
+	}
+
+	resp, status, err := route.Handler(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, status)
+	}
+	if resp.(gin.H)[ResourceKey].(*workflowCrudRecord).Approved {
+		t.Fatal("Approved should be reset on create")
+	}
+}
+
 func TestCrudCommonCreateForbiddenForNonPrivileged(t *testing.T) {
 	db := database.NewUnitTestDBProvider()
 	cc := NewCrudCommon(func() *sysAdminOnlyCrudRecord { return &sysAdminOnlyCrudRecord{} }, false, db)
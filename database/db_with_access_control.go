@@ -111,6 +111,13 @@ func (w *WithAccessControl[T]) DeleteOneById(ctx context.Context, record T, id R
 		return t, false, nil
 	}
 
+	// refuse deletes this user may not make even though they can edit
+	if p, ok := any(t).(PreDeleteRequest); ok {
+		if err := p.PreDeleteRequest(ctx, w.Database, w.AccessControlUser); err != nil {
+			return t, true, err
+		}
+	}
+
 	fmt.Println("deleting record", id)
 	return DeleteOneById(ctx, w.Database, record, id)
 }
@@ -135,6 +142,13 @@ func (w *WithAccessControl[T]) UpdateOneById(ctx context.Context, record T) (err
 		record.SetOwner(t.GetOwner())
 	}
 
+	// refuse changes this user may not make even though they can edit
+	if p, ok := any(record).(PreUpdateRequest); ok {
+		if err := p.PreUpdateRequest(ctx, w.Database, w.AccessControlUser, t); err != nil {
+			return err
+		}
+	}
+
 	// update it, validating the type while doing so
 	return UpdateOne(ctx, w.Database, record)
 }
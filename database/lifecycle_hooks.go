@@ -6,6 +6,25 @@ type PostCreate interface {
 	PostCreate(ctx context.Context, db Provider) error // function to call post-create
 }
 
+// PreCreateRequest is implemented by records with fields that a client may
+// not set when creating the record through the generic CRUD create route,
+// e.g. state only reached through the record's own workflow, or that refuse
+// some creates outright. The route calls it on the request body after
+// SetOwner; CreateOne does not, so the model's own creates may still set
+// those fields.
+type PreCreateRequest interface {
+	PreCreateRequest(ctx context.Context, db Provider, userId UserId) error // function to call on a generic create request body
+}
+
+// PreUpdateRequest is the update counterpart of PreCreateRequest, for fields
+// that only some of the users able to edit the record may change. It is
+// called by WithAccessControl.UpdateOneById, which backs the generic CRUD
+// update route, once the user is known to be able to edit the record;
+// UpdateOne does not call it.
+type PreUpdateRequest interface {
+	PreUpdateRequest(ctx context.Context, db Provider, userId UserId, existingValues CrudRecord) error // function to call on a generic update request body
+}
+
 type PreUpdate interface {
 	PreUpdate(ctx context.Context, db Provider, existingValues CrudRecord) error // function to call pre-update
 }
@@ -23,6 +42,14 @@ type PreDelete interface {
 	PreDelete(ctx context.Context, db Provider) error // function to call pre-delete
 }
 
+// PreDeleteRequest is the delete counterpart of PreUpdateRequest. It is
+// called by WithAccessControl.DeleteOneById, which backs the generic CRUD
+// delete route, once the user is known to be able to edit the record;
+// DeleteOneById does not call it.
+type PreDeleteRequest interface {
+	PreDeleteRequest(ctx context.Context, db Provider, userId UserId) error // function to call on a generic delete request
+}
+
 type PostDelete interface {
 	PostDelete(ctx context.Context, db Provider) error // function to call post-delete
 }
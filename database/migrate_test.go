@@ -120,3 +120,55 @@ func TestEmbeddedMigrations(t *testing.T) {
 		t.Fatalf("first migration = %q, want 0001_schema_skeleton.sql", migs[0].Name)
 	}
 }
+
+func TestBackfillArchivedRulesetSections(t *testing.T) {
+	migs, err := EmbeddedMigrations()
+	if err != nil {
+		t.Fatalf("EmbeddedMigrations: %v", err)
+	}
+	var before, backfill []Migration
+	for _, m := range migs {
+		if m.Name < "0074" {
+			before = append(before, m)
+		} else {
+			backfill = append(backfill, m)
+		}
+	}
+	db := openTestSqlite(t)
+	if err := Migrate(db, before); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	// r1 -> r2 -> r3, where only the current revision r3 kept its sections.
+	const zero = "0000000000000000"
+	const date = "2024-01-01T00:00:00Z"
+	for _, r := range []struct{ id, supersededBy string }{
+		{"0000000000000001", "0000000000000002"},
+		{"0000000000000002", "0000000000000003"},
+		{"0000000000000003", zero},
+	} {
+		if _, err := db.Exec("INSERT INTO ruleset (id, name, revision, superseded_by, date, owner) VALUES (?, 'r', 0, ?, ?, ?)",
+			r.id, r.supersededBy, date, zero); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, section := range []string{"00000000000000a1", "00000000000000a2"} {
+		if _, err := db.Exec("INSERT INTO ruleset_section (id, ruleset_id, section_id, section_index, created_at, updated_at) VALUES (?, '0000000000000003', ?, ?, ?, ?)",
+			"00000000000000b"+string(rune('1'+i)), section, i, date, date); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Migrate(db, backfill); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	for _, id := range []string{"0000000000000001", "0000000000000002", "0000000000000003"} {
+		var n int
+		if err := db.QueryRow("SELECT COUNT(*) FROM ruleset_section WHERE ruleset_id = ? AND section_id IN ('00000000000000a1', '00000000000000a2')", id).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 {
+			t.Fatalf("ruleset %s has %d sections, want 2", id, n)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+-- 0054_create_substitutes.sql
+-- The substitute table, matching the Substitute record shape
+-- (model/substitute.go). Each row opts a user in to the substitute pool for
+-- a single week.
+-- Table name equals record.Type() ("substitute").
+--   id      -> RecordId hex TEXT primary key
+--   week_id -> WeekId hex TEXT
+--   user_id -> UserId hex TEXT
+-- One row per (week, user): UNIQUE(week_id, user_id) mirrors
+-- Substitute.UniquenessEquivalent.
+CREATE TABLE substitute (
+    id      TEXT PRIMARY KEY,   -- RecordId hex string
+    week_id TEXT NOT NULL,      -- WeekId hex string
+    user_id TEXT NOT NULL,      -- UserId hex string
+    UNIQUE (week_id, user_id)
+);
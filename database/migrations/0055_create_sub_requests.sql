@@ -0,0 +1,24 @@
+-- 0055_create_sub_requests.sql
+-- The sub_request table, matching the SubRequest record shape
+-- (model/sub_request.go).
+-- Table name equals record.Type() ("sub_request").
+--   id                -> SubRequestId hex TEXT primary key
+--   week_id           -> WeekId hex TEXT
+--   team_id           -> TeamId hex TEXT
+--   format_line_index -> INTEGER
+--   rating_id         -> RatingId hex TEXT
+--   requested_by      -> UserId hex TEXT
+--   claimed_by        -> UserId hex TEXT (InvalidUserId while open)
+--   status            -> INTEGER (SubRequestStatus)
+--   note              -> TEXT
+CREATE TABLE sub_request (
+    id                TEXT PRIMARY KEY,   -- SubRequestId hex string
+    week_id           TEXT NOT NULL,      -- WeekId hex string
+    team_id           TEXT NOT NULL,      -- TeamId hex string
+    format_line_index INTEGER NOT NULL,
+    rating_id         TEXT NOT NULL,      -- RatingId hex string
+    requested_by      TEXT NOT NULL,      -- UserId hex string
+    claimed_by        TEXT NOT NULL,      -- UserId hex string
+    status            INTEGER NOT NULL,   -- SubRequestStatus
+    note              TEXT NOT NULL
+);
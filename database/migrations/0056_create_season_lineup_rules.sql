@@ -0,0 +1,16 @@
+-- 0056_create_season_lineup_rules.sql
+-- The season_lineup_rule table, matching the SeasonLineupRule record shape
+-- (model/season_lineup_rule.go). Each row enables one registered lineup rule
+-- (see model/lineup_rule.go) for a season; rules without a row are disabled.
+-- Table name equals record.Type() ("season_lineup_rule").
+--   id        -> RecordId hex TEXT primary key
+--   season_id -> SeasonId hex TEXT
+--   rule      -> LineupRuleName TEXT
+-- One row per (season, rule): UNIQUE(season_id, rule) mirrors
+-- SeasonLineupRule.UniquenessEquivalent.
+CREATE TABLE season_lineup_rule (
+    id        TEXT PRIMARY KEY,   -- RecordId hex string
+    season_id TEXT NOT NULL,      -- SeasonId hex string
+    rule      TEXT NOT NULL,      -- LineupRuleName
+    UNIQUE (season_id, rule)
+);
@@ -0,0 +1,6 @@
+-- 0057_add_season_blind_lineups.sql
+-- Add the blind-lineup flag to the season table. When set on a Season
+-- (model/season.go), a team's lineup pairings are hidden from everyone but the
+-- team's members until both teams in the weekly matchup have confirmed their
+-- lineups. Stored as INTEGER (0/1), matching the provider's bool mapping.
+ALTER TABLE season ADD COLUMN blind_lineups INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,29 @@
+-- 0058_create_lineup_revisions.sql
+-- The lineup_revision table, matching the LineupRevision record shape
+-- (model/lineup_revision.go). Each row is one numbered snapshot of a
+-- confirmed or official lineup's pairings; the pairings themselves live in
+-- lineup_revision_pairing (0059).
+-- Table name equals record.Type() ("lineup_revision").
+--   id          -> LineupRevisionId hex TEXT primary key
+--   lineup_id   -> LineupId hex TEXT
+--   revision    -> INTEGER, 1-based per lineup
+--   edited_by   -> UserId hex TEXT
+--   reason      -> TEXT
+--   status      -> INTEGER (LineupRevisionStatus)
+--   reviewed_by -> UserId hex TEXT (InvalidUserId until reviewed)
+--   created_at  -> RFC3339 TEXT
+--   updated_at  -> RFC3339 TEXT
+-- One row per (lineup, revision): UNIQUE(lineup_id, revision) mirrors
+-- LineupRevision.UniquenessEquivalent.
+CREATE TABLE lineup_revision (
+    id          TEXT PRIMARY KEY,   -- LineupRevisionId hex string
+    lineup_id   TEXT NOT NULL,      -- LineupId hex string
+    revision    INTEGER NOT NULL,
+    edited_by   TEXT NOT NULL,      -- UserId hex string
+    reason      TEXT NOT NULL,
+    status      INTEGER NOT NULL,   -- LineupRevisionStatus
+    reviewed_by TEXT NOT NULL,      -- UserId hex string
+    created_at  TEXT NOT NULL,      -- RFC3339
+    updated_at  TEXT NOT NULL,      -- RFC3339
+    UNIQUE (lineup_id, revision)
+);
@@ -0,0 +1,20 @@
+-- 0059_create_lineup_revision_pairings.sql
+-- The lineup_revision_pairing table, matching the LineupRevisionPairing
+-- record shape (model/lineup_revision.go). Each row is one format line's
+-- pairing as captured by a lineup_revision (0058).
+-- Table name equals record.Type() ("lineup_revision_pairing").
+--   id                -> RecordId hex TEXT primary key
+--   revision_id       -> LineupRevisionId hex TEXT
+--   player1           -> UserId hex TEXT
+--   player2           -> UserId hex TEXT
+--   format_line_index -> INTEGER
+-- One row per (revision, line): UNIQUE(revision_id, format_line_index)
+-- mirrors LineupRevisionPairing.UniquenessEquivalent.
+CREATE TABLE lineup_revision_pairing (
+    id                TEXT PRIMARY KEY,   -- RecordId hex string
+    revision_id       TEXT NOT NULL,      -- LineupRevisionId hex string
+    player1           TEXT NOT NULL,      -- UserId hex string
+    player2           TEXT NOT NULL,      -- UserId hex string
+    format_line_index INTEGER NOT NULL,
+    UNIQUE (revision_id, format_line_index)
+);
@@ -0,0 +1,27 @@
+-- 0060_create_match_score_events.sql
+-- The match_score_event table, matching the MatchScoreEvent record shape
+-- (model/match_score_event.go). Each row is one point, game or set won in a
+-- live-scored match; both sides of a match share one log, keyed by the side
+-- with the lower ID, and replaying it in sequence order gives the score.
+-- Table name equals record.Type() ("match_score_event").
+--   id          -> RecordId hex TEXT primary key
+--   match_id    -> IndividualMatchId hex TEXT (lower ID of the pair)
+--   sequence    -> INTEGER, 1-based per match
+--   scored_by   -> IndividualMatchId hex TEXT (side that won the unit)
+--   unit        -> INTEGER (ScoreCountingType)
+--   recorded_by -> UserId hex TEXT
+--   created_at  -> RFC3339 TEXT
+--   updated_at  -> RFC3339 TEXT
+-- One row per (match, sequence): UNIQUE(match_id, sequence) mirrors
+-- MatchScoreEvent.UniquenessEquivalent.
+CREATE TABLE match_score_event (
+    id          TEXT PRIMARY KEY,   -- RecordId hex string
+    match_id    TEXT NOT NULL,      -- IndividualMatchId hex string
+    sequence    INTEGER NOT NULL,
+    scored_by   TEXT NOT NULL,      -- IndividualMatchId hex string
+    unit        INTEGER NOT NULL,   -- ScoreCountingType
+    recorded_by TEXT NOT NULL,      -- UserId hex string
+    created_at  TEXT NOT NULL,      -- RFC3339
+    updated_at  TEXT NOT NULL,      -- RFC3339
+    UNIQUE (match_id, sequence)
+);
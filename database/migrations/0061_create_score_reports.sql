@@ -0,0 +1,50 @@
+-- 0061_create_score_reports.sql
+-- The score_report table, matching the ScoreReport record shape
+-- (model/score_report.go). Each row tracks whether a team match line's final
+-- score has been confirmed by the opposing team, disputed, or resolved by a
+-- season commissioner. Claims are from the point of view of team_id, the team
+-- playing the match_id side.
+-- Table name equals record.Type() ("score_report").
+--   id                -> RecordId hex TEXT primary key
+--   match_id          -> IndividualMatchId hex TEXT (lower ID of the pair)
+--   team_match_id     -> TeamMatchId hex TEXT
+--   team_id           -> TeamId hex TEXT
+--   opponent_team_id  -> TeamId hex TEXT
+--   reporting_team_id -> TeamId hex TEXT (unset when entered by a commissioner)
+--   reported_by       -> UserId hex TEXT
+--   reported_*        -> INTEGER (nested ScoreClaim flattened)
+--   status            -> INTEGER (ScoreReportStatus)
+--   responded_by      -> UserId hex TEXT
+--   disputed_*        -> INTEGER (nested ScoreClaim flattened)
+--   dispute_note      -> TEXT
+--   resolved_by       -> UserId hex TEXT
+--   created_at        -> RFC3339 TEXT
+--   updated_at        -> RFC3339 TEXT
+-- One row per match pair: UNIQUE(match_id) mirrors
+-- ScoreReport.UniquenessEquivalent.
+CREATE TABLE score_report (
+    id                                TEXT PRIMARY KEY,   -- RecordId hex string
+    match_id                          TEXT NOT NULL,      -- IndividualMatchId hex string
+    team_match_id                     TEXT NOT NULL,      -- TeamMatchId hex string
+    team_id                           TEXT NOT NULL,      -- TeamId hex string
+    opponent_team_id                  TEXT NOT NULL,      -- TeamId hex string
+    reporting_team_id                 TEXT NOT NULL,      -- TeamId hex string
+    reported_by                       TEXT NOT NULL,      -- UserId hex string
+    reported_main_value               INTEGER NOT NULL,
+    reported_secondary_value          INTEGER NOT NULL,
+    reported_opponent_main_value      INTEGER NOT NULL,
+    reported_opponent_secondary_value INTEGER NOT NULL,
+    reported_won                      INTEGER NOT NULL,   -- bool
+    status                            INTEGER NOT NULL,   -- ScoreReportStatus
+    responded_by                      TEXT NOT NULL,      -- UserId hex string
+    disputed_main_value               INTEGER NOT NULL,
+    disputed_secondary_value          INTEGER NOT NULL,
+    disputed_opponent_main_value      INTEGER NOT NULL,
+    disputed_opponent_secondary_value INTEGER NOT NULL,
+    disputed_won                      INTEGER NOT NULL,   -- bool
+    dispute_note                      TEXT NOT NULL,
+    resolved_by                       TEXT NOT NULL,      -- UserId hex string
+    created_at                        TEXT NOT NULL,      -- RFC3339
+    updated_at                        TEXT NOT NULL,      -- RFC3339
+    UNIQUE (match_id)
+);
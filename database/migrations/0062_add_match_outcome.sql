@@ -0,0 +1,6 @@
+-- 0062_add_match_outcome.sql
+-- Add the outcome column to the match table. It records how an
+-- IndividualMatch (model/individual_match.go) was decided: played out, or by a
+-- retirement, default, double default or walkover (model/match_outcome.go).
+-- Stored as INTEGER (MatchOutcome); existing matches were played out (0).
+ALTER TABLE match ADD COLUMN outcome INTEGER NOT NULL DEFAULT 0;
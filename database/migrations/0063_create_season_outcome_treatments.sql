@@ -0,0 +1,21 @@
+-- 0063_create_season_outcome_treatments.sql
+-- The season_outcome_treatment table, matching the SeasonOutcomeTreatment
+-- record shape (model/season_outcome_treatment.go). Each row overrides how
+-- matches decided by one MatchOutcome count toward a season's standings and
+-- statistics; outcomes without a row use their default treatment.
+-- Table name equals record.Type() ("season_outcome_treatment").
+--   id                         -> RecordId hex TEXT primary key
+--   season_id                  -> SeasonId hex TEXT
+--   outcome                    -> INTEGER (MatchOutcome)
+--   counts_toward_record       -> INTEGER (bool: 0/1)
+--   counts_toward_differential -> INTEGER (bool: 0/1)
+-- One row per (season, outcome): UNIQUE(season_id, outcome) mirrors
+-- SeasonOutcomeTreatment.UniquenessEquivalent.
+CREATE TABLE season_outcome_treatment (
+    id                         TEXT PRIMARY KEY,   -- RecordId hex string
+    season_id                  TEXT NOT NULL,      -- SeasonId hex string
+    outcome                    INTEGER NOT NULL,   -- MatchOutcome
+    counts_toward_record       INTEGER NOT NULL,
+    counts_toward_differential INTEGER NOT NULL,
+    UNIQUE (season_id, outcome)
+);
@@ -0,0 +1,32 @@
+-- 0064_create_player_rating_changes.sql
+-- The player_rating_change table, matching the PlayerRatingChange record shape
+-- (model/player_rating.go). Each row is the change in one player's dynamic
+-- rating caused by one completed doubles match. Rows are derived entirely
+-- from match results and may be deleted and rebuilt at any time.
+-- Table name equals record.Type() ("player_rating_change").
+--   id            -> RecordId hex TEXT primary key
+--   user_id       -> UserId hex TEXT
+--   sequence      -> INTEGER (1-based position of the match in the replay)
+--   match_id      -> IndividualMatchId hex TEXT (lower ID of the pair)
+--   week_id       -> WeekId hex TEXT
+--   date          -> RFC3339 TEXT
+--   won           -> INTEGER (bool: 0/1)
+--   games_for     -> INTEGER
+--   games_against -> INTEGER
+--   before        -> REAL
+--   after         -> REAL
+CREATE TABLE player_rating_change (
+    id            TEXT PRIMARY KEY,   -- RecordId hex string
+    user_id       TEXT NOT NULL,      -- UserId hex string
+    sequence      INTEGER NOT NULL,
+    match_id      TEXT NOT NULL,      -- IndividualMatchId hex string
+    week_id       TEXT NOT NULL,      -- WeekId hex string
+    date          TEXT NOT NULL,      -- RFC3339
+    won           INTEGER NOT NULL,
+    games_for     INTEGER NOT NULL,
+    games_against INTEGER NOT NULL,
+    before        REAL NOT NULL,
+    after         REAL NOT NULL
+);
+
+CREATE INDEX player_rating_change_user_id ON player_rating_change (user_id);
@@ -0,0 +1,7 @@
+-- 0065_add_format_points.sql
+-- Weighted line scoring for team matches (model/team_match_points.go).
+--   format.match_win_bonus -> INTEGER (bonus points for winning a team match)
+--   format_line.points     -> INTEGER (points a line win is worth; 0 counts as 1)
+-- Existing formats keep one point per line and no bonus.
+ALTER TABLE format ADD COLUMN match_win_bonus INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE format_line ADD COLUMN points INTEGER NOT NULL DEFAULT 0;
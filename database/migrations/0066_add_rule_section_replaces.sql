@@ -0,0 +1,6 @@
+-- 0066_add_rule_section_replaces.sql
+-- Section history for ruleset revision diffs (model/ruleset_diff.go).
+--   rule_section.replaces -> RuleSectionId hex TEXT (the section a modified
+--                            section was amended from; all zeros for added sections)
+-- Archived ruleset revisions keep their ruleset_section rows from now on.
+ALTER TABLE rule_section ADD COLUMN replaces TEXT NOT NULL DEFAULT '0000000000000000';
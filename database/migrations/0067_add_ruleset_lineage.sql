@@ -0,0 +1,16 @@
+-- 0067_add_ruleset_lineage.sql
+-- Ruleset lineage for the history browser (model/ruleset_history.go).
+--   ruleset.parent      -> RulesetId hex TEXT (revision this one was made from;
+--                          all zeros for a newly created ruleset)
+--   ruleset.change_kind -> INTEGER (RulesetChangeKind: how it was made from parent)
+--   ruleset.author      -> UserId hex TEXT (who made the revision)
+-- Existing revisions are linked to the revision they superseded as a generic
+-- amendment (RulesetChangeAmended = 1), authored by their owner.
+ALTER TABLE ruleset ADD COLUMN parent TEXT NOT NULL DEFAULT '0000000000000000';
+ALTER TABLE ruleset ADD COLUMN change_kind INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE ruleset ADD COLUMN author TEXT NOT NULL DEFAULT '0000000000000000';
+UPDATE ruleset SET author = owner;
+UPDATE ruleset
+SET parent = (SELECT p.id FROM ruleset p WHERE p.superseded_by = ruleset.id),
+    change_kind = 1
+WHERE EXISTS (SELECT 1 FROM ruleset p WHERE p.superseded_by = ruleset.id);
@@ -0,0 +1,32 @@
+-- 0068_add_proposal_amendments.sql
+-- Structured rule amendments carried by commissioner proposals
+-- (model/commissioner_proposal_amendment.go).
+--   commissioner_proposal.ruleset_id       -> RulesetId hex TEXT (ruleset the
+--                                             amendments target; all zeros for
+--                                             a free-text proposal)
+--   commissioner_proposal.applied_revision -> RulesetId hex TEXT (revision made
+--                                             by applying the amendments)
+-- The commissioner_proposal_amendment table matches the
+-- CommissionerProposalAmendment record shape; its table name equals
+-- record.Type() ("commissioner_proposal_amendment").
+--   id             -> RecordId hex TEXT primary key
+--   proposal_id    -> RecordId hex TEXT
+--   position       -> INTEGER (order within the proposal)
+--   amendment_type -> INTEGER (RuleAmendmentType)
+--   target_section -> RuleSectionId hex TEXT
+--   title          -> TEXT
+--   markdown       -> TEXT
+--   after          -> RuleSectionId hex TEXT
+ALTER TABLE commissioner_proposal ADD COLUMN ruleset_id TEXT NOT NULL DEFAULT '0000000000000000';
+ALTER TABLE commissioner_proposal ADD COLUMN applied_revision TEXT NOT NULL DEFAULT '0000000000000000';
+CREATE TABLE commissioner_proposal_amendment (
+    id             TEXT PRIMARY KEY,   -- RecordId hex string
+    proposal_id    TEXT NOT NULL,      -- RecordId hex string
+    position       INTEGER NOT NULL,
+    amendment_type INTEGER NOT NULL,
+    target_section TEXT NOT NULL DEFAULT '0000000000000000',
+    title          TEXT NOT NULL DEFAULT '',
+    markdown       TEXT NOT NULL DEFAULT '',
+    after          TEXT NOT NULL DEFAULT '0000000000000000',
+    UNIQUE (proposal_id, position)
+);
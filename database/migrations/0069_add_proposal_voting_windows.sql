@@ -0,0 +1,28 @@
+-- 0069_add_proposal_voting_windows.sql
+-- Voting deadlines, quorum and vote timestamps for commissioner proposals
+-- (model/commissioner_proposal_window.go).
+--   commissioner_proposal.deadline  -> RFC3339 TEXT (zero time for no deadline)
+--   commissioner_proposal.quorum    -> INTEGER (minimum votes cast, 0 for none)
+--   commissioner_proposal.closed_at -> RFC3339 TEXT (zero time while open)
+--   commissioner_proposal.outcome   -> INTEGER (ProposalOutcome, 0 = pending)
+--   commissioner_proposal_vote.cast_at -> RFC3339 TEXT (when last cast/changed)
+-- The commissioner_proposal_vote_change table is the log of every vote cast,
+-- matching the CommissionerProposalVoteChange record shape; its table name
+-- equals record.Type() ("commissioner_proposal_vote_change").
+--   id          -> RecordId hex TEXT primary key
+--   proposal_id -> RecordId hex TEXT
+--   user_id     -> UserId hex TEXT
+--   vote        -> INTEGER (bool: 0/1)
+--   cast_at     -> RFC3339 TEXT
+ALTER TABLE commissioner_proposal ADD COLUMN deadline TEXT NOT NULL DEFAULT '0001-01-01T00:00:00Z';
+ALTER TABLE commissioner_proposal ADD COLUMN quorum INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE commissioner_proposal ADD COLUMN closed_at TEXT NOT NULL DEFAULT '0001-01-01T00:00:00Z';
+ALTER TABLE commissioner_proposal ADD COLUMN outcome INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE commissioner_proposal_vote ADD COLUMN cast_at TEXT NOT NULL DEFAULT '0001-01-01T00:00:00Z';
+CREATE TABLE commissioner_proposal_vote_change (
+    id          TEXT PRIMARY KEY,   -- RecordId hex string
+    proposal_id TEXT NOT NULL,      -- RecordId hex string
+    user_id     TEXT NOT NULL,      -- UserId hex string
+    vote        INTEGER NOT NULL,
+    cast_at     TEXT NOT NULL       -- RFC3339
+);
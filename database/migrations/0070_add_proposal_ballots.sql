@@ -0,0 +1,43 @@
+-- 0070_add_proposal_ballots.sql
+-- Multi-option and ranked-choice commissioner proposals
+-- (model/commissioner_proposal_ballot.go).
+--   commissioner_proposal.kind           -> INTEGER (ProposalKind, 0 = yes/no)
+--   commissioner_proposal.winning_option -> RecordId hex TEXT (all zeros unless
+--                                           a multi-option proposal closed
+--                                           accepted)
+-- The commissioner_proposal_option table matches the
+-- CommissionerProposalOption record shape; its table name equals
+-- record.Type() ("commissioner_proposal_option").
+--   id          -> RecordId hex TEXT primary key
+--   proposal_id -> RecordId hex TEXT
+--   position    -> INTEGER (order within the proposal)
+--   text        -> TEXT
+-- The commissioner_proposal_ballot table holds one row per ranked choice,
+-- matching the CommissionerProposalBallot record shape; its table name equals
+-- record.Type() ("commissioner_proposal_ballot").
+--   id          -> RecordId hex TEXT primary key
+--   proposal_id -> RecordId hex TEXT
+--   user_id     -> UserId hex TEXT
+--   option_id   -> RecordId hex TEXT
+--   rank        -> INTEGER (0 = first choice)
+--   cast_at     -> RFC3339 TEXT
+-- UNIQUE(proposal_id, user_id, rank) and UNIQUE(proposal_id, user_id,
+-- option_id) mirror CommissionerProposalBallot.UniquenessEquivalent.
+ALTER TABLE commissioner_proposal ADD COLUMN kind INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE commissioner_proposal ADD COLUMN winning_option TEXT NOT NULL DEFAULT '0000000000000000';
+CREATE TABLE commissioner_proposal_option (
+    id          TEXT PRIMARY KEY,   -- RecordId hex string
+    proposal_id TEXT NOT NULL,      -- RecordId hex string
+    position    INTEGER NOT NULL,
+    text        TEXT NOT NULL
+);
+CREATE TABLE commissioner_proposal_ballot (
+    id          TEXT PRIMARY KEY,   -- RecordId hex string
+    proposal_id TEXT NOT NULL,      -- RecordId hex string
+    user_id     TEXT NOT NULL,      -- UserId hex string
+    option_id   TEXT NOT NULL,      -- RecordId hex string
+    rank        INTEGER NOT NULL,
+    cast_at     TEXT NOT NULL,      -- RFC3339
+    UNIQUE (proposal_id, user_id, rank),
+    UNIQUE (proposal_id, user_id, option_id)
+);
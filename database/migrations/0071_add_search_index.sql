@@ -0,0 +1,22 @@
+-- 0071_add_search_index.sql
+-- Full-text search over rules, blurbs and comments (database/search.go).
+-- search_index is an FTS5 virtual table holding one row per indexed record;
+-- records keep it current from their lifecycle hooks.
+--   kind      -> TEXT, the record's Type() (not tokenized)
+--   record_id -> RecordId hex TEXT (not tokenized)
+--   title     -> TEXT (empty for comments)
+--   content   -> TEXT (rule_section.markdown, blurb.content, comment.content)
+-- Existing rows are indexed here so the index starts complete.
+CREATE VIRTUAL TABLE search_index USING fts5(
+    kind UNINDEXED,
+    record_id UNINDEXED,
+    title,
+    content,
+    tokenize = 'unicode61'
+);
+INSERT INTO search_index (kind, record_id, title, content)
+    SELECT 'rule_section', id, title, markdown FROM rule_section;
+INSERT INTO search_index (kind, record_id, title, content)
+    SELECT 'blurb', id, title, content FROM blurb;
+INSERT INTO search_index (kind, record_id, title, content)
+    SELECT 'comment', id, '', content FROM comment;
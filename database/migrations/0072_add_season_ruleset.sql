@@ -0,0 +1,8 @@
+-- 0072_add_season_ruleset.sql
+-- The ruleset a season is played under (model/season_ruleset.go).
+--   season.ruleset           -> RulesetId hex TEXT (all zeros when unassigned;
+--                               the exact revision in force once pinned)
+--   season.ruleset_pinned_at -> RFC3339 TEXT (zero time until the season
+--                               starts and its revision is pinned)
+ALTER TABLE season ADD COLUMN ruleset TEXT NOT NULL DEFAULT '0000000000000000';
+ALTER TABLE season ADD COLUMN ruleset_pinned_at TEXT NOT NULL DEFAULT '0001-01-01T00:00:00Z';
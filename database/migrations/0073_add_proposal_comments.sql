@@ -0,0 +1,6 @@
+-- 0073_add_proposal_comments.sql
+-- Discussion threads on commissioner proposals
+-- (model/commissioner_proposal_comment.go). A comment is on exactly one of a
+-- blurb or a proposal.
+--   comment.proposal -> RecordId hex TEXT (all zeros for a comment on a blurb)
+ALTER TABLE comment ADD COLUMN proposal TEXT NOT NULL DEFAULT '0000000000000000';
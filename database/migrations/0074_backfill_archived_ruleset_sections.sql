@@ -0,0 +1,20 @@
+-- 0074_backfill_archived_ruleset_sections.sql
+-- Section relations for ruleset revisions archived before 0066
+-- (model/ruleset_diff.go). Those revisions lost their ruleset_section rows
+-- when they were superseded, so a diff against them showed every section as
+-- added. Each one is given the sections of the nearest later revision that
+-- still has rows, following superseded_by. Sections were edited in place
+-- back then, so this is the closest record of the revision that survives.
+WITH RECURSIVE chain(origin, current, date) AS (
+    SELECT r.id, r.superseded_by, r.date FROM ruleset r
+    WHERE r.superseded_by != '0000000000000000'
+      AND NOT EXISTS (SELECT 1 FROM ruleset_section rs WHERE rs.ruleset_id = r.id)
+    UNION ALL
+    SELECT c.origin, n.superseded_by, c.date FROM chain c
+    JOIN ruleset n ON n.id = c.current
+    WHERE n.superseded_by != '0000000000000000'
+      AND NOT EXISTS (SELECT 1 FROM ruleset_section rs WHERE rs.ruleset_id = c.current)
+)
+INSERT INTO ruleset_section (id, ruleset_id, section_id, section_index, created_at, updated_at)
+    SELECT lower(hex(randomblob(8))), c.origin, rs.section_id, rs.section_index, c.date, c.date
+    FROM chain c JOIN ruleset_section rs ON rs.ruleset_id = c.current;
@@ -0,0 +1,11 @@
+-- 0075_add_score_claim_no_winner.sql
+-- Claims with no winner in score reports (model/score_report.go).
+--   reported_no_winner -> INTEGER (bool; the reported claim is a double default)
+--   disputed_no_winner -> INTEGER (bool; the disputed claim is a double default)
+-- Reports of matches already decided by a double default (match.status 3 =
+-- lost, match.outcome 3 = double default) were stored as lost; they are
+-- marked as having no winner.
+ALTER TABLE score_report ADD COLUMN reported_no_winner INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE score_report ADD COLUMN disputed_no_winner INTEGER NOT NULL DEFAULT 0;
+UPDATE score_report SET reported_no_winner = 1, reported_won = 0
+WHERE match_id IN (SELECT id FROM match WHERE status = 3 AND outcome = 3);
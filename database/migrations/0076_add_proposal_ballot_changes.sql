@@ -0,0 +1,25 @@
+-- 0076_add_proposal_ballot_changes.sql
+-- Log of ballots cast on multi-option commissioner proposals
+-- (model/commissioner_proposal_ballot.go).
+-- The commissioner_proposal_ballot_change table holds one row per ranked
+-- choice of every ballot cast, including replaced ones, matching the
+-- CommissionerProposalBallotChange record shape; its table name equals
+-- record.Type() ("commissioner_proposal_ballot_change").
+--   id          -> RecordId hex TEXT primary key
+--   proposal_id -> RecordId hex TEXT
+--   user_id     -> UserId hex TEXT
+--   option_id   -> RecordId hex TEXT
+--   rank        -> INTEGER (0 = first choice)
+--   cast_at     -> RFC3339 TEXT (shared by the choices of one ballot)
+-- Ballots already cast are logged as they stand.
+CREATE TABLE commissioner_proposal_ballot_change (
+    id          TEXT PRIMARY KEY,   -- RecordId hex string
+    proposal_id TEXT NOT NULL,      -- RecordId hex string
+    user_id     TEXT NOT NULL,      -- UserId hex string
+    option_id   TEXT NOT NULL,      -- RecordId hex string
+    rank        INTEGER NOT NULL,
+    cast_at     TEXT NOT NULL       -- RFC3339
+);
+INSERT INTO commissioner_proposal_ballot_change (id, proposal_id, user_id, option_id, rank, cast_at)
+SELECT lower(hex(randomblob(8))), proposal_id, user_id, option_id, rank, cast_at
+FROM commissioner_proposal_ballot;
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// SearchDocument is the text of one CrudRecord as stored in a full-text
+// index. Kind is the record's Type(), so a hit can be loaded back with the
+// matching record type.
+type SearchDocument struct {
+	Kind    string
+	Id      RecordId
+	Title   string
+	Content string
+}
+
+// SearchHit is one record matching a full-text query. Lower Rank is a
+// better match; ranks are only comparable within one result list.
+type SearchHit struct {
+	Kind string   `json:"kind"`
+	Id   RecordId `json:"id"`
+	Rank float64  `json:"rank"`
+}
+
+// Searchable is implemented by CrudRecords whose text is kept in the
+// full-text index. The records index themselves from their lifecycle hooks
+// via IndexRecord / UnindexRecord.
+type Searchable interface {
+	CrudRecord
+	SearchDocument() SearchDocument
+}
+
+// TextSearcher is implemented by Providers that maintain a full-text index.
+// The SQLite provider uses an FTS5 virtual table; the in-memory provider
+// keeps a simple inverted index.
+type TextSearcher interface {
+	// IndexText adds the document to the index, replacing any previous
+	// document for the same kind and id
+	IndexText(ctx context.Context, doc SearchDocument) error
+
+	// RemoveText drops the document for the given kind and id (if any)
+	RemoveText(ctx context.Context, kind string, id RecordId) error
+
+	// SearchText returns the documents containing every term of the query,
+	// best match first. A limit of zero or less returns every match.
+	SearchText(ctx context.Context, query string, limit int) ([]SearchHit, error)
+}
+
+// ErrSearchUnsupported is returned by Search when the Provider does not
+// implement TextSearcher.
+var ErrSearchUnsupported = errors.New("database provider does not support full-text search")
+
+// IndexRecord puts the record's current text into the Provider's full-text
+// index. Providers without an index are silently skipped, so hooks can call
+// this unconditionally.
+func IndexRecord(ctx context.Context, db Provider, record Searchable) error {
+	s, ok := db.(TextSearcher)
+	if !ok {
+		return nil
+	}
+	return s.IndexText(ctx, record.SearchDocument())
+}
+
+// UnindexRecord removes the record from the Provider's full-text index, if
+// the Provider has one.
+func UnindexRecord(ctx context.Context, db Provider, record CrudRecord) error {
+	s, ok := db.(TextSearcher)
+	if !ok {
+		return nil
+	}
+	return s.RemoveText(ctx, record.Type(), record.GetId())
+}
+
+// Search runs a full-text query against the Provider's index. It does no
+// access control; callers filter the hits by each record's AccessibleTo.
+func Search(ctx context.Context, db Provider, query string, limit int) ([]SearchHit, error) {
+	s, ok := db.(TextSearcher)
+	if !ok {
+		return nil, ErrSearchUnsupported
+	}
+	if len(SearchTerms(query)) == 0 {
+		return []SearchHit{}, nil
+	}
+	return s.SearchText(ctx, query, limit)
+}
+
+// SearchTerms splits text into the lowercase letter/digit runs that the
+// index is built from. Query terms are matched as prefixes of these.
+func SearchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
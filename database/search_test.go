@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// searchProviders returns a fresh Provider of each kind with a full-text
+// index: the in-memory fallback and SQLite's FTS5 table.
+func searchProviders(t *testing.T) map[string]Provider {
+	t.Helper()
+	p, err := NewSqliteDbProvider(filepath.Join(t.TempDir(), "search.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		p.(*SqliteDbProvider).Disconnect()
+	})
+	return map[string]Provider{
+		"memory": NewUnitTestDBProvider(),
+		"sqlite": p,
+	}
+}
+
+func searchIds(t *testing.T, db Provider, query string) []RecordId {
+	t.Helper()
+	hits, err := Search(context.Background(), db, query, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]RecordId, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.Id)
+	}
+	return ids
+}
+
+func TestSearchIndex(t *testing.T) {
+	for name, db := range searchProviders(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			s := db.(TextSearcher)
+			docs := []SearchDocument{
+				{Kind: "a", Id: 100, Title: "Court etiquette", Content: "Call the score before serving."},
+				{Kind: "a", Id: 101, Title: "Scoring", Content: "Sets are played to six games; court changes on odd games."},
+				{Kind: "b", Id: 100, Content: "Great serving last week!"},
+			}
+			for _, doc := range docs {
+				if err := s.IndexText(ctx, doc); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// kind and id together identify a document
+			if ids := searchIds(t, db, "serving"); len(ids) != 2 {
+				t.Fatalf("expected 2 hits for 'serving', got %v", ids)
+			}
+			// terms are prefixes and are ANDed
+			if ids := searchIds(t, db, "serv SCORE"); len(ids) != 1 || ids[0] != 100 {
+				t.Fatalf("expected only 100 for 'serv SCORE', got %v", ids)
+			}
+			// a title match outranks a body match
+			if ids := searchIds(t, db, "court"); len(ids) != 2 || ids[0] != 100 {
+				t.Fatalf("expected 100 first for 'court', got %v", ids)
+			}
+			// query syntax is taken literally
+			if ids := searchIds(t, db, `"court" OR NOT*`); len(ids) != 0 {
+				t.Fatalf("expected no hits, got %v", ids)
+			}
+			if ids := searchIds(t, db, "  ... "); len(ids) != 0 {
+				t.Fatalf("expected no hits for an empty query, got %v", ids)
+			}
+
+			// re-indexing replaces the previous text
+			if err := s.IndexText(ctx, SearchDocument{Kind: "a", Id: 101, Title: "Tiebreaks", Content: "First to seven."}); err != nil {
+				t.Fatal(err)
+			}
+			if ids := searchIds(t, db, "court"); len(ids) != 1 {
+				t.Fatalf("expected stale text to be replaced, got %v", ids)
+			}
+			if ids := searchIds(t, db, "tiebreak"); len(ids) != 1 || ids[0] != 101 {
+				t.Fatalf("expected 101 for 'tiebreak', got %v", ids)
+			}
+
+			if err := s.RemoveText(ctx, "a", 100); err != nil {
+				t.Fatal(err)
+			}
+			if ids := searchIds(t, db, "serving"); len(ids) != 1 {
+				t.Fatalf("expected 1 hit after removal, got %v", ids)
+			}
+
+			hits, err := Search(ctx, db, "s", 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hits) != 1 {
+				t.Fatalf("expected limit to cap hits at 1, got %d", len(hits))
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"strings"
+)
+
+// The SQLite full-text index is the FTS5 virtual table search_index created
+// by migration 0071. Title matches are weighted double, matching the
+// in-memory fallback.
+
+func (s *SqliteDbProvider) IndexText(ctx context.Context, doc SearchDocument) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM search_index WHERE kind = ? AND record_id = ?", doc.Kind, doc.Id.String()); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO search_index (kind, record_id, title, content) VALUES (?, ?, ?, ?)",
+		doc.Kind, doc.Id.String(), doc.Title, doc.Content); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SqliteDbProvider) RemoveText(ctx context.Context, kind string, id RecordId) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM search_index WHERE kind = ? AND record_id = ?", kind, id.String())
+	return err
+}
+
+func (s *SqliteDbProvider) SearchText(ctx context.Context, query string, limit int) ([]SearchHit, error) {
+	// quote each term so FTS5 query syntax in user input is taken literally,
+	// and make it a prefix match; space-separated terms are ANDed
+	terms := SearchTerms(query)
+	if len(terms) == 0 {
+		return []SearchHit{}, nil
+	}
+	for i, term := range terms {
+		terms[i] = `"` + term + `"*`
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT kind, record_id, bm25(search_index, 0, 0, 2, 1) AS score FROM search_index "+
+			"WHERE search_index MATCH ? ORDER BY score, kind, record_id LIMIT ?",
+		strings.Join(terms, " "), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []SearchHit{}
+	for rows.Next() {
+		var hit SearchHit
+		var id string
+		if err := rows.Scan(&hit.Kind, &id, &hit.Rank); err != nil {
+			return nil, err
+		}
+		if hit.Id, err = RecordIdFromString(id); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
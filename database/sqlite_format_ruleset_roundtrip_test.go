@@ -233,6 +233,7 @@ func TestRulesetRoundTrip(t *testing.T) {
 	r.Revision = 1
 	r.Date = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	r.Owner = createTestUser(t, p).ID
+	r.Author = r.Owner
 	created, err := database.CreateOne(ctx, p, r)
 	if err != nil {
 		t.Fatalf("CreateOne(ruleset): %v", err)
@@ -422,6 +423,7 @@ func createTestRuleset(t *testing.T, p database.Provider) *model.Ruleset {
 	r := model.NewRuleset()
 	r.Name = "Test Ruleset"
 	r.Owner = createTestUser(t, p).ID
+	r.Author = r.Owner
 	v, err := database.CreateOne(context.Background(), p, r)
 	if err != nil {
 		t.Fatalf("create ruleset: %v", err)
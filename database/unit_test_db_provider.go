@@ -15,6 +15,7 @@ type RecordCache map[RecordId]CrudRecord // Map from a RecordId to the CrudRecor
 type UnitTestDbProvider struct {
 	Caches map[string]RecordCache // map from CrudRecord.Type to a RecordCache
 	mu     sync.Mutex
+	search memorySearchIndex // full-text fallback for the SQLite FTS5 index
 }
 
 func NewUnitTestDBProvider() *UnitTestDbProvider {
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type searchKey struct {
+	kind string
+	id   RecordId
+}
+
+// memorySearchIndex is the in-memory fallback for the SQLite FTS5 index: an
+// inverted index from each term to the documents containing it and how
+// often. Title terms count double so a title match outranks a passing
+// mention in the body. The zero value is ready to use.
+type memorySearchIndex struct {
+	mu       sync.Mutex
+	postings map[string]map[searchKey]int // map from term to per-document weight
+	terms    map[searchKey][]string       // map from document to the terms indexed for it
+}
+
+// remove drops a document's postings. The caller must hold m.mu.
+func (m *memorySearchIndex) remove(key searchKey) {
+	for _, term := range m.terms[key] {
+		delete(m.postings[term], key)
+		if len(m.postings[term]) == 0 {
+			delete(m.postings, term)
+		}
+	}
+	delete(m.terms, key)
+}
+
+func (u *UnitTestDbProvider) IndexText(ctx context.Context, doc SearchDocument) error {
+	m := &u.search
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.postings == nil {
+		m.postings = make(map[string]map[searchKey]int)
+		m.terms = make(map[searchKey][]string)
+	}
+
+	key := searchKey{kind: doc.Kind, id: doc.Id}
+	m.remove(key)
+
+	weights := map[string]int{}
+	for _, term := range SearchTerms(doc.Title) {
+		weights[term] += 2
+	}
+	for _, term := range SearchTerms(doc.Content) {
+		weights[term]++
+	}
+	for term, weight := range weights {
+		if m.postings[term] == nil {
+			m.postings[term] = make(map[searchKey]int)
+		}
+		m.postings[term][key] = weight
+		m.terms[key] = append(m.terms[key], term)
+	}
+	return nil
+}
+
+func (u *UnitTestDbProvider) RemoveText(ctx context.Context, kind string, id RecordId) error {
+	m := &u.search
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(searchKey{kind: kind, id: id})
+	return nil
+}
+
+func (u *UnitTestDbProvider) SearchText(ctx context.Context, query string, limit int) ([]SearchHit, error) {
+	m := &u.search
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// every query term must prefix-match some term of the document; a
+	// document's score is the summed weight of everything it matched
+	var scores map[searchKey]int
+	for _, prefix := range SearchTerms(query) {
+		matched := map[searchKey]int{}
+		for term, docs := range m.postings {
+			if !strings.HasPrefix(term, prefix) {
+				continue
+			}
+			for key, weight := range docs {
+				matched[key] += weight
+			}
+		}
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		for key := range scores {
+			if weight, ok := matched[key]; ok {
+				scores[key] += weight
+			} else {
+				delete(scores, key)
+			}
+		}
+	}
+
+	hits := make([]SearchHit, 0, len(scores))
+	for key, score := range scores {
+		hits = append(hits, SearchHit{Kind: key.kind, Id: key.id, Rank: -float64(score)})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank < hits[j].Rank
+		}
+		if hits[i].Kind != hits[j].Kind {
+			return hits[i].Kind < hits[j].Kind
+		}
+		return hits[i].Id < hits[j].Id
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
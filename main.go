@@ -27,7 +27,11 @@ import (
 	"intraclub/route/ruleset"
 	"intraclub/route/schedule"
 	"intraclub/route/scoringstructure"
+	"intraclub/route/search"
+	"intraclub/route/season"
 	"intraclub/route/seasoncommissioner"
+	"intraclub/route/stats"
+	"intraclub/route/substitute"
 	"intraclub/route/team"
 	"intraclub/route/user"
 	"intraclub/route/week"
@@ -136,6 +140,9 @@ func main() {
 	formatRatings := api.RouteFamily[*format.SetPossibleRatingsBody]{DatabaseProvider: db}
 	formatRatings.Handle(rg, format.GetPossibleRatings{}, format.SetPossibleRatings{})
 
+	formatLines := api.RouteFamily[*format.SetLinesBody]{DatabaseProvider: db}
+	formatLines.Handle(rg, format.GetLines{}, format.SetLines{})
+
 	rulesets := api.NewCrudCommon(model.NewRuleset, false, db)
 	rulesets.HandleRouteTypes(rg, api.CrudWrapperFunctionAll...)
 
@@ -157,6 +164,26 @@ func main() {
 	amendRulesetSections := api.RouteFamily[*model.RuleAmendment]{DatabaseProvider: db}
 	amendRulesetSections.Handle(rg, ruleset.AmendSections{})
 
+	// Archived revisions keep the sections they had, so any two revisions of
+	// a ruleset can be compared section by section.
+	rulesetDiff := api.RouteFamily[*ruleset.DiffQuery]{DatabaseProvider: db}
+	rulesetDiff.Handle(rg, ruleset.DiffRulesets{})
+
+	// Every revision records the revision it was made from, how and by whom,
+	// so a ruleset's history (forks included) can be browsed as a tree or
+	// read as it stood at a past date.
+	rulesetHistory := api.RouteFamily[*ruleset.HistoryQuery]{DatabaseProvider: db}
+	rulesetHistory.Handle(rg, ruleset.GetRulesetHistory{}, ruleset.GetRulesetAsOf{})
+
+	// A revision can be exported as one Markdown or HTML document for
+	// publishing the club rules.
+	rg.GET(api.AppendPathId(ruleset.BaseRoute)+"/export", ruleset.ExportRuleset(db))
+
+	// Existing rules kept elsewhere can be imported from a Markdown document,
+	// split into sections on its headings, after previewing the split.
+	rulesetImport := api.RouteFamily[*ruleset.ImportBody]{DatabaseProvider: db}
+	rulesetImport.Handle(rg, ruleset.PreviewImport{}, ruleset.ImportRuleset{})
+
 	// Commissioner proposals are the "manage club rules" feature: a season
 	// commissioner proposes a rule change / administrative action and the
 	// season's participants (commissioners + team captains) ratify it by
@@ -168,8 +195,30 @@ func main() {
 	proposals.HandleRouteTypes(rg, api.CrudWrapperFunctionAll...)
 	proposalVotes := api.NewCrudCommon(func() *model.CommissionerProposalVote { return &model.CommissionerProposalVote{} }, false, db)
 	proposalVotes.HandleRouteTypes(rg, api.CrudWrapperFunctionGetOne, api.CrudWrapperFunctionGetMany)
+	proposalAmendments := api.NewCrudCommon(func() *model.CommissionerProposalAmendment { return &model.CommissionerProposalAmendment{} }, false, db)
+	proposalAmendments.HandleRouteTypes(rg, api.CrudWrapperFunctionGetOne, api.CrudWrapperFunctionGetMany)
+	proposalVoteChanges := api.NewCrudCommon(func() *model.CommissionerProposalVoteChange { return &model.CommissionerProposalVoteChange{} }, false, db)
+	proposalVoteChanges.HandleRouteTypes(rg, api.CrudWrapperFunctionGetOne, api.CrudWrapperFunctionGetMany)
+	proposalOptions := api.NewCrudCommon(func() *model.CommissionerProposalOption { return &model.CommissionerProposalOption{} }, false, db)
+	proposalOptions.HandleRouteTypes(rg, api.CrudWrapperFunctionGetOne, api.CrudWrapperFunctionGetMany)
+	proposalBallots := api.NewCrudCommon(func() *model.CommissionerProposalBallot { return &model.CommissionerProposalBallot{} }, false, db)
+	proposalBallots.HandleRouteTypes(rg, api.CrudWrapperFunctionGetOne, api.CrudWrapperFunctionGetMany)
+	proposalBallotChanges := api.NewCrudCommon(func() *model.CommissionerProposalBallotChange { return &model.CommissionerProposalBallotChange{} }, false, db)
+	proposalBallotChanges.HandleRouteTypes(rg, api.CrudWrapperFunctionGetOne, api.CrudWrapperFunctionGetMany)
 	proposal.RegisterRoutes(rg, db)
 
+	// proposals with a voting deadline are closed in the background once it
+	// passes, and their participants are emailed the outcome (or, with no
+	// mail domain configured, it is only logged)
+	notify := proposal.Notifier(proposal.LogNotifier)
+	if cfg.mailDomain != "" {
+		notify, err = proposal.MailNotifier(cfg.mailDomain)
+		if err != nil {
+			log.Fatalf("failed to initialize proposal mailer: %v", err)
+		}
+	}
+	go proposal.RunCloser(context.Background(), db, proposalCloseInterval, notify)
+
 	rg.GET("/draft_order_patterns", model.GetDraftOrderPatterns)
 
 	draft.RegisterRoutes(rg, db)
@@ -186,6 +235,12 @@ func main() {
 	teamRatings := api.NewCrudCommon(func() *model.TeamRating { return &model.TeamRating{} }, false, db)
 	teamRatings.HandleRouteTypes(rg, api.CrudWrapperFunctionGetOne, api.CrudWrapperFunctionGetMany)
 
+	// Commissioners assign the ruleset a season is played under; the revision
+	// in force is pinned when the season starts and afterwards only moves on
+	// through accepted commissioner proposals.
+	season.RegisterRoutes(rg, db)
+	go season.RunPinner(context.Background(), db, seasonRulesetPinInterval)
+
 	// SeasonLateAdditions link a Season to Users added after the draft was
 	// completed. The generic surface is read-only (the season page shows them);
 	// writes go exclusively through the custom routes in route/lateaddition,
@@ -220,6 +275,11 @@ func main() {
 
 	lineup.RegisterRoutes(rg, db)
 
+	// Substitutes opt in to a week's sub pool; captains post sub requests
+	// which a substitute claims and the captain approves. An approved sub may
+	// be placed in the team's lineup for that week only.
+	substitute.RegisterRoutes(rg, db)
+
 	// Match scoring is driven through the custom route/match surface (generate,
 	// score, complete, week score sheet, standings); the underlying records are
 	// also exposed read-only here so the season page can render a score sheet
@@ -240,6 +300,10 @@ func main() {
 	matchEditors := api.NewCrudCommon(func() *model.MatchEditor { return &model.MatchEditor{} }, false, db)
 	matchEditors.HandleRouteTypes(rg, api.CrudWrapperFunctionGetOne, api.CrudWrapperFunctionGetMany)
 
+	// Partner / pairing statistics are computed from completed matches and
+	// are public, like the standings.
+	stats.RegisterRoutes(rg, db)
+
 	playoffStructures := api.NewCrudCommon(model.NewPlayoffStructure, false, db)
 	playoffStructures.HandleRouteTypes(rg, api.CrudWrapperFunctionAll...)
 
@@ -254,11 +318,12 @@ func main() {
 	blurbs := api.NewCrudCommon(model.NewBlurb, false, db)
 	blurbs.HandleRouteTypes(rg, api.CrudWrapperFunctionAll...)
 
-	// Comments attach to a blurb and support replies; reactions attach to
-	// comments. Generic CRUD covers create / list / read / update / delete
-	// (edits are gated by the model's EditableBy: owner / blurb owner /
-	// season commissioners / sysadmin); reactions go through the custom routes
-	// in route/comment.
+	// Comments attach to a blurb or a commissioner proposal and support
+	// replies; reactions attach to comments. Generic CRUD covers create / list
+	// / read / update / delete (edits are gated by the model's EditableBy:
+	// owner / blurb owner / season commissioners / sysadmin, and reads of a
+	// proposal's thread by its eligible voters); reactions go through the
+	// custom routes in route/comment.
 	comments := api.NewCrudCommon(model.NewComment, false, db)
 	comments.HandleRouteTypes(rg, api.CrudWrapperFunctionAll...)
 
@@ -284,6 +349,12 @@ func main() {
 	commentReactionRoutes := api.RouteFamily[*comment.ReactionBody]{DatabaseProvider: db}
 	commentReactionRoutes.Handle(rg, comment.React{}, comment.Unreact{})
 
+	// Rule sections, blurbs and comments keep themselves in a full-text index
+	// (FTS5 on SQLite) from their lifecycle hooks; search results are limited
+	// to what the caller can access.
+	searchRoutes := api.RouteFamily[*search.SearchQuery]{DatabaseProvider: db}
+	searchRoutes.Handle(rg, search.Search{})
+
 	err = r.Run(cfg.addr)
 	if err != nil {
 		panic(err)
@@ -298,6 +369,7 @@ type serverConfig struct {
 	dbPath          string
 	slowMode        bool
 	slowModeLatency time.Duration
+	mailDomain      string
 }
 
 // providerConfig converts the server's parsed database settings into the
@@ -318,6 +390,7 @@ func parseFlags() serverConfig {
 	dbPath := flag.String("db-path", "", "Path to the SQLite database file; falls back to INTRACLUB_DB_PATH env")
 	slowMode := flag.Bool("slow-mode", false, "Inject artificial latency into every API request to simulate a slow / high-RTT connection; falls back to INTRACLUB_SLOW_MODE env")
 	slowModeLatency := flag.Duration("slow-mode-latency", defaultSlowModeLatency, "Per-request artificial latency to inject when slow mode is enabled; falls back to INTRACLUB_SLOW_MODE_LATENCY env")
+	mailDomain := flag.String("mail-domain", "", "Domain to send notification email from, e.g. rcintra.club; falls back to INTRACLUB_MAIL_DOMAIN env")
 	flag.Parse()
 
 	jwtLifetime, err := resolveJwtLifetime(*jwtLifetimeFlag)
@@ -351,6 +424,7 @@ func parseFlags() serverConfig {
 		dbPath:          resolveDBPath(*dbPath),
 		slowMode:        slowModeEnabled,
 		slowModeLatency: latency,
+		mailDomain:      resolveMailDomain(*mailDomain),
 	}
 }
 
@@ -363,6 +437,24 @@ func resolveDBPath(flagPath string) string {
 	return os.Getenv("INTRACLUB_DB_PATH")
 }
 
+// resolveMailDomain returns the domain notification email is sent from,
+// preferring the explicit --mail-domain flag and falling back to the
+// INTRACLUB_MAIL_DOMAIN env var. Empty means notifications are only logged.
+func resolveMailDomain(flagDomain string) string {
+	if flagDomain != "" {
+		return flagDomain
+	}
+	return os.Getenv("INTRACLUB_MAIL_DOMAIN")
+}
+
+// proposalCloseInterval is how often proposals past their voting deadline
+// are looked for and closed.
+const proposalCloseInterval = time.Minute
+
+// seasonRulesetPinInterval is how often seasons that have started are looked
+// for and their ruleset revision pinned.
+const seasonRulesetPinInterval = time.Minute
+
 // resolveJwtLifetime returns the JWT token lifetime, preferring the explicit
 // --jwt-lifetime flag and falling back to the INTRACLUB_JWT_LIFETIME env var,
 // then to the package default of api.JwtLifetime.
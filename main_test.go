@@ -44,6 +44,29 @@ func TestResolveDBPath(t *testing.T) {
 	})
 }
 
+func TestResolveMailDomain(t *testing.T) {
+	prev, hadPrev := os.LookupEnv("INTRACLUB_MAIL_DOMAIN")
+	t.Cleanup(func() {
+		if hadPrev {
+			os.Setenv("INTRACLUB_MAIL_DOMAIN", prev)
+		} else {
+			os.Unsetenv("INTRACLUB_MAIL_DOMAIN")
+		}
+	})
+
+	os.Setenv("INTRACLUB_MAIL_DOMAIN", "env.example")
+	if got := resolveMailDomain("flag.example"); got != "flag.example" {
+		t.Errorf("resolveMailDomain = %q, want flag domain", got)
+	}
+	if got := resolveMailDomain(""); got != "env.example" {
+		t.Errorf("resolveMailDomain = %q, want env domain", got)
+	}
+	os.Unsetenv("INTRACLUB_MAIL_DOMAIN")
+	if got := resolveMailDomain(""); got != "" {
+		t.Errorf("resolveMailDomain = %q, want empty", got)
+	}
+}
+
 func TestIsLoopbackAddress(t *testing.T) {
 	tests := []struct {
 		name string
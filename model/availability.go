@@ -185,6 +185,24 @@ func (a *Availability) NewRecord() database.CrudRecord {
 	return new(Availability)
 }
 
+// PostCreate invalidates cached career statistics, which include attendance.
+func (a *Availability) PostCreate(ctx context.Context, db database.Provider) error {
+	InvalidateCareerStats()
+	return nil
+}
+
+// PostUpdate invalidates cached career statistics.
+func (a *Availability) PostUpdate(ctx context.Context, db database.Provider) error {
+	InvalidateCareerStats()
+	return nil
+}
+
+// PostDelete invalidates cached career statistics.
+func (a *Availability) PostDelete(ctx context.Context, db database.Provider) error {
+	InvalidateCareerStats()
+	return nil
+}
+
 func GetAvailabilityForTeam(ctx context.Context, db database.Provider, teamId TeamId, draftId DraftId) (map[database.UserId][]*Availability, error) {
 	output := make(map[database.UserId][]*Availability)
 	team, err := database.GetExistingRecordById(ctx, db, &Team{}, teamId.RecordId())
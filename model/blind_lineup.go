@@ -0,0 +1,118 @@
+package model
+
+import (
+	"context"
+
+	"intraclub/database"
+)
+
+// GetWeeklyOpponent returns the Team scheduled to play the given team in the
+// Season's WeeklyMatchup for the Week. It returns InvalidRecordId (and no
+// error) if the team has a bye or is not scheduled that week.
+func GetWeeklyOpponent(ctx context.Context, db database.Provider, seasonId SeasonId, weekId WeekId, teamId TeamId) (TeamId, error) {
+	weeklyMatchups, err := database.GetAllWhere[*WeeklyMatchup](ctx, db, func(_ context.Context, w *WeeklyMatchup) bool {
+		return w.SeasonId == seasonId && w.WeekId == weekId
+	})
+	if err != nil {
+		return TeamId(database.InvalidRecordId), err
+	}
+	for _, weeklyMatchup := range weeklyMatchups {
+		matchups, err := weeklyMatchup.GetMatchups(ctx, db)
+		if err != nil {
+			return TeamId(database.InvalidRecordId), err
+		}
+		for _, matchup := range matchups {
+			if matchup.Bye {
+				continue
+			}
+			if matchup.HomeTeam == teamId {
+				return matchup.AwayTeam, nil
+			}
+			if matchup.AwayTeam == teamId {
+				return matchup.HomeTeam, nil
+			}
+		}
+	}
+	return TeamId(database.InvalidRecordId), nil
+}
+
+// GetOpponent returns the Team this Lineup's team plays in its Week, or
+// InvalidRecordId (and no error) if it isn't scheduled to play anyone.
+func (l *Lineup) GetOpponent(ctx context.Context, db database.Provider) (TeamId, error) {
+	week, err := database.GetExistingRecordById(ctx, db, &Week{}, l.WeekId.RecordId())
+	if err != nil {
+		return TeamId(database.InvalidRecordId), err
+	}
+	season, err := week.GetSeason(ctx, db)
+	if err != nil || season == nil {
+		return TeamId(database.InvalidRecordId), err
+	}
+	return GetWeeklyOpponent(ctx, db, season.ID, l.WeekId, l.TeamId)
+}
+
+// IsRevealed reports whether this Lineup's pairings may be shown outside of
+// its team. Lineups are always revealed unless the Season uses blind lineups,
+// in which case both this Lineup and the opponent's Lineup for the Week must
+// be confirmed, so that both teams' lineups reveal at the same moment.
+func (l *Lineup) IsRevealed(ctx context.Context, db database.Provider) (bool, error) {
+	week, err := database.GetExistingRecordById(ctx, db, &Week{}, l.WeekId.RecordId())
+	if err != nil {
+		return false, err
+	}
+	season, err := week.GetSeason(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	if season == nil || !season.BlindLineups {
+		return true, nil
+	}
+	if !l.Confirmed {
+		return false, nil
+	}
+
+	opponent, err := GetWeeklyOpponent(ctx, db, season.ID, l.WeekId, l.TeamId)
+	if err != nil {
+		return false, err
+	}
+	if opponent == TeamId(database.InvalidRecordId) {
+		return false, nil
+	}
+	opponentLineups, err := database.GetAllWhere[*Lineup](ctx, db, func(_ context.Context, other *Lineup) bool {
+		return other.TeamId == opponent && other.WeekId == l.WeekId
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(opponentLineups) > 0 && opponentLineups[0].Confirmed, nil
+}
+
+// PairingsLocked reports whether this Lineup's pairings can only change with
+// a commissioner's approval before it is official. That is the case once a
+// blind Lineup is revealed, so a captain can't answer the opponent's pairings
+// by restacking their own.
+func (l *Lineup) PairingsLocked(ctx context.Context, db database.Provider) (bool, error) {
+	week, err := database.GetExistingRecordById(ctx, db, &Week{}, l.WeekId.RecordId())
+	if err != nil {
+		return false, err
+	}
+	season, err := week.GetSeason(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	if season == nil || !season.BlindLineups {
+		return false, nil
+	}
+	return l.IsRevealed(ctx, db)
+}
+
+// CanUserViewPairings reports whether the User may see this Lineup's
+// pairings: members of the Lineup's team always can, and everyone else can
+// once the Lineup is revealed.
+func (l *Lineup) CanUserViewPairings(ctx context.Context, db database.Provider, userId database.UserId) (bool, error) {
+	for _, member := range AccessibleByTeamMembers(ctx, db, l.TeamId) {
+		if member == userId {
+			return true, nil
+		}
+	}
+	return l.IsRevealed(ctx, db)
+}
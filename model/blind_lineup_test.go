@@ -0,0 +1,121 @@
+package model
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineupRevealedOutsideBlindSeason(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, w := newStoredWeeklyMatchup(t, db)
+	teams, err := season.GetTeams(ctx, db)
+	require.NoError(t, err)
+
+	lineup, err := database.CreateOne(ctx, db, &Lineup{TeamId: teams[0].ID, WeekId: w.WeekId})
+	require.NoError(t, err)
+	revealed, err := lineup.IsRevealed(ctx, db)
+	require.NoError(t, err)
+	require.True(t, revealed)
+	locked, err := lineup.PairingsLocked(ctx, db)
+	require.NoError(t, err)
+	require.False(t, locked)
+
+	// the pairing is visible to its team and the team it plays, but no one else
+	opponent, err := lineup.GetOpponent(ctx, db)
+	require.NoError(t, err)
+	require.NotEqual(t, TeamId(database.InvalidRecordId), opponent)
+	pairing := &LineupPairing{LineupId: lineup.ID, TeamId: teams[0].ID}
+	accessible := pairing.AccessibleTo(ctx, db)
+	for _, team := range []TeamId{teams[0].ID, opponent} {
+		for _, member := range AccessibleByTeamMembers(ctx, db, team) {
+			require.True(t, slices.Contains(accessible, member))
+		}
+	}
+	require.False(t, slices.Contains(accessible, database.EveryoneUserId))
+	require.False(t, slices.Contains(accessible, newStoredUser(t, db).ID))
+}
+
+func TestBlindLineupRevealsWhenBothConfirmed(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, w := newStoredWeeklyMatchup(t, db)
+	season.BlindLineups = true
+	require.NoError(t, database.UpdateOne(ctx, db, season))
+	matchups, err := w.GetMatchups(ctx, db)
+	require.NoError(t, err)
+	homeTeam, err := database.GetExistingRecordById(ctx, db, &Team{}, matchups[0].HomeTeam.RecordId())
+	require.NoError(t, err)
+	awayTeam, err := database.GetExistingRecordById(ctx, db, &Team{}, matchups[0].AwayTeam.RecordId())
+	require.NoError(t, err)
+
+	home, err := database.CreateOne(ctx, db, &Lineup{TeamId: homeTeam.ID, WeekId: w.WeekId, Confirmed: true})
+	require.NoError(t, err)
+	away, err := database.CreateOne(ctx, db, &Lineup{TeamId: awayTeam.ID, WeekId: w.WeekId})
+	require.NoError(t, err)
+
+	homeCaptain, err := homeTeam.GetCaptain(ctx, db)
+	require.NoError(t, err)
+	awayCaptain, err := awayTeam.GetCaptain(ctx, db)
+	require.NoError(t, err)
+
+	// only the home team has confirmed, so its lineup stays hidden from the
+	// away team
+	revealed, err := home.IsRevealed(ctx, db)
+	require.NoError(t, err)
+	require.False(t, revealed)
+	canView, err := home.CanUserViewPairings(ctx, db, homeCaptain)
+	require.NoError(t, err)
+	require.True(t, canView)
+	canView, err = home.CanUserViewPairings(ctx, db, awayCaptain)
+	require.NoError(t, err)
+	require.False(t, canView)
+
+	pairing := &LineupPairing{LineupId: home.ID, TeamId: homeTeam.ID}
+	accessible := pairing.AccessibleTo(ctx, db)
+	require.True(t, slices.Contains(accessible, homeCaptain))
+	require.False(t, slices.Contains(accessible, awayCaptain))
+	require.False(t, slices.Contains(accessible, database.EveryoneUserId))
+
+	away.Confirmed = true
+	require.NoError(t, database.UpdateOne(ctx, db, away))
+	for _, lineup := range []*Lineup{home, away} {
+		revealed, err := lineup.IsRevealed(ctx, db)
+		require.NoError(t, err)
+		require.True(t, revealed)
+	}
+	accessible = pairing.AccessibleTo(ctx, db)
+	require.True(t, slices.Contains(accessible, homeCaptain))
+	require.True(t, slices.Contains(accessible, awayCaptain))
+	require.False(t, slices.Contains(accessible, database.EveryoneUserId))
+
+	// once revealed, the confirmed lineups can only change with approval
+	locked, err := home.PairingsLocked(ctx, db)
+	require.NoError(t, err)
+	require.True(t, locked)
+	revision, err := ChangeLineupPairings(ctx, db, home, homeCaptain, "restack", nil)
+	require.NoError(t, err)
+	require.Equal(t, LineupRevisionPending, revision.Status)
+}
+
+func TestBlindLineupWithoutOpponentStaysHidden(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, _ := newDefaultSeasonWithTeams(t, db, 2)
+	season.BlindLineups = true
+	require.NoError(t, database.UpdateOne(ctx, db, season))
+	teams, err := season.GetTeams(ctx, db)
+	require.NoError(t, err)
+	week := newStoredWeek(t, db, season)
+
+	lineup, err := database.CreateOne(ctx, db, &Lineup{TeamId: teams[0].ID, WeekId: week.ID, Confirmed: true})
+	require.NoError(t, err)
+	revealed, err := lineup.IsRevealed(ctx, db)
+	require.NoError(t, err)
+	require.False(t, revealed)
+}
@@ -230,6 +230,25 @@ func (b *Blurb) CanUserCommentOrReact(ctx context.Context, db database.Provider,
 	return nil
 }
 
+// commentModerators are the blurb's owner and its season's commissioners.
+func (b *Blurb) commentModerators(ctx context.Context, db database.Provider) []database.UserId {
+	moderators := []database.UserId{b.Owner}
+	season, err := database.GetExistingRecordById(ctx, db, &Season{}, b.Season.RecordId())
+	if err != nil {
+		return moderators
+	}
+	commissioners, err := season.GetCommissioners(ctx, db)
+	if err == nil {
+		moderators = append(moderators, commissioners...)
+	}
+	return moderators
+}
+
+// commentReaders is everyone, like the blurb itself.
+func (b *Blurb) commentReaders(ctx context.Context, db database.Provider) []database.UserId {
+	return b.AccessibleTo(ctx, db)
+}
+
 func (b *Blurb) GetComments(ctx context.Context, db database.Provider) ([]*Comment, error) {
 	v, err := database.GetAllWhere[*Comment](ctx, db, func(_ context.Context, c *Comment) bool {
 		return c.Blurb == b.ID
@@ -247,6 +266,7 @@ func (b *Blurb) GetComments(ctx context.Context, db database.Provider) ([]*Comme
 
 // PostDelete cascades deletion to this blurb's blurb_photo and blurb_reaction
 // child rows. Without this, deleting a blurb would orphan those rows (see #97).
+// It also removes the blurb from the full-text index.
 func (b *Blurb) PostDelete(ctx context.Context, db database.Provider) error {
 	photos, err := database.GetAllWhere[*BlurbPhoto](ctx, db, func(_ context.Context, p *BlurbPhoto) bool {
 		return p.BlurbId == b.ID
@@ -272,7 +292,26 @@ func (b *Blurb) PostDelete(ctx context.Context, db database.Provider) error {
 		}
 	}
 
-	return nil
+	return database.UnindexRecord(ctx, db, b)
+}
+
+func (b *Blurb) SearchDocument() database.SearchDocument {
+	return database.SearchDocument{
+		Kind:    b.Type(),
+		Id:      b.GetId(),
+		Title:   b.Title,
+		Content: b.Content,
+	}
+}
+
+// PostCreate and PostUpdate keep the blurb's text in the full-text index
+// (see Search); PostDelete removes it.
+func (b *Blurb) PostCreate(ctx context.Context, db database.Provider) error {
+	return database.IndexRecord(ctx, db, b)
+}
+
+func (b *Blurb) PostUpdate(ctx context.Context, db database.Provider) error {
+	return database.IndexRecord(ctx, db, b)
 }
 
 func (b *Blurb) NewRecord() database.CrudRecord {
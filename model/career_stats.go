@@ -0,0 +1,256 @@
+package model
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"intraclub/database"
+)
+
+// CareerRecord is a player's record and set / game totals over a group of
+// completed matches. Sets and games are taken from each match's MainValue and
+// SecondaryValue respectively, and matches decided by a retirement, default or
+// walkover only count as the season's OutcomeTreatment allows.
+type CareerRecord struct {
+	Matches   int `json:"matches"`
+	Wins      int `json:"wins"`
+	Losses    int `json:"losses"`
+	SetsWon   int `json:"sets_won"`
+	SetsLost  int `json:"sets_lost"`
+	GamesWon  int `json:"games_won"`
+	GamesLost int `json:"games_lost"`
+}
+
+// WinRate returns the share of matches won.
+func (r *CareerRecord) WinRate() float64 {
+	if r.Matches == 0 {
+		return 0
+	}
+	return float64(r.Wins) / float64(r.Matches)
+}
+
+func (r *CareerRecord) add(result *LineResult) {
+	if result.Treatment.CountsTowardRecord {
+		r.Matches++
+		if result.Won() {
+			r.Wins++
+		} else {
+			r.Losses++
+		}
+	}
+	if result.Treatment.CountsTowardDifferential {
+		r.SetsWon += result.Match.MainValue
+		r.SetsLost += result.MainAgainst()
+		r.GamesWon += result.Match.SecondaryValue
+		r.GamesLost += result.SecondaryAgainst()
+	}
+}
+
+// CareerLineRecord is a player's record on one format line.
+type CareerLineRecord struct {
+	Line int `json:"line"` // format line index
+	CareerRecord
+}
+
+// CareerPartnerRecord is a player's record alongside one doubles partner.
+type CareerPartnerRecord struct {
+	PartnerId database.UserId `json:"partner_id"`
+	CareerRecord
+}
+
+// CareerSeasonRecord is a player's record in one season. Matches in a week
+// whose draft has no season yet are grouped under InvalidRecordId.
+type CareerSeasonRecord struct {
+	SeasonId SeasonId `json:"season_id"`
+	CareerRecord
+}
+
+// CareerStats is a player's history across every season: their overall
+// record, broken down by format line, partner and season, their win / loss
+// streaks and how often they have marked themselves available.
+type CareerStats struct {
+	UserId database.UserId `json:"user_id"`
+	CareerRecord
+	ByLine    []*CareerLineRecord    `json:"by_line"`    // ascending line index
+	ByPartner []*CareerPartnerRecord `json:"by_partner"` // most matches first
+	BySeason  []*CareerSeasonRecord  `json:"by_season"`  // in the order first played
+
+	// CurrentStreak is the run of consecutive results ending with the most
+	// recent match: positive for wins, negative for losses.
+	CurrentStreak     int `json:"current_streak"`
+	LongestWinStreak  int `json:"longest_win_streak"`
+	LongestLossStreak int `json:"longest_loss_streak"`
+
+	// Attendance is taken from the player's Availability responses, one per
+	// week; AttendanceRate is the share of those weeks marked available.
+	WeeksAvailable    int     `json:"weeks_available"`
+	WeeksMaybe        int     `json:"weeks_maybe"`
+	WeeksNotAvailable int     `json:"weeks_not_available"`
+	AttendanceRate    float64 `json:"attendance_rate"`
+}
+
+// careerStatsCacheKey identifies cached CareerStats: the same user may have
+// different results in different databases.
+type careerStatsCacheKey struct {
+	db     database.Provider
+	userId database.UserId
+}
+
+// careerStatsCache holds computed CareerStats by database and user. Any
+// change to a match result, the pairings it is assigned to or a player's
+// availability clears the whole cache (see InvalidateCareerStats) and bumps
+// its generation; stats built across an invalidation are not stored, so
+// entries never go stale.
+var careerStatsCache = struct {
+	sync.Mutex
+	generation uint64
+	stats      map[careerStatsCacheKey]*CareerStats
+}{stats: make(map[careerStatsCacheKey]*CareerStats)}
+
+// InvalidateCareerStats discards every cached CareerStats. It is called by the
+// lifecycle hooks of the records career statistics are built from.
+func InvalidateCareerStats() {
+	careerStatsCache.Lock()
+	defer careerStatsCache.Unlock()
+	careerStatsCache.generation++
+	careerStatsCache.stats = make(map[careerStatsCacheKey]*CareerStats)
+}
+
+// GetCareerStats returns the user's CareerStats, computing them from the
+// completed line results and their Availability if they are not cached. The
+// returned value is shared with the cache and must not be modified.
+func GetCareerStats(ctx context.Context, db database.Provider, userId database.UserId) (*CareerStats, error) {
+	key := careerStatsCacheKey{db: db, userId: userId}
+	careerStatsCache.Lock()
+	stats, ok := careerStatsCache.stats[key]
+	generation := careerStatsCache.generation
+	careerStatsCache.Unlock()
+	if ok {
+		return stats, nil
+	}
+
+	stats, err := buildCareerStats(ctx, db, userId)
+	if err != nil {
+		return nil, err
+	}
+	careerStatsCache.Lock()
+	if careerStatsCache.generation == generation {
+		careerStatsCache.stats[key] = stats
+	}
+	careerStatsCache.Unlock()
+	return stats, nil
+}
+
+func buildCareerStats(ctx context.Context, db database.Provider, userId database.UserId) (*CareerStats, error) {
+	all, err := GetCompletedLineResults(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*LineResult, 0)
+	for _, r := range all {
+		if r.HasPlayer(userId) {
+			results = append(results, r)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if !a.Week.Date.Equal(b.Week.Date) {
+			return a.Week.Date.Before(b.Week.Date)
+		}
+		if a.TeamMatch.ID != b.TeamMatch.ID {
+			return a.TeamMatch.ID < b.TeamMatch.ID
+		}
+		return a.Match.ID < b.Match.ID
+	})
+
+	stats := &CareerStats{
+		UserId:    userId,
+		ByLine:    make([]*CareerLineRecord, 0),
+		ByPartner: make([]*CareerPartnerRecord, 0),
+		BySeason:  make([]*CareerSeasonRecord, 0),
+	}
+	lines := make(map[int]*CareerLineRecord)
+	partners := make(map[database.UserId]*CareerPartnerRecord)
+	seasons := make(map[SeasonId]*CareerSeasonRecord)
+	for _, r := range results {
+		stats.CareerRecord.add(r)
+
+		line, ok := lines[r.Pairing.FormatLineIndex]
+		if !ok {
+			line = &CareerLineRecord{Line: r.Pairing.FormatLineIndex}
+			lines[line.Line] = line
+			stats.ByLine = append(stats.ByLine, line)
+		}
+		line.add(r)
+
+		if isDoubles(r.Pairing) {
+			partnerId := r.Pairing.Player1
+			if partnerId == userId {
+				partnerId = r.Pairing.Player2
+			}
+			partner, ok := partners[partnerId]
+			if !ok {
+				partner = &CareerPartnerRecord{PartnerId: partnerId}
+				partners[partnerId] = partner
+				stats.ByPartner = append(stats.ByPartner, partner)
+			}
+			partner.add(r)
+		}
+
+		season, ok := seasons[r.SeasonId]
+		if !ok {
+			season = &CareerSeasonRecord{SeasonId: r.SeasonId}
+			seasons[r.SeasonId] = season
+			stats.BySeason = append(stats.BySeason, season)
+		}
+		season.add(r)
+
+		if r.Treatment.CountsTowardRecord {
+			stats.addToStreak(r.Won())
+		}
+	}
+	sort.Slice(stats.ByLine, func(i, j int) bool {
+		return stats.ByLine[i].Line < stats.ByLine[j].Line
+	})
+	sort.SliceStable(stats.ByPartner, func(i, j int) bool {
+		return stats.ByPartner[i].Matches > stats.ByPartner[j].Matches
+	})
+
+	availability, err := database.GetAllWhere[*Availability](ctx, db, func(_ context.Context, a *Availability) bool {
+		return a.UserId == userId
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range availability {
+		switch a.Available {
+		case AvailabilityAvailable:
+			stats.WeeksAvailable++
+		case AvailabilityMaybe:
+			stats.WeeksMaybe++
+		case AvailabilityNotAvailable:
+			stats.WeeksNotAvailable++
+		}
+	}
+	if responded := stats.WeeksAvailable + stats.WeeksMaybe + stats.WeeksNotAvailable; responded > 0 {
+		stats.AttendanceRate = float64(stats.WeeksAvailable) / float64(responded)
+	}
+	return stats, nil
+}
+
+// addToStreak extends the current streak with the next result in order.
+func (s *CareerStats) addToStreak(won bool) {
+	switch {
+	case won && s.CurrentStreak > 0:
+		s.CurrentStreak++
+	case won:
+		s.CurrentStreak = 1
+	case s.CurrentStreak < 0:
+		s.CurrentStreak--
+	default:
+		s.CurrentStreak = -1
+	}
+	s.LongestWinStreak = max(s.LongestWinStreak, s.CurrentStreak)
+	s.LongestLossStreak = max(s.LongestLossStreak, -s.CurrentStreak)
+}
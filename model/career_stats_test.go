@@ -0,0 +1,124 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCareerStats(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, home, away := newPartnerStatsFixture(t, db)
+	storeLineResult(t, db, tm, home, away, 2, 0, 12, 5)
+	second, home2, away2 := newRematch(t, db, tm, home, away)
+	storeLineResult(t, db, second, home2, away2, 2, 1, 15, 13)
+	third, home3, away3 := newRematch(t, db, second, home2, away2)
+	storeLineResult(t, db, third, home3, away3, 0, 2, 4, 12)
+
+	stats, err := GetCareerStats(ctx, db, home.Player1)
+	require.NoError(t, err)
+	require.Equal(t, CareerRecord{Matches: 3, Wins: 2, Losses: 1, SetsWon: 4, SetsLost: 3, GamesWon: 31, GamesLost: 30}, stats.CareerRecord)
+	require.Len(t, stats.ByLine, 1)
+	require.Equal(t, home.FormatLineIndex, stats.ByLine[0].Line)
+	require.Equal(t, 3, stats.ByLine[0].Matches)
+	require.Len(t, stats.ByPartner, 1)
+	require.Equal(t, home.Player2, stats.ByPartner[0].PartnerId)
+	require.Equal(t, stats.CareerRecord, stats.ByPartner[0].CareerRecord)
+	require.Len(t, stats.BySeason, 1)
+	require.Equal(t, 3, stats.BySeason[0].Matches)
+
+	// won, won, then lost
+	require.Equal(t, -1, stats.CurrentStreak)
+	require.Equal(t, 2, stats.LongestWinStreak)
+	require.Equal(t, 1, stats.LongestLossStreak)
+
+	opponent, err := GetCareerStats(ctx, db, away.Player2)
+	require.NoError(t, err)
+	require.Equal(t, 1, opponent.CurrentStreak)
+	require.Equal(t, 2, opponent.LongestLossStreak)
+
+	// a player with no history has empty stats
+	newcomer, err := GetCareerStats(ctx, db, newStoredUser(t, db).ID)
+	require.NoError(t, err)
+	require.Zero(t, newcomer.Matches)
+	require.Empty(t, newcomer.ByLine)
+	require.Zero(t, newcomer.AttendanceRate)
+}
+
+func TestCareerStatsAttendance(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, home, away := newPartnerStatsFixture(t, db)
+	second, _, _ := newRematch(t, db, tm, home, away)
+	third, _, _ := newRematch(t, db, second, home, away)
+
+	for _, a := range []*Availability{
+		{UserId: home.Player1, WeekId: tm.WeekId, Available: AvailabilityAvailable},
+		{UserId: home.Player1, WeekId: second.WeekId, Available: AvailabilityNotAvailable},
+		{UserId: home.Player1, WeekId: third.WeekId, Available: AvailabilityAvailable},
+	} {
+		_, err := database.CreateOne(ctx, db, a)
+		require.NoError(t, err)
+	}
+	stats, err := GetCareerStats(ctx, db, home.Player1)
+	require.NoError(t, err)
+	require.Equal(t, 2, stats.WeeksAvailable)
+	require.Equal(t, 1, stats.WeeksNotAvailable)
+	require.InDelta(t, 2.0/3.0, stats.AttendanceRate, 1e-9)
+}
+
+func TestCareerStatsCache(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, home, away := newPartnerStatsFixture(t, db)
+	storeLineResult(t, db, tm, home, away, 2, 0, 12, 5)
+
+	first, err := GetCareerStats(ctx, db, home.Player1)
+	require.NoError(t, err)
+	cached, err := GetCareerStats(ctx, db, home.Player1)
+	require.NoError(t, err)
+	require.Same(t, first, cached)
+
+	// recording another result invalidates the cache
+	rematch, home2, away2 := newRematch(t, db, tm, home, away)
+	storeLineResult(t, db, rematch, home2, away2, 0, 2, 3, 12)
+	updated, err := GetCareerStats(ctx, db, home.Player1)
+	require.NoError(t, err)
+	require.NotSame(t, first, updated)
+	require.Equal(t, 2, updated.Matches)
+	require.Equal(t, -1, updated.CurrentStreak)
+
+	// so does a change to how the season counts outcomes
+	cached, err = GetCareerStats(ctx, db, home.Player1)
+	require.NoError(t, err)
+	require.Same(t, updated, cached)
+	season := newStoredSeason(t, db, newStoredUser(t, db).ID, nil)
+	require.NoError(t, SetOutcomeTreatment(ctx, db, season.ID, OutcomeDefault, OutcomeTreatment{}))
+	cached, err = GetCareerStats(ctx, db, home.Player1)
+	require.NoError(t, err)
+	require.NotSame(t, updated, cached)
+
+	// and a change to the pairing a result is credited to
+	lineup, err := database.GetExistingRecordById(ctx, db, &Lineup{}, home.LineupId.RecordId())
+	require.NoError(t, err)
+	team, err := database.GetExistingRecordById(ctx, db, &Team{}, home.TeamId.RecordId())
+	require.NoError(t, err)
+	spare := newStoredLineupPairing(t, db, lineup, team)
+	_, _, err = database.DeleteOneById(ctx, db, spare, spare.ID.RecordId())
+	require.NoError(t, err)
+	changed := *home
+	changed.Player1 = spare.Player1
+	require.NoError(t, database.UpdateOne(ctx, db, &changed))
+	moved, err := GetCareerStats(ctx, db, home.Player1)
+	require.NoError(t, err)
+	require.Equal(t, 1, moved.Matches)
+
+	// stats are cached per database
+	other, err := GetCareerStats(ctx, database.NewUnitTestDBProvider(), home.Player1)
+	require.NoError(t, err)
+	require.Zero(t, other.Matches)
+}
@@ -34,13 +34,14 @@ func (id *CommentId) UnmarshalJSON(data []byte) error {
 }
 
 type Comment struct {
-	ID        CommentId       `json:"id"`                           // unique ID for this comment
-	Blurb     BlurbId         `json:"blurb"`                        // ID of the Blurb that this comment is on
-	ReplyTo   CommentId       `json:"reply_to"`                     // ID of the Comment that this is in reference to (if any)
-	Owner     database.UserId `json:"user_id" bson:"user_id"`       // ID of the User that created this comment
-	Content   string          `json:"content" bson:"content"`       // content of the comment itself
-	EditedAt  time.Time       `json:"edited_at" bson:"edited_at"`   // time that this Comment was edited (if applicable)
-	CreatedAt time.Time       `json:"created_at" bson:"created_at"` // when this comment was created
+	ID        CommentId         `json:"id"`                           // unique ID for this comment
+	Blurb     BlurbId           `json:"blurb"`                        // ID of the Blurb that this comment is on (if any)
+	Proposal  database.RecordId `json:"proposal"`                     // ID of the CommissionerProposal that this comment is on (if any)
+	ReplyTo   CommentId         `json:"reply_to"`                     // ID of the Comment that this is in reference to (if any)
+	Owner     database.UserId   `json:"user_id" bson:"user_id"`       // ID of the User that created this comment
+	Content   string            `json:"content" bson:"content"`       // content of the comment itself
+	EditedAt  time.Time         `json:"edited_at" bson:"edited_at"`   // time that this Comment was edited (if applicable)
+	CreatedAt time.Time         `json:"created_at" bson:"created_at"` // when this comment was created
 }
 
 func (c *Comment) GetOwner() database.UserId {
@@ -83,12 +84,42 @@ func (c *Comment) SetId(id database.RecordId) {
 	c.ID = CommentId(id)
 }
 
-func (c *Comment) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
-	blurb, err := database.GetExistingRecordById(ctx, db, &Blurb{}, c.Blurb.RecordId())
+// commentTarget is a record that Comments are attached to: a Blurb or a
+// CommissionerProposal.
+type commentTarget interface {
+	// CanUserCommentOrReact returns an error if the user may not comment on
+	// the target or react to its comments
+	CanUserCommentOrReact(ctx context.Context, db database.Provider, u database.UserId) error
+
+	// commentModerators returns the users who may edit or delete any comment
+	// on the target, besides each comment's author and the sysadmin
+	commentModerators(ctx context.Context, db database.Provider) []database.UserId
+
+	// commentReaders returns the users who may read the comments on the target
+	commentReaders(ctx context.Context, db database.Provider) []database.UserId
+}
+
+// getTarget returns the Blurb or CommissionerProposal this comment is on.
+func (c *Comment) getTarget(ctx context.Context, db database.Provider) (commentTarget, error) {
+	if c.Proposal != database.InvalidRecordId {
+		return database.GetExistingRecordById(ctx, db, &CommissionerProposal{}, c.Proposal)
+	}
+	return database.GetExistingRecordById(ctx, db, &Blurb{}, c.Blurb.RecordId())
+}
+
+// CanUserCommentOrReact returns an error if the user may not reply to this
+// comment or react to it: the rules of the Blurb or CommissionerProposal
+// that it is on apply.
+func (c *Comment) CanUserCommentOrReact(ctx context.Context, db database.Provider, u database.UserId) error {
+	target, err := c.getTarget(ctx, db)
 	if err != nil {
-		return []database.UserId{}
+		return err
 	}
-	season, err := database.GetExistingRecordById(ctx, db, &Season{}, blurb.Season.RecordId())
+	return target.CanUserCommentOrReact(ctx, db, u)
+}
+
+func (c *Comment) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
+	target, err := c.getTarget(ctx, db)
 	if err != nil {
 		return []database.UserId{}
 	}
@@ -96,20 +127,23 @@ func (c *Comment) EditableBy(ctx context.Context, db database.Provider) []databa
 	editors := []database.UserId{
 		database.SysAdminUserId,
 		c.Owner,
-		blurb.Owner,
 	}
-
-	commissioners, err := season.GetCommissioners(ctx, db)
-	if err == nil {
-		for _, commissioner := range commissioners {
-			editors = append(editors, commissioner)
-		}
-	}
-	return editors
+	return append(editors, target.commentModerators(ctx, db)...)
 }
 
+// AccessibleTo is everyone for a comment on a Blurb, but only the eligible
+// voters (the commissioners and team captains) for a comment on a
+// CommissionerProposal.
 func (c *Comment) AccessibleTo(ctx context.Context, db database.Provider) []database.UserId {
-	return database.AccessibleToEveryone
+	target, err := c.getTarget(ctx, db)
+	if err != nil {
+		if c.Proposal == database.InvalidRecordId {
+			// a comment left behind by its blurb stays readable by everyone
+			return database.AccessibleToEveryone
+		}
+		return []database.UserId{}
+	}
+	return target.commentReaders(ctx, db)
 }
 
 func (c *Comment) SetOwner(userId database.UserId) {
@@ -121,6 +155,9 @@ func (c *Comment) StaticallyValid() error {
 	if c.Content == "" {
 		return fmt.Errorf("comment is empty")
 	}
+	if (c.Blurb == BlurbId(database.InvalidRecordId)) == (c.Proposal == database.InvalidRecordId) {
+		return errors.New("comment must be on exactly one of a blurb or a proposal")
+	}
 
 	// The self-reference and created-timestamp checks only apply once the
 	// comment has been persisted (an ID has been assigned). The generic CRUD
@@ -145,32 +182,21 @@ func (c *Comment) DynamicallyValid(ctx context.Context, db database.Provider) er
 	if err != nil {
 		return err
 	}
-	blurb, err := database.GetExistingRecordById(ctx, db, &Blurb{}, c.Blurb.RecordId())
-	if err != nil {
-		return err
-	}
 
-	season, err := database.GetExistingRecordById(ctx, db, &Season{}, blurb.Season.RecordId())
-	if err != nil {
+	// the author must be a participant of the blurb's season, or an eligible
+	// voter on the proposal
+	if err := c.CanUserCommentOrReact(ctx, db, c.Owner); err != nil {
 		return err
 	}
 
-	isParticipant, err := season.IsUserIdASeasonParticipant(ctx, db, c.Owner)
-	if err != nil {
-		return err
-	}
-	if !isParticipant {
-		return fmt.Errorf("user %s is not a participant in season %s", c.Owner, season.ID)
-	}
-
 	if c.ReplyTo != CommentId(database.InvalidRecordId) {
 		v, err := database.GetExistingRecordById(ctx, db, &Comment{}, c.ReplyTo.RecordId())
 		if err != nil {
 			return err
 		}
 
-		if v.Blurb != c.Blurb {
-			return errors.New("referenced comment references a different blurb")
+		if v.Blurb != c.Blurb || v.Proposal != c.Proposal {
+			return errors.New("referenced comment is on a different blurb or proposal")
 		}
 	}
 
@@ -189,7 +215,8 @@ func (c *Comment) DynamicallyValid(ctx context.Context, db database.Provider) er
 }
 
 // PostDelete cascades deletion to this comment's comment_reaction child rows.
-// Without this, deleting a comment would orphan those rows (see #97).
+// Without this, deleting a comment would orphan those rows (see #97). It also
+// removes the comment from the full-text index.
 func (c *Comment) PostDelete(ctx context.Context, db database.Provider) error {
 	reactions, err := database.GetAllWhere[*CommentReaction](ctx, db, func(_ context.Context, r *CommentReaction) bool {
 		return r.CommentId == c.ID
@@ -202,13 +229,31 @@ func (c *Comment) PostDelete(ctx context.Context, db database.Provider) error {
 			return err
 		}
 	}
-	return nil
+	return database.UnindexRecord(ctx, db, c)
 }
 
 func (c *Comment) NewRecord() database.CrudRecord {
 	return new(Comment)
 }
 
+func (c *Comment) SearchDocument() database.SearchDocument {
+	return database.SearchDocument{
+		Kind:    c.Type(),
+		Id:      c.GetId(),
+		Content: c.Content,
+	}
+}
+
+// PostCreate and PostUpdate keep the comment's text in the full-text index
+// (see Search); PostDelete removes it.
+func (c *Comment) PostCreate(ctx context.Context, db database.Provider) error {
+	return database.IndexRecord(ctx, db, c)
+}
+
+func (c *Comment) PostUpdate(ctx context.Context, db database.Provider) error {
+	return database.IndexRecord(ctx, db, c)
+}
+
 // CommentReaction is a child-table record that assigns a single Reaction from
 // a User to a Comment. This is the normalized replacement for the former inline
 // `Comment.Reactions` slice, enabling the relationship to be queried/indexed
@@ -252,30 +297,21 @@ func (r *CommentReaction) DynamicallyValid(ctx context.Context, db database.Prov
 	if err != nil {
 		return err
 	}
-	blurb, err := database.GetExistingRecordById(ctx, db, &Blurb{}, commentRec.Blurb.RecordId())
-	if err != nil {
-		return err
-	}
-	season, err := database.GetExistingRecordById(ctx, db, &Season{}, blurb.Season.RecordId())
-	if err != nil {
-		return err
-	}
 
-	// The reacting user must be a participant of the comment's blurb's season.
-	// This mirrors the participant check that the custom /react routes enforce,
-	// so the generic CRUD surface can't be used to bypass it.
-	isParticipant, err := season.IsUserIdASeasonParticipant(ctx, db, r.UserId)
-	if err != nil {
-		return err
-	}
-	if !isParticipant {
-		return fmt.Errorf("user %s is not a participant in season %s", r.UserId, season.ID)
-	}
-	return nil
+	// The reacting user must be a participant of the comment's blurb's season
+	// (or an eligible voter on its proposal). This mirrors the check that the
+	// custom /react routes enforce, so the generic CRUD surface can't be used
+	// to bypass it.
+	return commentRec.CanUserCommentOrReact(ctx, db, r.UserId)
 }
 
+// AccessibleTo follows the comment being reacted to.
 func (r *CommentReaction) AccessibleTo(ctx context.Context, db database.Provider) []database.UserId {
-	return database.AccessibleToEveryone
+	commentRec, err := database.GetExistingRecordById(ctx, db, &Comment{}, r.CommentId.RecordId())
+	if err != nil {
+		return []database.UserId{}
+	}
+	return commentRec.AccessibleTo(ctx, db)
 }
 
 func (r *CommentReaction) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"intraclub/database"
 )
@@ -21,11 +22,34 @@ import (
 // is not currently exposed via a REST CRUD route (see main.go), so removing the
 // field is not a breaking wire change; in-process reads can reassemble the
 // relationship rows into the old map shape via `CommissionerProposal.GetVotes`.
+//
+// A rule-change proposal may also carry structured RuleAmendments (see
+// CommissionerProposalAmendment) targeting RulesetId, which are applied once
+// the proposal is accepted; AppliedRevision is the resulting Ruleset revision.
+//
+// A proposal may have a voting Deadline and a Quorum (the minimum number of
+// votes cast for the result to stand). A proposal with a deadline is decided
+// when it is closed at the deadline (see CloseExpiredProposals), and votes may
+// be changed until then; one without a deadline is decided as soon as enough
+// votes are in. Once closed, the Outcome is frozen and no more votes are taken.
+//
+// A proposal's Kind is either a yes/no question, voted on with
+// CommissionerProposalVote, or a choice between CommissionerProposalOptions,
+// voted on with CommissionerProposalBallots and tallied by plurality or by
+// instant-runoff (see CommissionerProposal.Tally).
 type CommissionerProposal struct {
-	ID              database.RecordId `json:"id"`               // unique ID for this proposal
-	Description     string            `json:"description"`      // description of the change or action
-	SeasonId        SeasonId          `json:"season_id"`        // season that this pertains to
+	ID              database.RecordId `json:"id"`                // unique ID for this proposal
+	Description     string            `json:"description"`       // description of the change or action
+	SeasonId        SeasonId          `json:"season_id"`         // season that this pertains to
 	MustBeUnanimous bool              `json:"must_be_unanimous"` // true if this proposal must get unanimous consent to pass
+	RulesetId       RulesetId         `json:"ruleset_id"`        // ruleset the amendments target, empty for a free-text proposal
+	AppliedRevision RulesetId         `json:"applied_revision"`  // revision produced by applying the amendments, empty until applied
+	Deadline        time.Time         `json:"deadline"`          // when voting closes, zero for no deadline
+	Quorum          int               `json:"quorum"`            // minimum number of votes cast for the result to stand, 0 for none
+	ClosedAt        time.Time         `json:"closed_at"`         // when voting was closed, zero while open
+	Outcome         ProposalOutcome   `json:"outcome"`           // result frozen when voting was closed
+	Kind            ProposalKind      `json:"kind"`              // yes/no, plurality or ranked-choice
+	WinningOption   database.RecordId `json:"winning_option"`    // option chosen by a multi-option proposal, frozen when voting was closed
 }
 
 func (c *CommissionerProposal) GetOwner() database.UserId {
@@ -37,6 +61,39 @@ func (c *CommissionerProposal) PreUpdate(ctx context.Context, db database.Provid
 	if c.MustBeUnanimous != old.MustBeUnanimous {
 		return fmt.Errorf("'must be unanimous' constraint can not be updated after creation")
 	}
+	if c.Kind != old.Kind {
+		return fmt.Errorf("proposal kind can not be updated after creation")
+	}
+	if c.RulesetId != old.RulesetId || c.AppliedRevision != old.AppliedRevision {
+		return fmt.Errorf("ruleset amendments can only be changed through the proposal's amendments")
+	}
+	if !c.ClosedAt.Equal(old.ClosedAt) || c.Outcome != old.Outcome || c.WinningOption != old.WinningOption {
+		return fmt.Errorf("a proposal can only be closed when its voting deadline passes")
+	}
+	if old.Closed() && (!c.Deadline.Equal(old.Deadline) || c.Quorum != old.Quorum) {
+		return fmt.Errorf("voting window can not be changed after the proposal has closed")
+	}
+	if !c.Deadline.Equal(old.Deadline) || c.Quorum != old.Quorum {
+		started, err := old.votingStarted(ctx, db)
+		if err != nil {
+			return err
+		}
+		if started {
+			return fmt.Errorf("voting window can not be changed once voting has started")
+		}
+	}
+	return nil
+}
+
+// PreCreateRequest clears the fields only reached through the proposal's own
+// workflow, so a proposal can't be created already closed or applied. Its
+// amendments, and the ruleset they target, are set through SetAmendments.
+func (c *CommissionerProposal) PreCreateRequest(_ context.Context, _ database.Provider, _ database.UserId) error {
+	c.ClosedAt = time.Time{}
+	c.Outcome = ProposalOutcomePending
+	c.WinningOption = database.InvalidRecordId
+	c.RulesetId = RulesetId(database.InvalidRecordId)
+	c.AppliedRevision = RulesetId(database.InvalidRecordId)
 	return nil
 }
 
@@ -82,6 +139,18 @@ func (c *CommissionerProposal) StaticallyValid() error {
 	if c.Description == "" {
 		return errors.New("empty description")
 	}
+	if c.Quorum < 0 {
+		return errors.New("quorum must not be negative")
+	}
+	if err := c.Outcome.StaticallyValid(); err != nil {
+		return err
+	}
+	if err := c.Kind.StaticallyValid(); err != nil {
+		return err
+	}
+	if c.Kind != ProposalKindYesNo && c.MustBeUnanimous {
+		return fmt.Errorf("a %s proposal can't require unanimous consent", c.Kind)
+	}
 	return nil
 }
 
@@ -92,6 +161,9 @@ func (c *CommissionerProposal) DynamicallyValid(ctx context.Context, db database
 	if err != nil {
 		return err
 	}
+	if c.Quorum > len(possibleVoters) {
+		return fmt.Errorf("quorum of %d exceeds the %d possible voters", c.Quorum, len(possibleVoters))
+	}
 
 	// validate that each recorded voter is a valid captain or commissioner
 	rows, err := c.getVoteRows(ctx, db)
@@ -110,6 +182,15 @@ func (c *CommissionerProposal) DynamicallyValid(ctx context.Context, db database
 			return fmt.Errorf("voter %s not found in possible voters for commissioner proposal", row.UserId)
 		}
 	}
+
+	// the targeted ruleset and the applied revision must exist
+	for _, id := range []RulesetId{c.RulesetId, c.AppliedRevision} {
+		if !id.Empty() {
+			if err := database.ExistsById(ctx, db, &Ruleset{}, id.RecordId()); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -157,22 +238,38 @@ func (c *CommissionerProposal) GetVotes(ctx context.Context, db database.Provide
 	return result, nil
 }
 
-func (c *CommissionerProposal) Vote(ctx context.Context, db database.Provider, voterId database.UserId, vote bool) error {
-	// only an entitled voter (a commissioner or team captain in the Season) may
-	// cast a vote on this proposal
+// checkCanVote returns an error unless voting on the proposal is open at now
+// and voterId is an entitled voter (a commissioner or team captain in the
+// Season).
+func (c *CommissionerProposal) checkCanVote(ctx context.Context, db database.Provider, voterId database.UserId, now time.Time) error {
+	if c.Closed() {
+		return errors.New("voting on this proposal has closed")
+	}
+	if !c.AppliedRevision.Empty() {
+		return errors.New("this proposal's amendments have already been applied")
+	}
+	if c.DeadlinePassed(now) {
+		return errors.New("the voting deadline for this proposal has passed")
+	}
 	possibleVoters, err := c.GetAllVoterIds(ctx, db)
 	if err != nil {
 		return err
 	}
-	valid := false
 	for _, possible := range possibleVoters {
 		if voterId == possible {
-			valid = true
-			break
+			return nil
 		}
 	}
-	if !valid {
-		return fmt.Errorf("voter %s not found in possible voters for commissioner proposal", voterId)
+	return fmt.Errorf("voter %s not found in possible voters for commissioner proposal", voterId)
+}
+
+func (c *CommissionerProposal) Vote(ctx context.Context, db database.Provider, voterId database.UserId, vote bool) error {
+	if c.Kind != ProposalKindYesNo {
+		return fmt.Errorf("a %s proposal is voted on with a ballot", c.Kind)
+	}
+	now := time.Now()
+	if err := c.checkCanVote(ctx, db, voterId, now); err != nil {
+		return err
 	}
 
 	// if this voter has already voted, update their existing relationship row
@@ -182,19 +279,36 @@ func (c *CommissionerProposal) Vote(ctx context.Context, db database.Provider, v
 	if err != nil {
 		return err
 	}
+	var existing *CommissionerProposalVote
 	for _, row := range rows {
 		if row.UserId == voterId {
-			row.Vote = vote
-			return database.UpdateOne(ctx, db, row)
+			existing = row
+			break
 		}
 	}
+	if existing != nil {
+		existing.Vote = vote
+		existing.CastAt = now
+		err = database.UpdateOne(ctx, db, existing)
+	} else {
+		_, err = database.CreateOne(ctx, db, &CommissionerProposalVote{
+			ProposalId: c.ID,
+			UserId:     voterId,
+			Vote:       vote,
+			CastAt:     now,
+		})
+	}
+	if err != nil {
+		return err
+	}
 
-	row := &CommissionerProposalVote{
+	// keep every vote cast, including changes, in the proposal's vote log
+	_, err = database.CreateOne(ctx, db, &CommissionerProposalVoteChange{
 		ProposalId: c.ID,
 		UserId:     voterId,
 		Vote:       vote,
-	}
-	_, err = database.CreateOne(ctx, db, row)
+		CastAt:     now,
+	})
 	return err
 }
 
@@ -214,7 +328,21 @@ func (c *CommissionerProposal) VotesToPassOrFail(voterIds []database.UserId) (vo
 	}
 }
 
+// Status reports whether the proposal has been accepted or rejected. A closed
+// proposal reports its frozen Outcome, and one with a deadline is undecided
+// until it is closed; otherwise the votes cast so far decide it, with
+// acceptance also requiring the Quorum.
 func (c *CommissionerProposal) Status(ctx context.Context, db database.Provider) (accepted, rejected bool, err error) {
+	if c.Closed() {
+		return c.Outcome == ProposalOutcomeAccepted, c.Outcome != ProposalOutcomeAccepted, nil
+	}
+	if !c.Deadline.IsZero() {
+		return false, false, nil
+	}
+	if c.Kind != ProposalKindYesNo {
+		return c.optionStatus(ctx, db)
+	}
+
 	voterIds, err := c.GetAllVoterIds(ctx, db)
 	if err != nil {
 		return false, false, err
@@ -238,8 +366,8 @@ func (c *CommissionerProposal) Status(ctx context.Context, db database.Provider)
 			votesAgainst += 1
 		}
 
-		// if we reach the pass threshold, then return
-		if votesInFavor >= votesNeededToPass {
+		// if we reach the pass threshold (and the quorum), then return
+		if votesInFavor >= votesNeededToPass && votesInFavor+votesAgainst >= c.Quorum {
 			return true, false, nil
 		}
 		// if we reach the fail threshold then return
@@ -252,8 +380,9 @@ func (c *CommissionerProposal) Status(ctx context.Context, db database.Provider)
 	return false, false, nil
 }
 
-// PostDelete cascades deletion to this proposal's commissioner_proposal_vote
-// child rows. Without this, deleting a proposal would orphan those rows (see #97).
+// PostDelete cascades deletion to this proposal's votes, ballots, options,
+// amendments, vote and ballot logs, and its discussion thread's comments.
+// Without this, deleting a proposal would orphan those rows (see #97).
 func (c *CommissionerProposal) PostDelete(ctx context.Context, db database.Provider) error {
 	votes, err := database.GetAllWhere[*CommissionerProposalVote](ctx, db, func(_ context.Context, v *CommissionerProposalVote) bool {
 		return v.ProposalId == c.ID
@@ -266,6 +395,60 @@ func (c *CommissionerProposal) PostDelete(ctx context.Context, db database.Provi
 			return err
 		}
 	}
+	changes, err := c.GetVoteChanges(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, v := range changes {
+		if _, _, err := database.DeleteOneById(ctx, db, v, v.ID); err != nil {
+			return err
+		}
+	}
+	amendments, err := c.GetAmendments(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, a := range amendments {
+		if _, _, err := database.DeleteOneById(ctx, db, a, a.ID); err != nil {
+			return err
+		}
+	}
+	ballots, err := c.getBallotRows(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, b := range ballots {
+		if _, _, err := database.DeleteOneById(ctx, db, b, b.ID); err != nil {
+			return err
+		}
+	}
+	ballotChanges, err := c.GetBallotChanges(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, b := range ballotChanges {
+		if _, _, err := database.DeleteOneById(ctx, db, b, b.ID); err != nil {
+			return err
+		}
+	}
+	options, err := c.GetOptions(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, o := range options {
+		if _, _, err := database.DeleteOneById(ctx, db, o, o.ID); err != nil {
+			return err
+		}
+	}
+	comments, err := c.GetComments(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if _, _, err := database.DeleteOneById(ctx, db, comment, comment.ID.RecordId()); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -288,6 +471,7 @@ type CommissionerProposalVote struct {
 	ProposalId database.RecordId `json:"proposal_id"`
 	UserId     database.UserId   `json:"user_id"`
 	Vote       bool              `json:"vote"`
+	CastAt     time.Time         `json:"cast_at"` // when the vote was last cast or changed
 }
 
 func (v *CommissionerProposalVote) GetOwner() database.UserId {
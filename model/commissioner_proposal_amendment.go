@@ -0,0 +1,288 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"intraclub/database"
+)
+
+// CommissionerProposalAmendment is one structured RuleAmendment carried by a
+// CommissionerProposal, to be applied to the proposal's Ruleset once the
+// proposal is accepted. Position orders a proposal's amendments. The
+// amendment's new section is stored flattened as Title and Markdown.
+type CommissionerProposalAmendment struct {
+	ID            database.RecordId `json:"id"`
+	ProposalId    database.RecordId `json:"proposal_id"`
+	Position      int               `json:"position"`
+	AmendmentType RuleAmendmentType `json:"amendment_type"`
+	TargetSection RuleSectionId     `json:"target_section"`
+	Title         string            `json:"title"`
+	Markdown      string            `json:"markdown"`
+	After         RuleSectionId     `json:"after"`
+}
+
+// NewCommissionerProposalAmendment stores a RuleAmendment for a proposal at
+// the given position.
+func NewCommissionerProposalAmendment(proposalId database.RecordId, position int, a *RuleAmendment) *CommissionerProposalAmendment {
+	return &CommissionerProposalAmendment{
+		ProposalId:    proposalId,
+		Position:      position,
+		AmendmentType: a.Type,
+		TargetSection: a.TargetSection,
+		Title:         a.NewSection.Title,
+		Markdown:      a.NewSection.Markdown,
+		After:         a.After,
+	}
+}
+
+// RuleAmendment returns the stored amendment.
+func (p *CommissionerProposalAmendment) RuleAmendment() *RuleAmendment {
+	return &RuleAmendment{
+		Type:          p.AmendmentType,
+		TargetSection: p.TargetSection,
+		NewSection:    RuleSection{Title: p.Title, Markdown: p.Markdown},
+		After:         p.After,
+	}
+}
+
+func (p *CommissionerProposalAmendment) GetOwner() database.UserId {
+	return database.InvalidUserId
+}
+
+func (p *CommissionerProposalAmendment) SetOwner(userId database.UserId) {}
+
+func (p *CommissionerProposalAmendment) Type() string {
+	return "commissioner_proposal_amendment"
+}
+
+func (p *CommissionerProposalAmendment) GetId() database.RecordId {
+	return p.ID
+}
+
+func (p *CommissionerProposalAmendment) SetId(id database.RecordId) {
+	p.ID = id
+}
+
+func (p *CommissionerProposalAmendment) StaticallyValid() error {
+	return p.RuleAmendment().StaticallyValid()
+}
+
+func (p *CommissionerProposalAmendment) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	return database.ExistsById(ctx, db, &CommissionerProposal{}, p.ProposalId)
+}
+
+// AccessibleTo exposes the amendment to those who can view the proposal.
+func (p *CommissionerProposalAmendment) AccessibleTo(ctx context.Context, db database.Provider) []database.UserId {
+	proposal, err := database.GetExistingRecordById(ctx, db, &CommissionerProposal{}, p.ProposalId)
+	if err != nil {
+		return nil
+	}
+	return proposal.AccessibleTo(ctx, db)
+}
+
+func (p *CommissionerProposalAmendment) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
+	return []database.UserId{database.SysAdminUserId}
+}
+
+func (p *CommissionerProposalAmendment) NewRecord() database.CrudRecord {
+	return new(CommissionerProposalAmendment)
+}
+
+// GetAmendments returns the proposal's amendments in order.
+func (c *CommissionerProposal) GetAmendments(ctx context.Context, db database.Provider) ([]*CommissionerProposalAmendment, error) {
+	rows, err := database.GetAllWhere[*CommissionerProposalAmendment](ctx, db, func(_ context.Context, p *CommissionerProposalAmendment) bool {
+		return p.ProposalId == c.ID
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(rows, func(a, b *CommissionerProposalAmendment) int {
+		return a.Position - b.Position
+	})
+	return rows, nil
+}
+
+// SetAmendments replaces the amendments the proposal carries with the given
+// ones, targeting the given Ruleset (or none, making the proposal free text
+// again). Amendments can't change once anyone has voted or voting has closed,
+// and the Ruleset must be current.
+func (c *CommissionerProposal) SetAmendments(ctx context.Context, db database.Provider, rulesetId RulesetId, amendments []*RuleAmendment) error {
+	if !c.AppliedRevision.Empty() {
+		return errors.New("proposal amendments have already been applied")
+	}
+	if c.Closed() {
+		return errors.New("proposal amendments can't change once voting has closed")
+	}
+	if c.Kind != ProposalKindYesNo && len(amendments) > 0 {
+		return fmt.Errorf("only a yes/no proposal can carry rule amendments, not a %s one", c.Kind)
+	}
+	votes, err := c.getVoteRows(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(votes) > 0 {
+		return errors.New("proposal amendments can't change once voting has started")
+	}
+	if rulesetId.Empty() != (len(amendments) == 0) {
+		return errors.New("a ruleset must be given with amendments, and only with amendments")
+	}
+	if !rulesetId.Empty() {
+		ruleset, err := database.GetExistingRecordById(ctx, db, &Ruleset{}, rulesetId.RecordId())
+		if err != nil {
+			return err
+		}
+		if ruleset.Archived() {
+			return fmt.Errorf("ruleset %s has been superseded by %s", ruleset.ID, ruleset.SupersededBy)
+		}
+	}
+	for i, a := range amendments {
+		if err := a.StaticallyValid(); err != nil {
+			return fmt.Errorf("amendment %d: %w", i+1, err)
+		}
+	}
+
+	existing, err := c.GetAmendments(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, row := range existing {
+		if _, _, err := database.DeleteOneById(ctx, db, &CommissionerProposalAmendment{}, row.ID); err != nil {
+			return err
+		}
+	}
+	for i, a := range amendments {
+		if _, err := database.CreateOne(ctx, db, NewCommissionerProposalAmendment(c.ID, i, a)); err != nil {
+			return err
+		}
+	}
+	// raw provider update, which bypasses the PreUpdate hook that keeps the
+	// ruleset from being changed through generic CRUD
+	updated := *c
+	updated.RulesetId = rulesetId
+	if err := db.Update(ctx, &updated); err != nil {
+		return err
+	}
+	*c = updated
+	return nil
+}
+
+// UnadoptedChanges returns the changes between the revision the proposal's
+// season has pinned and the revision the proposal amends, when that is a later
+// revision of the pinned one, e.g. one amended mid-season without a vote. The
+// season adopts those changes along with the proposal's amendments, so voters
+// are shown them. It returns nil if there are none.
+func (c *CommissionerProposal) UnadoptedChanges(ctx context.Context, db database.Provider) (*RulesetDiff, error) {
+	if c.RulesetId.Empty() || !c.AppliedRevision.Empty() {
+		return nil, nil
+	}
+	season, err := database.GetExistingRecordById(ctx, db, &Season{}, c.SeasonId.RecordId())
+	if err != nil {
+		return nil, err
+	}
+	if !season.RulesetPinned() || season.Ruleset == c.RulesetId {
+		return nil, nil
+	}
+	pinned, err := database.GetExistingRecordById(ctx, db, &Ruleset{}, season.Ruleset.RecordId())
+	if err != nil {
+		return nil, err
+	}
+	inLineage, err := pinned.supersededInto(ctx, db, c.RulesetId)
+	if err != nil || !inLineage {
+		return nil, err
+	}
+	target, err := database.GetExistingRecordById(ctx, db, &Ruleset{}, c.RulesetId.RecordId())
+	if err != nil {
+		return nil, err
+	}
+	return pinned.Diff(ctx, db, target)
+}
+
+// applyAmendmentsMu serializes ApplyAmendments, so that two callers can't
+// both find a proposal unapplied and amend its Ruleset twice.
+var applyAmendmentsMu sync.Mutex
+
+// ApplyAmendments applies the proposal's amendments to its Ruleset through
+// Ruleset.AmendAll once the proposal has been accepted, and links the
+// resulting revision back to the proposal. It does nothing for a free-text
+// proposal, one not yet accepted, or one already applied, and refuses if the
+// Ruleset has since been superseded. Applying closes voting on the proposal if
+// it is still open. If the proposal's season has pinned a revision of the same
+// ruleset, the new revision becomes the one in force for the season. If any
+// step fails nothing is applied, so it can be retried. Returns the new
+// revision, if any.
+//
+// Votes, the apply route and the background closer may all apply the same
+// proposal at once, so applying is serialized and works from a fresh copy of
+// the proposal, which c is refreshed with.
+func (c *CommissionerProposal) ApplyAmendments(ctx context.Context, db database.Provider) (*Ruleset, error) {
+	applyAmendmentsMu.Lock()
+	defer applyAmendmentsMu.Unlock()
+	current, err := database.GetExistingRecordById(ctx, db, &CommissionerProposal{}, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	*c = *current
+	if c.RulesetId.Empty() || !c.AppliedRevision.Empty() {
+		return nil, nil
+	}
+	accepted, _, err := c.Status(ctx, db)
+	if err != nil || !accepted {
+		return nil, err
+	}
+	rows, err := c.GetAmendments(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	amendments := make([]*RuleAmendment, 0, len(rows))
+	for _, row := range rows {
+		amendments = append(amendments, row.RuleAmendment())
+	}
+	ruleset, err := database.GetExistingRecordById(ctx, db, &Ruleset{}, c.RulesetId.RecordId())
+	if err != nil {
+		return nil, err
+	}
+	season, err := database.GetExistingRecordById(ctx, db, &Season{}, c.SeasonId.RecordId())
+	if err != nil {
+		return nil, err
+	}
+	revision, undo, err := ruleset.amendAll(ctx, db, amendments)
+	if err != nil {
+		return nil, err
+	}
+
+	// an accepted proposal is how a revision becomes binding for a season
+	// that has already pinned its ruleset. The season adopts it before the
+	// proposal is marked applied, and a failure undoes both, so applying can
+	// be retried.
+	pinned := season.Ruleset
+	adopted, err := season.AdoptRevision(ctx, db, c.RulesetId, revision)
+	if err != nil {
+		return nil, errors.Join(err, undo())
+	}
+
+	// applying the amendments closes voting, freezing the accepted outcome that
+	// applied them (a proposal with no deadline would otherwise stay open)
+	updated := *c
+	updated.AppliedRevision = revision.ID
+	if !updated.Closed() {
+		updated.ClosedAt = time.Now()
+		updated.Outcome = ProposalOutcomeAccepted
+	}
+	if err := db.Update(ctx, &updated); err != nil {
+		if adopted {
+			_, restoreErr := season.changeRuleset(ctx, db, func(current *Season) (bool, error) {
+				current.Ruleset = pinned
+				return true, nil
+			})
+			err = errors.Join(err, restoreErr)
+		}
+		return nil, errors.Join(err, undo())
+	}
+	*c = updated
+	return revision, nil
+}
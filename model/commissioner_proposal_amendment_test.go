@@ -0,0 +1,296 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"intraclub/database"
+)
+
+// acceptProposal votes yes with every participant of the proposal's season.
+func acceptProposal(t *testing.T, db database.Provider, season *Season, proposal *CommissionerProposal) {
+	ctx := context.Background()
+	commissioners, err := season.GetCommissioners(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	teams, err := season.GetTeams(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	voters := commissioners
+	for _, team := range teams {
+		captain, err := team.GetCaptain(ctx, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		voters = append(voters, captain)
+	}
+	for _, voter := range voters {
+		if err := proposal.Vote(ctx, db, voter, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertProposalStatus(t, proposal, db, true, false)
+}
+
+func TestCommissionerProposalAmendmentsAppliedOnAcceptance(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, proposal := newStoredCommissionerProposal(t, db, false)
+	ruleset := newValidStoredRulesetWithXSections(t, db, 3)
+	sections, err := ruleset.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// modify the second section, then move it (by its original id) to the front
+	err = proposal.SetAmendments(ctx, db, ruleset.ID, []*RuleAmendment{
+		{Type: RuleAmendmentTypeModifySection, TargetSection: sections[1], NewSection: RuleSection{Title: "Scoring", Markdown: "new scoring"}},
+		{Type: RuleAmendmentTypeReorderSection, TargetSection: sections[1]},
+		{Type: RuleAmendmentTypeRemoveSection, TargetSection: sections[2]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := proposal.GetAmendments(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 3 || stored[0].AmendmentType != RuleAmendmentTypeModifySection || stored[2].TargetSection != sections[2] {
+		t.Fatalf("expected the three amendments to be stored in order, got %+v", stored)
+	}
+
+	// nothing is applied before acceptance
+	revision, err := proposal.ApplyAmendments(ctx, db)
+	if err != nil || revision != nil {
+		t.Fatalf("expected nothing applied before acceptance, got %v, %v", revision, err)
+	}
+
+	acceptProposal(t, db, season, proposal)
+	if err := proposal.SetAmendments(ctx, db, RulesetId(database.InvalidRecordId), nil); err == nil {
+		t.Fatal("expected amendments to be frozen once voting started")
+	}
+	revision, err = proposal.ApplyAmendments(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revision == nil || proposal.AppliedRevision != revision.ID {
+		t.Fatalf("expected the applied revision to be linked to the proposal, got %v", revision)
+	}
+	newSections, err := revision.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newSections) != 2 || newSections[1] != sections[0] {
+		t.Fatalf("expected the modified section first and %s second, got %v", sections[0], newSections)
+	}
+	modified, err := database.GetExistingRecordById(ctx, db, &RuleSection{}, newSections[0].RecordId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified.Replaces != sections[1] || modified.Markdown != "new scoring" {
+		t.Fatalf("expected the modified section to replace %s, got %+v", sections[1], modified)
+	}
+
+	// applying again is a no-op
+	again, err := proposal.ApplyAmendments(ctx, db)
+	if err != nil || again != nil {
+		t.Fatalf("expected applying twice to do nothing, got %v, %v", again, err)
+	}
+
+	// and applying closed voting, so the outcome can't be changed afterwards
+	if !proposal.Closed() || proposal.Outcome != ProposalOutcomeAccepted {
+		t.Fatalf("expected the applied proposal to be closed accepted, got %v, %s", proposal.ClosedAt, proposal.Outcome)
+	}
+	commissioners, err := season.GetCommissioners(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proposal.Vote(ctx, db, commissioners[0], false); err == nil {
+		t.Fatal("expected voting on an applied proposal to fail")
+	}
+	assertProposalStatus(t, proposal, db, true, false)
+}
+
+func TestCommissionerProposalAmendmentsRefusedOnSupersededRuleset(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, proposal := newStoredCommissionerProposal(t, db, false)
+	ruleset := newValidStoredRulesetWithXSections(t, db, 2)
+	sections, err := ruleset.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = proposal.SetAmendments(ctx, db, ruleset.ID, []*RuleAmendment{
+		{Type: RuleAmendmentTypeRemoveSection, TargetSection: sections[0]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the ruleset is amended by someone else while the proposal is voted on
+	latest := addSectionRevisionToEndOfExistingRuleset(t, db, ruleset)
+	acceptProposal(t, db, season, proposal)
+	if _, err := proposal.ApplyAmendments(ctx, db); err == nil {
+		t.Fatal("expected applying to a superseded ruleset to be refused")
+	}
+	if !proposal.AppliedRevision.Empty() {
+		t.Fatal("expected no applied revision")
+	}
+	latest, err = database.GetExistingRecordById(ctx, db, &Ruleset{}, latest.ID.RecordId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Archived() {
+		t.Fatal("expected the latest revision to be left alone")
+	}
+
+	// a superseded ruleset can't be targeted in the first place either
+	_, other := newStoredCommissionerProposal(t, db, false)
+	err = other.SetAmendments(ctx, db, ruleset.ID, []*RuleAmendment{
+		{Type: RuleAmendmentTypeRemoveSection, TargetSection: sections[0]},
+	})
+	if err == nil {
+		t.Fatal("expected targeting a superseded ruleset to be refused")
+	}
+}
+
+func TestCommissionerProposalAmendmentsRetriedAfterFailure(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, proposal := newStoredCommissionerProposal(t, db, false)
+	ruleset := newValidStoredRulesetWithXSections(t, db, 2)
+	sections, err := ruleset.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = proposal.SetAmendments(ctx, db, ruleset.ID, []*RuleAmendment{
+		{Type: RuleAmendmentTypeRemoveSection, TargetSection: sections[0]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	acceptProposal(t, db, season, proposal)
+
+	// the season can't be read, so nothing is applied
+	if err := db.Delete(ctx, season); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proposal.ApplyAmendments(ctx, db); err == nil {
+		t.Fatal("expected applying to fail without the season")
+	}
+	ruleset, err = database.GetExistingRecordById(ctx, db, &Ruleset{}, ruleset.ID.RecordId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ruleset.Archived() || !proposal.AppliedRevision.Empty() {
+		t.Fatal("expected neither the ruleset nor the proposal to be changed")
+	}
+
+	// so applying can be retried once the season is back
+	if _, err := db.Create(ctx, season); err != nil {
+		t.Fatal(err)
+	}
+	revision, err := proposal.ApplyAmendments(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revision == nil || proposal.AppliedRevision != revision.ID {
+		t.Fatalf("expected the retry to apply the amendments, got %v", revision)
+	}
+}
+
+func TestCommissionerProposalAmendmentsAppliedOnce(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, proposal := newStoredCommissionerProposal(t, db, false)
+	ruleset := newValidStoredRulesetWithXSections(t, db, 2)
+	sections, err := ruleset.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = proposal.SetAmendments(ctx, db, ruleset.ID, []*RuleAmendment{
+		{Type: RuleAmendmentTypeRemoveSection, TargetSection: sections[0]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	acceptProposal(t, db, season, proposal)
+
+	// each caller applies from its own copy, read before any of them applied
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		stale := *proposal
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = stale.ApplyAmendments(ctx, db)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		t.Fatal(err)
+	}
+	revisions, err := database.GetAllWhere[*Ruleset](ctx, db, func(_ context.Context, r *Ruleset) bool {
+		return r.Parent == ruleset.ID
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 {
+		t.Fatalf("expected the amendments to be applied once, got %d revisions", len(revisions))
+	}
+}
+
+func TestRulesetAmendAllRollsBack(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	ruleset := newValidStoredRulesetWithXSections(t, db, 2)
+	sections, err := ruleset.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := database.GetAllWhere[*Ruleset](ctx, db, func(_ context.Context, _ *Ruleset) bool {
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ruleset.AmendAll(ctx, db, []*RuleAmendment{
+		{Type: RuleAmendmentTypeModifySection, TargetSection: sections[0], NewSection: RuleSection{Title: "Renamed", Markdown: "changed"}},
+		{Type: RuleAmendmentTypeAddSection, NewSection: RuleSection{Title: "New", Markdown: "new"}, After: sections[1]},
+		{Type: RuleAmendmentTypeRemoveSection, TargetSection: RuleSectionId(database.RecordId(12345))},
+	})
+	if err == nil {
+		t.Fatal("expected removing a missing section to fail")
+	}
+
+	after, err := database.GetAllWhere[*Ruleset](ctx, db, func(_ context.Context, _ *Ruleset) bool {
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("expected the revisions made to be rolled back, had %d now %d", len(before), len(after))
+	}
+	ruleset, err = database.GetExistingRecordById(ctx, db, &Ruleset{}, ruleset.ID.RecordId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ruleset.Archived() {
+		t.Fatal("expected the ruleset to be current again")
+	}
+	got, err := ruleset.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != sections[0] || got[1] != sections[1] {
+		t.Fatalf("expected sections %v, got %v", sections, got)
+	}
+}
@@ -0,0 +1,624 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"intraclub/database"
+)
+
+// ProposalKind is how a CommissionerProposal is voted on.
+type ProposalKind int
+
+const (
+	// ProposalKindYesNo is voted on for or against with CommissionerProposalVote
+	ProposalKindYesNo ProposalKind = iota
+	// ProposalKindPlurality is a choice of one option per ballot, won by the
+	// option with the most votes
+	ProposalKindPlurality
+	// ProposalKindRankedChoice is a ranking of options per ballot, won by
+	// instant-runoff
+	ProposalKindRankedChoice
+	ProposalKindInvalid
+)
+
+func (k ProposalKind) String() string {
+	switch k {
+	case ProposalKindYesNo:
+		return "yes/no"
+	case ProposalKindPlurality:
+		return "plurality"
+	case ProposalKindRankedChoice:
+		return "ranked-choice"
+	}
+	return "invalid"
+}
+
+func (k ProposalKind) StaticallyValid() error {
+	if k < 0 || k >= ProposalKindInvalid {
+		return fmt.Errorf("invalid proposal kind: %d", k)
+	}
+	return nil
+}
+
+// CommissionerProposalOption is one of the options a multi-option
+// CommissionerProposal chooses between, ordered by Position.
+type CommissionerProposalOption struct {
+	ID         database.RecordId `json:"id"`
+	ProposalId database.RecordId `json:"proposal_id"`
+	Position   int               `json:"position"`
+	Text       string            `json:"text"`
+}
+
+func (o *CommissionerProposalOption) GetOwner() database.UserId {
+	return database.InvalidUserId
+}
+
+func (o *CommissionerProposalOption) SetOwner(userId database.UserId) {}
+
+func (o *CommissionerProposalOption) Type() string {
+	return "commissioner_proposal_option"
+}
+
+func (o *CommissionerProposalOption) GetId() database.RecordId {
+	return o.ID
+}
+
+func (o *CommissionerProposalOption) SetId(id database.RecordId) {
+	o.ID = id
+}
+
+func (o *CommissionerProposalOption) StaticallyValid() error {
+	o.Text = strings.TrimSpace(o.Text)
+	if o.Text == "" {
+		return errors.New("empty option")
+	}
+	return nil
+}
+
+func (o *CommissionerProposalOption) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	return database.ExistsById(ctx, db, &CommissionerProposal{}, o.ProposalId)
+}
+
+// AccessibleTo exposes the option to those who can view the proposal.
+func (o *CommissionerProposalOption) AccessibleTo(ctx context.Context, db database.Provider) []database.UserId {
+	proposal, err := database.GetExistingRecordById(ctx, db, &CommissionerProposal{}, o.ProposalId)
+	if err != nil {
+		return nil
+	}
+	return proposal.AccessibleTo(ctx, db)
+}
+
+func (o *CommissionerProposalOption) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
+	return []database.UserId{database.SysAdminUserId}
+}
+
+func (o *CommissionerProposalOption) NewRecord() database.CrudRecord {
+	return new(CommissionerProposalOption)
+}
+
+// UniquenessEquivalent keeps a proposal from offering the same option twice.
+func (o *CommissionerProposalOption) UniquenessEquivalent(other *CommissionerProposalOption) error {
+	if o.ProposalId == other.ProposalId && strings.EqualFold(o.Text, other.Text) {
+		return fmt.Errorf("commissioner proposal %s already has option %q", o.ProposalId, o.Text)
+	}
+	return nil
+}
+
+// GetOptions returns the proposal's options in order.
+func (c *CommissionerProposal) GetOptions(ctx context.Context, db database.Provider) ([]*CommissionerProposalOption, error) {
+	options, err := database.GetAllWhere[*CommissionerProposalOption](ctx, db, func(_ context.Context, o *CommissionerProposalOption) bool {
+		return o.ProposalId == c.ID
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(options, func(a, b *CommissionerProposalOption) int {
+		return a.Position - b.Position
+	})
+	return options, nil
+}
+
+// SetOptions replaces the options of a multi-option proposal. Options can't
+// change once anyone has cast a ballot.
+func (c *CommissionerProposal) SetOptions(ctx context.Context, db database.Provider, texts []string) error {
+	if c.Kind == ProposalKindYesNo {
+		return errors.New("a yes/no proposal has no options")
+	}
+	if c.Closed() {
+		return errors.New("voting on this proposal has closed")
+	}
+	ballots, err := c.getBallotRows(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(ballots) > 0 {
+		return errors.New("proposal options can't change once voting has started")
+	}
+	if len(texts) < 2 {
+		return errors.New("a multi-option proposal needs at least two options")
+	}
+	options := make([]*CommissionerProposalOption, 0, len(texts))
+	for i, text := range texts {
+		option := &CommissionerProposalOption{ProposalId: c.ID, Position: i, Text: text}
+		if err := option.StaticallyValid(); err != nil {
+			return fmt.Errorf("option %d: %w", i+1, err)
+		}
+		for _, other := range options {
+			if err := option.UniquenessEquivalent(other); err != nil {
+				return err
+			}
+		}
+		options = append(options, option)
+	}
+
+	existing, err := c.GetOptions(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, o := range existing {
+		if _, _, err := database.DeleteOneById(ctx, db, o, o.ID); err != nil {
+			return err
+		}
+	}
+	for _, o := range options {
+		if _, err := database.CreateOne(ctx, db, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CommissionerProposalBallot is one ranked choice on a voter's ballot for a
+// multi-option CommissionerProposal: the voter's Rank'th choice (0 being their
+// first) is OptionId. A plurality ballot has only a first choice. It replaces
+// the boolean CommissionerProposalVote for multi-option proposals.
+type CommissionerProposalBallot struct {
+	ID         database.RecordId `json:"id"`
+	ProposalId database.RecordId `json:"proposal_id"`
+	UserId     database.UserId   `json:"user_id"`
+	OptionId   database.RecordId `json:"option_id"`
+	Rank       int               `json:"rank"`
+	CastAt     time.Time         `json:"cast_at"`
+}
+
+func (b *CommissionerProposalBallot) GetOwner() database.UserId {
+	return database.InvalidUserId
+}
+
+func (b *CommissionerProposalBallot) SetOwner(userId database.UserId) {}
+
+func (b *CommissionerProposalBallot) Type() string {
+	return "commissioner_proposal_ballot"
+}
+
+func (b *CommissionerProposalBallot) GetId() database.RecordId {
+	return b.ID
+}
+
+func (b *CommissionerProposalBallot) SetId(id database.RecordId) {
+	b.ID = id
+}
+
+func (b *CommissionerProposalBallot) StaticallyValid() error {
+	if b.Rank < 0 {
+		return errors.New("rank must not be negative")
+	}
+	return nil
+}
+
+func (b *CommissionerProposalBallot) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	if err := database.ExistsById(ctx, db, &User{}, b.UserId.RecordId()); err != nil {
+		return err
+	}
+	option, err := database.GetExistingRecordById(ctx, db, &CommissionerProposalOption{}, b.OptionId)
+	if err != nil {
+		return err
+	}
+	if option.ProposalId != b.ProposalId {
+		return fmt.Errorf("option %s is not an option of commissioner proposal %s", b.OptionId, b.ProposalId)
+	}
+	return nil
+}
+
+// AccessibleTo exposes the ballot only to those who can view the proposal.
+func (b *CommissionerProposalBallot) AccessibleTo(ctx context.Context, db database.Provider) []database.UserId {
+	proposal, err := database.GetExistingRecordById(ctx, db, &CommissionerProposal{}, b.ProposalId)
+	if err != nil {
+		return nil
+	}
+	return proposal.AccessibleTo(ctx, db)
+}
+
+func (b *CommissionerProposalBallot) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
+	return []database.UserId{database.SysAdminUserId}
+}
+
+func (b *CommissionerProposalBallot) NewRecord() database.CrudRecord {
+	return new(CommissionerProposalBallot)
+}
+
+// UniquenessEquivalent enforces that a voter ranks each option at most once
+// and gives each rank to at most one option.
+func (b *CommissionerProposalBallot) UniquenessEquivalent(other *CommissionerProposalBallot) error {
+	if b.ProposalId != other.ProposalId || b.UserId != other.UserId {
+		return nil
+	}
+	if b.Rank == other.Rank {
+		return fmt.Errorf("voter %s already has a choice ranked %d on commissioner proposal %s", b.UserId, b.Rank+1, b.ProposalId)
+	}
+	if b.OptionId == other.OptionId {
+		return fmt.Errorf("voter %s already ranked option %s on commissioner proposal %s", b.UserId, b.OptionId, b.ProposalId)
+	}
+	return nil
+}
+
+// getBallotRows returns all CommissionerProposalBallot rows of this proposal.
+func (c *CommissionerProposal) getBallotRows(ctx context.Context, db database.Provider) ([]*CommissionerProposalBallot, error) {
+	return database.GetAllWhere[*CommissionerProposalBallot](ctx, db, func(_ context.Context, b *CommissionerProposalBallot) bool {
+		return b.ProposalId == c.ID
+	})
+}
+
+// GetBallots reassembles the ballot rows into each voter's ranking of
+// options, first choice first.
+func (c *CommissionerProposal) GetBallots(ctx context.Context, db database.Provider) (map[database.UserId][]database.RecordId, error) {
+	rows, err := c.getBallotRows(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(rows, func(a, b *CommissionerProposalBallot) int {
+		return a.Rank - b.Rank
+	})
+	ballots := map[database.UserId][]database.RecordId{}
+	for _, row := range rows {
+		ballots[row.UserId] = append(ballots[row.UserId], row.OptionId)
+	}
+	return ballots, nil
+}
+
+// CastBallot records the voter's ballot on a multi-option proposal, replacing
+// any ballot they cast before: their choice for a plurality proposal, or
+// their ranking of some or all of the options (first choice first) for a
+// ranked-choice one. Voters are eligible as for Vote.
+func (c *CommissionerProposal) CastBallot(ctx context.Context, db database.Provider, voterId database.UserId, ranking []database.RecordId) error {
+	if c.Kind == ProposalKindYesNo {
+		return errors.New("a yes/no proposal is voted on with a vote, not a ballot")
+	}
+	now := time.Now()
+	if err := c.checkCanVote(ctx, db, voterId, now); err != nil {
+		return err
+	}
+	if len(ranking) == 0 {
+		return errors.New("a ballot must choose an option")
+	}
+	if c.Kind == ProposalKindPlurality && len(ranking) > 1 {
+		return errors.New("a plurality ballot chooses exactly one option")
+	}
+	options, err := c.GetOptions(ctx, db)
+	if err != nil {
+		return err
+	}
+	for i, optionId := range ranking {
+		if !slices.ContainsFunc(options, func(o *CommissionerProposalOption) bool { return o.ID == optionId }) {
+			return fmt.Errorf("option %s is not an option of this proposal", optionId)
+		}
+		if slices.Contains(ranking[:i], optionId) {
+			return fmt.Errorf("option %s is ranked more than once", optionId)
+		}
+	}
+
+	rows, err := c.getBallotRows(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if row.UserId == voterId {
+			if _, _, err := database.DeleteOneById(ctx, db, row, row.ID); err != nil {
+				return err
+			}
+		}
+	}
+	for rank, optionId := range ranking {
+		_, err := database.CreateOne(ctx, db, &CommissionerProposalBallot{
+			ProposalId: c.ID,
+			UserId:     voterId,
+			OptionId:   optionId,
+			Rank:       rank,
+			CastAt:     now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// keep every ballot cast, including changes, in the proposal's ballot log
+	for rank, optionId := range ranking {
+		_, err := database.CreateOne(ctx, db, &CommissionerProposalBallotChange{
+			ProposalId: c.ID,
+			UserId:     voterId,
+			OptionId:   optionId,
+			Rank:       rank,
+			CastAt:     now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CommissionerProposalBallotChange is a log entry of one ranked choice on a
+// ballot cast on a multi-option CommissionerProposal. Unlike
+// CommissionerProposalBallot, whose rows are replaced when a voter casts a
+// new ballot, every ballot is kept, so the log shows how ballots changed.
+// The choices of one ballot share its CastAt.
+type CommissionerProposalBallotChange struct {
+	ID         database.RecordId `json:"id"`
+	ProposalId database.RecordId `json:"proposal_id"`
+	UserId     database.UserId   `json:"user_id"`
+	OptionId   database.RecordId `json:"option_id"`
+	Rank       int               `json:"rank"`
+	CastAt     time.Time         `json:"cast_at"`
+}
+
+func (b *CommissionerProposalBallotChange) GetOwner() database.UserId {
+	return database.InvalidUserId
+}
+
+func (b *CommissionerProposalBallotChange) SetOwner(userId database.UserId) {}
+
+func (b *CommissionerProposalBallotChange) Type() string {
+	return "commissioner_proposal_ballot_change"
+}
+
+func (b *CommissionerProposalBallotChange) GetId() database.RecordId {
+	return b.ID
+}
+
+func (b *CommissionerProposalBallotChange) SetId(id database.RecordId) {
+	b.ID = id
+}
+
+func (b *CommissionerProposalBallotChange) StaticallyValid() error {
+	if b.Rank < 0 {
+		return errors.New("rank must not be negative")
+	}
+	if b.CastAt.IsZero() {
+		return errors.New("ballot change must have a time")
+	}
+	return nil
+}
+
+func (b *CommissionerProposalBallotChange) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	if err := database.ExistsById(ctx, db, &CommissionerProposal{}, b.ProposalId); err != nil {
+		return err
+	}
+	return database.ExistsById(ctx, db, &User{}, b.UserId.RecordId())
+}
+
+// AccessibleTo exposes the ballot log only to those who can view the proposal.
+func (b *CommissionerProposalBallotChange) AccessibleTo(ctx context.Context, db database.Provider) []database.UserId {
+	proposal, err := database.GetExistingRecordById(ctx, db, &CommissionerProposal{}, b.ProposalId)
+	if err != nil {
+		return nil
+	}
+	return proposal.AccessibleTo(ctx, db)
+}
+
+func (b *CommissionerProposalBallotChange) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
+	return []database.UserId{database.SysAdminUserId}
+}
+
+func (b *CommissionerProposalBallotChange) NewRecord() database.CrudRecord {
+	return new(CommissionerProposalBallotChange)
+}
+
+// GetBallotChanges returns the proposal's ballot log, oldest ballot first and
+// each ballot's choices in rank order.
+func (c *CommissionerProposal) GetBallotChanges(ctx context.Context, db database.Provider) ([]*CommissionerProposalBallotChange, error) {
+	changes, err := database.GetAllWhere[*CommissionerProposalBallotChange](ctx, db, func(_ context.Context, b *CommissionerProposalBallotChange) bool {
+		return b.ProposalId == c.ID
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(changes, func(a, b *CommissionerProposalBallotChange) int {
+		if n := a.CastAt.Compare(b.CastAt); n != 0 {
+			return n
+		}
+		return a.Rank - b.Rank
+	})
+	return changes, nil
+}
+
+// TallyCount is the number of ballots counting toward an option in a round.
+type TallyCount struct {
+	OptionId database.RecordId `json:"option_id"`
+	Text     string            `json:"text"`
+	Votes    int               `json:"votes"`
+}
+
+// TallyRound is one round of counting: the votes of each option still in the
+// running, the ballots that no longer rank any of them, and the options
+// eliminated at the end of the round.
+type TallyRound struct {
+	Counts     []TallyCount        `json:"counts"`
+	Exhausted  int                 `json:"exhausted"`
+	Eliminated []database.RecordId `json:"eliminated"`
+}
+
+// ProposalTally is the count of a multi-option proposal's ballots. A
+// plurality count has one round; an instant-runoff count has a round per
+// elimination. Winner is empty if no ballots were cast or the count ended in
+// a tie.
+type ProposalTally struct {
+	Kind    ProposalKind      `json:"kind"`
+	Ballots int               `json:"ballots"`
+	Rounds  []*TallyRound     `json:"rounds"`
+	Winner  database.RecordId `json:"winner"`
+	Tie     bool              `json:"tie"`
+}
+
+// Tally counts the proposal's ballots.
+func (c *CommissionerProposal) Tally(ctx context.Context, db database.Provider) (*ProposalTally, error) {
+	if c.Kind == ProposalKindYesNo {
+		return nil, errors.New("a yes/no proposal has no ballots to tally")
+	}
+	options, err := c.GetOptions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	ballots, err := c.GetBallots(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	rankings := make([][]database.RecordId, 0, len(ballots))
+	for _, ranking := range ballots {
+		rankings = append(rankings, ranking)
+	}
+	return tallyBallots(c.Kind, options, rankings), nil
+}
+
+// tallyBallots counts rankings of options. Each round counts every ballot
+// toward its highest-ranked option still in the running. An option with more
+// than half of the ballots still counting wins; otherwise, for a
+// ranked-choice count, the option with the fewest votes is eliminated and
+// another round is counted. Options tied for the fewest votes are eliminated
+// together only if their combined votes are fewer than the next option's,
+// so that none of them could have overtaken it. Otherwise the tie is broken
+// by the most recent earlier round in which they differ, and the count ends
+// in a tie if none does. A plurality count stops after the first round, won
+// by the option with the most votes.
+func tallyBallots(kind ProposalKind, options []*CommissionerProposalOption, rankings [][]database.RecordId) *ProposalTally {
+	tally := &ProposalTally{Kind: kind, Ballots: len(rankings), Rounds: []*TallyRound{}, Winner: database.InvalidRecordId}
+	remaining := slices.Clone(options)
+	for len(remaining) > 0 {
+		round := &TallyRound{Counts: make([]TallyCount, len(remaining)), Eliminated: []database.RecordId{}}
+		index := make(map[database.RecordId]int, len(remaining))
+		for i, o := range remaining {
+			round.Counts[i] = TallyCount{OptionId: o.ID, Text: o.Text}
+			index[o.ID] = i
+		}
+		for _, ranking := range rankings {
+			counted := false
+			for _, optionId := range ranking {
+				if i, ok := index[optionId]; ok {
+					round.Counts[i].Votes++
+					counted = true
+					break
+				}
+			}
+			if !counted {
+				round.Exhausted++
+			}
+		}
+		tally.Rounds = append(tally.Rounds, round)
+
+		active := len(rankings) - round.Exhausted
+		if active == 0 {
+			return tally
+		}
+		most, fewest := 0, active
+		for _, count := range round.Counts {
+			most = max(most, count.Votes)
+			fewest = min(fewest, count.Votes)
+		}
+		leaders := 0
+		for _, count := range round.Counts {
+			if count.Votes == most {
+				leaders++
+				tally.Winner = count.OptionId
+			}
+		}
+		if most*2 > active || (kind == ProposalKindPlurality && leaders == 1) {
+			return tally
+		}
+		tally.Winner = database.InvalidRecordId
+		if kind == ProposalKindPlurality || most == fewest {
+			tally.Tie = true
+			return tally
+		}
+
+		var lowest []database.RecordId
+		nextLowest := most
+		for _, count := range round.Counts {
+			if count.Votes == fewest {
+				lowest = append(lowest, count.OptionId)
+			} else {
+				nextLowest = min(nextLowest, count.Votes)
+			}
+		}
+		if len(lowest) > 1 && len(lowest)*fewest >= nextLowest {
+			lowest = breakEliminationTie(tally.Rounds[:len(tally.Rounds)-1], lowest)
+			if len(lowest) > 1 {
+				tally.Tie = true
+				return tally
+			}
+		}
+
+		var next []*CommissionerProposalOption
+		for _, o := range remaining {
+			if slices.Contains(lowest, o.ID) {
+				round.Eliminated = append(round.Eliminated, o.ID)
+			} else {
+				next = append(next, o)
+			}
+		}
+		remaining = next
+	}
+	return tally
+}
+
+// breakEliminationTie narrows the options tied for elimination down to those
+// with the fewest votes in the most recent of the earlier rounds in which
+// they differ. It returns them unchanged if they were tied in every round.
+func breakEliminationTie(earlier []*TallyRound, tied []database.RecordId) []database.RecordId {
+	for i := len(earlier) - 1; i >= 0; i-- {
+		votes := make(map[database.RecordId]int, len(tied))
+		for _, count := range earlier[i].Counts {
+			if slices.Contains(tied, count.OptionId) {
+				votes[count.OptionId] = count.Votes
+			}
+		}
+		fewest := votes[tied[0]]
+		for _, optionId := range tied {
+			fewest = min(fewest, votes[optionId])
+		}
+		var lowest []database.RecordId
+		for _, optionId := range tied {
+			if votes[optionId] == fewest {
+				lowest = append(lowest, optionId)
+			}
+		}
+		if len(lowest) < len(tied) {
+			return lowest
+		}
+	}
+	return tied
+}
+
+// optionStatus reports whether a multi-option proposal without a deadline
+// has been decided, which happens once every possible voter has cast a
+// ballot: accepted if the Tally has a winner, rejected on a tie.
+func (c *CommissionerProposal) optionStatus(ctx context.Context, db database.Provider) (accepted, rejected bool, err error) {
+	voterIds, err := c.GetAllVoterIds(ctx, db)
+	if err != nil {
+		return false, false, err
+	}
+	tally, err := c.Tally(ctx, db)
+	if err != nil {
+		return false, false, err
+	}
+	if tally.Ballots < len(voterIds) || tally.Ballots < c.Quorum {
+		return false, false, nil
+	}
+	if tally.Winner == database.InvalidRecordId {
+		return false, true, nil
+	}
+	return true, false, nil
+}
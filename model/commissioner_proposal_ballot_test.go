@@ -0,0 +1,209 @@
+package model
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"intraclub/database"
+)
+
+// newStoredMultiOptionProposal creates a proposal of the given kind with the
+// given options, and returns it with its options and possible voters.
+func newStoredMultiOptionProposal(t *testing.T, db database.Provider, kind ProposalKind, deadline time.Time, texts ...string) (*CommissionerProposal, []*CommissionerProposalOption, []database.UserId) {
+	ctx := context.Background()
+	season, _ := newDefaultSeasonWithTeams(t, db, 4)
+	proposal := NewCommissionerProposal()
+	proposal.Description = "which night should playoffs be on?"
+	proposal.SeasonId = season.ID
+	proposal.Kind = kind
+	proposal.Deadline = deadline
+	proposal, err := database.CreateOne(ctx, db, proposal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proposal.SetOptions(ctx, db, texts); err != nil {
+		t.Fatal(err)
+	}
+	options, err := proposal.GetOptions(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	voters, err := proposal.GetAllVoterIds(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return proposal, options, voters
+}
+
+func TestCommissionerProposalPluralityBallots(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	proposal, options, voters := newStoredMultiOptionProposal(t, db, ProposalKindPlurality, time.Time{}, "Tue", "Wed", "Thu")
+	tue, wed := options[0].ID, options[1].ID
+
+	if err := proposal.Vote(ctx, db, voters[0], true); err == nil {
+		t.Fatal("expected a yes/no vote on a plurality proposal to fail")
+	}
+	if err := proposal.CastBallot(ctx, db, voters[0], []database.RecordId{tue, wed}); err == nil {
+		t.Fatal("expected ranking more than one option on a plurality proposal to fail")
+	}
+	if err := proposal.CastBallot(ctx, db, voters[0], []database.RecordId{database.RecordId(12345)}); err == nil {
+		t.Fatal("expected choosing an unknown option to fail")
+	}
+
+	// the first voter changes their mind
+	if err := proposal.CastBallot(ctx, db, voters[0], []database.RecordId{tue}); err != nil {
+		t.Fatal(err)
+	}
+	if err := proposal.CastBallot(ctx, db, voters[0], []database.RecordId{wed}); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := proposal.GetBallotChanges(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].OptionId != tue || changes[1].OptionId != wed || changes[1].UserId != voters[0] {
+		t.Fatalf("expected both ballots in the ballot log, got %+v", changes)
+	}
+	if err := proposal.SetOptions(ctx, db, []string{"Mon", "Fri"}); err == nil {
+		t.Fatal("expected options to be frozen once voting started")
+	}
+	for _, voter := range voters[1:] {
+		assertProposalStatus(t, proposal, db, false, false)
+		if err := proposal.CastBallot(ctx, db, voter, []database.RecordId{tue}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertProposalStatus(t, proposal, db, true, false)
+
+	tally, err := proposal.Tally(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tally.Ballots != len(voters) || len(tally.Rounds) != 1 || tally.Winner != tue {
+		t.Fatalf("expected Tue to win a single round, got %+v", tally)
+	}
+	if counts := tally.Rounds[0].Counts; counts[0].Votes != len(voters)-1 || counts[1].Votes != 1 || counts[2].Votes != 0 {
+		t.Fatalf("unexpected counts %+v", counts)
+	}
+}
+
+func TestCommissionerProposalRankedChoiceClosesWithWinner(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	deadline := time.Now().Add(time.Hour)
+	proposal, options, voters := newStoredMultiOptionProposal(t, db, ProposalKindRankedChoice, deadline, "Tue", "Wed", "Thu")
+	if len(voters) < 5 {
+		t.Fatalf("expected at least 5 voters, got %d", len(voters))
+	}
+	tue, wed, thu := options[0].ID, options[1].ID, options[2].ID
+
+	// Tue and Wed tie on first choices, and Thu's voter prefers Wed
+	ballots := [][]database.RecordId{
+		{tue},
+		{tue, thu},
+		{wed},
+		{wed, thu},
+		{thu, wed},
+	}
+	for i, ranking := range ballots {
+		if err := proposal.CastBallot(ctx, db, voters[i], ranking); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := proposal.CastBallot(ctx, db, voters[0], []database.RecordId{tue, tue}); err == nil {
+		t.Fatal("expected ranking an option twice to fail")
+	}
+	// undecided until the deadline
+	assertProposalStatus(t, proposal, db, false, false)
+
+	closed, err := CloseExpiredProposals(ctx, db, deadline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(closed) != 1 || closed[0].Outcome != ProposalOutcomeAccepted || closed[0].WinningOption != wed {
+		t.Fatalf("expected Wed to win by instant-runoff, got %+v", closed)
+	}
+	if err := proposal.CastBallot(ctx, db, voters[0], []database.RecordId{thu}); err == nil {
+		t.Fatal("expected casting a ballot on a closed proposal to fail")
+	}
+
+	tally, err := proposal.Tally(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tally.Rounds) != 2 {
+		t.Fatalf("expected two rounds, got %+v", tally.Rounds)
+	}
+	if eliminated := tally.Rounds[0].Eliminated; len(eliminated) != 1 || eliminated[0] != thu {
+		t.Fatalf("expected Thu eliminated in the first round, got %v", eliminated)
+	}
+	if counts := tally.Rounds[1].Counts; len(counts) != 2 || counts[0].Votes != 2 || counts[1].Votes != 3 {
+		t.Fatalf("expected Thu's ballot to count toward Wed in the second round, got %+v", counts)
+	}
+}
+
+func TestTallyBallotsRankedChoice(t *testing.T) {
+	options := []*CommissionerProposalOption{{ID: 1, Text: "A"}, {ID: 2, Text: "B"}, {ID: 3, Text: "C"}}
+
+	// all three tie in the first round
+	tally := tallyBallots(ProposalKindRankedChoice, options, [][]database.RecordId{{1}, {2}, {3}})
+	if !tally.Tie || tally.Winner != database.InvalidRecordId || len(tally.Rounds) != 1 {
+		t.Fatalf("expected an all-way tie, got %+v", tally)
+	}
+
+	// C is eliminated and its only ballot is exhausted, leaving A and B tied
+	tally = tallyBallots(ProposalKindRankedChoice, options, [][]database.RecordId{{1}, {1}, {2}, {2}, {3}})
+	if !tally.Tie || len(tally.Rounds) != 2 || tally.Rounds[1].Exhausted != 1 {
+		t.Fatalf("expected A and B to tie after C's ballot is exhausted, got %+v", tally)
+	}
+
+	tally = tallyBallots(ProposalKindRankedChoice, options, nil)
+	if tally.Tie || tally.Winner != database.InvalidRecordId {
+		t.Fatalf("expected no winner without ballots, got %+v", tally)
+	}
+
+	tally = tallyBallots(ProposalKindPlurality, options, [][]database.RecordId{{1}, {2}, {2}, {3}})
+	if tally.Winner != 2 || len(tally.Rounds) != 1 {
+		t.Fatalf("expected B to win a plurality, got %+v", tally)
+	}
+}
+
+func TestTallyBallotsTieForLast(t *testing.T) {
+	options := []*CommissionerProposalOption{{ID: 1, Text: "A"}, {ID: 2, Text: "B"}, {ID: 3, Text: "C"}, {ID: 4, Text: "D"}}
+	repeat := func(n int, ranking ...database.RecordId) [][]database.RecordId {
+		rankings := make([][]database.RecordId, n)
+		for i := range rankings {
+			rankings[i] = ranking
+		}
+		return rankings
+	}
+
+	// B and C tie for last with 3 each; eliminating both would hand A the
+	// win, although either one's voters would carry the other past A
+	rankings := slices.Concat(repeat(4, 1), repeat(3, 2, 3), repeat(3, 3, 2))
+	tally := tallyBallots(ProposalKindRankedChoice, options[:3], rankings)
+	if !tally.Tie || tally.Winner != database.InvalidRecordId || len(tally.Rounds) != 1 {
+		t.Fatalf("expected an unbreakable tie for last to end the count, got %+v", tally)
+	}
+
+	// the same tie is broken by the earlier round, in which C had fewer votes
+	// than B: D's ballot brings C level, and C goes out
+	rankings = slices.Concat(repeat(4, 1), repeat(3, 2, 3), repeat(2, 3, 2), repeat(1, 4, 3))
+	tally = tallyBallots(ProposalKindRankedChoice, options, rankings)
+	if tally.Winner != 2 || len(tally.Rounds) != 3 {
+		t.Fatalf("expected B to win once C is eliminated, got %+v", tally)
+	}
+	if eliminated := tally.Rounds[1].Eliminated; len(eliminated) != 1 || eliminated[0] != 3 {
+		t.Fatalf("expected only C eliminated in the second round, got %v", eliminated)
+	}
+
+	// options whose combined votes can't reach the next one go out together
+	rankings = slices.Concat(repeat(5, 1), repeat(4, 2), repeat(1, 3), repeat(1, 4))
+	tally = tallyBallots(ProposalKindRankedChoice, options, rankings)
+	if eliminated := tally.Rounds[0].Eliminated; len(eliminated) != 2 {
+		t.Fatalf("expected C and D eliminated together, got %v", eliminated)
+	}
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"intraclub/database"
+)
+
+// A CommissionerProposal has a discussion thread of Comments (with replies
+// and reactions, as on a Blurb) whose Proposal is the proposal's ID. Only the
+// proposal's eligible voters, the season's commissioners and team captains,
+// may read or take part in the thread.
+
+// CanUserCommentOrReact returns an error unless the user is an eligible voter
+// on the proposal.
+func (c *CommissionerProposal) CanUserCommentOrReact(ctx context.Context, db database.Provider, u database.UserId) error {
+	voters, err := c.GetAllVoterIds(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(voters, u) {
+		return fmt.Errorf("user '%s' is not an eligible voter on proposal '%s'", u, c.ID)
+	}
+	return nil
+}
+
+// commentModerators are the season's commissioners.
+func (c *CommissionerProposal) commentModerators(ctx context.Context, db database.Provider) []database.UserId {
+	return c.EditableBy(ctx, db)
+}
+
+// commentReaders are the eligible voters, like the proposal itself.
+func (c *CommissionerProposal) commentReaders(ctx context.Context, db database.Provider) []database.UserId {
+	return c.AccessibleTo(ctx, db)
+}
+
+// GetComments returns the proposal's discussion thread, oldest first.
+func (c *CommissionerProposal) GetComments(ctx context.Context, db database.Provider) ([]*Comment, error) {
+	comments, err := database.GetAllWhere[*Comment](ctx, db, func(_ context.Context, comment *Comment) bool {
+		return comment.Proposal == c.ID
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(comments, func(a, b *Comment) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return comments, nil
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"intraclub/database"
+)
+
+func newStoredProposalComment(t *testing.T, db database.Provider, user database.UserId, proposal *CommissionerProposal, content string) *Comment {
+	c := NewComment()
+	c.Owner = user
+	c.Proposal = proposal.ID
+	c.Content = content
+	v, err := database.CreateOne(context.Background(), db, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestProposalCommentThread(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, proposal := newStoredCommissionerProposal(t, db, false)
+	captain := getAnyTeamCaptain(t, db, season)
+	outsider := newStoredUser(t, db)
+
+	first := newStoredProposalComment(t, db, captain, proposal, "I think the new tiebreak format is too long")
+	reply := NewComment()
+	reply.Owner = captain
+	reply.Proposal = proposal.ID
+	reply.ReplyTo = first.ID
+	reply.Content = "Though it beats a third set"
+	if _, err := database.CreateOne(ctx, db, reply); err != nil {
+		t.Fatal(err)
+	}
+
+	// only eligible voters may take part
+	c := NewComment()
+	c.Owner = outsider.ID
+	c.Proposal = proposal.ID
+	c.Content = "content"
+	if _, err := database.CreateOne(ctx, db, c); err == nil {
+		t.Fatal("expected a comment by a non-voter to be rejected")
+	}
+	if err := first.React(ctx, db, outsider.ID, ThumbsUp); err == nil {
+		t.Fatal("expected a reaction by a non-voter to be rejected")
+	}
+	if err := database.Validate(ctx, db, &CommentReaction{CommentId: first.ID, UserId: outsider.ID, ReactionType: ThumbsUp}); err == nil {
+		t.Fatal("expected a reaction row by a non-voter to be invalid")
+	}
+	if err := first.React(ctx, db, captain, ThumbsUp); err != nil {
+		t.Fatal(err)
+	}
+
+	// and read the thread
+	if !database.NewWithAccessControl[*Comment](ctx, db, captain).CanUserAccess(first) {
+		t.Fatal("expected an eligible voter to be able to read the thread")
+	}
+	if database.NewWithAccessControl[*Comment](ctx, db, outsider.ID).CanUserAccess(first) {
+		t.Fatal("expected a non-voter not to be able to read the thread")
+	}
+	if kinds := searchKinds(t, db, outsider.ID, "tiebreak"); len(kinds) != 0 {
+		t.Fatalf("expected search to hide the thread from a non-voter, got %v", kinds)
+	}
+	if kinds := searchKinds(t, db, captain, "tiebreak"); kinds["comment"] != 1 {
+		t.Fatalf("expected search to find the comment for a voter, got %v", kinds)
+	}
+
+	thread, err := proposal.GetComments(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thread) != 2 || thread[0].ID != first.ID {
+		t.Fatalf("expected the two comments oldest first, got %v", thread)
+	}
+
+	if _, _, err := database.DeleteOneById(ctx, db, &CommissionerProposal{}, proposal.ID); err != nil {
+		t.Fatal(err)
+	}
+	comments, err := database.GetAll[*Comment](ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 0 {
+		t.Fatalf("expected deleting the proposal to delete its thread, got %d comments", len(comments))
+	}
+}
+
+func TestCommentMustBeOnOneTarget(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	blurb, season := newDefaultBlurb(t, db)
+	proposal := newStoredCommissionerProposalForSeason(t, db, season, false)
+	captain := getAnyTeamCaptain(t, db, season)
+
+	c := newValidComment(captain, blurb.ID)
+	c.Proposal = proposal.ID
+	if _, err := database.CreateOne(ctx, db, c); err == nil {
+		t.Fatal("expected a comment on both a blurb and a proposal to be rejected")
+	}
+	c = newValidComment(captain, BlurbId(database.InvalidRecordId))
+	if _, err := database.CreateOne(ctx, db, c); err == nil {
+		t.Fatal("expected a comment on neither a blurb nor a proposal to be rejected")
+	}
+
+	// replies stay on the same target
+	onBlurb := newStoredComment(t, db, captain, blurb)
+	reply := NewComment()
+	reply.Owner = captain
+	reply.Proposal = proposal.ID
+	reply.ReplyTo = onBlurb.ID
+	reply.Content = "content"
+	if _, err := database.CreateOne(ctx, db, reply); err == nil {
+		t.Fatal("expected a reply on a proposal to a blurb's comment to be rejected")
+	}
+}
@@ -0,0 +1,260 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"intraclub/database"
+)
+
+// ProposalOutcome is the frozen result of a CommissionerProposal whose voting
+// has closed.
+type ProposalOutcome int
+
+const (
+	ProposalOutcomePending ProposalOutcome = iota
+	ProposalOutcomeAccepted
+	ProposalOutcomeRejected
+	// ProposalOutcomeNoQuorum is a proposal rejected because too few votes
+	// were cast by the deadline
+	ProposalOutcomeNoQuorum
+	ProposalOutcomeInvalid
+)
+
+func (o ProposalOutcome) String() string {
+	switch o {
+	case ProposalOutcomePending:
+		return "pending"
+	case ProposalOutcomeAccepted:
+		return "accepted"
+	case ProposalOutcomeRejected:
+		return "rejected"
+	case ProposalOutcomeNoQuorum:
+		return "no quorum"
+	}
+	return "invalid"
+}
+
+func (o ProposalOutcome) StaticallyValid() error {
+	if o < 0 || o >= ProposalOutcomeInvalid {
+		return fmt.Errorf("invalid proposal outcome: %d", o)
+	}
+	return nil
+}
+
+// Closed returns true once voting on the proposal has been closed.
+func (c *CommissionerProposal) Closed() bool {
+	return !c.ClosedAt.IsZero()
+}
+
+// votingStarted returns true once anyone has voted on the proposal or cast a
+// ballot on its options.
+func (c *CommissionerProposal) votingStarted(ctx context.Context, db database.Provider) (bool, error) {
+	votes, err := c.getVoteRows(ctx, db)
+	if err != nil || len(votes) > 0 {
+		return len(votes) > 0, err
+	}
+	ballots, err := c.getBallotRows(ctx, db)
+	return len(ballots) > 0, err
+}
+
+// DeadlinePassed returns true if the proposal has a voting deadline at or
+// before now.
+func (c *CommissionerProposal) DeadlinePassed(now time.Time) bool {
+	return !c.Deadline.IsZero() && !now.Before(c.Deadline)
+}
+
+// FinalOutcome computes the outcome of the proposal from the votes cast: it
+// is accepted if enough votes are in favor to pass it (see VotesToPassOrFail)
+// and the quorum was met, and rejected otherwise. A multi-option proposal is
+// accepted if the quorum was met and its Tally has a winner.
+func (c *CommissionerProposal) FinalOutcome(ctx context.Context, db database.Provider) (ProposalOutcome, error) {
+	if c.Kind != ProposalKindYesNo {
+		tally, err := c.Tally(ctx, db)
+		if err != nil {
+			return ProposalOutcomeInvalid, err
+		}
+		if tally.Ballots < c.Quorum {
+			return ProposalOutcomeNoQuorum, nil
+		}
+		if tally.Winner == database.InvalidRecordId {
+			return ProposalOutcomeRejected, nil
+		}
+		return ProposalOutcomeAccepted, nil
+	}
+
+	voterIds, err := c.GetAllVoterIds(ctx, db)
+	if err != nil {
+		return ProposalOutcomeInvalid, err
+	}
+	votesNeededToPass, _ := c.VotesToPassOrFail(voterIds)
+	rows, err := c.getVoteRows(ctx, db)
+	if err != nil {
+		return ProposalOutcomeInvalid, err
+	}
+	if len(rows) < c.Quorum {
+		return ProposalOutcomeNoQuorum, nil
+	}
+	votesInFavor := 0
+	for _, row := range rows {
+		if row.Vote {
+			votesInFavor++
+		}
+	}
+	if votesInFavor >= votesNeededToPass {
+		return ProposalOutcomeAccepted, nil
+	}
+	return ProposalOutcomeRejected, nil
+}
+
+// Close closes voting on the proposal at the given time, freezing its
+// FinalOutcome (and, for an accepted multi-option proposal, the winning
+// option).
+func (c *CommissionerProposal) Close(ctx context.Context, db database.Provider, now time.Time) error {
+	if c.Closed() {
+		return fmt.Errorf("proposal %s is already closed", c.ID)
+	}
+	outcome, err := c.FinalOutcome(ctx, db)
+	if err != nil {
+		return err
+	}
+	winner := database.InvalidRecordId
+	if c.Kind != ProposalKindYesNo && outcome == ProposalOutcomeAccepted {
+		tally, err := c.Tally(ctx, db)
+		if err != nil {
+			return err
+		}
+		winner = tally.Winner
+	}
+	// raw provider update, which bypasses the PreUpdate hook that keeps the
+	// outcome from being changed through generic CRUD
+	updated := *c
+	updated.ClosedAt = now
+	updated.Outcome = outcome
+	updated.WinningOption = winner
+	if err := db.Update(ctx, &updated); err != nil {
+		return err
+	}
+	*c = updated
+	return nil
+}
+
+// CloseExpiredProposals closes every open proposal whose voting deadline has
+// passed as of now, returning the proposals it closed. A proposal that fails
+// to close doesn't stop the others from closing; the errors are joined.
+func CloseExpiredProposals(ctx context.Context, db database.Provider, now time.Time) ([]*CommissionerProposal, error) {
+	expired, err := database.GetAllWhere[*CommissionerProposal](ctx, db, func(_ context.Context, c *CommissionerProposal) bool {
+		return !c.Closed() && c.DeadlinePassed(now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	closed := make([]*CommissionerProposal, 0, len(expired))
+	var errs []error
+	for _, c := range expired {
+		if err := c.Close(ctx, db, now); err != nil {
+			errs = append(errs, fmt.Errorf("closing proposal %s: %w", c.ID, err))
+			continue
+		}
+		closed = append(closed, c)
+	}
+	return closed, errors.Join(errs...)
+}
+
+// GetParticipantEmails returns the email addresses of the proposal's possible
+// voters, for notifying them.
+func (c *CommissionerProposal) GetParticipantEmails(ctx context.Context, db database.Provider) ([]EmailAddress, error) {
+	voterIds, err := c.GetAllVoterIds(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	emails := make([]EmailAddress, 0, len(voterIds))
+	for _, id := range voterIds {
+		user, err := database.GetExistingRecordById(ctx, db, &User{}, id.RecordId())
+		if err != nil {
+			return nil, err
+		}
+		if user.Email != "" && !slices.Contains(emails, user.Email) {
+			emails = append(emails, user.Email)
+		}
+	}
+	return emails, nil
+}
+
+// CommissionerProposalVoteChange is a log entry of a vote cast on a
+// CommissionerProposal. Unlike CommissionerProposalVote, which holds each
+// voter's current vote, every vote cast (including each change of mind) gets
+// its own entry.
+type CommissionerProposalVoteChange struct {
+	ID         database.RecordId `json:"id"`
+	ProposalId database.RecordId `json:"proposal_id"`
+	UserId     database.UserId   `json:"user_id"`
+	Vote       bool              `json:"vote"`
+	CastAt     time.Time         `json:"cast_at"`
+}
+
+func (v *CommissionerProposalVoteChange) GetOwner() database.UserId {
+	return database.InvalidUserId
+}
+
+func (v *CommissionerProposalVoteChange) SetOwner(userId database.UserId) {}
+
+func (v *CommissionerProposalVoteChange) Type() string {
+	return "commissioner_proposal_vote_change"
+}
+
+func (v *CommissionerProposalVoteChange) GetId() database.RecordId {
+	return v.ID
+}
+
+func (v *CommissionerProposalVoteChange) SetId(id database.RecordId) {
+	v.ID = id
+}
+
+func (v *CommissionerProposalVoteChange) StaticallyValid() error {
+	if v.CastAt.IsZero() {
+		return fmt.Errorf("vote change must have a time")
+	}
+	return nil
+}
+
+func (v *CommissionerProposalVoteChange) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	if err := database.ExistsById(ctx, db, &CommissionerProposal{}, v.ProposalId); err != nil {
+		return err
+	}
+	return database.ExistsById(ctx, db, &User{}, v.UserId.RecordId())
+}
+
+// AccessibleTo exposes the vote log only to those who can view the proposal.
+func (v *CommissionerProposalVoteChange) AccessibleTo(ctx context.Context, db database.Provider) []database.UserId {
+	proposal, err := database.GetExistingRecordById(ctx, db, &CommissionerProposal{}, v.ProposalId)
+	if err != nil {
+		return nil
+	}
+	return proposal.AccessibleTo(ctx, db)
+}
+
+func (v *CommissionerProposalVoteChange) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
+	return []database.UserId{database.SysAdminUserId}
+}
+
+func (v *CommissionerProposalVoteChange) NewRecord() database.CrudRecord {
+	return new(CommissionerProposalVoteChange)
+}
+
+// GetVoteChanges returns the proposal's vote log, oldest first.
+func (c *CommissionerProposal) GetVoteChanges(ctx context.Context, db database.Provider) ([]*CommissionerProposalVoteChange, error) {
+	changes, err := database.GetAllWhere[*CommissionerProposalVoteChange](ctx, db, func(_ context.Context, v *CommissionerProposalVoteChange) bool {
+		return v.ProposalId == c.ID
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(changes, func(a, b *CommissionerProposalVoteChange) int {
+		return a.CastAt.Compare(b.CastAt)
+	})
+	return changes, nil
+}
@@ -0,0 +1,236 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"intraclub/database"
+)
+
+// newStoredProposalWithWindow creates a majority proposal with the given
+// voting deadline and quorum, and returns it with its possible voters.
+func newStoredProposalWithWindow(t *testing.T, db database.Provider, deadline time.Time, quorum int) (*CommissionerProposal, []database.UserId) {
+	season, _ := newDefaultSeasonWithTeams(t, db, 4)
+	proposal := NewCommissionerProposal()
+	proposal.Description = "test description"
+	proposal.SeasonId = season.ID
+	proposal.Deadline = deadline
+	proposal.Quorum = quorum
+	proposal, err := database.CreateOne(context.Background(), db, proposal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	voters, err := proposal.GetAllVoterIds(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return proposal, voters
+}
+
+func TestCommissionerProposalDeadlineClosesWithOutcome(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	deadline := time.Now().Add(time.Hour)
+	proposal, voters := newStoredProposalWithWindow(t, db, deadline, 0)
+	votesToPass, _ := proposal.VotesToPassOrFail(voters)
+
+	// a voter changes their mind before the deadline
+	if err := proposal.Vote(ctx, db, voters[0], false); err != nil {
+		t.Fatal(err)
+	}
+	for _, voter := range voters[:votesToPass] {
+		if err := proposal.Vote(ctx, db, voter, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// undecided until the deadline, even with enough votes in favor
+	assertProposalStatus(t, proposal, db, false, false)
+
+	changes, err := proposal.GetVoteChanges(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != votesToPass+1 || changes[0].Vote || !changes[1].Vote || changes[0].UserId != voters[0] {
+		t.Fatalf("expected every vote cast to be logged in order, got %+v", changes)
+	}
+	for _, change := range changes {
+		if change.CastAt.IsZero() {
+			t.Fatal("expected every logged vote to have a time")
+		}
+	}
+
+	// nothing closes before the deadline
+	closed, err := CloseExpiredProposals(ctx, db, deadline.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(closed) != 0 {
+		t.Fatalf("expected nothing closed before the deadline, got %d", len(closed))
+	}
+
+	closed, err = CloseExpiredProposals(ctx, db, deadline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(closed) != 1 || closed[0].ID != proposal.ID || closed[0].Outcome != ProposalOutcomeAccepted {
+		t.Fatalf("expected the proposal to close accepted, got %+v", closed)
+	}
+	proposal, err = database.GetExistingRecordById(ctx, db, &CommissionerProposal{}, proposal.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proposal.Closed() || !proposal.ClosedAt.Equal(deadline) {
+		t.Fatalf("expected the proposal to be stored closed at the deadline, got %v", proposal.ClosedAt)
+	}
+	assertProposalStatus(t, proposal, db, true, false)
+
+	// votes are frozen once closed
+	if err := proposal.Vote(ctx, db, voters[0], false); err == nil {
+		t.Fatal("expected voting on a closed proposal to fail")
+	}
+	assertProposalStatus(t, proposal, db, true, false)
+
+	// and it isn't closed again
+	closed, err = CloseExpiredProposals(ctx, db, deadline.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(closed) != 0 {
+		t.Fatalf("expected a closed proposal not to be closed again, got %d", len(closed))
+	}
+
+	// nor can the outcome be changed through an update
+	updated := copyProposal(proposal)
+	updated.Outcome = ProposalOutcomeRejected
+	if err := database.UpdateOne(ctx, db, updated); err == nil {
+		t.Fatal("expected changing the outcome to fail")
+	}
+}
+
+func TestCommissionerProposalDeadlineWithoutQuorum(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	deadline := time.Now().Add(time.Hour)
+	proposal, voters := newStoredProposalWithWindow(t, db, deadline, 0)
+	votesToPass, _ := proposal.VotesToPassOrFail(voters)
+
+	// require every voter to take part
+	proposal.Quorum = len(voters)
+	if err := database.UpdateOne(ctx, db, proposal); err != nil {
+		t.Fatal(err)
+	}
+	proposal.Quorum = len(voters) + 1
+	if err := database.UpdateOne(ctx, db, proposal); err == nil {
+		t.Fatal("expected a quorum larger than the possible voters to fail")
+	}
+	proposal.Quorum = len(voters)
+
+	for _, voter := range voters[:votesToPass] {
+		if err := proposal.Vote(ctx, db, voter, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := proposal.Close(ctx, db, deadline); err != nil {
+		t.Fatal(err)
+	}
+	if proposal.Outcome != ProposalOutcomeNoQuorum {
+		t.Fatalf("expected no quorum, got %s", proposal.Outcome)
+	}
+	assertProposalStatus(t, proposal, db, false, true)
+}
+
+func TestCommissionerProposalVotingAfterDeadlineRefused(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	proposal, voters := newStoredProposalWithWindow(t, db, time.Now().Add(time.Hour), 0)
+	if err := proposal.Vote(ctx, db, voters[0], true); err != nil {
+		t.Fatal(err)
+	}
+
+	// the window is locked once voting has started
+	updated := *proposal
+	updated.Deadline = time.Time{}
+	if err := database.UpdateOne(ctx, db, &updated); err == nil {
+		t.Fatal("expected removing the deadline after voting started to fail")
+	}
+	updated = *proposal
+	updated.Quorum = 1
+	if err := database.UpdateOne(ctx, db, &updated); err == nil {
+		t.Fatal("expected changing the quorum after voting started to fail")
+	}
+
+	// the deadline passes before the proposal is closed: a raw provider update,
+	// which bypasses the PreUpdate hook, stands in for the clock moving on
+	proposal.Deadline = time.Now().Add(-time.Minute)
+	if err := db.Update(ctx, proposal); err != nil {
+		t.Fatal(err)
+	}
+	if err := proposal.Vote(ctx, db, voters[1], true); err == nil {
+		t.Fatal("expected voting after the deadline to fail")
+	}
+}
+
+func TestCommissionerProposalQuorumWithoutDeadline(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	proposal, voters := newStoredProposalWithWindow(t, db, time.Time{}, 0)
+	votesToPass, _ := proposal.VotesToPassOrFail(voters)
+	proposal.Quorum = votesToPass + 1
+	if err := database.UpdateOne(ctx, db, proposal); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, voter := range voters[:votesToPass] {
+		if err := proposal.Vote(ctx, db, voter, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// enough in favor, but not enough votes cast
+	assertProposalStatus(t, proposal, db, false, false)
+
+	if err := proposal.Vote(ctx, db, voters[votesToPass], false); err != nil {
+		t.Fatal(err)
+	}
+	assertProposalStatus(t, proposal, db, true, false)
+}
+
+func TestCommissionerProposalCreatedOpen(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+
+	// a generic create request can't start a proposal already closed
+	requested := NewCommissionerProposal()
+	requested.ClosedAt = time.Now()
+	requested.Outcome = ProposalOutcomeAccepted
+	requested.WinningOption = database.NewRecordId()
+	requested.RulesetId = RulesetId(database.NewRecordId())
+	requested.AppliedRevision = RulesetId(database.NewRecordId())
+	if err := requested.PreCreateRequest(ctx, db, database.InvalidUserId); err != nil {
+		t.Fatal(err)
+	}
+	if requested.Closed() || requested.Outcome != ProposalOutcomePending || requested.WinningOption != database.InvalidRecordId {
+		t.Fatalf("expected the workflow fields to be cleared, got %+v", requested)
+	}
+	if !requested.RulesetId.Empty() || !requested.AppliedRevision.Empty() {
+		t.Fatalf("expected the amended ruleset to be cleared, got %+v", requested)
+	}
+
+	// nor can a proposal that closed with nobody voting take on amendments
+	deadline := time.Now().Add(time.Hour)
+	proposal, _ := newStoredProposalWithWindow(t, db, deadline, 0)
+	if err := proposal.Close(ctx, db, deadline); err != nil {
+		t.Fatal(err)
+	}
+	ruleset := newValidStoredRulesetWithOneSection(t, db)
+	sections, err := ruleset.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = proposal.SetAmendments(ctx, db, ruleset.ID, []*RuleAmendment{
+		{Type: RuleAmendmentTypeRemoveSection, TargetSection: sections[0]},
+	})
+	if err == nil {
+		t.Fatal("expected amendments on a closed proposal to be refused")
+	}
+}
@@ -54,9 +54,10 @@ func (id FormatId) String() string {
 //   - old guy / young guy
 //   - young guy / young guy.
 type Format struct {
-	ID     FormatId        `json:"id"`      // unique ID for the Format
-	UserId database.UserId `json:"user_id"` // owner of the Format
-	Name   string          `json:"name"`    // name for the Format, e.g. "Men's Intraclub 1/2/3"
+	ID            FormatId        `json:"id"`              // unique ID for the Format
+	UserId        database.UserId `json:"user_id"`         // owner of the Format
+	Name          string          `json:"name"`            // name for the Format, e.g. "Men's Intraclub 1/2/3"
+	MatchWinBonus int             `json:"match_win_bonus"` // bonus points for winning a team match overall
 }
 
 // API/JSON shape decision: the former inline `possible_ratings`
@@ -146,6 +147,9 @@ func (f *Format) StaticallyValid() error {
 	if f.Name == "" {
 		return errors.New("format has no name")
 	}
+	if f.MatchWinBonus < 0 {
+		return errors.New("format match win bonus can't be negative")
+	}
 	return nil
 }
 
@@ -220,8 +224,8 @@ func (f *Format) GetLines(ctx context.Context, db database.Provider) ([]FormatLi
 // SetLines replaces this format's lines with the provided list, preserving
 // order as FormatIndex values in the FormatLine join table. The format must
 // already have an assigned ID (i.e. be created). It rejects an empty list,
-// duplicate / reversed-duplicate lines, and lines whose ratings are not among
-// the format's possible ratings.
+// duplicate / reversed-duplicate lines, lines whose ratings are not among the
+// format's possible ratings, and lines worth negative points.
 func (f *Format) SetLines(ctx context.Context, db database.Provider, lines []FormatLine) error {
 	if len(lines) == 0 {
 		return errors.New("format has no lines")
@@ -232,6 +236,9 @@ func (f *Format) SetLines(ctx context.Context, db database.Provider, lines []For
 		return err
 	}
 	for i, line1 := range lines {
+		if line1.Points < 0 {
+			return fmt.Errorf("line %d can't be worth negative points", i)
+		}
 		if !IsRatingInOptionsList(possibleRatings, line1.Player1Rating) {
 			return fmt.Errorf("rating for player 1 in line %d (%s) is not in possible options list", i, line1.Player1Rating)
 		}
@@ -262,6 +269,7 @@ func (f *Format) SetLines(ctx context.Context, db database.Provider, lines []For
 			FormatIndex:   i,
 			Player1Rating: line.Player1Rating,
 			Player2Rating: line.Player2Rating,
+			Points:        line.Points,
 		}); err != nil {
 			return err
 		}
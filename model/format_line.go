@@ -16,12 +16,25 @@ import (
 // FormatIndex preserves the ordering of lines within a format; it is the value
 // referenced by `LineupPairing.FormatLineIndex`. A natural unique constraint
 // on (FormatId, FormatIndex) prevents two lines from sharing a position.
+//
+// Points is what winning the line is worth toward the team match, e.g. 2 for
+// a league's top line; zero (unset) counts as 1, so every line is worth the
+// same by default.
 type FormatLine struct {
 	ID            database.RecordId `json:"id"`
 	FormatId      FormatId          `json:"format_id"`
 	FormatIndex   int               `json:"format_index"`
 	Player1Rating RatingId          `json:"player_1_rating"`
 	Player2Rating RatingId          `json:"player_2_rating"`
+	Points        int               `json:"points"`
+}
+
+// WinPoints returns the points a win on this line is worth.
+func (l *FormatLine) WinPoints() int {
+	if l.Points == 0 {
+		return 1
+	}
+	return l.Points
 }
 
 // EquivalentTo reports whether two lines pair the same two ratings, regardless
@@ -59,6 +72,9 @@ func (l *FormatLine) SetId(id database.RecordId) {
 }
 
 func (l *FormatLine) StaticallyValid() error {
+	if l.Points < 0 {
+		return fmt.Errorf("line %d can't be worth negative points", l.FormatIndex)
+	}
 	return nil
 }
 
@@ -65,6 +65,7 @@ type IndividualMatch struct {
 	SecondaryValue int
 	WinOverride    bool
 	Status         IndividualMatchStatus
+	Outcome        MatchOutcome        // how the match was decided, see MarkOutcome
 	_structure     *ScoringStructure   `json:"-" bson:"-"`
 	_subStructures []*ScoringStructure `json:"-" bson:"-"`
 	_completed     []CompletedSecondary
@@ -116,6 +117,9 @@ func (s *IndividualMatch) StaticallyValid() error {
 	if s.SecondaryValue < 0 {
 		return fmt.Errorf("secondary value is negative")
 	}
+	if !s.Outcome.Valid() {
+		return fmt.Errorf("match outcome %d is not valid", s.Outcome)
+	}
 	return nil
 }
 
@@ -196,15 +200,21 @@ func (s *IndividualMatch) WonSecondary(opp *IndividualMatch) bool {
 	return currentSubstructure.WinningScore(s.SecondaryValue, opp.SecondaryValue)
 }
 
+// MarkStatus sets this side's status and stores the opponent with the
+// matching status and this side's Outcome. A win for this side is a loss for
+// the opponent, and a double default is a loss for both.
 func (s *IndividualMatch) MarkStatus(ctx context.Context, db database.Provider, newStatus IndividualMatchStatus, opp *IndividualMatch) error {
 	s.Status = newStatus
 
 	oppStatus := MatchInProgress
 	if newStatus == MatchWon {
 		oppStatus = MatchLost
+	} else if newStatus == MatchLost && s.Outcome == OutcomeDoubleDefault {
+		oppStatus = MatchLost
 	}
 
 	opp.Status = oppStatus
+	opp.Outcome = s.Outcome
 	return database.UpdateOne(ctx, db, opp)
 }
 
@@ -310,9 +320,19 @@ func (s *IndividualMatch) GetSecondaryPointTotal() int {
 	return output
 }
 
-// PostDelete cascades deletion to this match's match_editor child rows.
-// Without this, deleting a match would orphan those rows (see #97).
+// PostUpdate invalidates cached career statistics, as any update may record
+// or change a result.
+func (s *IndividualMatch) PostUpdate(ctx context.Context, db database.Provider) error {
+	InvalidateCareerStats()
+	return nil
+}
+
+// PostDelete cascades deletion to this match's match_editor,
+// match_score_event and score_report child rows. Without this, deleting a
+// match would orphan those rows (see #97). It also invalidates cached career
+// statistics.
 func (s *IndividualMatch) PostDelete(ctx context.Context, db database.Provider) error {
+	InvalidateCareerStats()
 	editors, err := database.GetAllWhere[*MatchEditor](ctx, db, func(_ context.Context, e *MatchEditor) bool {
 		return e.MatchId == s.ID
 	})
@@ -324,6 +344,27 @@ func (s *IndividualMatch) PostDelete(ctx context.Context, db database.Provider)
 			return err
 		}
 	}
+	events, err := database.GetAllWhere[*MatchScoreEvent](ctx, db, func(_ context.Context, e *MatchScoreEvent) bool {
+		return e.MatchId == s.ID
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if _, _, err := database.DeleteOneById(ctx, db, e, e.ID); err != nil {
+			return err
+		}
+	}
+	// the pair's report is shared by both sides, so it goes with either one
+	report, err := s.GetScoreReport(ctx, db)
+	if err != nil {
+		return err
+	}
+	if report != nil {
+		if _, _, err := database.DeleteOneById(ctx, db, report, report.ID.RecordId()); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
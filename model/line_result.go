@@ -0,0 +1,151 @@
+package model
+
+import (
+	"context"
+
+	"intraclub/database"
+)
+
+// LineResult is one side of a completed IndividualMatch, resolved through the
+// TeamMatchIndividualMatch join table to the LineupPairing that played it. It
+// is the building block for the player / partner statistics, which all need
+// to know who played a line, who they played against and how it went.
+type LineResult struct {
+	TeamMatch       *TeamMatch
+	SeasonId        SeasonId         // season of the team match's week, InvalidRecordId if unassigned
+	Week            *Week            // week the team match was played in
+	Pairing         *LineupPairing   // pairing that played this side of the match
+	Match           *IndividualMatch // this side's score
+	OpponentPairing *LineupPairing   // pairing on the other side, nil if there is no opponent
+	Opponent        *IndividualMatch // the other side's score, nil if there is no opponent
+	Treatment       OutcomeTreatment // how the match's outcome counts in its season
+}
+
+// Won reports whether this side won the match.
+func (r *LineResult) Won() bool {
+	return r.Match.Status == MatchWon
+}
+
+// MainAgainst returns the opponent's main value (e.g. sets), or zero if the
+// match has no opponent.
+func (r *LineResult) MainAgainst() int {
+	if r.Opponent == nil {
+		return 0
+	}
+	return r.Opponent.MainValue
+}
+
+// SecondaryAgainst returns the opponent's secondary value (e.g. games), or
+// zero if the match has no opponent.
+func (r *LineResult) SecondaryAgainst() int {
+	if r.Opponent == nil {
+		return 0
+	}
+	return r.Opponent.SecondaryValue
+}
+
+// HasPlayer reports whether the given user played on this side of the match.
+func (r *LineResult) HasPlayer(userId database.UserId) bool {
+	return r.Pairing.Player1 == userId || r.Pairing.Player2 == userId
+}
+
+// isCompleted reports whether an IndividualMatch has a final result. Both
+// sides of a double default are lost.
+func isCompleted(m *IndividualMatch) bool {
+	return m.Status == MatchWon || m.Status == MatchLost
+}
+
+// GetCompletedLineResults walks every completed IndividualMatch assigned to a
+// TeamMatch and returns one LineResult per side, in no particular order.
+// Matches that are unstarted or in progress are skipped.
+func GetCompletedLineResults(ctx context.Context, db database.Provider) ([]*LineResult, error) {
+	rows, err := database.GetAll[*TeamMatchIndividualMatch](ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	// index the join rows by individual match so each side can find the
+	// pairing that played its opponent
+	byMatch := make(map[IndividualMatchId]*TeamMatchIndividualMatch, len(rows))
+	for _, row := range rows {
+		byMatch[row.IndividualMatchId] = row
+	}
+
+	teamMatches := make(map[TeamMatchId]*TeamMatch)
+	weeks := make(map[WeekId]*Week)
+	seasons := make(map[WeekId]SeasonId)
+	treatments := make(map[SeasonId]OutcomeTreatments)
+
+	output := make([]*LineResult, 0, len(rows))
+	for _, row := range rows {
+		match, exists, err := database.GetOneById(ctx, db, &IndividualMatch{}, row.IndividualMatchId.RecordId())
+		if err != nil {
+			return nil, err
+		}
+		if !exists || !isCompleted(match) {
+			continue
+		}
+
+		tm, ok := teamMatches[row.TeamMatchId]
+		if !ok {
+			tm, err = database.GetExistingRecordById(ctx, db, &TeamMatch{}, row.TeamMatchId.RecordId())
+			if err != nil {
+				return nil, err
+			}
+			teamMatches[row.TeamMatchId] = tm
+		}
+
+		week, ok := weeks[tm.WeekId]
+		if !ok {
+			week, err = database.GetExistingRecordById(ctx, db, &Week{}, tm.WeekId.RecordId())
+			if err != nil {
+				return nil, err
+			}
+			weeks[tm.WeekId] = week
+			season, err := week.GetSeason(ctx, db)
+			if err != nil {
+				return nil, err
+			}
+			if season != nil {
+				seasons[tm.WeekId] = season.ID
+				if _, ok := treatments[season.ID]; !ok {
+					treatments[season.ID], err = GetOutcomeTreatments(ctx, db, season.ID)
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+
+		pairing, err := database.GetExistingRecordById(ctx, db, &LineupPairing{}, row.LineupPairingId.RecordId())
+		if err != nil {
+			return nil, err
+		}
+
+		result := &LineResult{
+			TeamMatch: tm,
+			SeasonId:  seasons[tm.WeekId],
+			Week:      week,
+			Pairing:   pairing,
+			Match:     match,
+			Treatment: treatments[seasons[tm.WeekId]].For(match.Outcome),
+		}
+
+		if match.Opponent != IndividualMatchId(database.InvalidRecordId) {
+			opp, err := database.GetExistingRecordById(ctx, db, &IndividualMatch{}, match.Opponent.RecordId())
+			if err != nil {
+				return nil, err
+			}
+			result.Opponent = opp
+			if oppRow, ok := byMatch[opp.ID]; ok {
+				oppPairing, err := database.GetExistingRecordById(ctx, db, &LineupPairing{}, oppRow.LineupPairingId.RecordId())
+				if err != nil {
+					return nil, err
+				}
+				result.OpponentPairing = oppPairing
+			}
+		}
+		output = append(output, result)
+	}
+	return output, nil
+}
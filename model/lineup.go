@@ -2,7 +2,9 @@ package model
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 
 	"intraclub/database"
 )
@@ -90,6 +92,52 @@ func (l *Lineup) DynamicallyValid(ctx context.Context, db database.Provider) err
 	return nil
 }
 
+// PreUpdate runs the Season's enabled LineupRules whenever the lineup is being
+// confirmed or marked official. Once confirmed or official a lineup stays so;
+// later changes to its pairings go through a LineupRevision instead.
+func (l *Lineup) PreUpdate(ctx context.Context, db database.Provider, existingValues database.CrudRecord) error {
+	existing := existingValues.(*Lineup)
+	if (existing.Confirmed && !l.Confirmed) || (existing.Official && !l.Official) {
+		return errors.New("a confirmed or official lineup can only be changed through a lineup revision")
+	}
+	if l.Official && !l.Confirmed {
+		return errors.New("lineup must be confirmed before it can be marked official")
+	}
+	if (l.Confirmed && !existing.Confirmed) || (l.Official && !existing.Official) {
+		return ValidateLineupRules(ctx, db, l)
+	}
+	return nil
+}
+
+// PreCreateRequest clears Confirmed and Official, which are only set through
+// ConfirmLineup and MarkOfficial once the lineup's pairings are in place.
+func (l *Lineup) PreCreateRequest(_ context.Context, _ database.Provider, _ database.UserId) error {
+	l.Confirmed = false
+	l.Official = false
+	return nil
+}
+
+// PreUpdateRequest refuses to let anyone but a season commissioner mark the
+// lineup official, since EditableBy also lets the team's captains update it.
+func (l *Lineup) PreUpdateRequest(ctx context.Context, db database.Provider, userId database.UserId, existingValues database.CrudRecord) error {
+	existing := existingValues.(*Lineup)
+	if l.Official == existing.Official {
+		return nil
+	}
+	week, err := database.GetExistingRecordById(ctx, db, &Week{}, existing.WeekId.RecordId())
+	if err != nil {
+		return err
+	}
+	season, err := week.GetSeason(ctx, db)
+	if err != nil {
+		return err
+	}
+	if season == nil || !slices.Contains(season.EditableBy(ctx, db), userId) {
+		return errors.New("only a season commissioner may mark the lineup official")
+	}
+	return nil
+}
+
 func (l *Lineup) GetFormat(ctx context.Context, db database.Provider) (*Format, error) {
 	week, err := database.GetExistingRecordById(ctx, db, &Week{}, l.WeekId.RecordId())
 	if err != nil {
@@ -102,8 +150,9 @@ func (l *Lineup) GetFormat(ctx context.Context, db database.Provider) (*Format,
 	return database.GetExistingRecordById(ctx, db, &Format{}, draft.Format.RecordId())
 }
 
-// PostDelete cascades deletion to this lineup's lineup_pairing child rows.
-// Without this, deleting a lineup would orphan those rows (see #97).
+// PostDelete cascades deletion to this lineup's lineup_pairing and
+// lineup_revision child rows. Without this, deleting a lineup would orphan
+// those rows (see #97).
 func (l *Lineup) PostDelete(ctx context.Context, db database.Provider) error {
 	pairings, err := database.GetAllWhere[*LineupPairing](ctx, db, func(_ context.Context, p *LineupPairing) bool {
 		return p.LineupId == l.ID
@@ -116,6 +165,15 @@ func (l *Lineup) PostDelete(ctx context.Context, db database.Provider) error {
 			return err
 		}
 	}
+	revisions, err := l.GetRevisions(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, r := range revisions {
+		if _, _, err := database.DeleteOneById(ctx, db, r, r.ID.RecordId()); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
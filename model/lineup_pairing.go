@@ -71,8 +71,24 @@ func (l *LineupPairing) EditableBy(ctx context.Context, db database.Provider) []
 	return EditableByTeamCaptainOrCoCaptains(ctx, db, l.TeamId)
 }
 
+// AccessibleTo returns the team's members and, once the pairing's Lineup is
+// revealed (see Lineup.IsRevealed), the members of the team they play that
+// week, so a blind lineup stays hidden from the opponent until it is revealed.
 func (l *LineupPairing) AccessibleTo(ctx context.Context, db database.Provider) []database.UserId {
-	return AccessibleByTeamMembers(ctx, db, l.TeamId)
+	members := AccessibleByTeamMembers(ctx, db, l.TeamId)
+	lineup, exists, err := database.GetOneById(ctx, db, &Lineup{}, l.LineupId.RecordId())
+	if err != nil || !exists {
+		return members
+	}
+	revealed, err := lineup.IsRevealed(ctx, db)
+	if err != nil || !revealed {
+		return members
+	}
+	opponent, err := lineup.GetOpponent(ctx, db)
+	if err != nil || opponent == TeamId(database.InvalidRecordId) {
+		return members
+	}
+	return append(members, AccessibleByTeamMembers(ctx, db, opponent)...)
 }
 
 func (l *LineupPairing) SetOwner(userId database.UserId) {
@@ -95,15 +111,21 @@ func (l *LineupPairing) DynamicallyValid(ctx context.Context, db database.Provid
 		return err
 	}
 
-	// validate that both players are members of this team
-	isMember1, err := team.IsTeamMember(ctx, db, l.Player1)
+	lineup, err := database.GetExistingRecordById(ctx, db, &Lineup{}, l.LineupId.RecordId())
+	if err != nil {
+		return err
+	}
+
+	// validate that both players are members of this team, or substitutes
+	// approved to play for this team in the lineup's week
+	isMember1, err := isTeamMemberOrApprovedSub(ctx, db, team, lineup.WeekId, l.FormatLineIndex, l.Player1)
 	if err != nil {
 		return err
 	}
 	if !isMember1 {
 		return fmt.Errorf("player 1 is not team member for team %s", l.TeamId)
 	}
-	isMember2, err := team.IsTeamMember(ctx, db, l.Player2)
+	isMember2, err := isTeamMemberOrApprovedSub(ctx, db, team, lineup.WeekId, l.FormatLineIndex, l.Player2)
 	if err != nil {
 		return err
 	}
@@ -148,9 +170,14 @@ func (l *LineupPairing) GetFormat(ctx context.Context, db database.Provider) (*F
 // rating expected by their format line. Previously the inline `RatingsMap`
 // silently returned a zero rating for players without an assignment; now a
 // player with no TeamRating is treated as an error, since such a player is
-// not valid for lineup validation.
+// not valid for lineup validation. An approved substitute plays at the rating
+// of their SubRequest.
 func (l *LineupPairing) ValidatePlayerRatings(ctx context.Context, db database.Provider) error {
-	format, err := l.GetFormat(ctx, db)
+	lineup, err := database.GetExistingRecordById(ctx, db, &Lineup{}, l.LineupId.RecordId())
+	if err != nil {
+		return err
+	}
+	format, err := lineup.GetFormat(ctx, db)
 	if err != nil {
 		return err
 	}
@@ -164,7 +191,7 @@ func (l *LineupPairing) ValidatePlayerRatings(ctx context.Context, db database.P
 	}
 	line := lines[l.FormatLineIndex]
 
-	rating1, err := team.GetRating(ctx, db, l.Player1)
+	rating1, err := lineupPlayerRating(ctx, db, team, lineup.WeekId, l.FormatLineIndex, l.Player1)
 	if err != nil {
 		return err
 	}
@@ -172,7 +199,7 @@ func (l *LineupPairing) ValidatePlayerRatings(ctx context.Context, db database.P
 		return fmt.Errorf("player 1 has rating %s, expected %s for line index %d for format", rating1, line.Player1Rating, l.FormatLineIndex)
 	}
 
-	rating2, err := team.GetRating(ctx, db, l.Player2)
+	rating2, err := lineupPlayerRating(ctx, db, team, lineup.WeekId, l.FormatLineIndex, l.Player2)
 	if err != nil {
 		return err
 	}
@@ -182,10 +209,99 @@ func (l *LineupPairing) ValidatePlayerRatings(ctx context.Context, db database.P
 	return nil
 }
 
+// isTeamMemberOrApprovedSub checks whether the player is a member of the team
+// or has an approved SubRequest to play for the team on the given line in the
+// given week.
+func isTeamMemberOrApprovedSub(ctx context.Context, db database.Provider, team *Team, weekId WeekId, formatLineIndex int, player database.UserId) (bool, error) {
+	isMember, err := team.IsTeamMember(ctx, db, player)
+	if err != nil || isMember {
+		return isMember, err
+	}
+	sub, err := GetApprovedSubRequest(ctx, db, team.ID, weekId, formatLineIndex, player)
+	if err != nil {
+		return false, err
+	}
+	return sub != nil, nil
+}
+
+// lineupPlayerRating returns the rating a player carries on the given line of
+// the team's lineup for the given week: their TeamRating if they are a team
+// member, otherwise the rating of their approved SubRequest for that line.
+func lineupPlayerRating(ctx context.Context, db database.Provider, team *Team, weekId WeekId, formatLineIndex int, player database.UserId) (RatingId, error) {
+	isMember, err := team.IsTeamMember(ctx, db, player)
+	if err != nil {
+		return 0, err
+	}
+	if isMember {
+		return team.GetRating(ctx, db, player)
+	}
+	sub, err := GetApprovedSubRequest(ctx, db, team.ID, weekId, formatLineIndex, player)
+	if err != nil {
+		return 0, err
+	}
+	if sub == nil {
+		return 0, fmt.Errorf("no rating assigned for user %s on team %s", player, team.ID)
+	}
+	return sub.RatingId, nil
+}
+
 func (l *LineupPairing) NewRecord() database.CrudRecord {
 	return new(LineupPairing)
 }
 
+// PreCreateRequest refuses to add a pairing to a confirmed Lineup through the
+// generic create route; see checkLineupUnconfirmed.
+func (l *LineupPairing) PreCreateRequest(ctx context.Context, db database.Provider, _ database.UserId) error {
+	return checkLineupUnconfirmed(ctx, db, l.LineupId)
+}
+
+// PreUpdateRequest refuses to change a pairing of a confirmed Lineup, or to
+// move a pairing onto one, through the generic update route.
+func (l *LineupPairing) PreUpdateRequest(ctx context.Context, db database.Provider, _ database.UserId, existingValues database.CrudRecord) error {
+	existing := existingValues.(*LineupPairing)
+	if err := checkLineupUnconfirmed(ctx, db, existing.LineupId); err != nil {
+		return err
+	}
+	if l.LineupId == existing.LineupId {
+		return nil
+	}
+	return checkLineupUnconfirmed(ctx, db, l.LineupId)
+}
+
+// PreDeleteRequest refuses to remove a pairing from a confirmed Lineup
+// through the generic delete route.
+func (l *LineupPairing) PreDeleteRequest(ctx context.Context, db database.Provider, _ database.UserId) error {
+	return checkLineupUnconfirmed(ctx, db, l.LineupId)
+}
+
+// checkLineupUnconfirmed refuses a generic write to the pairings of a
+// confirmed Lineup. Those changes must go through ChangeLineupPairings, which
+// records them as a LineupRevision and, once the Lineup is official, holds
+// them for a commissioner's approval.
+func checkLineupUnconfirmed(ctx context.Context, db database.Provider, lineupId LineupId) error {
+	lineup, err := database.GetExistingRecordById(ctx, db, &Lineup{}, lineupId.RecordId())
+	if err != nil {
+		return err
+	}
+	if lineup.Confirmed {
+		return fmt.Errorf("lineup %s is confirmed; change its pairings through /lineup/set so the change is recorded as a revision", lineupId)
+	}
+	return nil
+}
+
+// PostUpdate invalidates cached career statistics, which credit a match to
+// the players of the pairing it is assigned to.
+func (l *LineupPairing) PostUpdate(ctx context.Context, db database.Provider) error {
+	InvalidateCareerStats()
+	return nil
+}
+
+// PostDelete invalidates cached career statistics.
+func (l *LineupPairing) PostDelete(ctx context.Context, db database.Provider) error {
+	InvalidateCareerStats()
+	return nil
+}
+
 // NewLineupPairing returns a new LineupPairing record. It is the conventional
 // constructor used by the generic CRUD route registration.
 func NewLineupPairing() *LineupPairing {
@@ -0,0 +1,709 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"intraclub/database"
+)
+
+type LineupRevisionId database.RecordId
+
+func (id LineupRevisionId) RecordId() database.RecordId {
+	return database.RecordId(id)
+}
+
+func (id LineupRevisionId) String() string {
+	return id.RecordId().String()
+}
+
+func (id LineupRevisionId) MarshalJSON() ([]byte, error) {
+	return id.RecordId().MarshalJSON()
+}
+
+func (id *LineupRevisionId) UnmarshalJSON(bytes []byte) error {
+	rid := database.RecordId(0)
+	if err := (*database.RecordId)(&rid).UnmarshalJSON(bytes); err != nil {
+		return err
+	}
+	*id = LineupRevisionId(rid)
+	return nil
+}
+
+type LineupRevisionStatus int
+
+const (
+	LineupRevisionApproved LineupRevisionStatus = iota
+	LineupRevisionPending
+	LineupRevisionRejected
+	LineupRevisionInvalid
+)
+
+func (s LineupRevisionStatus) String() string {
+	switch s {
+	case LineupRevisionApproved:
+		return "approved"
+	case LineupRevisionPending:
+		return "pending"
+	case LineupRevisionRejected:
+		return "rejected"
+	default:
+		return "invalid"
+	}
+}
+
+func (s LineupRevisionStatus) Valid() bool {
+	return s < LineupRevisionInvalid
+}
+
+// ErrLineupAlreadyPlayed is returned when a LineupRevision would change a
+// Lineup whose week's TeamMatch has already been generated.
+var ErrLineupAlreadyPlayed = errors.New("team match has already been generated for this lineup")
+
+// LineupRevision is a snapshot of a confirmed or official Lineup's pairings.
+// A revision is recorded when the Lineup is confirmed and again each time its
+// pairings change afterwards. Changes made before the Lineup is official are
+// applied straight away and recorded as approved; changes to an official
+// Lineup are recorded as pending and only reach the live LineupPairing rows
+// once a season commissioner approves them.
+type LineupRevision struct {
+	ID         LineupRevisionId     `json:"id"`
+	LineupId   LineupId             `json:"lineup_id"`
+	Revision   int                  `json:"revision"`  // 1-based, increasing per Lineup
+	EditedBy   database.UserId      `json:"edited_by"` // captain/co-captain who made the change
+	Reason     string               `json:"reason"`    // why the lineup changed, e.g. an injury
+	Status     LineupRevisionStatus `json:"status"`
+	ReviewedBy database.UserId      `json:"reviewed_by"` // commissioner who approved/rejected a post-official change
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+}
+
+func NewLineupRevision() *LineupRevision {
+	return &LineupRevision{}
+}
+
+func (r *LineupRevision) GetOwner() database.UserId {
+	return r.EditedBy
+}
+
+func (r *LineupRevision) SetOwner(userId database.UserId) {
+	r.EditedBy = userId
+}
+
+func (r *LineupRevision) GetTimeStamps() (created, updated time.Time) {
+	return r.CreatedAt, r.UpdatedAt
+}
+
+func (r *LineupRevision) SetCreateTimestamp(t time.Time) time.Time {
+	oldValue := r.CreatedAt
+	r.CreatedAt = t
+	return oldValue
+}
+
+func (r *LineupRevision) SetUpdateTimestamp(t time.Time) time.Time {
+	oldValue := r.UpdatedAt
+	r.UpdatedAt = t
+	return oldValue
+}
+
+func (r *LineupRevision) UniquenessEquivalent(other *LineupRevision) error {
+	if r.LineupId == other.LineupId && r.Revision == other.Revision {
+		return fmt.Errorf("duplicate revision %d for lineup %s", r.Revision, r.LineupId)
+	}
+	return nil
+}
+
+func (r *LineupRevision) Type() string {
+	return "lineup_revision"
+}
+
+func (r *LineupRevision) GetId() database.RecordId {
+	return r.ID.RecordId()
+}
+
+func (r *LineupRevision) SetId(id database.RecordId) {
+	r.ID = LineupRevisionId(id)
+}
+
+// EditableBy returns nobody but the system administrator, since revisions are
+// only written by the lineup change and approval flows.
+func (r *LineupRevision) EditableBy(_ context.Context, _ database.Provider) []database.UserId {
+	return []database.UserId{database.SysAdminUserId}
+}
+
+func (r *LineupRevision) AccessibleTo(ctx context.Context, db database.Provider) []database.UserId {
+	lineup, exists, err := database.GetOneById(ctx, db, &Lineup{}, r.LineupId.RecordId())
+	if err != nil || !exists {
+		return []database.UserId{database.SysAdminUserId}
+	}
+	return lineup.AccessibleTo(ctx, db)
+}
+
+func (r *LineupRevision) StaticallyValid() error {
+	if !r.Status.Valid() {
+		return fmt.Errorf("lineup revision status %d is not valid", r.Status)
+	}
+	if r.Revision < 1 {
+		return fmt.Errorf("lineup revision number %d must be positive", r.Revision)
+	}
+	if r.Reason == "" {
+		return errors.New("lineup revision must have a reason")
+	}
+	return nil
+}
+
+func (r *LineupRevision) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	err := database.ExistsById(ctx, db, &Lineup{}, r.LineupId.RecordId())
+	if err != nil {
+		return err
+	}
+	return database.ExistsById(ctx, db, &User{}, r.EditedBy.RecordId())
+}
+
+func (r *LineupRevision) PreUpdate(_ context.Context, _ database.Provider, existingValues database.CrudRecord) error {
+	existing := existingValues.(*LineupRevision)
+	if r.LineupId != existing.LineupId || r.Revision != existing.Revision {
+		return fmt.Errorf("lineup revision %s cannot be moved", existing.ID)
+	}
+	if existing.Status != LineupRevisionPending && r.Status != existing.Status {
+		return fmt.Errorf("lineup revision %s is already %s", existing.ID, existing.Status)
+	}
+	return nil
+}
+
+// PostDelete cascades deletion to this revision's lineup_revision_pairing
+// child rows.
+func (r *LineupRevision) PostDelete(ctx context.Context, db database.Provider) error {
+	pairings, err := r.GetPairings(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, p := range pairings {
+		if _, _, err := database.DeleteOneById(ctx, db, p, p.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *LineupRevision) NewRecord() database.CrudRecord {
+	return new(LineupRevision)
+}
+
+// GetPairings returns the pairings captured by this revision, sorted by
+// format line index.
+func (r *LineupRevision) GetPairings(ctx context.Context, db database.Provider) ([]*LineupRevisionPairing, error) {
+	pairings, err := database.GetAllWhere[*LineupRevisionPairing](ctx, db, func(_ context.Context, p *LineupRevisionPairing) bool {
+		return p.RevisionId == r.ID
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pairings, func(i, j int) bool {
+		return pairings[i].FormatLineIndex < pairings[j].FormatLineIndex
+	})
+	return pairings, nil
+}
+
+// Approve applies a pending revision to its Lineup's live pairings and marks
+// it approved by the given commissioner. It fails once the week's TeamMatch
+// has been generated, since the generated matches already reference the
+// previous pairings.
+func (r *LineupRevision) Approve(ctx context.Context, db database.Provider, reviewer database.UserId) error {
+	if r.Status != LineupRevisionPending {
+		return fmt.Errorf("lineup revision %s is %s, not %s", r.ID, r.Status, LineupRevisionPending)
+	}
+	lineup, err := database.GetExistingRecordById(ctx, db, &Lineup{}, r.LineupId.RecordId())
+	if err != nil {
+		return err
+	}
+	played, err := lineup.HasTeamMatch(ctx, db)
+	if err != nil {
+		return err
+	}
+	if played {
+		return ErrLineupAlreadyPlayed
+	}
+	revisionPairings, err := r.GetPairings(ctx, db)
+	if err != nil {
+		return err
+	}
+	pairings := make([]*LineupPairing, 0, len(revisionPairings))
+	for _, p := range revisionPairings {
+		pairings = append(pairings, p.toLineupPairing(lineup))
+	}
+	if err := ReplaceLineupPairings(ctx, db, lineup, pairings); err != nil {
+		return err
+	}
+
+	approved := *r
+	approved.Status = LineupRevisionApproved
+	approved.ReviewedBy = reviewer
+	return r.updateTo(ctx, db, &approved)
+}
+
+// Reject marks a pending revision as rejected by the given commissioner,
+// leaving the Lineup's live pairings untouched.
+func (r *LineupRevision) Reject(ctx context.Context, db database.Provider, reviewer database.UserId) error {
+	if r.Status != LineupRevisionPending {
+		return fmt.Errorf("lineup revision %s is %s, not %s", r.ID, r.Status, LineupRevisionPending)
+	}
+	rejected := *r
+	rejected.Status = LineupRevisionRejected
+	rejected.ReviewedBy = reviewer
+	return r.updateTo(ctx, db, &rejected)
+}
+
+// updateTo stores the updated copy of this LineupRevision, only overwriting
+// the receiver once the update has passed validation.
+func (r *LineupRevision) updateTo(ctx context.Context, db database.Provider, updated *LineupRevision) error {
+	if err := database.UpdateOne(ctx, db, updated); err != nil {
+		return err
+	}
+	*r = *updated
+	return nil
+}
+
+// LineupRevisionPairing is one format line's pairing as captured by a
+// LineupRevision.
+type LineupRevisionPairing struct {
+	ID              database.RecordId `json:"id"`
+	RevisionId      LineupRevisionId  `json:"revision_id"`
+	Player1         database.UserId   `json:"player1"`
+	Player2         database.UserId   `json:"player2"`
+	FormatLineIndex int               `json:"format_line_index"`
+}
+
+func NewLineupRevisionPairing() *LineupRevisionPairing {
+	return &LineupRevisionPairing{}
+}
+
+func (p *LineupRevisionPairing) GetOwner() database.UserId {
+	return database.InvalidUserId
+}
+
+func (p *LineupRevisionPairing) SetOwner(_ database.UserId) {
+	// ownership follows the parent LineupRevision
+}
+
+func (p *LineupRevisionPairing) UniquenessEquivalent(other *LineupRevisionPairing) error {
+	if p.RevisionId == other.RevisionId && p.FormatLineIndex == other.FormatLineIndex {
+		return fmt.Errorf("duplicate pairing for revision %s and format line index %d", p.RevisionId, p.FormatLineIndex)
+	}
+	return nil
+}
+
+func (p *LineupRevisionPairing) Type() string {
+	return "lineup_revision_pairing"
+}
+
+func (p *LineupRevisionPairing) GetId() database.RecordId {
+	return p.ID
+}
+
+func (p *LineupRevisionPairing) SetId(id database.RecordId) {
+	p.ID = id
+}
+
+func (p *LineupRevisionPairing) EditableBy(_ context.Context, _ database.Provider) []database.UserId {
+	return []database.UserId{database.SysAdminUserId}
+}
+
+func (p *LineupRevisionPairing) AccessibleTo(ctx context.Context, db database.Provider) []database.UserId {
+	revision, exists, err := database.GetOneById(ctx, db, &LineupRevision{}, p.RevisionId.RecordId())
+	if err != nil || !exists {
+		return []database.UserId{database.SysAdminUserId}
+	}
+	return revision.AccessibleTo(ctx, db)
+}
+
+func (p *LineupRevisionPairing) StaticallyValid() error {
+	if p.Player1 == p.Player2 {
+		return fmt.Errorf("player 1 ID is the same as player 2 ID")
+	}
+	if p.FormatLineIndex < 0 {
+		return fmt.Errorf("format line index %d is negative", p.FormatLineIndex)
+	}
+	return nil
+}
+
+func (p *LineupRevisionPairing) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	return database.ExistsById(ctx, db, &LineupRevision{}, p.RevisionId.RecordId())
+}
+
+func (p *LineupRevisionPairing) NewRecord() database.CrudRecord {
+	return new(LineupRevisionPairing)
+}
+
+// samePlayers reports whether two revision pairings place the same players in
+// the same slots.
+func (p *LineupRevisionPairing) samePlayers(other *LineupRevisionPairing) bool {
+	return p.Player1 == other.Player1 && p.Player2 == other.Player2
+}
+
+// toLineupPairing returns a new, unsaved LineupPairing for the Lineup with
+// this revision pairing's players and line.
+func (p *LineupRevisionPairing) toLineupPairing(lineup *Lineup) *LineupPairing {
+	pairing := NewLineupPairing()
+	pairing.LineupId = lineup.ID
+	pairing.TeamId = lineup.TeamId
+	pairing.Player1 = p.Player1
+	pairing.Player2 = p.Player2
+	pairing.FormatLineIndex = p.FormatLineIndex
+	return pairing
+}
+
+// GetLineupPairings returns the Lineup's live pairings sorted by format line
+// index.
+func (l *Lineup) GetLineupPairings(ctx context.Context, db database.Provider) ([]*LineupPairing, error) {
+	pairings, err := database.GetAllWhere[*LineupPairing](ctx, db, func(_ context.Context, p *LineupPairing) bool {
+		return p.LineupId == l.ID
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pairings, func(i, j int) bool {
+		return pairings[i].FormatLineIndex < pairings[j].FormatLineIndex
+	})
+	return pairings, nil
+}
+
+// GetRevisions returns the Lineup's revisions, oldest first.
+func (l *Lineup) GetRevisions(ctx context.Context, db database.Provider) ([]*LineupRevision, error) {
+	revisions, err := database.GetAllWhere[*LineupRevision](ctx, db, func(_ context.Context, r *LineupRevision) bool {
+		return r.LineupId == l.ID
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+// GetRevision returns the Lineup's revision with the given number, or nil if
+// there is no such revision.
+func (l *Lineup) GetRevision(ctx context.Context, db database.Provider, revision int) (*LineupRevision, error) {
+	revisions, err := database.GetAllWhere[*LineupRevision](ctx, db, func(_ context.Context, r *LineupRevision) bool {
+		return r.LineupId == l.ID && r.Revision == revision
+	})
+	if err != nil || len(revisions) == 0 {
+		return nil, err
+	}
+	return revisions[0], nil
+}
+
+// GetLatestApprovedRevision returns the Lineup's most recent approved
+// revision, or nil if it has none.
+func (l *Lineup) GetLatestApprovedRevision(ctx context.Context, db database.Provider) (*LineupRevision, error) {
+	revisions, err := l.GetRevisions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if revisions[i].Status == LineupRevisionApproved {
+			return revisions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// HasTeamMatch reports whether a TeamMatch has been generated for the
+// Lineup's team and week.
+func (l *Lineup) HasTeamMatch(ctx context.Context, db database.Provider) (bool, error) {
+	matches, err := database.GetAllWhere[*TeamMatch](ctx, db, func(_ context.Context, tm *TeamMatch) bool {
+		return tm.WeekId == l.WeekId && (tm.HomeTeam == l.TeamId || tm.AwayTeam == l.TeamId)
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(matches) > 0, nil
+}
+
+// ReplaceLineupPairings replaces the Lineup's live pairings with the given
+// unsaved pairings. Deleting the existing rows first means an empty set
+// clears the lineup; creating fresh rows re-validates each one. The new
+// pairings are validated before anything is deleted, and if one still fails
+// to be created the old rows are put back, so a failed replacement leaves the
+// lineup as it was.
+func ReplaceLineupPairings(ctx context.Context, db database.Provider, lineup *Lineup, pairings []*LineupPairing) error {
+	for _, p := range pairings {
+		if err := database.Validate(ctx, db, p); err != nil {
+			return err
+		}
+	}
+	if err := validateDistinctPairings(pairings); err != nil {
+		return err
+	}
+	oldPairings, err := lineup.GetLineupPairings(ctx, db)
+	if err != nil {
+		return err
+	}
+	for i, p := range oldPairings {
+		if _, _, err := database.DeleteOneById(ctx, db, p, p.ID.RecordId()); err != nil {
+			return errors.Join(err, restoreLineupPairings(ctx, db, nil, oldPairings[:i]))
+		}
+	}
+	created := make([]*LineupPairing, 0, len(pairings))
+	for _, p := range pairings {
+		v, err := database.CreateOne(ctx, db, p)
+		if err != nil {
+			return errors.Join(err, restoreLineupPairings(ctx, db, created, oldPairings))
+		}
+		created = append(created, v)
+	}
+	return nil
+}
+
+// restoreLineupPairings undoes a failed ReplaceLineupPairings, deleting the
+// pairings it created and putting back the deleted ones. The deleted rows were
+// valid before, so they are put back with a raw provider create that keeps
+// their IDs.
+func restoreLineupPairings(ctx context.Context, db database.Provider, created, deleted []*LineupPairing) error {
+	var errs []error
+	for _, p := range created {
+		if _, _, err := database.DeleteOneById(ctx, db, p, p.ID.RecordId()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, p := range deleted {
+		if _, err := db.Create(ctx, p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RecordLineupRevision snapshots the Lineup's live pairings as a new approved
+// revision, e.g. when the Lineup is confirmed.
+func RecordLineupRevision(ctx context.Context, db database.Provider, lineup *Lineup, editor database.UserId, reason string) (*LineupRevision, error) {
+	pairings, err := lineup.GetLineupPairings(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	return createLineupRevision(ctx, db, lineup, editor, reason, LineupRevisionApproved, pairings)
+}
+
+// ChangeLineupPairings replaces the pairings of a Lineup on behalf of one of
+// its captains, keeping the revision history of confirmed Lineups:
+//
+//   - an unconfirmed Lineup is changed directly and no revision is recorded;
+//   - a confirmed Lineup is changed directly and the result is recorded as an
+//     approved revision;
+//   - an official Lineup, or a blind Lineup that has been revealed, is left
+//     untouched and the change is recorded as a pending revision for a
+//     commissioner to approve. Any older pending revision is rejected, as it
+//     is superseded by the new one.
+//
+// Confirmed and official Lineups require a reason. The returned revision is
+// nil for unconfirmed Lineups.
+func ChangeLineupPairings(ctx context.Context, db database.Provider, lineup *Lineup, editor database.UserId, reason string, pairings []*LineupPairing) (*LineupRevision, error) {
+	if !lineup.Confirmed {
+		return nil, ReplaceLineupPairings(ctx, db, lineup, pairings)
+	}
+	if reason == "" {
+		return nil, errors.New("a reason is required to change a confirmed lineup")
+	}
+	if err := ensureBaselineRevision(ctx, db, lineup, editor); err != nil {
+		return nil, err
+	}
+
+	locked, err := lineup.PairingsLocked(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if !lineup.Official && !locked {
+		if err := ReplaceLineupPairings(ctx, db, lineup, pairings); err != nil {
+			return nil, err
+		}
+		return RecordLineupRevision(ctx, db, lineup, editor, reason)
+	}
+
+	played, err := lineup.HasTeamMatch(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if played {
+		return nil, ErrLineupAlreadyPlayed
+	}
+	// the proposed pairings aren't stored yet, so validate them up front to
+	// stop a commissioner from approving a change that can't be applied
+	for _, p := range pairings {
+		if err := database.Validate(ctx, db, p); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateDistinctPairings(pairings); err != nil {
+		return nil, err
+	}
+
+	revisions, err := lineup.GetRevisions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	for _, revision := range revisions {
+		if revision.Status != LineupRevisionPending {
+			continue
+		}
+		if err := revision.Reject(ctx, db, editor); err != nil {
+			return nil, err
+		}
+	}
+	return createLineupRevision(ctx, db, lineup, editor, reason, LineupRevisionPending, pairings)
+}
+
+// validateDistinctPairings mirrors the LineupPairing uniqueness constraint for
+// a set of pairings that hasn't been stored yet: no player may appear twice in
+// the same slot, and no line may be filled twice.
+func validateDistinctPairings(pairings []*LineupPairing) error {
+	player1 := make(map[database.UserId]bool, len(pairings))
+	player2 := make(map[database.UserId]bool, len(pairings))
+	lines := make(map[int]bool, len(pairings))
+	for _, p := range pairings {
+		if player1[p.Player1] {
+			return fmt.Errorf("duplicate player 1 %s", p.Player1)
+		}
+		if player2[p.Player2] {
+			return fmt.Errorf("duplicate player 2 %s", p.Player2)
+		}
+		if lines[p.FormatLineIndex] {
+			return fmt.Errorf("duplicate format line index %d", p.FormatLineIndex)
+		}
+		player1[p.Player1] = true
+		player2[p.Player2] = true
+		lines[p.FormatLineIndex] = true
+	}
+	return nil
+}
+
+// ensureBaselineRevision records the Lineup's current pairings as its first
+// revision if it has none yet, e.g. because it was confirmed through the
+// generic CRUD routes, so that the first change has something to diff against.
+func ensureBaselineRevision(ctx context.Context, db database.Provider, lineup *Lineup, editor database.UserId) error {
+	revisions, err := lineup.GetRevisions(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(revisions) > 0 {
+		return nil
+	}
+	_, err = RecordLineupRevision(ctx, db, lineup, editor, "confirmed")
+	return err
+}
+
+// createLineupRevision stores the next revision of the Lineup along with a
+// copy of each pairing.
+func createLineupRevision(ctx context.Context, db database.Provider, lineup *Lineup, editor database.UserId, reason string, status LineupRevisionStatus, pairings []*LineupPairing) (*LineupRevision, error) {
+	revisions, err := lineup.GetRevisions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	revision := NewLineupRevision()
+	revision.LineupId = lineup.ID
+	revision.Revision = len(revisions) + 1
+	revision.EditedBy = editor
+	revision.Reason = reason
+	revision.Status = status
+	revision, err = database.CreateOne(ctx, db, revision)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pairings {
+		revisionPairing := NewLineupRevisionPairing()
+		revisionPairing.RevisionId = revision.ID
+		revisionPairing.Player1 = p.Player1
+		revisionPairing.Player2 = p.Player2
+		revisionPairing.FormatLineIndex = p.FormatLineIndex
+		if _, err := database.CreateOne(ctx, db, revisionPairing); err != nil {
+			return nil, err
+		}
+	}
+	return revision, nil
+}
+
+// LineupLineChange describes how one format line differs between two
+// revisions of a Lineup. Before or After is nil when the line was empty in
+// that revision.
+type LineupLineChange struct {
+	FormatLineIndex int                    `json:"format_line_index"`
+	Before          *LineupRevisionPairing `json:"before"`
+	After           *LineupRevisionPairing `json:"after"`
+}
+
+// DiffLineupRevisions returns the format lines whose pairing differs between
+// the two revisions, in format line order. Lines that are unchanged are left
+// out.
+func DiffLineupRevisions(ctx context.Context, db database.Provider, from, to *LineupRevision) ([]*LineupLineChange, error) {
+	if from.LineupId != to.LineupId {
+		return nil, fmt.Errorf("revisions %s and %s belong to different lineups", from.ID, to.ID)
+	}
+	before, err := from.GetPairings(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	after, err := to.GetPairings(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[int]*LineupLineChange)
+	for _, p := range before {
+		changes[p.FormatLineIndex] = &LineupLineChange{FormatLineIndex: p.FormatLineIndex, Before: p}
+	}
+	for _, p := range after {
+		change, ok := changes[p.FormatLineIndex]
+		if !ok {
+			changes[p.FormatLineIndex] = &LineupLineChange{FormatLineIndex: p.FormatLineIndex, After: p}
+			continue
+		}
+		if change.Before.samePlayers(p) {
+			delete(changes, p.FormatLineIndex)
+			continue
+		}
+		change.After = p
+	}
+
+	output := make([]*LineupLineChange, 0, len(changes))
+	for _, change := range changes {
+		output = append(output, change)
+	}
+	sort.Slice(output, func(i, j int) bool {
+		return output[i].FormatLineIndex < output[j].FormatLineIndex
+	})
+	return output, nil
+}
+
+// GetApprovedLineupPairings returns the live pairings of the Lineup that make
+// up its latest approved revision, sorted by format line index. A Lineup
+// without any revisions has all of its live pairings returned. It is an error
+// for the live pairings to have drifted from the latest approved revision,
+// e.g. through the generic lineup_pairing routes.
+func GetApprovedLineupPairings(ctx context.Context, db database.Provider, lineup *Lineup) ([]*LineupPairing, error) {
+	live, err := lineup.GetLineupPairings(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := lineup.GetLatestApprovedRevision(ctx, db)
+	if err != nil || revision == nil {
+		return live, err
+	}
+	approved, err := revision.GetPairings(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(live) != len(approved) {
+		return nil, fmt.Errorf("lineup %s does not match approved revision %d", lineup.ID, revision.Revision)
+	}
+	for i := range live {
+		if live[i].FormatLineIndex != approved[i].FormatLineIndex ||
+			live[i].Player1 != approved[i].Player1 || live[i].Player2 != approved[i].Player2 {
+			return nil, fmt.Errorf("lineup %s does not match approved revision %d on format line index %d", lineup.ID, revision.Revision, approved[i].FormatLineIndex)
+		}
+	}
+	return live, nil
+}
@@ -0,0 +1,164 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newStoredRevisionLineup builds a confirmed, official lineup with a single
+// pairing on line 0, and returns the lineup, its team and the pairing.
+func newStoredRevisionLineup(t *testing.T, db database.Provider) (*Lineup, *Team, *LineupPairing) {
+	ctx := context.Background()
+	format := newDefaultStoredFormat(t, db)
+	commissioner := newStoredUser(t, db)
+
+	draft := NewDraft()
+	draft.Owner = commissioner.ID
+	draft.Format = format.ID
+	draftV, err := database.CreateOne(ctx, db, draft)
+	require.NoError(t, err)
+
+	team := newStoredTeam(t, db, newStoredUser(t, db).ID)
+
+	week := NewWeek()
+	week.DraftId = draftV.ID
+	week.Date = time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	weekV, err := database.CreateOne(ctx, db, week)
+	require.NoError(t, err)
+
+	lineup, err := database.CreateOne(ctx, db, &Lineup{TeamId: team.ID, WeekId: weekV.ID, Confirmed: true, Official: true})
+	require.NoError(t, err)
+	pairing := newStoredLineupPairing(t, db, lineup, team)
+	return lineup, team, pairing
+}
+
+// newProposedPairing returns an unsaved line 0 pairing for the lineup with two
+// new, validly rated team members.
+func newProposedPairing(t *testing.T, db database.Provider, lineup *Lineup, team *Team) *LineupPairing {
+	stored := newStoredLineupPairing(t, db, lineup, team)
+	_, _, err := database.DeleteOneById(context.Background(), db, stored, stored.ID.RecordId())
+	require.NoError(t, err)
+	return &LineupPairing{LineupId: lineup.ID, TeamId: team.ID, Player1: stored.Player1, Player2: stored.Player2}
+}
+
+func TestLineupRevisionPendingUntilApproved(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	lineup, team, original := newStoredRevisionLineup(t, db)
+	editor := newStoredUser(t, db).ID
+	reviewer := newStoredUser(t, db).ID
+
+	_, err := ChangeLineupPairings(ctx, db, lineup, editor, "", []*LineupPairing{newProposedPairing(t, db, lineup, team)})
+	require.Error(t, err)
+
+	first, err := ChangeLineupPairings(ctx, db, lineup, editor, "injury", []*LineupPairing{newProposedPairing(t, db, lineup, team)})
+	require.NoError(t, err)
+	require.Equal(t, LineupRevisionPending, first.Status)
+	require.Equal(t, 2, first.Revision) // revision 1 is the baseline of the official lineup
+
+	// the pending change is not live, and match generation still sees the
+	// original pairing
+	approved, err := GetApprovedLineupPairings(ctx, db, lineup)
+	require.NoError(t, err)
+	require.Len(t, approved, 1)
+	require.Equal(t, original.Player1, approved[0].Player1)
+
+	// a newer change supersedes the pending one
+	proposed := newProposedPairing(t, db, lineup, team)
+	second, err := ChangeLineupPairings(ctx, db, lineup, editor, "injury, take two", []*LineupPairing{proposed})
+	require.NoError(t, err)
+	require.Equal(t, 3, second.Revision)
+	first, err = database.GetExistingRecordById(ctx, db, &LineupRevision{}, first.ID.RecordId())
+	require.NoError(t, err)
+	require.Equal(t, LineupRevisionRejected, first.Status)
+	require.Error(t, first.Approve(ctx, db, reviewer))
+
+	require.NoError(t, second.Approve(ctx, db, reviewer))
+	require.Equal(t, LineupRevisionApproved, second.Status)
+	require.Equal(t, reviewer, second.ReviewedBy)
+	approved, err = GetApprovedLineupPairings(ctx, db, lineup)
+	require.NoError(t, err)
+	require.Len(t, approved, 1)
+	require.Equal(t, proposed.Player1, approved[0].Player1)
+	require.Equal(t, proposed.Player2, approved[0].Player2)
+
+	baseline, err := lineup.GetRevision(ctx, db, 1)
+	require.NoError(t, err)
+	changes, err := DiffLineupRevisions(ctx, db, baseline, second)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, 0, changes[0].FormatLineIndex)
+	require.Equal(t, original.Player1, changes[0].Before.Player1)
+	require.Equal(t, proposed.Player1, changes[0].After.Player1)
+
+	changes, err = DiffLineupRevisions(ctx, db, second, second)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestLineupRevisionApprovalRefusedAfterMatchGenerated(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	lineup, team, _ := newStoredRevisionLineup(t, db)
+	editor := newStoredUser(t, db).ID
+
+	revision, err := ChangeLineupPairings(ctx, db, lineup, editor, "injury", []*LineupPairing{newProposedPairing(t, db, lineup, team)})
+	require.NoError(t, err)
+
+	opponent := newStoredTeam(t, db, newStoredUser(t, db).ID)
+	_, err = database.CreateOne(ctx, db, &TeamMatch{WeekId: lineup.WeekId, HomeTeam: team.ID, AwayTeam: opponent.ID, Lineup: lineup.ID})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, revision.Approve(ctx, db, editor), ErrLineupAlreadyPlayed)
+	require.Equal(t, LineupRevisionPending, revision.Status)
+}
+
+func TestLineupRevisionApprovalKeepsPairingsOnFailure(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	lineup, team, original := newStoredRevisionLineup(t, db)
+	editor := newStoredUser(t, db).ID
+
+	proposed := newProposedPairing(t, db, lineup, team)
+	revision, err := ChangeLineupPairings(ctx, db, lineup, editor, "injury", []*LineupPairing{proposed})
+	require.NoError(t, err)
+
+	// the proposed player leaves the team before the change is approved
+	assignments, err := database.GetAllWhere[*TeamAssignment](ctx, db, func(_ context.Context, a *TeamAssignment) bool {
+		return a.TeamId == team.ID && a.UserId == proposed.Player1
+	})
+	require.NoError(t, err)
+	require.Len(t, assignments, 1)
+	_, _, err = database.DeleteOneById(ctx, db, assignments[0], assignments[0].GetId())
+	require.NoError(t, err)
+
+	require.Error(t, revision.Approve(ctx, db, editor))
+	require.Equal(t, LineupRevisionPending, revision.Status)
+	live, err := lineup.GetLineupPairings(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, live, 1)
+	require.Equal(t, original.ID, live[0].ID)
+}
+
+func TestApprovedLineupPairingsDetectsDrift(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	lineup, _, pairing := newStoredRevisionLineup(t, db)
+
+	_, err := RecordLineupRevision(ctx, db, lineup, newStoredUser(t, db).ID, "confirmed")
+	require.NoError(t, err)
+	_, err = GetApprovedLineupPairings(ctx, db, lineup)
+	require.NoError(t, err)
+
+	// removing a pairing outside of the revision flow leaves the live lineup
+	// out of step with its approved revision
+	_, _, err = database.DeleteOneById(ctx, db, pairing, pairing.ID.RecordId())
+	require.NoError(t, err)
+	_, err = GetApprovedLineupPairings(ctx, db, lineup)
+	require.Error(t, err)
+}
@@ -0,0 +1,274 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"intraclub/database"
+)
+
+// LineupRuleName identifies a LineupRule. It is the value stored in a
+// SeasonLineupRule to enable the rule for a Season.
+type LineupRuleName string
+
+const (
+	LineupRuleNoStackingByRating LineupRuleName = "no_stacking_by_rating"
+	LineupRuleNoStackingByGrade  LineupRuleName = "no_stacking_by_grade"
+	LineupRuleOneLinePerPlayer   LineupRuleName = "one_line_per_player"
+	LineupRuleAvailability       LineupRuleName = "availability"
+)
+
+// LineupRuleInput is everything a LineupRule needs to check a Lineup, loaded
+// once by ValidateLineupRules and shared between rules.
+type LineupRuleInput struct {
+	Lineup   *Lineup
+	Team     *Team
+	Week     *Week
+	Draft    *Draft
+	Format   *Format
+	Pairings []*LineupPairing // ordered by FormatLineIndex
+}
+
+// LineupRule is a club rule that a Lineup must satisfy before it can be
+// confirmed or marked official. Rules are registered with RegisterLineupRule
+// and are only checked for a Season once a commissioner has enabled them via
+// a SeasonLineupRule record.
+type LineupRule interface {
+	Name() LineupRuleName
+	Description() string
+	Validate(ctx context.Context, db database.Provider, input *LineupRuleInput) error
+}
+
+var lineupRules = make(map[LineupRuleName]LineupRule)
+
+// RegisterLineupRule makes a LineupRule available to be enabled per Season,
+// replacing any rule previously registered with the same name.
+func RegisterLineupRule(rule LineupRule) {
+	lineupRules[rule.Name()] = rule
+}
+
+// GetLineupRule returns the registered LineupRule with the given name.
+func GetLineupRule(name LineupRuleName) (LineupRule, bool) {
+	rule, ok := lineupRules[name]
+	return rule, ok
+}
+
+// GetLineupRules returns every registered LineupRule, sorted by name.
+func GetLineupRules() []LineupRule {
+	rules := make([]LineupRule, 0, len(lineupRules))
+	for _, rule := range lineupRules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name() < rules[j].Name()
+	})
+	return rules
+}
+
+func init() {
+	RegisterLineupRule(noStackingByRatingRule{})
+	RegisterLineupRule(noStackingByGradeRule{})
+	RegisterLineupRule(oneLinePerPlayerRule{})
+	RegisterLineupRule(availabilityRule{})
+}
+
+// ValidateLineupRules checks the Lineup against every LineupRule enabled for
+// its Season, returning all violations joined into a single error. Lineups
+// whose Week is not assigned to a Season have no rules to check.
+func ValidateLineupRules(ctx context.Context, db database.Provider, lineup *Lineup) error {
+	week, err := database.GetExistingRecordById(ctx, db, &Week{}, lineup.WeekId.RecordId())
+	if err != nil {
+		return err
+	}
+	season, err := week.GetSeason(ctx, db)
+	if err != nil {
+		return err
+	}
+	if season == nil {
+		return nil
+	}
+	enabled, err := GetEnabledLineupRules(ctx, db, season.ID)
+	if err != nil {
+		return err
+	}
+	if len(enabled) == 0 {
+		return nil
+	}
+
+	input := &LineupRuleInput{Lineup: lineup, Week: week}
+	input.Team, err = database.GetExistingRecordById(ctx, db, &Team{}, lineup.TeamId.RecordId())
+	if err != nil {
+		return err
+	}
+	input.Draft, err = database.GetExistingRecordById(ctx, db, &Draft{}, week.DraftId.RecordId())
+	if err != nil {
+		return err
+	}
+	input.Format, err = database.GetExistingRecordById(ctx, db, &Format{}, input.Draft.Format.RecordId())
+	if err != nil {
+		return err
+	}
+	input.Pairings, err = database.GetAllWhere[*LineupPairing](ctx, db, func(_ context.Context, p *LineupPairing) bool {
+		return p.LineupId == lineup.ID
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(input.Pairings, func(i, j int) bool {
+		return input.Pairings[i].FormatLineIndex < input.Pairings[j].FormatLineIndex
+	})
+
+	violations := make([]error, 0)
+	for _, rule := range enabled {
+		if err := rule.Validate(ctx, db, input); err != nil {
+			violations = append(violations, fmt.Errorf("%s: %w", rule.Name(), err))
+		}
+	}
+	return errors.Join(violations...)
+}
+
+// checkLineOrder compares the strength of every pairing against the pairings
+// on the lines above it, where higher strength is stronger, and returns an
+// error for the first pairing that is stronger than a pairing above it.
+func checkLineOrder(pairings []*LineupPairing, strength func(p *LineupPairing) float64) error {
+	for i, lower := range pairings {
+		for _, higher := range pairings[:i] {
+			if higher.FormatLineIndex == lower.FormatLineIndex {
+				continue
+			}
+			if strength(lower) > strength(higher) {
+				return fmt.Errorf("pairing on line index %d is stronger than pairing on line index %d", lower.FormatLineIndex, higher.FormatLineIndex)
+			}
+		}
+	}
+	return nil
+}
+
+// noStackingByRatingRule requires that the combined rating of each pairing,
+// ranked by the Format's possible ratings, does not exceed the combined
+// rating of any pairing on a higher line.
+type noStackingByRatingRule struct{}
+
+func (noStackingByRatingRule) Name() LineupRuleName {
+	return LineupRuleNoStackingByRating
+}
+
+func (noStackingByRatingRule) Description() string {
+	return "Pairs must be ordered by combined rating, strongest on the highest line"
+}
+
+func (noStackingByRatingRule) Validate(ctx context.Context, db database.Provider, input *LineupRuleInput) error {
+	possibleRatings, err := input.Format.GetPossibleRatings(ctx, db)
+	if err != nil {
+		return err
+	}
+	// possible ratings are ordered highest-skill first, so a lower index is
+	// a stronger player
+	rank := make(map[RatingId]int, len(possibleRatings))
+	for i, rating := range possibleRatings {
+		rank[rating] = len(possibleRatings) - i
+	}
+	strengths := make(map[LineupPairingId]float64, len(input.Pairings))
+	for _, pairing := range input.Pairings {
+		for _, player := range []database.UserId{pairing.Player1, pairing.Player2} {
+			rating, err := lineupPlayerRating(ctx, db, input.Team, input.Lineup.WeekId, pairing.FormatLineIndex, player)
+			if err != nil {
+				return err
+			}
+			strengths[pairing.ID] += float64(rank[rating])
+		}
+	}
+	return checkLineOrder(input.Pairings, func(p *LineupPairing) float64 {
+		return strengths[p.ID]
+	})
+}
+
+// noStackingByGradeRule requires that the combined pre-draft grade of each
+// pairing does not exceed the combined grade of any pairing on a higher line.
+// Ungraded players count as zero.
+type noStackingByGradeRule struct{}
+
+func (noStackingByGradeRule) Name() LineupRuleName {
+	return LineupRuleNoStackingByGrade
+}
+
+func (noStackingByGradeRule) Description() string {
+	return "Pairs must be ordered by combined pre-draft grade, strongest on the highest line"
+}
+
+func (noStackingByGradeRule) Validate(ctx context.Context, db database.Provider, input *LineupRuleInput) error {
+	grades, err := GetPreDraftGradesByDraftId(ctx, db, input.Draft.ID)
+	if err != nil {
+		return err
+	}
+	possibleRatings, err := input.Format.GetPossibleRatings(ctx, db)
+	if err != nil {
+		return err
+	}
+	return checkLineOrder(input.Pairings, func(p *LineupPairing) float64 {
+		return GetDraftAggregateForPlayer(grades, possibleRatings, p.Player1).Aggregate +
+			GetDraftAggregateForPlayer(grades, possibleRatings, p.Player2).Aggregate
+	})
+}
+
+// oneLinePerPlayerRule requires that each player appears at most once in the
+// Lineup, across all of its lines.
+type oneLinePerPlayerRule struct{}
+
+func (oneLinePerPlayerRule) Name() LineupRuleName {
+	return LineupRuleOneLinePerPlayer
+}
+
+func (oneLinePerPlayerRule) Description() string {
+	return "Each player may play at most one line per week"
+}
+
+func (oneLinePerPlayerRule) Validate(_ context.Context, _ database.Provider, input *LineupRuleInput) error {
+	lines := make(map[database.UserId]int)
+	for _, pairing := range input.Pairings {
+		for _, player := range []database.UserId{pairing.Player1, pairing.Player2} {
+			if line, ok := lines[player]; ok {
+				return fmt.Errorf("player %s is on line index %d and line index %d", player, line, pairing.FormatLineIndex)
+			}
+			lines[player] = pairing.FormatLineIndex
+		}
+	}
+	return nil
+}
+
+// availabilityRule requires that every player in the Lineup has marked
+// themselves available or maybe for the Week.
+type availabilityRule struct{}
+
+func (availabilityRule) Name() LineupRuleName {
+	return LineupRuleAvailability
+}
+
+func (availabilityRule) Description() string {
+	return "Every player must be marked available or maybe for the week"
+}
+
+func (availabilityRule) Validate(ctx context.Context, db database.Provider, input *LineupRuleInput) error {
+	availability, err := database.GetAllWhere[*Availability](ctx, db, func(_ context.Context, a *Availability) bool {
+		return a.WeekId == input.Lineup.WeekId
+	})
+	if err != nil {
+		return err
+	}
+	options := make(map[database.UserId]AvailabilityOption, len(availability))
+	for _, a := range availability {
+		options[a.UserId] = a.Available
+	}
+	for _, pairing := range input.Pairings {
+		for _, player := range []database.UserId{pairing.Player1, pairing.Player2} {
+			// players who haven't responded are AvailabilityUnset
+			option := options[player]
+			if option != AvailabilityAvailable && option != AvailabilityMaybe {
+				return fmt.Errorf("player %s is marked %s for week %s", player, option, input.Week.ID)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+// lineupRuleFixture is a single-team season whose two-line format's ratings
+// are ordered line 0 strongest. The team has four rated members, and a lineup
+// for the first week places players[0:2] on line 0 and players[2:4] on line 1.
+type lineupRuleFixture struct {
+	season  *Season
+	draft   *Draft
+	format  *Format
+	week    *Week
+	lineup  *Lineup
+	players []database.UserId
+}
+
+func newLineupRuleFixture(t *testing.T, db database.Provider) *lineupRuleFixture {
+	ctx := context.Background()
+	season, _ := newDefaultSeason(t, db)
+	teams, err := season.GetTeams(ctx, db)
+	require.NoError(t, err)
+	team := teams[0]
+	draft, err := season.GetDraft(ctx, db)
+	require.NoError(t, err)
+	format, err := database.GetExistingRecordById(ctx, db, &Format{}, draft.Format.RecordId())
+	require.NoError(t, err)
+	lines, err := format.GetLines(ctx, db)
+	require.NoError(t, err)
+	week := newStoredWeek(t, db, season)
+
+	ratings := []RatingId{lines[0].Player1Rating, lines[0].Player2Rating, lines[1].Player1Rating, lines[1].Player2Rating}
+	players := make([]database.UserId, 0, len(ratings))
+	for _, rating := range ratings {
+		player := newStoredUser(t, db).ID
+		_, err := database.CreateOne(ctx, db, &TeamAssignment{TeamId: team.ID, UserId: player, Role: TeamRoleMember})
+		require.NoError(t, err)
+		_, err = database.CreateOne(ctx, db, &TeamRating{TeamId: team.ID, UserId: player, RatingId: rating})
+		require.NoError(t, err)
+		players = append(players, player)
+	}
+
+	lineup, err := database.CreateOne(ctx, db, &Lineup{TeamId: team.ID, WeekId: week.ID})
+	require.NoError(t, err)
+	for i := range lines {
+		_, err := database.CreateOne(ctx, db, &LineupPairing{
+			LineupId:        lineup.ID,
+			TeamId:          team.ID,
+			Player1:         players[2*i],
+			Player2:         players[2*i+1],
+			FormatLineIndex: i,
+		})
+		require.NoError(t, err)
+	}
+
+	return &lineupRuleFixture{
+		season:  season,
+		draft:   draft,
+		format:  format,
+		week:    week,
+		lineup:  lineup,
+		players: players,
+	}
+}
+
+// confirm attempts to confirm the fixture's lineup, leaving it unchanged on
+// failure.
+func (f *lineupRuleFixture) confirm(t *testing.T, db database.Provider) error {
+	confirmed := *f.lineup
+	confirmed.Confirmed = true
+	err := database.UpdateOne(context.Background(), db, &confirmed)
+	if err == nil {
+		f.lineup = &confirmed
+	}
+	return err
+}
+
+func TestLineupRulesDisabledByDefault(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	f := newLineupRuleFixture(t, db)
+	require.NoError(t, f.confirm(t, db))
+}
+
+func TestLineupRuleAvailability(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	f := newLineupRuleFixture(t, db)
+	require.NoError(t, EnableLineupRule(context.Background(), db, f.season.ID, LineupRuleAvailability))
+
+	err := f.confirm(t, db)
+	require.ErrorContains(t, err, string(LineupRuleAvailability))
+
+	setAvailability(t, db, f.players[0], f.week.ID, AvailabilityAvailable)
+	setAvailability(t, db, f.players[1], f.week.ID, AvailabilityMaybe)
+	setAvailability(t, db, f.players[2], f.week.ID, AvailabilityAvailable)
+	setAvailability(t, db, f.players[3], f.week.ID, AvailabilityNotAvailable)
+	require.ErrorContains(t, f.confirm(t, db), AvailabilityNotAvailable.String())
+
+	require.NoError(t, DisableLineupRule(context.Background(), db, f.season.ID, LineupRuleAvailability))
+	require.NoError(t, f.confirm(t, db))
+}
+
+func TestLineupRuleCheckedWhenMarkedOfficial(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	f := newLineupRuleFixture(t, db)
+	require.NoError(t, f.confirm(t, db))
+	require.NoError(t, EnableLineupRule(context.Background(), db, f.season.ID, LineupRuleAvailability))
+
+	official := *f.lineup
+	official.Official = true
+	require.ErrorContains(t, database.UpdateOne(context.Background(), db, &official), string(LineupRuleAvailability))
+}
+
+func TestLineupRuleNoStackingByRating(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	f := newLineupRuleFixture(t, db)
+	require.NoError(t, EnableLineupRule(ctx, db, f.season.ID, LineupRuleNoStackingByRating))
+
+	require.NoError(t, ValidateLineupRules(ctx, db, f.lineup))
+
+	// ranking the line 1 ratings above the line 0 ratings makes line 1 the
+	// stronger pair
+	ratings, err := f.format.GetPossibleRatings(ctx, db)
+	require.NoError(t, err)
+	reversed := make(RatingList, 0, len(ratings))
+	for i := len(ratings) - 1; i >= 0; i-- {
+		reversed = append(reversed, ratings[i])
+	}
+	require.NoError(t, f.format.SetPossibleRatings(ctx, db, reversed))
+	require.ErrorContains(t, f.confirm(t, db), "line index 1 is stronger than pairing on line index 0")
+}
+
+func TestLineupRuleNoStackingByGrade(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	f := newLineupRuleFixture(t, db)
+	require.NoError(t, EnableLineupRule(ctx, db, f.season.ID, LineupRuleNoStackingByGrade))
+
+	// grade the line 1 players above the line 0 players
+	possibleRatings, err := f.format.GetPossibleRatings(ctx, db)
+	require.NoError(t, err)
+	for _, player := range f.players[2:] {
+		_, err := database.CreateOne(ctx, db, &DraftAvailablePlayer{DraftId: f.draft.ID, PlayerId: player})
+		require.NoError(t, err)
+		_, err = database.CreateOne(ctx, db, &PreDraftGrade{
+			PlayerId: player,
+			DraftId:  f.draft.ID,
+			GraderId: f.draft.Owner,
+			Modifier: StrongModifier,
+			Rating:   possibleRatings[0],
+		})
+		require.NoError(t, err)
+	}
+	require.ErrorContains(t, f.confirm(t, db), string(LineupRuleNoStackingByGrade))
+}
+
+func TestLineupRuleOneLinePerPlayer(t *testing.T) {
+	rule, ok := GetLineupRule(LineupRuleOneLinePerPlayer)
+	require.True(t, ok)
+	input := &LineupRuleInput{Pairings: []*LineupPairing{
+		{Player1: 1, Player2: 2, FormatLineIndex: 0},
+		{Player1: 3, Player2: 4, FormatLineIndex: 1},
+	}}
+	require.NoError(t, rule.Validate(context.Background(), nil, input))
+
+	input.Pairings = append(input.Pairings, &LineupPairing{Player1: 5, Player2: 2, FormatLineIndex: 2})
+	require.ErrorContains(t, rule.Validate(context.Background(), nil, input), "line index 0 and line index 2")
+}
+
+func TestSeasonLineupRuleValidation(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, _ := newDefaultSeason(t, db)
+
+	_, err := database.CreateOne(ctx, db, &SeasonLineupRule{SeasonId: season.ID, Rule: "no_sandbagging"})
+	require.ErrorContains(t, err, "unknown lineup rule")
+
+	require.NoError(t, EnableLineupRule(ctx, db, season.ID, LineupRuleOneLinePerPlayer))
+	require.NoError(t, EnableLineupRule(ctx, db, season.ID, LineupRuleOneLinePerPlayer))
+	_, err = database.CreateOne(ctx, db, &SeasonLineupRule{SeasonId: season.ID, Rule: LineupRuleOneLinePerPlayer})
+	require.Error(t, err)
+
+	rules, err := GetEnabledLineupRules(ctx, db, season.ID)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, LineupRuleOneLinePerPlayer, rules[0].Name())
+}
+
+func TestLineupOfficialOnlySetByCommissioner(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	f := newLineupRuleFixture(t, db)
+	captain := EditableByTeamCaptainOrCoCaptains(ctx, db, f.lineup.TeamId)[0]
+	commissioner := f.season.EditableBy(ctx, db)[0]
+
+	// the captain confirms through the generic update, but can't go further
+	confirmed := *f.lineup
+	confirmed.Confirmed = true
+	wac := database.NewWithAccessControl[*Lineup](ctx, db, captain)
+	require.NoError(t, wac.UpdateOneById(ctx, &confirmed))
+	official := confirmed
+	official.Official = true
+	require.ErrorContains(t, wac.UpdateOneById(ctx, &official), "commissioner")
+
+	// and confirmation can't be taken back
+	unconfirmed := confirmed
+	unconfirmed.Confirmed = false
+	require.Error(t, wac.UpdateOneById(ctx, &unconfirmed))
+
+	// a commissioner marks it official through MarkOfficial instead
+	require.NoError(t, official.PreUpdateRequest(ctx, db, commissioner, &confirmed))
+	require.NoError(t, database.UpdateOne(ctx, db, &official))
+	cleared := official
+	cleared.Official = false
+	require.Error(t, database.UpdateOne(ctx, db, &cleared))
+
+	// nor can a generic create start out official
+	requested := &Lineup{Confirmed: true, Official: true}
+	require.NoError(t, requested.PreCreateRequest(ctx, db, captain))
+	require.False(t, requested.Confirmed || requested.Official)
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// markdownToHTML renders the subset of Markdown used in RuleSections as HTML:
+// ATX headings, paragraphs, bulleted and numbered lists, block quotes, fenced
+// code blocks, horizontal rules, and inline code, bold, italics and links.
+// All text is escaped, so any raw HTML in the source is shown rather than
+// rendered, and links are only kept for http, https and mailto URLs and
+// relative references. Headings are demoted by headingOffset levels (capped
+// at <h6>) so they nest under the heading of the document they're put into.
+func markdownToHTML(markdown string, headingOffset int) string {
+	var b strings.Builder
+	lines := splitLines(markdown)
+	var paragraph []string
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + renderInline(strings.Join(paragraph, "\n")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flushParagraph()
+		case strings.HasPrefix(trimmed, "```"):
+			flushParagraph()
+			b.WriteString("<pre><code>")
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				b.WriteString(html.EscapeString(lines[i]) + "\n")
+			}
+			b.WriteString("</code></pre>\n")
+		case markdownRule.MatchString(trimmed):
+			flushParagraph()
+			b.WriteString("<hr>\n")
+		case markdownHeading.MatchString(trimmed):
+			flushParagraph()
+			m := markdownHeading.FindStringSubmatch(trimmed)
+			level := min(len(m[1])+headingOffset, 6)
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, renderInline(strings.TrimRight(m[2], "# ")), level)
+		case strings.HasPrefix(trimmed, ">"):
+			flushParagraph()
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			i--
+			b.WriteString("<blockquote>\n" + markdownToHTML(strings.Join(quoted, "\n"), headingOffset) + "</blockquote>\n")
+		case markdownBullet.MatchString(line) || markdownNumbered.MatchString(line):
+			flushParagraph()
+			list, item := "ul", markdownBullet
+			if markdownNumbered.MatchString(line) {
+				list, item = "ol", markdownNumbered
+			}
+			b.WriteString("<" + list + ">\n")
+			for ; i < len(lines) && item.MatchString(lines[i]); i++ {
+				text := item.ReplaceAllString(lines[i], "")
+				// indented lines continue the item
+				for i+1 < len(lines) && strings.HasPrefix(lines[i+1], "  ") && !item.MatchString(lines[i+1]) {
+					i++
+					text += "\n" + strings.TrimSpace(lines[i])
+				}
+				b.WriteString("<li>" + renderInline(text) + "</li>\n")
+			}
+			i--
+			b.WriteString("</" + list + ">\n")
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flushParagraph()
+	return b.String()
+}
+
+var (
+	markdownHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownRule     = regexp.MustCompile(`^([-*_])(\s*[-*_]){2,}$`)
+	markdownBullet   = regexp.MustCompile(`^\s*[-*+]\s+`)
+	markdownNumbered = regexp.MustCompile(`^\s*\d+[.)]\s+`)
+	markdownInline   = regexp.MustCompile("`([^`]+)`|\\*\\*(.+?)\\*\\*|__(.+?)__|\\*([^*]+)\\*|\\b_([^_]+)_\\b|\\[([^\\]]+)\\]\\(((?:[^()\\s]|\\([^()\\s]*\\))+)\\)")
+)
+
+// renderInline renders the inline Markdown of a block of text as escaped
+// HTML.
+func renderInline(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range markdownInline.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:m[0]]))
+		group := func(n int) string {
+			return text[m[2*n]:m[2*n+1]]
+		}
+		switch {
+		case m[2] >= 0:
+			b.WriteString("<code>" + html.EscapeString(group(1)) + "</code>")
+		case m[4] >= 0:
+			b.WriteString("<strong>" + renderInline(group(2)) + "</strong>")
+		case m[6] >= 0:
+			b.WriteString("<strong>" + renderInline(group(3)) + "</strong>")
+		case m[8] >= 0:
+			b.WriteString("<em>" + renderInline(group(4)) + "</em>")
+		case m[10] >= 0:
+			b.WriteString("<em>" + renderInline(group(5)) + "</em>")
+		default:
+			label := renderInline(group(6))
+			if href, ok := safeLink(group(7)); ok {
+				b.WriteString(`<a href="` + html.EscapeString(href) + `">` + label + "</a>")
+			} else {
+				b.WriteString(label)
+			}
+		}
+		last = m[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// safeLink returns the link if it is an http, https or mailto URL or a
+// relative reference, which are safe to put in an href.
+func safeLink(link string) (string, bool) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return u.String(), true
+	}
+	return "", false
+}
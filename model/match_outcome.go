@@ -0,0 +1,107 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"intraclub/database"
+)
+
+// MatchOutcome records how an IndividualMatch was decided. Most matches are
+// played out and decided by the ScoringStructure; the other outcomes decide a
+// match without it being finished (or started), and each can be given its own
+// standings treatment per Season (see SeasonOutcomeTreatment).
+type MatchOutcome int
+
+const (
+	OutcomePlayed        MatchOutcome = iota // decided by the score
+	OutcomeRetired                           // a side retired mid-match; the partial score is kept
+	OutcomeDefault                           // a side defaulted, e.g. didn't show up
+	OutcomeDoubleDefault                     // both sides defaulted; neither wins
+	OutcomeWalkover                          // a side withdrew before the match, giving the other a walkover
+	OutcomeInvalid
+)
+
+func (o MatchOutcome) String() string {
+	switch o {
+	case OutcomePlayed:
+		return "played"
+	case OutcomeRetired:
+		return "retired"
+	case OutcomeDefault:
+		return "default"
+	case OutcomeDoubleDefault:
+		return "double default"
+	case OutcomeWalkover:
+		return "walkover"
+	default:
+		return "invalid"
+	}
+}
+
+func (o MatchOutcome) Valid() bool {
+	return o >= OutcomePlayed && o < OutcomeInvalid
+}
+
+// OutcomeTreatment is how matches decided by a MatchOutcome count toward
+// standings and player statistics.
+type OutcomeTreatment struct {
+	CountsTowardRecord       bool `json:"counts_toward_record"`       // the match counts as a win/loss
+	CountsTowardDifferential bool `json:"counts_toward_differential"` // the match's sets and games count toward set/game differentials
+}
+
+// DefaultOutcomeTreatment returns the treatment used for an outcome when the
+// Season hasn't configured one: every outcome counts toward the win/loss
+// record, but only matches that were played or retired count toward set and
+// game differentials.
+func DefaultOutcomeTreatment(outcome MatchOutcome) OutcomeTreatment {
+	switch outcome {
+	case OutcomePlayed, OutcomeRetired:
+		return OutcomeTreatment{CountsTowardRecord: true, CountsTowardDifferential: true}
+	default:
+		return OutcomeTreatment{CountsTowardRecord: true}
+	}
+}
+
+// OutcomeTreatments holds a Season's configured OutcomeTreatment per outcome.
+type OutcomeTreatments map[MatchOutcome]OutcomeTreatment
+
+// For returns the treatment of an outcome, falling back to its default.
+func (t OutcomeTreatments) For(outcome MatchOutcome) OutcomeTreatment {
+	if treatment, ok := t[outcome]; ok {
+		return treatment
+	}
+	return DefaultOutcomeTreatment(outcome)
+}
+
+// MarkOutcome decides this match by an outcome other than playing it out.
+// This side is the one that retired, defaulted or withdrew, and the opponent
+// wins; for a double default both sides lose. A retirement keeps the partial
+// score, while the other outcomes clear it since the match wasn't played.
+func (s *IndividualMatch) MarkOutcome(ctx context.Context, db database.Provider, outcome MatchOutcome, opp *IndividualMatch) error {
+	if !outcome.Valid() || outcome == OutcomePlayed {
+		return fmt.Errorf("outcome %d is not a retirement, default or walkover", outcome)
+	}
+	if opp == nil || opp.ID != s.Opponent {
+		return errors.New("an outcome can only be marked on a match with an opponent")
+	}
+	if outcome != OutcomeRetired {
+		s.MainValue, s.SecondaryValue = 0, 0
+		opp.MainValue, opp.SecondaryValue = 0, 0
+	}
+	s.WinOverride, opp.WinOverride = false, false
+	s.Outcome = outcome
+
+	if outcome == OutcomeDoubleDefault {
+		if err := s.MarkStatus(ctx, db, MatchLost, opp); err != nil {
+			return err
+		}
+		return database.UpdateOne(ctx, db, s)
+	}
+	opp.Outcome = outcome
+	if err := opp.MarkStatus(ctx, db, MatchWon, s); err != nil {
+		return err
+	}
+	return database.UpdateOne(ctx, db, opp)
+}
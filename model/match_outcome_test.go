@@ -0,0 +1,106 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkOutcome(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	ss := newDefaultStoredScoringStructure(t, db)
+
+	// a retirement keeps the partial score and hands the match to the opponent
+	match1, match2 := newStoredMatchPair(t, db, ss)
+	recordGames(t, db, match1, match2, sixZeroDustedFlow[:4])
+	require.NoError(t, match1.MarkOutcome(ctx, db, OutcomeRetired, match2))
+	require.Equal(t, MatchLost, match1.Status)
+	require.Equal(t, MatchWon, match2.Status)
+	require.Equal(t, OutcomeRetired, match2.Outcome)
+	require.Equal(t, 4, match1.SecondaryValue)
+	stored, err := database.GetExistingRecordById(ctx, db, &IndividualMatch{}, match2.ID.RecordId())
+	require.NoError(t, err)
+	require.Equal(t, MatchWon, stored.Status)
+	require.Equal(t, OutcomeRetired, stored.Outcome)
+
+	// a decided match can't be scored live any further
+	_, err = match1.RecordScoreEvent(ctx, db, Game, database.InvalidUserId)
+	require.Error(t, err)
+
+	// a default clears any score
+	match1, match2 = newStoredMatchPair(t, db, ss)
+	match1.MainValue, match2.MainValue = 1, 3
+	require.NoError(t, match1.MarkOutcome(ctx, db, OutcomeDefault, match2))
+	require.Equal(t, MatchLost, match1.Status)
+	require.Equal(t, MatchWon, match2.Status)
+	require.Zero(t, match1.MainValue)
+	require.Zero(t, match2.MainValue)
+
+	// both sides lose a double default
+	match1, match2 = newStoredMatchPair(t, db, ss)
+	require.NoError(t, match1.MarkOutcome(ctx, db, OutcomeDoubleDefault, match2))
+	require.Equal(t, MatchLost, match1.Status)
+	require.Equal(t, MatchLost, match2.Status)
+	require.Equal(t, OutcomeDoubleDefault, match2.Outcome)
+
+	require.Error(t, match1.MarkOutcome(ctx, db, OutcomePlayed, match2))
+	require.Error(t, match1.MarkOutcome(ctx, db, OutcomeWalkover, nil))
+}
+
+func TestOutcomeTreatments(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season := newStoredSeason(t, db, newStoredUser(t, db).ID, nil)
+
+	treatments, err := GetOutcomeTreatments(ctx, db, season.ID)
+	require.NoError(t, err)
+	require.Equal(t, DefaultOutcomeTreatment(OutcomeDefault), treatments.For(OutcomeDefault))
+	require.False(t, treatments.For(OutcomeWalkover).CountsTowardDifferential)
+	require.True(t, treatments.For(OutcomeRetired).CountsTowardDifferential)
+
+	// setting a treatment twice replaces the first
+	require.NoError(t, SetOutcomeTreatment(ctx, db, season.ID, OutcomeDefault, OutcomeTreatment{CountsTowardRecord: true, CountsTowardDifferential: true}))
+	require.NoError(t, SetOutcomeTreatment(ctx, db, season.ID, OutcomeDefault, OutcomeTreatment{CountsTowardDifferential: true}))
+	treatments, err = GetOutcomeTreatments(ctx, db, season.ID)
+	require.NoError(t, err)
+	require.Len(t, treatments, 1)
+	require.Equal(t, OutcomeTreatment{CountsTowardDifferential: true}, treatments.For(OutcomeDefault))
+
+	require.Error(t, SetOutcomeTreatment(ctx, db, season.ID, OutcomePlayed, OutcomeTreatment{}))
+}
+
+func TestGetPartnerStatsOutcomeTreatment(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, home, away := newPartnerStatsFixture(t, db)
+	homeMatch, awayMatch := newStoredMatchPair(t, db, newDefaultStoredScoringStructure(t, db))
+	for _, side := range []struct {
+		pairing *LineupPairing
+		match   *IndividualMatch
+	}{{home, homeMatch}, {away, awayMatch}} {
+		_, err := database.CreateOne(ctx, db, &TeamMatchIndividualMatch{
+			TeamMatchId:       tm.ID,
+			LineupPairingId:   side.pairing.ID,
+			IndividualMatchId: side.match.ID,
+		})
+		require.NoError(t, err)
+	}
+
+	// by default a walkover counts as a win but not toward differentials
+	require.NoError(t, awayMatch.MarkOutcome(ctx, db, OutcomeWalkover, homeMatch))
+	stats, err := GetPartnerStats(ctx, db, PartnerStatsFilter{})
+	require.NoError(t, err)
+	for _, s := range stats {
+		require.Equal(t, 1, s.Matches)
+		require.Zero(t, s.SetsWon+s.SetsLost+s.GamesWon+s.GamesLost)
+		if s.Player1 == min(home.Player1, home.Player2) {
+			require.Equal(t, 1, s.Wins)
+		} else {
+			require.Equal(t, 1, s.Losses)
+		}
+	}
+}
@@ -0,0 +1,483 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"intraclub/database"
+)
+
+// MatchScoreEvent is one entry in the live scoring log of an IndividualMatch
+// pair: ScoredBy won a single point, game or set. The log is shared by both
+// sides of the match and keyed by the side with the lower ID, so that it can
+// be replayed in Sequence order against the match's ScoringStructure to
+// derive the current score (see ReplayMatchScore).
+type MatchScoreEvent struct {
+	ID         database.RecordId `json:"id"`
+	MatchId    IndividualMatchId `json:"match_id"`    // side of the pair with the lower ID, see scoreLogId
+	Sequence   int               `json:"sequence"`    // 1-based position in the match's log
+	ScoredBy   IndividualMatchId `json:"scored_by"`   // side that won the unit
+	Unit       ScoreCountingType `json:"unit"`        // point, game or set
+	RecordedBy database.UserId   `json:"recorded_by"` // match editor who recorded the event
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+func NewMatchScoreEvent() *MatchScoreEvent {
+	return &MatchScoreEvent{}
+}
+
+func (e *MatchScoreEvent) GetOwner() database.UserId {
+	return e.RecordedBy
+}
+
+func (e *MatchScoreEvent) SetOwner(userId database.UserId) {
+	e.RecordedBy = userId
+}
+
+func (e *MatchScoreEvent) GetTimeStamps() (created, updated time.Time) {
+	return e.CreatedAt, e.UpdatedAt
+}
+
+func (e *MatchScoreEvent) SetCreateTimestamp(t time.Time) time.Time {
+	oldValue := e.CreatedAt
+	e.CreatedAt = t
+	return oldValue
+}
+
+func (e *MatchScoreEvent) SetUpdateTimestamp(t time.Time) time.Time {
+	oldValue := e.UpdatedAt
+	e.UpdatedAt = t
+	return oldValue
+}
+
+func (e *MatchScoreEvent) UniquenessEquivalent(other *MatchScoreEvent) error {
+	if e.MatchId == other.MatchId && e.Sequence == other.Sequence {
+		return fmt.Errorf("duplicate score event %d for match %s", e.Sequence, e.MatchId)
+	}
+	return nil
+}
+
+func (e *MatchScoreEvent) Type() string {
+	return "match_score_event"
+}
+
+func (e *MatchScoreEvent) GetId() database.RecordId {
+	return e.ID
+}
+
+func (e *MatchScoreEvent) SetId(id database.RecordId) {
+	e.ID = id
+}
+
+func (e *MatchScoreEvent) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
+	match, exists, err := database.GetOneById(ctx, db, &IndividualMatch{}, e.MatchId.RecordId())
+	if err != nil || !exists {
+		return []database.UserId{database.SysAdminUserId}
+	}
+	return match.EditableBy(ctx, db)
+}
+
+func (e *MatchScoreEvent) AccessibleTo(_ context.Context, _ database.Provider) []database.UserId {
+	return database.AccessibleToEveryone
+}
+
+func (e *MatchScoreEvent) StaticallyValid() error {
+	if e.Sequence < 1 {
+		return fmt.Errorf("score event sequence %d must be positive", e.Sequence)
+	}
+	return e.Unit.StaticallyValid()
+}
+
+func (e *MatchScoreEvent) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	match, err := database.GetExistingRecordById(ctx, db, &IndividualMatch{}, e.MatchId.RecordId())
+	if err != nil {
+		return err
+	}
+	if e.ScoredBy != match.ID && e.ScoredBy != match.Opponent {
+		return fmt.Errorf("side %s is not part of match %s", e.ScoredBy, e.MatchId)
+	}
+	return nil
+}
+
+func (e *MatchScoreEvent) NewRecord() database.CrudRecord {
+	return new(MatchScoreEvent)
+}
+
+// scoreLogId returns the ID that both sides of a match pair file their score
+// events under: the lower of the two IndividualMatch IDs.
+func (s *IndividualMatch) scoreLogId() IndividualMatchId {
+	if s.Opponent != IndividualMatchId(database.InvalidRecordId) && s.Opponent < s.ID {
+		return s.Opponent
+	}
+	return s.ID
+}
+
+// GetScoreEvents returns the live scoring log of this match pair, in
+// Sequence order.
+func (s *IndividualMatch) GetScoreEvents(ctx context.Context, db database.Provider) ([]*MatchScoreEvent, error) {
+	logId := s.scoreLogId()
+	events, err := database.GetAllWhere[*MatchScoreEvent](ctx, db, func(_ context.Context, e *MatchScoreEvent) bool {
+		return e.MatchId == logId
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Sequence < events[j].Sequence
+	})
+	return events, nil
+}
+
+// HasScoreEvents reports whether this match pair is being scored live, in
+// which case its aggregate score is derived from the event log and should not
+// be set directly.
+func (s *IndividualMatch) HasScoreEvents(ctx context.Context, db database.Provider) (bool, error) {
+	events, err := s.GetScoreEvents(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	return len(events) > 0, nil
+}
+
+// scoringTree is a ScoringStructure with its secondary structures resolved,
+// e.g. a match (sets) whose secondaries are sets (games) whose secondaries
+// may in turn be games (points).
+type scoringTree struct {
+	structure   *ScoringStructure
+	secondaries []*scoringTree
+}
+
+func loadScoringTree(ctx context.Context, db database.Provider, id ScoringStructureId) (*scoringTree, error) {
+	structure, err := database.GetExistingRecordById(ctx, db, &ScoringStructure{}, id.RecordId())
+	if err != nil {
+		return nil, err
+	}
+	tree := &scoringTree{structure: structure}
+	secondary, err := structure.GetSecondaryScoringStructures(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	for _, secondaryId := range secondary {
+		subtree, err := loadScoringTree(ctx, db, secondaryId)
+		if err != nil {
+			return nil, err
+		}
+		tree.secondaries = append(tree.secondaries, subtree)
+	}
+	return tree, nil
+}
+
+// scoreLevel is the running score at one level of a scoringTree, counted from
+// the perspective of the side the log is keyed by ("us").
+type scoreLevel struct {
+	tree      *scoringTree
+	us, them  int
+	completed []CompletedSecondary // finished secondary units, e.g. set scores in games
+	current   *scoreLevel          // secondary unit in progress, if any
+}
+
+func (l *scoreLevel) unit() ScoreCountingType {
+	return l.tree.structure.WinConditionCountingType
+}
+
+func (l *scoreLevel) decided() bool {
+	return l.tree.structure.WinningScore(l.us, l.them) || l.tree.structure.WinningScore(l.them, l.us)
+}
+
+// record applies a unit won by us (or them) to this level. A unit of this
+// level's own counting type is awarded here outright, discarding any partial
+// secondary unit in progress; smaller units are passed down to the current
+// secondary unit. It reports whether this level is now decided.
+func (l *scoreLevel) record(us bool, unit ScoreCountingType) (bool, error) {
+	if l.decided() {
+		return false, fmt.Errorf("%s is already decided", l.unit())
+	}
+	if unit == l.unit() {
+		l.current = nil
+		if us {
+			l.us++
+		} else {
+			l.them++
+		}
+		return l.decided(), nil
+	}
+	if len(l.tree.secondaries) == 0 {
+		return false, fmt.Errorf("cannot record a %s in a %s scored without %ss", unit, l.unit(), unit)
+	}
+	if l.current == nil {
+		index := len(l.completed)
+		if index >= len(l.tree.secondaries) {
+			return false, fmt.Errorf("no %s remains to be played", l.unit().Secondary())
+		}
+		l.current = &scoreLevel{tree: l.tree.secondaries[index]}
+	}
+	currentDecided, err := l.current.record(us, unit)
+	if err != nil {
+		return false, err
+	}
+	if currentDecided {
+		l.completed = append(l.completed, CompletedSecondary{UsValue: l.current.us, ThemValue: l.current.them})
+		if l.current.us > l.current.them {
+			l.us++
+		} else {
+			l.them++
+		}
+		l.current = nil
+	}
+	return l.decided(), nil
+}
+
+// LiveScoreLevel is the score at one level of a live match, e.g. sets won,
+// games in the current set, or points in the current game.
+type LiveScoreLevel struct {
+	Unit ScoreCountingType `json:"unit"`
+	Us   int               `json:"us"`
+	Them int               `json:"them"`
+}
+
+// LiveScore is the score of a match derived by replaying its event log, from
+// the perspective of Side.
+type LiveScore struct {
+	Side      IndividualMatchId     `json:"side"`
+	Opponent  IndividualMatchId     `json:"opponent"`
+	Status    IndividualMatchStatus `json:"status"`
+	Completed []CompletedSecondary  `json:"completed"` // finished secondary units at the top level, e.g. set scores
+	Levels    []LiveScoreLevel      `json:"levels"`    // top level first, down to the unit in progress
+	Events    []*MatchScoreEvent    `json:"events"`
+}
+
+// replayScore replays the events against the scoring tree, returning the top
+// scoreLevel from the perspective of the log's side.
+func replayScore(tree *scoringTree, logId IndividualMatchId, events []*MatchScoreEvent) (*scoreLevel, error) {
+	top := &scoreLevel{tree: tree}
+	for _, event := range events {
+		if _, err := top.record(event.ScoredBy == logId, event.Unit); err != nil {
+			return nil, fmt.Errorf("score event %d: %w", event.Sequence, err)
+		}
+	}
+	return top, nil
+}
+
+// liveScoreFor builds the LiveScore of the replayed top level for the side.
+func (s *IndividualMatch) liveScoreFor(top *scoreLevel, events []*MatchScoreEvent) *LiveScore {
+	flip := s.ID != s.scoreLogId()
+	score := &LiveScore{
+		Side:      s.ID,
+		Opponent:  s.Opponent,
+		Status:    MatchUnstarted,
+		Completed: []CompletedSecondary{},
+		Levels:    []LiveScoreLevel{},
+		Events:    events,
+	}
+	for _, completed := range top.completed {
+		if flip {
+			completed = completed.Reverse()
+		}
+		score.Completed = append(score.Completed, completed)
+	}
+	for level := top; level != nil; level = level.current {
+		us, them := level.us, level.them
+		if flip {
+			us, them = them, us
+		}
+		score.Levels = append(score.Levels, LiveScoreLevel{Unit: level.unit(), Us: us, Them: them})
+	}
+	if len(events) > 0 {
+		score.Status = MatchInProgress
+	}
+	if top.decided() {
+		won := top.us > top.them
+		if flip {
+			won = !won
+		}
+		score.Status = MatchLost
+		if won {
+			score.Status = MatchWon
+		}
+	}
+	return score
+}
+
+// ReplayMatchScore derives the current score of this match by replaying its
+// event log against the ScoringStructure and its secondary structures.
+func (s *IndividualMatch) ReplayMatchScore(ctx context.Context, db database.Provider) (*LiveScore, error) {
+	tree, err := loadScoringTree(ctx, db, s.Structure)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.GetScoreEvents(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	top, err := replayScore(tree, s.scoreLogId(), events)
+	if err != nil {
+		return nil, err
+	}
+	return s.liveScoreFor(top, events), nil
+}
+
+// RecordScoreEvent appends a unit won by this side to the match's event log
+// and brings both sides' aggregate scores up to date. The unit may be any
+// level of the ScoringStructure, e.g. a point, or a whole game when points
+// aren't being tracked. A match that is completed, or already scored without
+// events, can't be scored live.
+func (s *IndividualMatch) RecordScoreEvent(ctx context.Context, db database.Provider, unit ScoreCountingType, recordedBy database.UserId) (*LiveScore, error) {
+	if s.Opponent == IndividualMatchId(database.InvalidRecordId) {
+		return nil, errors.New("live scoring requires a match with an opponent")
+	}
+	if s.Outcome != OutcomePlayed {
+		return nil, fmt.Errorf("match was decided by %s", s.Outcome)
+	}
+	if err := unit.StaticallyValid(); err != nil {
+		return nil, err
+	}
+	tree, err := loadScoringTree(ctx, db, s.Structure)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.GetScoreEvents(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLiveScorable(ctx, db, len(events)); err != nil {
+		return nil, err
+	}
+
+	event := NewMatchScoreEvent()
+	event.MatchId = s.scoreLogId()
+	event.Sequence = len(events) + 1
+	if len(events) > 0 {
+		event.Sequence = events[len(events)-1].Sequence + 1
+	}
+	event.ScoredBy = s.ID
+	event.Unit = unit
+	event.RecordedBy = recordedBy
+
+	// replay with the new event first, so that an event the scoring structure
+	// can't accept is never logged
+	events = append(events, event)
+	top, err := replayScore(tree, event.MatchId, events)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := database.CreateOne(ctx, db, event); err != nil {
+		return nil, err
+	}
+	if err := s.syncAggregateScore(ctx, db, top, len(events)); err != nil {
+		return nil, err
+	}
+	return s.liveScoreFor(top, events), nil
+}
+
+// checkLiveScorable refuses score events on a match that has been completed,
+// or that was scored through RecordScore before any event was logged. The
+// replayed log would otherwise overwrite that score, and its winner, behind
+// the back of any ScoreReport already made for it.
+func (s *IndividualMatch) checkLiveScorable(ctx context.Context, db database.Provider, eventCount int) error {
+	logSide, err := database.GetExistingRecordById(ctx, db, &IndividualMatch{}, s.scoreLogId().RecordId())
+	if err != nil {
+		return err
+	}
+	otherSide, err := logSide.GetOpponent(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, side := range []*IndividualMatch{logSide, otherSide} {
+		if side.Status == MatchWon || side.Status == MatchLost {
+			return errors.New("match has already been completed")
+		}
+		if eventCount == 0 && (side.MainValue > 0 || side.SecondaryValue > 0 || side.WinOverride) {
+			return errors.New("match has already been scored without live scoring")
+		}
+	}
+	return nil
+}
+
+// UndoScoreEvents removes the last count events from the match's event log and
+// brings both sides' aggregate scores back in line with the remaining events.
+func (s *IndividualMatch) UndoScoreEvents(ctx context.Context, db database.Provider, count int) (*LiveScore, error) {
+	if s.Outcome != OutcomePlayed {
+		return nil, fmt.Errorf("match was decided by %s", s.Outcome)
+	}
+	events, err := s.GetScoreEvents(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if count < 1 || count > len(events) {
+		return nil, fmt.Errorf("can undo between 1 and %d score events, not %d", len(events), count)
+	}
+	tree, err := loadScoringTree(ctx, db, s.Structure)
+	if err != nil {
+		return nil, err
+	}
+	remaining := events[:len(events)-count]
+	top, err := replayScore(tree, s.scoreLogId(), remaining)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events[len(remaining):] {
+		if _, _, err := database.DeleteOneById(ctx, db, event, event.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.syncAggregateScore(ctx, db, top, len(remaining)); err != nil {
+		return nil, err
+	}
+	return s.liveScoreFor(top, remaining), nil
+}
+
+// syncAggregateScore writes the replayed score onto both sides of the match:
+// MainValue holds top-level units won (e.g. sets), SecondaryValue the total
+// secondary units won across the match (e.g. games), and Status follows the
+// replay, so that standings and stats read live-scored matches the same way
+// as ones scored with RecordScore.
+func (s *IndividualMatch) syncAggregateScore(ctx context.Context, db database.Provider, top *scoreLevel, eventCount int) error {
+	logSide, err := database.GetExistingRecordById(ctx, db, &IndividualMatch{}, s.scoreLogId().RecordId())
+	if err != nil {
+		return err
+	}
+	otherSide, err := logSide.GetOpponent(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	logSide.MainValue, otherSide.MainValue = top.us, top.them
+	logSide.SecondaryValue, otherSide.SecondaryValue = 0, 0
+	for _, completed := range top.completed {
+		logSide.SecondaryValue += completed.UsValue
+		otherSide.SecondaryValue += completed.ThemValue
+	}
+	if top.current != nil {
+		logSide.SecondaryValue += top.current.us
+		otherSide.SecondaryValue += top.current.them
+	}
+	logSide.WinOverride, otherSide.WinOverride = false, false
+
+	switch {
+	case top.decided() && top.us > top.them:
+		logSide.Status, otherSide.Status = MatchWon, MatchLost
+	case top.decided():
+		logSide.Status, otherSide.Status = MatchLost, MatchWon
+	case eventCount > 0:
+		logSide.Status, otherSide.Status = MatchInProgress, MatchInProgress
+	default:
+		logSide.Status, otherSide.Status = MatchUnstarted, MatchUnstarted
+	}
+
+	if err := database.UpdateOne(ctx, db, logSide); err != nil {
+		return err
+	}
+	if err := database.UpdateOne(ctx, db, otherSide); err != nil {
+		return err
+	}
+	if s.ID == logSide.ID {
+		s.MainValue, s.SecondaryValue, s.WinOverride, s.Status = logSide.MainValue, logSide.SecondaryValue, false, logSide.Status
+	} else {
+		s.MainValue, s.SecondaryValue, s.WinOverride, s.Status = otherSide.MainValue, otherSide.SecondaryValue, false, otherSide.Status
+	}
+	return nil
+}
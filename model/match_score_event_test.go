@@ -0,0 +1,187 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordGames records one game event per entry in flow, won by match1 when
+// the entry is true and by match2 otherwise.
+func recordGames(t *testing.T, db database.Provider, match1, match2 *IndividualMatch, flow []bool) {
+	for _, won := range flow {
+		side := match2
+		if won {
+			side = match1
+		}
+		_, err := side.RecordScoreEvent(context.Background(), db, Game, database.InvalidUserId)
+		require.NoError(t, err)
+	}
+}
+
+// newStoredPointScoredMatchStructure builds a best-of-three-sets structure
+// whose sets are scored in games and whose games are scored in points (first
+// to four, win by two).
+func newStoredPointScoredMatchStructure(t *testing.T, db database.Provider) *ScoringStructure {
+	ctx := context.Background()
+	owner := newStoredUser(t, db)
+
+	game, err := database.CreateOne(ctx, db, &ScoringStructure{
+		Owner:                    owner.ID,
+		Name:                     "test-point-game",
+		WinConditionCountingType: Point,
+		WinCondition:             WinCondition{WinThreshold: 4, MustWinBy: 2},
+	})
+	require.NoError(t, err)
+
+	set, err := database.CreateOne(ctx, db, &ScoringStructure{
+		Owner:                    owner.ID,
+		Name:                     "test-point-set",
+		WinConditionCountingType: Game,
+		WinCondition:             WinCondition{WinThreshold: 6, MustWinBy: 2, InstantWinThreshold: 7},
+	})
+	require.NoError(t, err)
+	games := make(ScoringStructureList, 13)
+	for i := range games {
+		games[i] = game.ID
+	}
+	require.NoError(t, set.SetSecondaryScoringStructures(ctx, db, games))
+
+	match, err := database.CreateOne(ctx, db, &ScoringStructure{
+		Owner:                    owner.ID,
+		Name:                     "test-point-match",
+		WinConditionCountingType: Set,
+		WinCondition:             WinCondition{WinThreshold: 2, MustWinBy: 1},
+	})
+	require.NoError(t, err)
+	require.NoError(t, match.SetSecondaryScoringStructures(ctx, db, ScoringStructureList{set.ID, set.ID, set.ID}))
+	return match
+}
+
+func TestLiveScoringReplaysGames(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	ss := newDefaultStoredScoringStructure(t, db)
+	match1, match2 := newStoredMatchPair(t, db, ss)
+
+	recordGames(t, db, match1, match2, closeThreeSets)
+
+	// the aggregate fields on both stored sides follow the replayed log
+	match1, err := database.GetExistingRecordById(ctx, db, &IndividualMatch{}, match1.ID.RecordId())
+	require.NoError(t, err)
+	match2, err = database.GetExistingRecordById(ctx, db, &IndividualMatch{}, match2.ID.RecordId())
+	require.NoError(t, err)
+	require.Equal(t, MatchWon, match1.Status)
+	require.Equal(t, MatchLost, match2.Status)
+	require.Equal(t, 2, match1.MainValue)
+	require.Equal(t, 1, match2.MainValue)
+	require.Equal(t, 6+5+6, match1.SecondaryValue)
+	require.Equal(t, 4+7+4, match2.SecondaryValue)
+
+	score, err := match2.ReplayMatchScore(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, MatchLost, score.Status)
+	require.Equal(t, []CompletedSecondary{{4, 6}, {7, 5}, {4, 6}}, score.Completed)
+	require.Len(t, score.Events, len(closeThreeSets))
+
+	// nothing more can be recorded once the match is decided
+	_, err = match2.RecordScoreEvent(ctx, db, Game, database.InvalidUserId)
+	require.Error(t, err)
+	events, err := match1.GetScoreEvents(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, events, len(closeThreeSets))
+}
+
+func TestLiveScoringRefusedOnScoredMatch(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	ss := newDefaultStoredScoringStructure(t, db)
+	match1, match2 := newStoredMatchPair(t, db, ss)
+
+	// a score recorded without events can't be taken over by live scoring
+	match2.MainValue = 1
+	require.NoError(t, database.UpdateOne(ctx, db, match2))
+	_, err := match1.RecordScoreEvent(ctx, db, Game, database.InvalidUserId)
+	require.ErrorContains(t, err, "without live scoring")
+
+	// nor can a completed match
+	match2.MainValue = 0
+	match2.Status = MatchLost
+	require.NoError(t, database.UpdateOne(ctx, db, match2))
+	_, err = match1.RecordScoreEvent(ctx, db, Game, database.InvalidUserId)
+	require.ErrorContains(t, err, "already been completed")
+
+	events, err := match1.GetScoreEvents(ctx, db)
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestLiveScoringUndo(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	ss := newDefaultStoredScoringStructure(t, db)
+	match1, match2 := newStoredMatchPair(t, db, ss)
+
+	recordGames(t, db, match1, match2, sixZeroDustedFlow[:6])
+	require.Equal(t, 1, match1.MainValue)
+
+	score, err := match1.UndoScoreEvents(ctx, db, 1)
+	require.NoError(t, err)
+	require.Equal(t, MatchInProgress, score.Status)
+	require.Equal(t, []LiveScoreLevel{{Unit: Set, Us: 0, Them: 0}, {Unit: Game, Us: 5, Them: 0}}, score.Levels)
+	require.Equal(t, 0, match1.MainValue)
+	require.Equal(t, 5, match1.SecondaryValue)
+
+	_, err = match1.UndoScoreEvents(ctx, db, 6)
+	require.Error(t, err)
+
+	score, err = match2.UndoScoreEvents(ctx, db, 5)
+	require.NoError(t, err)
+	require.Equal(t, MatchUnstarted, score.Status)
+	match1, err = database.GetExistingRecordById(ctx, db, &IndividualMatch{}, match1.ID.RecordId())
+	require.NoError(t, err)
+	require.Equal(t, MatchUnstarted, match1.Status)
+	require.Zero(t, match1.SecondaryValue)
+}
+
+func TestLiveScoringPoints(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	ss := newStoredPointScoredMatchStructure(t, db)
+	match1, match2 := newStoredMatchPair(t, db, ss)
+
+	for i := 0; i < 3; i++ {
+		_, err := match1.RecordScoreEvent(ctx, db, Point, database.InvalidUserId)
+		require.NoError(t, err)
+	}
+	score, err := match2.RecordScoreEvent(ctx, db, Point, database.InvalidUserId)
+	require.NoError(t, err)
+	require.Equal(t, []LiveScoreLevel{
+		{Unit: Set, Us: 0, Them: 0},
+		{Unit: Game, Us: 0, Them: 0},
+		{Unit: Point, Us: 1, Them: 3},
+	}, score.Levels)
+
+	// first to four points and two clear takes the game
+	score, err = match1.RecordScoreEvent(ctx, db, Point, database.InvalidUserId)
+	require.NoError(t, err)
+	require.Equal(t, []LiveScoreLevel{{Unit: Set, Us: 0, Them: 0}, {Unit: Game, Us: 1, Them: 0}}, score.Levels)
+
+	// a whole game can be recorded when points aren't tracked, discarding any
+	// partial game
+	_, err = match1.RecordScoreEvent(ctx, db, Point, database.InvalidUserId)
+	require.NoError(t, err)
+	score, err = match2.RecordScoreEvent(ctx, db, Game, database.InvalidUserId)
+	require.NoError(t, err)
+	require.Equal(t, []LiveScoreLevel{{Unit: Set, Us: 0, Them: 0}, {Unit: Game, Us: 1, Them: 1}}, score.Levels)
+	require.Equal(t, 1, match2.SecondaryValue)
+
+	// a whole set can be awarded at once, discarding the games played in it
+	_, err = match1.RecordScoreEvent(ctx, db, Set, database.InvalidUserId)
+	require.NoError(t, err)
+	require.Equal(t, 1, match1.MainValue)
+	require.Zero(t, match1.SecondaryValue)
+}
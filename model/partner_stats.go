@@ -0,0 +1,132 @@
+package model
+
+import (
+	"context"
+	"sort"
+
+	"intraclub/database"
+)
+
+// PartnerStats aggregates the results of every completed match two players
+// have played together as a doubles pairing, regardless of which team or
+// season they played for. Sets and games are taken from each match's
+// MainValue and SecondaryValue respectively. Matches decided by a retirement,
+// default or walkover only count toward the record and differentials as the
+// season's OutcomeTreatment allows.
+type PartnerStats struct {
+	Player1          database.UserId `json:"player1"` // lower of the two user IDs
+	Player2          database.UserId `json:"player2"` // higher of the two user IDs
+	Matches          int             `json:"matches"`
+	Wins             int             `json:"wins"`
+	Losses           int             `json:"losses"`
+	SetsWon          int             `json:"sets_won"`
+	SetsLost         int             `json:"sets_lost"`
+	SetDifferential  int             `json:"set_differential"`
+	GamesWon         int             `json:"games_won"`
+	GamesLost        int             `json:"games_lost"`
+	GameDifferential int             `json:"game_differential"`
+	Lines            []int           `json:"lines"` // distinct format line indexes played, ascending
+}
+
+// WinRate returns the share of matches this pairing won.
+func (p *PartnerStats) WinRate() float64 {
+	if p.Matches == 0 {
+		return 0
+	}
+	return float64(p.Wins) / float64(p.Matches)
+}
+
+// PartnerStatsFilter restricts which completed matches are counted by
+// GetPartnerStats. Zero-valued fields do not filter.
+type PartnerStatsFilter struct {
+	SeasonId SeasonId        // only matches played in this season
+	TeamId   TeamId          // only matches played for this team
+	UserId   database.UserId // only pairings including this user
+}
+
+func (f PartnerStatsFilter) matches(r *LineResult) bool {
+	if f.SeasonId != SeasonId(database.InvalidRecordId) && r.SeasonId != f.SeasonId {
+		return false
+	}
+	if f.TeamId != TeamId(database.InvalidRecordId) && r.Pairing.TeamId != f.TeamId {
+		return false
+	}
+	if f.UserId != database.InvalidUserId && !r.HasPlayer(f.UserId) {
+		return false
+	}
+	return true
+}
+
+type partnerKey struct {
+	player1 database.UserId
+	player2 database.UserId
+}
+
+func newPartnerKey(a, b database.UserId) partnerKey {
+	if b < a {
+		a, b = b, a
+	}
+	return partnerKey{player1: a, player2: b}
+}
+
+// GetPartnerStats returns PartnerStats for every unordered pair of players
+// who have completed a match together, subject to the filter. Results are
+// sorted by matches played, then by win rate.
+func GetPartnerStats(ctx context.Context, db database.Provider, filter PartnerStatsFilter) ([]*PartnerStats, error) {
+	results, err := GetCompletedLineResults(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[partnerKey]*PartnerStats)
+	lines := make(map[partnerKey]map[int]bool)
+	for _, r := range results {
+		if !filter.matches(r) {
+			continue
+		}
+		key := newPartnerKey(r.Pairing.Player1, r.Pairing.Player2)
+		s, ok := stats[key]
+		if !ok {
+			s = &PartnerStats{Player1: key.player1, Player2: key.player2}
+			stats[key] = s
+			lines[key] = make(map[int]bool)
+		}
+		if r.Treatment.CountsTowardRecord {
+			s.Matches++
+			if r.Won() {
+				s.Wins++
+			} else {
+				s.Losses++
+			}
+		}
+		if r.Treatment.CountsTowardDifferential {
+			s.SetsWon += r.Match.MainValue
+			s.SetsLost += r.MainAgainst()
+			s.GamesWon += r.Match.SecondaryValue
+			s.GamesLost += r.SecondaryAgainst()
+		}
+		lines[key][r.Pairing.FormatLineIndex] = true
+	}
+
+	output := make([]*PartnerStats, 0, len(stats))
+	for key, s := range stats {
+		s.SetDifferential = s.SetsWon - s.SetsLost
+		s.GameDifferential = s.GamesWon - s.GamesLost
+		s.Lines = make([]int, 0, len(lines[key]))
+		for line := range lines[key] {
+			s.Lines = append(s.Lines, line)
+		}
+		sort.Ints(s.Lines)
+		output = append(output, s)
+	}
+	sort.Slice(output, func(i, j int) bool {
+		if output[i].Matches != output[j].Matches {
+			return output[i].Matches > output[j].Matches
+		}
+		if output[i].WinRate() != output[j].WinRate() {
+			return output[i].WinRate() > output[j].WinRate()
+		}
+		return output[i].Player1 < output[j].Player1
+	})
+	return output, nil
+}
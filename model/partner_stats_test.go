@@ -0,0 +1,155 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+// storeLineResult stores a pair of opposing IndividualMatch records with the
+// given final score and links each side to its pairing in the TeamMatch.
+func storeLineResult(t *testing.T, db database.Provider, tm *TeamMatch, home, away *LineupPairing, homeSets, awaySets, homeGames, awayGames int) {
+	ctx := context.Background()
+	scoring := newDefaultStoredScoringStructure(t, db)
+	homeMatch, awayMatch := newStoredMatchPair(t, db, scoring)
+
+	homeMatch.MainValue, homeMatch.SecondaryValue = homeSets, homeGames
+	awayMatch.MainValue, awayMatch.SecondaryValue = awaySets, awayGames
+	homeMatch.Status, awayMatch.Status = MatchLost, MatchWon
+	if homeSets > awaySets {
+		homeMatch.Status, awayMatch.Status = MatchWon, MatchLost
+	}
+	require.NoError(t, database.UpdateOne(ctx, db, homeMatch))
+	require.NoError(t, database.UpdateOne(ctx, db, awayMatch))
+
+	for _, side := range []struct {
+		pairing *LineupPairing
+		match   *IndividualMatch
+	}{{home, homeMatch}, {away, awayMatch}} {
+		_, err := database.CreateOne(ctx, db, &TeamMatchIndividualMatch{
+			TeamMatchId:       tm.ID,
+			LineupPairingId:   side.pairing.ID,
+			IndividualMatchId: side.match.ID,
+		})
+		require.NoError(t, err)
+	}
+}
+
+// newPartnerStatsFixture stores a TeamMatch with one pairing per team.
+func newPartnerStatsFixture(t *testing.T, db database.Provider) (*TeamMatch, *LineupPairing, *LineupPairing) {
+	ctx := context.Background()
+	tm := newStoredTeamMatch(t, db)
+	homeLineup, err := database.GetExistingRecordById(ctx, db, &Lineup{}, tm.Lineup.RecordId())
+	require.NoError(t, err)
+	homeTeam, err := database.GetExistingRecordById(ctx, db, &Team{}, tm.HomeTeam.RecordId())
+	require.NoError(t, err)
+	awayTeam, err := database.GetExistingRecordById(ctx, db, &Team{}, tm.AwayTeam.RecordId())
+	require.NoError(t, err)
+	awayLineup, err := database.CreateOne(ctx, db, &Lineup{TeamId: awayTeam.ID, WeekId: tm.WeekId})
+	require.NoError(t, err)
+	return tm, newStoredLineupPairing(t, db, homeLineup, homeTeam), newStoredLineupPairing(t, db, awayLineup, awayTeam)
+}
+
+// newRematch stores a TeamMatch between the same two teams in a later Week of
+// the same Draft, with both sides fielding the same players as before.
+func newRematch(t *testing.T, db database.Provider, tm *TeamMatch, home, away *LineupPairing) (*TeamMatch, *LineupPairing, *LineupPairing) {
+	ctx := context.Background()
+	previous, err := database.GetExistingRecordById(ctx, db, &Week{}, tm.WeekId.RecordId())
+	require.NoError(t, err)
+	week := NewWeek()
+	week.DraftId = previous.DraftId
+	week.Date = previous.Date.AddDate(0, 0, 7)
+	weekV, err := database.CreateOne(ctx, db, week)
+	require.NoError(t, err)
+
+	pairings := make([]*LineupPairing, 0, 2)
+	lineups := make([]LineupId, 0, 2)
+	for _, p := range []*LineupPairing{home, away} {
+		lineup, err := database.CreateOne(ctx, db, &Lineup{TeamId: p.TeamId, WeekId: weekV.ID})
+		require.NoError(t, err)
+		pairing, err := database.CreateOne(ctx, db, &LineupPairing{
+			LineupId:        lineup.ID,
+			TeamId:          p.TeamId,
+			Player1:         p.Player1,
+			Player2:         p.Player2,
+			FormatLineIndex: p.FormatLineIndex,
+		})
+		require.NoError(t, err)
+		lineups = append(lineups, lineup.ID)
+		pairings = append(pairings, pairing)
+	}
+
+	rematch, err := database.CreateOne(ctx, db, &TeamMatch{WeekId: weekV.ID, HomeTeam: tm.HomeTeam, AwayTeam: tm.AwayTeam, Lineup: lineups[0]})
+	require.NoError(t, err)
+	return rematch, pairings[0], pairings[1]
+}
+
+func TestGetPartnerStats(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	tm, home, away := newPartnerStatsFixture(t, db)
+	storeLineResult(t, db, tm, home, away, 2, 0, 12, 5)
+	rematch, home2, away2 := newRematch(t, db, tm, home, away)
+	storeLineResult(t, db, rematch, home2, away2, 1, 2, 15, 16)
+
+	// an unfinished match between the same players is not counted
+	third, home3, _ := newRematch(t, db, rematch, home2, away2)
+	unfinished, _ := newStoredMatchPair(t, db, newDefaultStoredScoringStructure(t, db))
+	_, err := database.CreateOne(context.Background(), db, &TeamMatchIndividualMatch{
+		TeamMatchId:       third.ID,
+		LineupPairingId:   home3.ID,
+		IndividualMatchId: unfinished.ID,
+	})
+	require.NoError(t, err)
+
+	stats, err := GetPartnerStats(context.Background(), db, PartnerStatsFilter{})
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+
+	byPlayer := make(map[database.UserId]*PartnerStats)
+	for _, s := range stats {
+		require.Less(t, s.Player1, s.Player2)
+		byPlayer[s.Player1] = s
+	}
+	homeStats := byPlayer[min(home.Player1, home.Player2)]
+	require.NotNil(t, homeStats)
+	require.Equal(t, 2, homeStats.Matches)
+	require.Equal(t, 1, homeStats.Wins)
+	require.Equal(t, 1, homeStats.Losses)
+	require.Equal(t, 3, homeStats.SetsWon)
+	require.Equal(t, 2, homeStats.SetsLost)
+	require.Equal(t, 1, homeStats.SetDifferential)
+	require.Equal(t, 27, homeStats.GamesWon)
+	require.Equal(t, 21, homeStats.GamesLost)
+	require.Equal(t, 6, homeStats.GameDifferential)
+	require.Equal(t, []int{0}, homeStats.Lines)
+
+	awayStats := byPlayer[min(away.Player1, away.Player2)]
+	require.NotNil(t, awayStats)
+	require.Equal(t, -1, awayStats.SetDifferential)
+	require.Equal(t, -6, awayStats.GameDifferential)
+}
+
+func TestGetPartnerStatsFilters(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	tm, home, away := newPartnerStatsFixture(t, db)
+	storeLineResult(t, db, tm, home, away, 2, 1, 14, 10)
+
+	stats, err := GetPartnerStats(context.Background(), db, PartnerStatsFilter{TeamId: tm.AwayTeam})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	require.Equal(t, 0, stats[0].Wins)
+	require.Equal(t, 1, stats[0].Losses)
+
+	stats, err = GetPartnerStats(context.Background(), db, PartnerStatsFilter{UserId: home.Player2})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	require.Equal(t, 1, stats[0].Wins)
+
+	// the team match's week is not assigned to a season
+	stats, err = GetPartnerStats(context.Background(), db, PartnerStatsFilter{SeasonId: SeasonId(1234)})
+	require.NoError(t, err)
+	require.Empty(t, stats)
+}
@@ -0,0 +1,459 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"intraclub/database"
+)
+
+const (
+	// InitialPlayerRating is the dynamic rating of a player before their first
+	// rated match.
+	InitialPlayerRating = 1500.0
+
+	// PlayerRatingK is the largest change in rating a single match can cause
+	// before weighting by the games margin.
+	PlayerRatingK = 32.0
+)
+
+// PlayerRatingChange is one step in a player's dynamic rating history: the
+// change caused by a single completed doubles match. Ratings follow a
+// team-average Elo model (see replayPlayerRatings) and the history is derived
+// entirely from match results, so it can be discarded and rebuilt at any time
+// with RebuildPlayerRatings.
+type PlayerRatingChange struct {
+	ID           database.RecordId `json:"id"`
+	UserId       database.UserId   `json:"user_id"`
+	Sequence     int               `json:"sequence"` // 1-based position of the match in the replay
+	MatchId      IndividualMatchId `json:"match_id"` // side of the pair with the lower ID
+	WeekId       WeekId            `json:"week_id"`
+	Date         time.Time         `json:"date"`
+	Won          bool              `json:"won"`
+	GamesFor     int               `json:"games_for"`
+	GamesAgainst int               `json:"games_against"`
+	Before       float64           `json:"before"`
+	After        float64           `json:"after"`
+}
+
+func NewPlayerRatingChange() *PlayerRatingChange {
+	return &PlayerRatingChange{}
+}
+
+func (p *PlayerRatingChange) GetOwner() database.UserId {
+	return database.InvalidUserId
+}
+
+func (p *PlayerRatingChange) SetOwner(userId database.UserId) {
+	// rating history is derived from match results and has no owner
+}
+
+func (p *PlayerRatingChange) Type() string {
+	return "player_rating_change"
+}
+
+func (p *PlayerRatingChange) GetId() database.RecordId {
+	return p.ID
+}
+
+func (p *PlayerRatingChange) SetId(id database.RecordId) {
+	p.ID = id
+}
+
+func (p *PlayerRatingChange) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
+	return []database.UserId{database.SysAdminUserId}
+}
+
+func (p *PlayerRatingChange) AccessibleTo(ctx context.Context, db database.Provider) []database.UserId {
+	return database.AccessibleToEveryone
+}
+
+func (p *PlayerRatingChange) StaticallyValid() error {
+	return nil
+}
+
+func (p *PlayerRatingChange) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	return database.ExistsById(ctx, db, &User{}, p.UserId.RecordId())
+}
+
+func (p *PlayerRatingChange) NewRecord() database.CrudRecord {
+	return new(PlayerRatingChange)
+}
+
+// ratedMatch is a completed doubles match pair as replayed by the rating
+// engine, from the point of view of the side with the lower ID.
+type ratedMatch struct {
+	matchId      IndividualMatchId
+	teamMatchId  TeamMatchId
+	week         *Week
+	us, them     [2]database.UserId
+	won          bool
+	gamesFor     int
+	gamesAgainst int
+}
+
+// sameResult reports whether a stored change was made by this match with the
+// same players and result, so that it doesn't need to be replayed.
+func (m *ratedMatch) sameResult(c *PlayerRatingChange) bool {
+	won, gamesFor, gamesAgainst := m.won, m.gamesFor, m.gamesAgainst
+	switch c.UserId {
+	case m.us[0], m.us[1]:
+	case m.them[0], m.them[1]:
+		won, gamesFor, gamesAgainst = !won, m.gamesAgainst, m.gamesFor
+	default:
+		return false
+	}
+	return c.MatchId == m.matchId && c.Won == won && c.GamesFor == gamesFor && c.GamesAgainst == gamesAgainst
+}
+
+// isDoubles reports whether a pairing fielded two distinct players.
+func isDoubles(p *LineupPairing) bool {
+	return p != nil && p.Player1 != database.InvalidUserId && p.Player2 != database.InvalidUserId && p.Player1 != p.Player2
+}
+
+// getRatedMatches returns every completed doubles match that affects dynamic
+// ratings, in chronological order: by week, then team match, then match. Only
+// matches with a single winner whose outcome counts toward both the record
+// and the differentials are rated, so defaults and walkovers don't move a
+// rating unless a season counts them fully. The games margin is taken from
+// the secondary values (e.g. games in a match of sets), falling back to the
+// main values for a structure without secondary units.
+func getRatedMatches(ctx context.Context, db database.Provider) ([]*ratedMatch, error) {
+	results, err := GetCompletedLineResults(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]*ratedMatch, 0, len(results)/2)
+	for _, r := range results {
+		if r.Opponent == nil || r.Opponent.ID < r.Match.ID || r.Match.Status == r.Opponent.Status {
+			continue
+		}
+		if !r.Treatment.CountsTowardRecord || !r.Treatment.CountsTowardDifferential {
+			continue
+		}
+		if !isDoubles(r.Pairing) || !isDoubles(r.OpponentPairing) {
+			continue
+		}
+		m := &ratedMatch{
+			matchId:      r.Match.ID,
+			teamMatchId:  r.TeamMatch.ID,
+			week:         r.Week,
+			us:           [2]database.UserId{r.Pairing.Player1, r.Pairing.Player2},
+			them:         [2]database.UserId{r.OpponentPairing.Player1, r.OpponentPairing.Player2},
+			won:          r.Won(),
+			gamesFor:     r.Match.SecondaryValue,
+			gamesAgainst: r.Opponent.SecondaryValue,
+		}
+		if m.gamesFor == 0 && m.gamesAgainst == 0 {
+			m.gamesFor, m.gamesAgainst = r.Match.MainValue, r.Opponent.MainValue
+		}
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if !a.week.Date.Equal(b.week.Date) {
+			return a.week.Date.Before(b.week.Date)
+		}
+		if a.teamMatchId != b.teamMatchId {
+			return a.teamMatchId < b.teamMatchId
+		}
+		return a.matchId < b.matchId
+	})
+	return matches, nil
+}
+
+// marginMultiplier scales a rating change by the games margin of the match,
+// so a lopsided win moves ratings further than a close one. It is 1 for an
+// even margin and grows logarithmically, e.g. about 2.7 for 6-0 6-0.
+func marginMultiplier(gamesFor, gamesAgainst int) float64 {
+	margin := math.Abs(float64(gamesFor - gamesAgainst))
+	return math.Log(margin + math.E)
+}
+
+// replayPlayerRatings applies each match in order to the ratings, which are
+// updated in place, and returns the resulting changes numbered on from
+// sequence. Each side is rated as the average of its two players; the
+// expected result follows the usual Elo curve, and both players on a side
+// gain (or lose) the same amount, weighted by the games margin, that their
+// opponents lose (or gain).
+func replayPlayerRatings(matches []*ratedMatch, ratings map[database.UserId]float64, sequence int) []*PlayerRatingChange {
+	rating := func(userId database.UserId) float64 {
+		if r, ok := ratings[userId]; ok {
+			return r
+		}
+		return InitialPlayerRating
+	}
+	changes := make([]*PlayerRatingChange, 0, len(matches)*4)
+	for _, m := range matches {
+		sequence++
+		us := (rating(m.us[0]) + rating(m.us[1])) / 2
+		them := (rating(m.them[0]) + rating(m.them[1])) / 2
+		expected := 1 / (1 + math.Pow(10, (them-us)/400))
+		actual := 0.0
+		if m.won {
+			actual = 1
+		}
+		delta := PlayerRatingK * marginMultiplier(m.gamesFor, m.gamesAgainst) * (actual - expected)
+
+		for _, side := range []struct {
+			players      [2]database.UserId
+			won          bool
+			gamesFor     int
+			gamesAgainst int
+			delta        float64
+		}{
+			{m.us, m.won, m.gamesFor, m.gamesAgainst, delta},
+			{m.them, !m.won, m.gamesAgainst, m.gamesFor, -delta},
+		} {
+			for _, userId := range side.players {
+				before := rating(userId)
+				ratings[userId] = before + side.delta
+				changes = append(changes, &PlayerRatingChange{
+					UserId:       userId,
+					Sequence:     sequence,
+					MatchId:      m.matchId,
+					WeekId:       m.week.ID,
+					Date:         m.week.Date,
+					Won:          side.won,
+					GamesFor:     side.gamesFor,
+					GamesAgainst: side.gamesAgainst,
+					Before:       before,
+					After:        before + side.delta,
+				})
+			}
+		}
+	}
+	return changes
+}
+
+func getPlayerRatingChanges(ctx context.Context, db database.Provider, filter func(*PlayerRatingChange) bool) ([]*PlayerRatingChange, error) {
+	changes, err := database.GetAllWhere[*PlayerRatingChange](ctx, db, func(_ context.Context, c *PlayerRatingChange) bool {
+		return filter(c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Sequence != changes[j].Sequence {
+			return changes[i].Sequence < changes[j].Sequence
+		}
+		return changes[i].UserId < changes[j].UserId
+	})
+	return changes, nil
+}
+
+// playerRatingsMu serializes updates to the stored rating history. Ratings
+// are brought up to date on read (see UpdatePlayerRatings), so without it two
+// concurrent requests would both append the same pending changes.
+var playerRatingsMu sync.Mutex
+
+// storePlayerRatingChanges creates every change, or none of them: on failure
+// the changes already created are deleted again, so a partly replayed match
+// is never left in the history.
+func storePlayerRatingChanges(ctx context.Context, db database.Provider, changes []*PlayerRatingChange) error {
+	for i, change := range changes {
+		if _, err := database.CreateOne(ctx, db, change); err != nil {
+			return errors.Join(err, restorePlayerRatingChanges(ctx, db, changes[:i], nil))
+		}
+	}
+	return nil
+}
+
+// restorePlayerRatingChanges undoes a failed rebuild by deleting the changes
+// it created and re-inserting the ones it deleted.
+func restorePlayerRatingChanges(ctx context.Context, db database.Provider, created, deleted []*PlayerRatingChange) error {
+	var errs []error
+	for _, change := range created {
+		if _, _, err := database.DeleteOneById(ctx, db, change, change.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, change := range deleted {
+		if _, err := db.Create(ctx, change); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RebuildPlayerRatings discards the stored rating history and replays every
+// rated match from scratch. The result depends only on the match results, so
+// rebuilding is always safe and reproducible.
+func RebuildPlayerRatings(ctx context.Context, db database.Provider) error {
+	playerRatingsMu.Lock()
+	defer playerRatingsMu.Unlock()
+	matches, err := getRatedMatches(ctx, db)
+	if err != nil {
+		return err
+	}
+	return rebuildPlayerRatings(ctx, db, matches)
+}
+
+// rebuildPlayerRatings replaces the stored history with a replay of matches.
+// The replay is computed before anything is deleted, and a failure part way
+// through restores the previous history.
+func rebuildPlayerRatings(ctx context.Context, db database.Provider, matches []*ratedMatch) error {
+	replayed := replayPlayerRatings(matches, make(map[database.UserId]float64), 0)
+	existing, err := database.GetAll[*PlayerRatingChange](ctx, db)
+	if err != nil {
+		return err
+	}
+	for i, change := range existing {
+		if _, _, err := database.DeleteOneById(ctx, db, change, change.ID); err != nil {
+			return errors.Join(err, restorePlayerRatingChanges(ctx, db, nil, existing[:i]))
+		}
+	}
+	if err := storePlayerRatingChanges(ctx, db, replayed); err != nil {
+		return errors.Join(err, restorePlayerRatingChanges(ctx, db, nil, existing))
+	}
+	return nil
+}
+
+// UpdatePlayerRatings brings the stored rating history up to date with the
+// match results. When the history is still an exact prefix of the rated
+// matches, only the matches completed since are replayed; a result that was
+// changed or removed, one completed out of order, or a change stored twice
+// rebuilds the history from scratch. Updates are serialized, so calling it
+// again, or from concurrent requests, stores nothing new.
+func UpdatePlayerRatings(ctx context.Context, db database.Provider) error {
+	playerRatingsMu.Lock()
+	defer playerRatingsMu.Unlock()
+	matches, err := getRatedMatches(ctx, db)
+	if err != nil {
+		return err
+	}
+	changes, err := getPlayerRatingChanges(ctx, db, func(*PlayerRatingChange) bool { return true })
+	if err != nil {
+		return err
+	}
+
+	ratings := make(map[database.UserId]float64)
+	applied := 0
+	for i, change := range changes {
+		if change.Sequence > len(matches) || !matches[change.Sequence-1].sameResult(change) {
+			return rebuildPlayerRatings(ctx, db, matches)
+		}
+		if i > 0 && changes[i-1].Sequence == change.Sequence && changes[i-1].UserId == change.UserId {
+			return rebuildPlayerRatings(ctx, db, matches)
+		}
+		ratings[change.UserId] = change.After
+		applied = max(applied, change.Sequence)
+	}
+	return storePlayerRatingChanges(ctx, db, replayPlayerRatings(matches[applied:], ratings, applied))
+}
+
+// GetPlayerRatingHistory returns the user's rating changes in the order they
+// were applied.
+func GetPlayerRatingHistory(ctx context.Context, db database.Provider, userId database.UserId) ([]*PlayerRatingChange, error) {
+	return getPlayerRatingChanges(ctx, db, func(c *PlayerRatingChange) bool {
+		return c.UserId == userId
+	})
+}
+
+// PlayerRating is a player's current dynamic rating.
+type PlayerRating struct {
+	UserId  database.UserId `json:"user_id"`
+	Rating  float64         `json:"rating"`
+	Matches int             `json:"matches"` // rated matches played
+}
+
+// GetPlayerRatings returns the current dynamic rating of every player with a
+// rated match, highest first.
+func GetPlayerRatings(ctx context.Context, db database.Provider) ([]*PlayerRating, error) {
+	changes, err := getPlayerRatingChanges(ctx, db, func(*PlayerRatingChange) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[database.UserId]*PlayerRating)
+	for _, change := range changes {
+		r, ok := byUser[change.UserId]
+		if !ok {
+			r = &PlayerRating{UserId: change.UserId}
+			byUser[change.UserId] = r
+		}
+		r.Rating = change.After
+		r.Matches++
+	}
+	output := make([]*PlayerRating, 0, len(byUser))
+	for _, r := range byUser {
+		output = append(output, r)
+	}
+	sortPlayerRatings(output)
+	return output, nil
+}
+
+func sortPlayerRatings(ratings []*PlayerRating) {
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		return ratings[i].UserId < ratings[j].UserId
+	})
+}
+
+// SuggestedRating is the Rating tier suggested for a player in a Draft based
+// on their dynamic rating.
+type SuggestedRating struct {
+	PlayerRating
+	RatingId RatingId `json:"rating_id"`
+}
+
+// SuggestRatings ranks the draft's available players by dynamic rating (a
+// player without a rated match has InitialPlayerRating) and suggests a tier
+// from the format's possible ratings for each. When the draft has rating
+// cutoffs, a player's rank is treated as a pick and given the rating of that
+// pick; otherwise the players are split evenly across the ratings.
+func SuggestRatings(ctx context.Context, db database.Provider, draft *Draft) ([]*SuggestedRating, error) {
+	possibleRatings, err := draft.GetAvailableRatings(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	players, err := draft.GetAvailablePlayers(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	current, err := GetPlayerRatings(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	cutoffs, err := draft.GetRatingCutoffs(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[database.UserId]*PlayerRating, len(current))
+	for _, r := range current {
+		byUser[r.UserId] = r
+	}
+	ranked := make([]*PlayerRating, 0, len(players))
+	for _, userId := range players {
+		r, ok := byUser[userId]
+		if !ok {
+			r = &PlayerRating{UserId: userId, Rating: InitialPlayerRating}
+		}
+		ranked = append(ranked, r)
+	}
+	sortPlayerRatings(ranked)
+
+	output := make([]*SuggestedRating, 0, len(ranked))
+	if len(possibleRatings) == 0 {
+		return output, nil
+	}
+	for i, r := range ranked {
+		suggested := &SuggestedRating{PlayerRating: *r}
+		if len(cutoffs) > 0 {
+			suggested.RatingId, err = draft.GetRatingForPick(ctx, db, possibleRatings, i)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			suggested.RatingId = possibleRatings[i*len(possibleRatings)/len(ranked)]
+		}
+		output = append(output, suggested)
+	}
+	return output, nil
+}
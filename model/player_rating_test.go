@@ -0,0 +1,184 @@
+package model
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+// latestRating returns the user's rating after their last rated match.
+func latestRating(t *testing.T, db database.Provider, userId database.UserId) float64 {
+	history, err := GetPlayerRatingHistory(context.Background(), db, userId)
+	require.NoError(t, err)
+	require.NotEmpty(t, history)
+	return history[len(history)-1].After
+}
+
+func TestPlayerRatings(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, home, away := newPartnerStatsFixture(t, db)
+	storeLineResult(t, db, tm, home, away, 2, 0, 12, 5)
+	require.NoError(t, UpdatePlayerRatings(ctx, db))
+
+	// evenly matched newcomers: the winners gain exactly what the losers lose
+	history, err := GetPlayerRatingHistory(ctx, db, home.Player1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, 1, history[0].Sequence)
+	require.True(t, history[0].Won)
+	require.Equal(t, 12, history[0].GamesFor)
+	require.Equal(t, float64(InitialPlayerRating), history[0].Before)
+	gain := history[0].After - history[0].Before
+	require.Greater(t, gain, 0.0)
+	require.Equal(t, InitialPlayerRating+gain, latestRating(t, db, home.Player2))
+	require.Equal(t, InitialPlayerRating-gain, latestRating(t, db, away.Player1))
+	require.Equal(t, InitialPlayerRating-gain, latestRating(t, db, away.Player2))
+
+	ratings, err := GetPlayerRatings(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, ratings, 4)
+	require.Greater(t, ratings[0].Rating, ratings[3].Rating)
+	require.Equal(t, 1, ratings[0].Matches)
+
+	// a closer result moves ratings less than a lopsided one
+	require.Less(t, marginMultiplier(7, 6), marginMultiplier(12, 5))
+	require.Equal(t, 1.0, marginMultiplier(6, 6))
+
+	// an upset by the lower-rated side is worth more than the first win was
+	rematch, home2, away2 := newRematch(t, db, tm, home, away)
+	storeLineResult(t, db, rematch, home2, away2, 0, 2, 5, 12)
+	require.NoError(t, UpdatePlayerRatings(ctx, db))
+	history, err = GetPlayerRatingHistory(ctx, db, away.Player1)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, 2, history[1].Sequence)
+	require.Greater(t, history[1].After-history[1].Before, gain)
+
+	// incrementally updated ratings match a rebuild from scratch
+	incremental, err := GetPlayerRatings(ctx, db)
+	require.NoError(t, err)
+	require.NoError(t, RebuildPlayerRatings(ctx, db))
+	rebuilt, err := GetPlayerRatings(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, incremental, rebuilt)
+	all, err := database.GetAll[*PlayerRatingChange](ctx, db)
+	require.NoError(t, err)
+	require.Len(t, all, 8)
+}
+
+func TestUpdatePlayerRatingsRebuildsChangedResults(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, home, away := newPartnerStatsFixture(t, db)
+	storeLineResult(t, db, tm, home, away, 2, 0, 12, 5)
+	require.NoError(t, UpdatePlayerRatings(ctx, db))
+	require.Greater(t, latestRating(t, db, home.Player1), float64(InitialPlayerRating))
+
+	// the result is corrected after ratings were computed
+	links, err := database.GetAllWhere[*TeamMatchIndividualMatch](ctx, db, func(_ context.Context, l *TeamMatchIndividualMatch) bool {
+		return l.TeamMatchId == tm.ID
+	})
+	require.NoError(t, err)
+	for _, link := range links {
+		m, err := database.GetExistingRecordById(ctx, db, &IndividualMatch{}, link.IndividualMatchId.RecordId())
+		require.NoError(t, err)
+		copied := *m
+		if link.LineupPairingId == home.ID {
+			copied.MainValue, copied.SecondaryValue, copied.Status = 0, 5, MatchLost
+		} else {
+			copied.MainValue, copied.SecondaryValue, copied.Status = 2, 12, MatchWon
+		}
+		require.NoError(t, database.UpdateOne(ctx, db, &copied))
+	}
+
+	require.NoError(t, UpdatePlayerRatings(ctx, db))
+	history, err := GetPlayerRatingHistory(ctx, db, home.Player1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.False(t, history[0].Won)
+	require.Less(t, history[0].After, float64(InitialPlayerRating))
+}
+
+func TestUpdatePlayerRatingsIsIdempotent(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, home, away := newPartnerStatsFixture(t, db)
+	storeLineResult(t, db, tm, home, away, 2, 0, 12, 5)
+
+	// concurrent updates, e.g. two rating requests at once, store each change once
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = UpdatePlayerRatings(ctx, db)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	all, err := database.GetAll[*PlayerRatingChange](ctx, db)
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+
+	// a change stored twice (e.g. by an earlier concurrent update) is repaired
+	duplicate := *all[0]
+	_, err = database.CreateOne(ctx, db, &duplicate)
+	require.NoError(t, err)
+	require.NoError(t, UpdatePlayerRatings(ctx, db))
+	all, err = database.GetAll[*PlayerRatingChange](ctx, db)
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	ratings, err := GetPlayerRatings(ctx, db)
+	require.NoError(t, err)
+	for _, r := range ratings {
+		require.Equal(t, 1, r.Matches)
+	}
+}
+
+func TestSuggestRatings(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, home, away := newPartnerStatsFixture(t, db)
+	storeLineResult(t, db, tm, home, away, 2, 0, 12, 5)
+	require.NoError(t, UpdatePlayerRatings(ctx, db))
+
+	// the winners and losers of the rated match, plus a player with no rating
+	newcomer := newStoredUser(t, db)
+	draft := newStoredDraft(t, db, newcomer.ID)
+	for _, player := range []database.UserId{home.Player1, away.Player1} {
+		_, err := database.CreateOne(ctx, db, &DraftAvailablePlayer{DraftId: draft.ID, PlayerId: player})
+		require.NoError(t, err)
+	}
+	ratings, err := draft.GetAvailableRatings(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, ratings, 4)
+
+	suggestions, err := SuggestRatings(ctx, db, draft)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 3)
+	require.Equal(t, home.Player1, suggestions[0].UserId)
+	require.Equal(t, newcomer.ID, suggestions[1].UserId)
+	require.Equal(t, float64(InitialPlayerRating), suggestions[1].Rating)
+	require.Equal(t, away.Player1, suggestions[2].UserId)
+	// without cutoffs the players are spread evenly across the ratings
+	require.Equal(t, ratings[0], suggestions[0].RatingId)
+	require.Equal(t, ratings[1], suggestions[1].RatingId)
+	require.Equal(t, ratings[2], suggestions[2].RatingId)
+
+	// with cutoffs, only the top pick gets the highest rating
+	_, err = draft.AssignRatingCutoff(ctx, db, ratings[0], 0)
+	require.NoError(t, err)
+	suggestions, err = SuggestRatings(ctx, db, draft)
+	require.NoError(t, err)
+	require.Equal(t, ratings[0], suggestions[0].RatingId)
+	require.Equal(t, ratings[3], suggestions[1].RatingId)
+	require.Equal(t, ratings[3], suggestions[2].RatingId)
+}
@@ -0,0 +1,144 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"intraclub/database"
+)
+
+// PlayingTimeFlagThreshold is how far a member's play rate may fall below
+// their availability rate before the member is flagged in the playing-time
+// report (e.g. available 80% of the time but playing only 50% of the time).
+const PlayingTimeFlagThreshold = 0.25
+
+// PlayingTimeEntry summarizes one team member's availability and lineup
+// appearances over a season.
+type PlayingTimeEntry struct {
+	UserId           database.UserId `json:"user_id"`
+	WeeksAvailable   int             `json:"weeks_available"`
+	WeeksMaybe       int             `json:"weeks_maybe"`
+	WeeksUnavailable int             `json:"weeks_unavailable"`
+	WeeksUnset       int             `json:"weeks_unset"`
+	WeeksPlayed      int             `json:"weeks_played"`      // weeks the member appeared in an official lineup
+	LinesPlayed      []int           `json:"lines_played"`      // format line index of every line played in those weeks, ascending
+	AvailabilityRate float64         `json:"availability_rate"` // share of lineup weeks the member was marked available
+	PlayRate         float64         `json:"play_rate"`         // share of lineup weeks the member played
+	Flagged          bool            `json:"flagged"`           // play rate is well below availability rate
+}
+
+// PlayingTimeReport is the per-team playing-time fairness report for a Season.
+// Rates are computed over LineupWeeks, i.e. the weeks in which the team had an
+// official Lineup, so that weeks which have not been played yet don't count
+// against anyone.
+type PlayingTimeReport struct {
+	SeasonId    SeasonId            `json:"season_id"`
+	TeamId      TeamId              `json:"team_id"`
+	Weeks       int                 `json:"weeks"`
+	LineupWeeks int                 `json:"lineup_weeks"`
+	Entries     []*PlayingTimeEntry `json:"entries"`
+}
+
+// GetPlayingTimeReport builds the PlayingTimeReport for a team in the Season,
+// with one entry per team member.
+func GetPlayingTimeReport(ctx context.Context, db database.Provider, season *Season, team *Team) (*PlayingTimeReport, error) {
+	if !season.IsTeamAssignedToSeason(ctx, db, team.ID) {
+		return nil, fmt.Errorf("team %s is not assigned to season %s", team.ID, season.ID)
+	}
+
+	weeks, err := GetWeeksForDraft(ctx, db, season.DraftId)
+	if err != nil {
+		return nil, err
+	}
+	members, err := team.GetMembers(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[database.UserId]*PlayingTimeEntry, len(members))
+	report := &PlayingTimeReport{
+		SeasonId: season.ID,
+		TeamId:   team.ID,
+		Weeks:    len(weeks),
+		Entries:  make([]*PlayingTimeEntry, 0, len(members)),
+	}
+	for _, member := range members {
+		if _, exists := entries[member]; exists {
+			continue
+		}
+		entry := &PlayingTimeEntry{UserId: member, LinesPlayed: []int{}}
+		entries[member] = entry
+		report.Entries = append(report.Entries, entry)
+	}
+
+	availableInLineupWeeks := make(map[database.UserId]int, len(members))
+	for _, week := range weeks {
+		lineups, err := database.GetAllWhere[*Lineup](ctx, db, func(_ context.Context, l *Lineup) bool {
+			return l.TeamId == team.ID && l.WeekId == week.ID && l.Official
+		})
+		if err != nil {
+			return nil, err
+		}
+		hasLineup := len(lineups) > 0
+		if hasLineup {
+			report.LineupWeeks++
+			pairings, err := database.GetAllWhere[*LineupPairing](ctx, db, func(_ context.Context, p *LineupPairing) bool {
+				return p.LineupId == lineups[0].ID
+			})
+			if err != nil {
+				return nil, err
+			}
+			// a member on more than one line still played one week
+			played := make(map[database.UserId]bool)
+			for _, pairing := range pairings {
+				for _, player := range []database.UserId{pairing.Player1, pairing.Player2} {
+					if entry, ok := entries[player]; ok {
+						if !played[player] {
+							played[player] = true
+							entry.WeeksPlayed++
+						}
+						entry.LinesPlayed = append(entry.LinesPlayed, pairing.FormatLineIndex)
+					}
+				}
+			}
+		}
+
+		availability, err := database.GetAllWhere[*Availability](ctx, db, func(_ context.Context, a *Availability) bool {
+			return a.WeekId == week.ID
+		})
+		if err != nil {
+			return nil, err
+		}
+		options := make(map[database.UserId]AvailabilityOption, len(availability))
+		for _, a := range availability {
+			options[a.UserId] = a.Available
+		}
+		for member, entry := range entries {
+			switch options[member] {
+			case AvailabilityAvailable:
+				entry.WeeksAvailable++
+				if hasLineup {
+					availableInLineupWeeks[member]++
+				}
+			case AvailabilityMaybe:
+				entry.WeeksMaybe++
+			case AvailabilityNotAvailable:
+				entry.WeeksUnavailable++
+			default:
+				entry.WeeksUnset++
+			}
+		}
+	}
+
+	for _, entry := range report.Entries {
+		sort.Ints(entry.LinesPlayed)
+		if report.LineupWeeks == 0 {
+			continue
+		}
+		entry.AvailabilityRate = float64(availableInLineupWeeks[entry.UserId]) / float64(report.LineupWeeks)
+		entry.PlayRate = float64(entry.WeeksPlayed) / float64(report.LineupWeeks)
+		entry.Flagged = entry.AvailabilityRate-entry.PlayRate >= PlayingTimeFlagThreshold
+	}
+	return report, nil
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayingTimeReport(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, _ := newDefaultSeasonWithTeams(t, db, 1)
+	teams, err := season.GetTeams(ctx, db)
+	require.NoError(t, err)
+	team := teams[0]
+
+	draft, err := season.GetDraft(ctx, db)
+	require.NoError(t, err)
+	format, err := database.GetExistingRecordById(ctx, db, &Format{}, draft.Format.RecordId())
+	require.NoError(t, err)
+	lines, err := format.GetLines(ctx, db)
+	require.NoError(t, err)
+
+	captain, err := team.GetCaptain(ctx, db)
+	require.NoError(t, err)
+	starter := newStoredUser(t, db)
+	benched := newStoredUser(t, db)
+	for _, user := range []*User{starter, benched} {
+		_, err = database.CreateOne(ctx, db, &TeamAssignment{TeamId: team.ID, UserId: user.ID, Role: TeamRoleMember})
+		require.NoError(t, err)
+	}
+	_, err = database.CreateOne(ctx, db, &TeamRating{TeamId: team.ID, UserId: captain, RatingId: lines[0].Player1Rating})
+	require.NoError(t, err)
+	for _, user := range []*User{starter, benched} {
+		_, err = database.CreateOne(ctx, db, &TeamRating{TeamId: team.ID, UserId: user.ID, RatingId: lines[0].Player2Rating})
+		require.NoError(t, err)
+	}
+
+	// three weeks, the first two of which have been played
+	weeks := []*Week{newStoredWeek(t, db, season), newStoredWeek(t, db, season), newStoredWeek(t, db, season)}
+	for _, week := range weeks[:2] {
+		lineup, err := database.CreateOne(ctx, db, &Lineup{TeamId: team.ID, WeekId: week.ID, Confirmed: true, Official: true})
+		require.NoError(t, err)
+		_, err = database.CreateOne(ctx, db, &LineupPairing{LineupId: lineup.ID, TeamId: team.ID, Player1: captain, Player2: starter.ID, FormatLineIndex: 0})
+		require.NoError(t, err)
+	}
+	for _, week := range weeks {
+		_, err = database.CreateOne(ctx, db, &Availability{UserId: benched.ID, WeekId: week.ID, Available: AvailabilityAvailable})
+		require.NoError(t, err)
+	}
+	_, err = database.CreateOne(ctx, db, &Availability{UserId: starter.ID, WeekId: weeks[0].ID, Available: AvailabilityMaybe})
+	require.NoError(t, err)
+	_, err = database.CreateOne(ctx, db, &Availability{UserId: starter.ID, WeekId: weeks[2].ID, Available: AvailabilityNotAvailable})
+	require.NoError(t, err)
+
+	report, err := GetPlayingTimeReport(ctx, db, season, team)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Weeks)
+	require.Equal(t, 2, report.LineupWeeks)
+	require.Len(t, report.Entries, 3)
+
+	byUser := make(map[database.UserId]*PlayingTimeEntry)
+	for _, entry := range report.Entries {
+		byUser[entry.UserId] = entry
+	}
+
+	s := byUser[starter.ID]
+	require.Equal(t, 2, s.WeeksPlayed)
+	require.Equal(t, []int{0, 0}, s.LinesPlayed)
+	require.Equal(t, 1, s.WeeksMaybe)
+	require.Equal(t, 1, s.WeeksUnavailable)
+	require.Equal(t, 1, s.WeeksUnset)
+	require.Equal(t, 1.0, s.PlayRate)
+	require.False(t, s.Flagged)
+
+	b := byUser[benched.ID]
+	require.Equal(t, 3, b.WeeksAvailable)
+	require.Equal(t, 0, b.WeeksPlayed)
+	require.Empty(t, b.LinesPlayed)
+	require.Equal(t, 1.0, b.AvailabilityRate)
+	require.Equal(t, 0.0, b.PlayRate)
+	require.True(t, b.Flagged)
+
+	// playing two lines in a week is still one week played
+	lineups, err := database.GetAllWhere[*Lineup](ctx, db, func(_ context.Context, l *Lineup) bool {
+		return l.TeamId == team.ID && l.WeekId == weeks[0].ID
+	})
+	require.NoError(t, err)
+	// raw create: the players aren't rated for line 1, which doesn't matter here
+	_, err = db.Create(ctx, &LineupPairing{LineupId: lineups[0].ID, TeamId: team.ID, Player1: captain, Player2: starter.ID, FormatLineIndex: 1})
+	require.NoError(t, err)
+	report, err = GetPlayingTimeReport(ctx, db, season, team)
+	require.NoError(t, err)
+	for _, entry := range report.Entries {
+		if entry.UserId == starter.ID {
+			require.Equal(t, 2, entry.WeeksPlayed)
+			require.Equal(t, []int{0, 0, 1}, entry.LinesPlayed)
+			require.Equal(t, 1.0, entry.PlayRate)
+		}
+	}
+}
+
+func TestPlayingTimeReportRequiresSeasonTeam(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	season, _ := newDefaultSeasonWithTeams(t, db, 1)
+	other := newStoredTeam(t, db, newStoredUser(t, db).ID)
+
+	_, err := GetPlayingTimeReport(context.Background(), db, season, other)
+	require.Error(t, err)
+}
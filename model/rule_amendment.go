@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -51,6 +52,115 @@ func (r *Ruleset) Amend(ctx context.Context, db database.Provider, a *RuleAmendm
 	return nil, fmt.Errorf("unhandled rule amendment type: %d", a.Type)
 }
 
+// AmendAll applies the amendments in order as one change: either every
+// amendment is applied and the final revision returned, or none is and this
+// Ruleset is left as it was. A later amendment targeting (or placing after) a
+// section modified by an earlier one follows it to its replacement.
+func (r *Ruleset) AmendAll(ctx context.Context, db database.Provider, amendments []*RuleAmendment) (*Ruleset, error) {
+	revision, _, err := r.amendAll(ctx, db, amendments)
+	return revision, err
+}
+
+// amendAll is AmendAll, also returning a function that undoes the change for
+// a caller with more to do that can still fail.
+func (r *Ruleset) amendAll(ctx context.Context, db database.Provider, amendments []*RuleAmendment) (*Ruleset, func() error, error) {
+	if r.Archived() {
+		return nil, nil, fmt.Errorf("ruleset %s has been superseded by %s", r.ID, r.SupersededBy)
+	}
+	existingSections, err := database.GetAllWhere[*RuleSection](ctx, db, func(_ context.Context, s *RuleSection) bool {
+		return s.Parent == r.ID
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := r
+	var revisions []*Ruleset
+	replaced := map[RuleSectionId]RuleSectionId{}
+	for i, amendment := range amendments {
+		a := *amendment
+		if id, ok := replaced[a.TargetSection]; ok {
+			a.TargetSection = id
+		}
+		if id, ok := replaced[a.After]; ok {
+			a.After = id
+		}
+		next, err := current.Amend(ctx, db, &a)
+		if err == nil && next == nil {
+			err = errors.New("amendment was not applied")
+		}
+		if err != nil {
+			if rollbackErr := r.rollbackAmendments(ctx, db, revisions, existingSections); rollbackErr != nil {
+				return nil, nil, fmt.Errorf("amendment %d: %w (rolling back: %s)", i+1, err, rollbackErr)
+			}
+			return nil, nil, fmt.Errorf("amendment %d: %w", i+1, err)
+		}
+		if next.ID != current.ID {
+			revisions = append(revisions, next)
+		}
+		if a.Type == RuleAmendmentTypeModifySection {
+			if id, err := next.replacementOf(ctx, db, a.TargetSection); err == nil && !id.Empty() {
+				replaced[amendment.TargetSection] = id
+				replaced[a.TargetSection] = id
+			}
+		}
+		current = next
+	}
+	undo := func() error {
+		return r.rollbackAmendments(ctx, db, revisions, existingSections)
+	}
+	return current, undo, nil
+}
+
+// replacementOf returns the section of this Ruleset that Replaces the given
+// one, or an empty RuleSectionId if there is none.
+func (r *Ruleset) replacementOf(ctx context.Context, db database.Provider, id RuleSectionId) (RuleSectionId, error) {
+	sectionIds, err := r.GetSections(ctx, db)
+	if err != nil {
+		return RuleSectionId(database.InvalidRecordId), err
+	}
+	for _, sectionId := range sectionIds {
+		section, err := database.GetExistingRecordById(ctx, db, &RuleSection{}, sectionId.RecordId())
+		if err != nil {
+			return RuleSectionId(database.InvalidRecordId), err
+		}
+		if section.Replaces == id {
+			return section.ID, nil
+		}
+	}
+	return RuleSectionId(database.InvalidRecordId), nil
+}
+
+// rollbackAmendments undoes a partially or fully applied AmendAll: it deletes the new
+// revisions (which cascades to the sections added to them), the sections
+// added to this Ruleset and marks this Ruleset as current again.
+func (r *Ruleset) rollbackAmendments(ctx context.Context, db database.Provider, revisions []*Ruleset, existingSections []*RuleSection) error {
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if _, _, err := database.DeleteOneById(ctx, db, &Ruleset{}, revisions[i].ID.RecordId()); err != nil {
+			return err
+		}
+	}
+	existing := make(map[RuleSectionId]bool, len(existingSections))
+	for _, s := range existingSections {
+		existing[s.ID] = true
+	}
+	added, err := database.GetAllWhere[*RuleSection](ctx, db, func(_ context.Context, s *RuleSection) bool {
+		return s.Parent == r.ID && !existing[s.ID]
+	})
+	if err != nil {
+		return err
+	}
+	for _, s := range added {
+		if _, _, err := database.DeleteOneById(ctx, db, &RuleSection{}, s.ID.RecordId()); err != nil {
+			return err
+		}
+	}
+	// raw provider update, which bypasses the PreUpdate hook that forbids
+	// direct modification
+	r.SupersededBy = RulesetId(database.InvalidRecordId)
+	return db.Update(ctx, r)
+}
+
 func (r *Ruleset) HandleAddSection(ctx context.Context, db database.Provider, a *RuleAmendment) (new *Ruleset, err error) {
 	// update the parent and the owner in the new section to add
 	a.NewSection.Parent = r.ID
@@ -100,17 +210,12 @@ func (r *Ruleset) HandleAddSection(ctx context.Context, db database.Provider, a
 			newRelations = append(newRelations, existingRelations[i])
 		}
 
-		// Add new section
-		newSectionRelation := &RulesetSection{
+		// Add new section (linked to the new revision by HandleAmendment)
+		newRelations = append(newRelations, &RulesetSection{
 			RulesetId:    r.ID,
 			SectionId:    v.ID,
 			SectionIndex: targetIndex + 1,
-		}
-		_, err = database.CreateOne(ctx, db, newSectionRelation)
-		if err != nil {
-			return nil, err
-		}
-		newRelations = append(newRelations, newSectionRelation)
+		})
 
 		// Add sections after target
 		for i := targetIndex + 1; i < len(existingRelations); i++ {
@@ -119,14 +224,15 @@ func (r *Ruleset) HandleAddSection(ctx context.Context, db database.Provider, a
 	}
 
 	// Handle the amendment - update section relations
-	newRuleset, err := r.HandleAmendment(getRuleAmendmentContext(), db, newRelations)
+	newRuleset, err := r.HandleAmendment(getRuleAmendmentContext(), db, a, newRelations)
 	if err != nil {
 		fmt.Printf("Error handling amendment: %s\n", err)
 		fmt.Printf("Deleting newly-created section after failed amendment %s\n", v.ID)
-		_, _, err = database.DeleteOneById(ctx, db, &RuleSection{}, v.ID.RecordId())
-		if err != nil {
-			return nil, fmt.Errorf("error deleting newly-created section %s after amendment: %s", v.ID, err.Error())
+		_, _, deleteErr := database.DeleteOneById(ctx, db, &RuleSection{}, v.ID.RecordId())
+		if deleteErr != nil {
+			return nil, fmt.Errorf("error deleting newly-created section %s after amendment: %s", v.ID, deleteErr.Error())
 		}
+		return nil, err
 	}
 	return newRuleset, nil
 }
@@ -150,14 +256,8 @@ func (r *Ruleset) HandleRemoveSection(ctx context.Context, db database.Provider,
 		return nil, fmt.Errorf("deleting section would make ruleset empty")
 	}
 
-	// Delete the target section relation
-	_, _, err = database.DeleteOneById(ctx, db, &RulesetSection{}, a.TargetSection.RecordId())
-	if err != nil {
-		return nil, err
-	}
-
 	// Handle the amendment
-	newRuleset, err := r.HandleAmendment(getRuleAmendmentContext(), db, newRelations)
+	newRuleset, err := r.HandleAmendment(getRuleAmendmentContext(), db, a, newRelations)
 	return newRuleset, err
 }
 
@@ -173,29 +273,41 @@ func (r *Ruleset) HandleModifySection(ctx context.Context, db database.Provider,
 		return nil, fmt.Errorf("new section is not updated")
 	}
 
-	if existing.Markdown == a.NewSection.Markdown && existing.Title != a.NewSection.Title {
-		// if the title is changed but the markdown content is not changed, we do not
-		// need to trigger a new revision. Not a meaningful rule change at that point
-		// so there is not a real reason to track it in the revision history. Instead, just
-		// update the existing RuleSection and return any errors we encounter during this
-		existing.Title = a.NewSection.Title
-		err = db.Update(getRuleAmendmentContext(), existing)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
+	// Any change, even to the title alone, is a new revision: the modified
+	// section is stored as a new RuleSection that Replaces the existing one, so
+	// the archived revision keeps the old section for comparison (see
+	// Ruleset.Diff) and any other ruleset sharing it is left alone.
+	replacement := &RuleSection{
+		Parent:   r.ID,
+		Title:    a.NewSection.Title,
+		Markdown: a.NewSection.Markdown,
+		Owner:    r.Owner,
+		Replaces: existing.ID,
+	}
+	v, err := database.CreateOne(ctx, db, replacement)
+	if err != nil {
+		return nil, err
 	}
 
-	// Update the RuleSection content
-	existing.Title = a.NewSection.Title
-	existing.Markdown = a.NewSection.Markdown
-	err = db.Update(getRuleAmendmentContext(), existing)
+	existingRelations, err := r.GetSectionRelations(ctx, db)
 	if err != nil {
 		return nil, err
 	}
+	newRelations := make([]*RulesetSection, 0, len(existingRelations))
+	for _, sr := range existingRelations {
+		srCopy := *sr
+		if srCopy.SectionId == existing.ID {
+			srCopy.SectionId = v.ID
+		}
+		newRelations = append(newRelations, &srCopy)
+	}
 
-	// No revision needed for modify without order change, just update the section
-	return r, nil
+	newRuleset, err := r.HandleAmendment(getRuleAmendmentContext(), db, a, newRelations)
+	if err != nil {
+		_, _, _ = database.DeleteOneById(ctx, db, &RuleSection{}, v.ID.RecordId())
+		return nil, err
+	}
+	return newRuleset, nil
 }
 
 func (r *Ruleset) HandleReorderSection(ctx context.Context, db database.Provider, a *RuleAmendment) (new *Ruleset, err error) {
@@ -236,19 +348,11 @@ func (r *Ruleset) HandleReorderSection(ctx context.Context, db database.Provider
 		}
 	}
 
-	// Update section indices for all relations
-	for i, sr := range newRelations {
-		srCopy := *sr
-		srCopy.SectionIndex = i
-		err = db.Update(getRuleAmendmentContext(), &srCopy)
-		if err != nil {
-			return nil, err
-		}
-	}
-	return r, nil
+	// Handle the amendment (the new revision gets the reordered indices)
+	return r.HandleAmendment(getRuleAmendmentContext(), db, a, newRelations)
 }
 
-func (r *Ruleset) HandleAmendment(ctx context.Context, db database.Provider, newRelations []*RulesetSection) (newRuleset *Ruleset, err error) {
+func (r *Ruleset) HandleAmendment(ctx context.Context, db database.Provider, a *RuleAmendment, newRelations []*RulesetSection) (newRuleset *Ruleset, err error) {
 	// Build section ID list from relations
 	newSectionIds := make([]RuleSectionId, 0, len(newRelations))
 	for _, sr := range newRelations {
@@ -259,8 +363,14 @@ func (r *Ruleset) HandleAmendment(ctx context.Context, db database.Provider, new
 	// the Copy operation here will reset the timestamp to the current time
 	copied := r.Copy(newSectionIds)
 
-	// increment the revision number by one
+	// increment the revision number by one, recording the change and its
+	// author (the owner when the amendment doesn't name one)
 	copied.Revision += 1
+	copied.ChangeKind = a.Type.ChangeKind()
+	copied.Author = a.Author
+	if copied.Author == database.InvalidUserId {
+		copied.Author = r.Owner
+	}
 
 	// create a new ruleset with the updated values
 	newRuleset, err = database.CreateOne(ctx, db, copied)
@@ -292,15 +402,9 @@ func (r *Ruleset) HandleAmendment(ctx context.Context, db database.Provider, new
 		return nil, err
 	}
 
-	// Delete all old section relations
-	oldRelations, _ := r.GetSectionRelations(ctx, db)
-	for _, oldRel := range oldRelations {
-		if oldRel.RulesetId == r.ID {
-			_, _, _ = database.DeleteOneById(ctx, db, &RulesetSection{}, oldRel.ID)
-		}
-	}
-
-	// Add all new section relations to the new ruleset
+	// The archived revision keeps its own section relations, so it can still
+	// be read (and diffed) as it stood. Add all new section relations to the
+	// new ruleset
 	for i, sr := range newRelations {
 		newRuleSectionRelation := &RulesetSection{
 			RulesetId:    newRuleset.ID,
@@ -333,6 +437,9 @@ func (r *Ruleset) Copy(newSectionIds []RuleSectionId) *Ruleset {
 		SupersededBy: r.SupersededBy,
 		Date:         time.Now(),
 		Owner:        r.Owner,
+		Parent:       r.ID,
+		ChangeKind:   RulesetChangeAmended,
+		Author:       r.Owner,
 	}
 }
 
@@ -341,6 +448,10 @@ type RuleAmendment struct {
 	TargetSection RuleSectionId     `json:"target_section"`
 	NewSection    RuleSection       `json:"new_section"`
 	After         RuleSectionId     `json:"after"`
+
+	// Author is the user making the amendment, recorded on the new
+	// revision. It is set by the route from the request's token.
+	Author database.UserId `json:"-"`
 }
 
 func (r *RuleAmendment) DynamicallyValid(ctx context.Context, db database.Provider) error {
@@ -395,6 +506,10 @@ func (r *RuleAmendment) StaticallyValid() error {
 			// user cannot set rule section ID in the new section contents (set automatically on amendment)
 			return fmt.Errorf("'new section' ID must not be set when adding a new section")
 		}
+		if !r.NewSection.Replaces.Empty() {
+			// a new section doesn't replace anything (set automatically on modification)
+			return fmt.Errorf("'new section' replaces must not be set when adding a new section")
+		}
 		if r.NewSection.Markdown == "" {
 			// section contents cannot be empty
 			return fmt.Errorf("'new section' contents must not be empty when adding a new section")
@@ -68,6 +68,18 @@ type Ruleset struct {
 	// new Ruleset if they would like to modify it for their
 	// own Season, for example.
 	Owner database.UserId `json:"owner"`
+
+	// Parent is the RulesetId of the revision this Ruleset was
+	// made from by an amendment, a rename or a Fork, or empty
+	// for a newly created Ruleset.
+	Parent RulesetId `json:"parent"`
+
+	// ChangeKind is how this Ruleset was made from its Parent.
+	ChangeKind RulesetChangeKind `json:"change_kind"`
+
+	// Author is the UserId who made this revision. This is the
+	// Owner unless e.g. a system administrator amended it.
+	Author database.UserId `json:"author"`
 }
 
 func (r *Ruleset) PreUpdate(ctx context.Context, db database.Provider, existingValues database.CrudRecord) error {
@@ -82,6 +94,25 @@ func (r *Ruleset) Archived() bool {
 	return !r.SupersededBy.Empty()
 }
 
+// Latest follows SupersededBy from this Ruleset to the revision that has not
+// been superseded, which may be this one.
+func (r *Ruleset) Latest(ctx context.Context, db database.Provider) (*Ruleset, error) {
+	seen := map[RulesetId]bool{}
+	current := r
+	for current.Archived() {
+		if seen[current.ID] {
+			return nil, fmt.Errorf("ruleset %s is superseded in a cycle", r.ID)
+		}
+		seen[current.ID] = true
+		next, err := database.GetExistingRecordById(ctx, db, &Ruleset{}, current.SupersededBy.RecordId())
+		if err != nil {
+			return nil, err
+		}
+		current = next
+	}
+	return current, nil
+}
+
 // NewRuleset creates a new *Ruleset with default values
 func NewRuleset() *Ruleset {
 	return &Ruleset{
@@ -91,6 +122,9 @@ func NewRuleset() *Ruleset {
 		SupersededBy: 0,
 		Date:         time.Now(),
 		Owner:        0,
+		Parent:       0,
+		ChangeKind:   RulesetChangeCreated,
+		Author:       0,
 	}
 }
 
@@ -133,6 +167,30 @@ func (r *Ruleset) StaticallyValid() error {
 		return fmt.Errorf("revision cannot be negative")
 	}
 
+	if err := r.ChangeKind.StaticallyValid(); err != nil {
+		return err
+	}
+	if (r.ChangeKind == RulesetChangeCreated) != r.Parent.Empty() {
+		// only a newly created ruleset has no parent revision
+		return fmt.Errorf("parent must be set for a %s revision, and only for one", r.ChangeKind)
+	}
+
+	if r.Author == database.InvalidUserId {
+		return fmt.Errorf("author must be set")
+	}
+
+	return nil
+}
+
+// PreCreateRequest makes a Ruleset created through the generic create route a
+// new, unrevised ruleset authored by the requesting user. Its lineage is only
+// set by Amend, Fork and the other revisions made from an existing Ruleset.
+func (r *Ruleset) PreCreateRequest(_ context.Context, _ database.Provider, userId database.UserId) error {
+	r.Author = userId
+	r.Parent = RulesetId(database.InvalidRecordId)
+	r.ChangeKind = RulesetChangeCreated
+	r.SupersededBy = RulesetId(database.InvalidRecordId)
+	r.Revision = 0
 	return nil
 }
 
@@ -181,6 +239,18 @@ func (r *Ruleset) DynamicallyValid(ctx context.Context, db database.Provider) er
 		return err
 	}
 
+	// author and parent revision must exist
+	err = database.ExistsById(ctx, db, &User{}, r.Author.RecordId())
+	if err != nil {
+		return err
+	}
+	if !r.Parent.Empty() {
+		err = database.ExistsById(ctx, db, &Ruleset{}, r.Parent.RecordId())
+		if err != nil {
+			return err
+		}
+	}
+
 	// each RuleSection must exist
 	sectionRelations, err := r.GetSectionRelations(ctx, db)
 	if err != nil {
@@ -250,33 +320,40 @@ func (r *Ruleset) RemoveSection(ctx context.Context, db database.Provider, secti
 	return nil
 }
 
-// Fork creates a new Ruleset that is a copy of this one, with a new owner.
-// The new ruleset gets an incremented revision number and copies all sections
-// from the original ruleset. Returns an error if the ruleset has no sections.
 // PostDelete cascades deletion to this ruleset's rule_section and
 // ruleset_section rows. Without this, deleting a ruleset would orphan those
-// rows (see #97).
+// rows (see #97). A section created for this ruleset that another ruleset
+// still uses, e.g. one reused by an import, is kept.
 func (r *Ruleset) PostDelete(ctx context.Context, db database.Provider) error {
-	sections, err := database.GetAllWhere[*RuleSection](ctx, db, func(_ context.Context, s *RuleSection) bool {
-		return s.Parent == r.ID
+	relations, err := database.GetAllWhere[*RulesetSection](ctx, db, func(_ context.Context, s *RulesetSection) bool {
+		return s.RulesetId == r.ID
 	})
 	if err != nil {
 		return err
 	}
-	for _, s := range sections {
-		if _, _, err := database.DeleteOneById(ctx, db, s, s.ID.RecordId()); err != nil {
+	for _, s := range relations {
+		if _, _, err := database.DeleteOneById(ctx, db, s, s.ID); err != nil {
 			return err
 		}
 	}
 
-	relations, err := database.GetAllWhere[*RulesetSection](ctx, db, func(_ context.Context, s *RulesetSection) bool {
-		return s.RulesetId == r.ID
+	sections, err := database.GetAllWhere[*RuleSection](ctx, db, func(_ context.Context, s *RuleSection) bool {
+		return s.Parent == r.ID
 	})
 	if err != nil {
 		return err
 	}
-	for _, s := range relations {
-		if _, _, err := database.DeleteOneById(ctx, db, s, s.ID); err != nil {
+	for _, s := range sections {
+		used, err := database.GetAllWhere[*RulesetSection](ctx, db, func(_ context.Context, rs *RulesetSection) bool {
+			return rs.SectionId == s.ID
+		})
+		if err != nil {
+			return err
+		}
+		if len(used) > 0 {
+			continue
+		}
+		if _, _, err := database.DeleteOneById(ctx, db, s, s.ID.RecordId()); err != nil {
 			return err
 		}
 	}
@@ -288,6 +365,11 @@ func (r *Ruleset) NewRecord() database.CrudRecord {
 	return new(Ruleset)
 }
 
+// Fork creates a new Ruleset that is a copy of this one, with a new owner.
+// The new ruleset gets an incremented revision number and copies all sections
+// from the original ruleset. It starts its own line of revisions, so it is not
+// superseded even when this one is. Returns an error if the ruleset has no
+// sections.
 func (r *Ruleset) Fork(ctx context.Context, db database.Provider, newUserId database.UserId) (*Ruleset, error) {
 	sectionRelations, err := r.GetSectionRelations(ctx, db)
 	if err != nil {
@@ -301,9 +383,10 @@ func (r *Ruleset) Fork(ctx context.Context, db database.Provider, newUserId data
 	r2.ID = RulesetId(database.InvalidRecordId)
 	r2.Name = r.Name
 	r2.Revision = r.Revision + 1
-	r2.SupersededBy = r.SupersededBy
-	r2.Date = r.Date
 	r2.Owner = newUserId
+	r2.Parent = r.ID
+	r2.ChangeKind = RulesetChangeForked
+	r2.Author = newUserId
 
 	newRuleset, err := database.CreateOne(ctx, db, r2)
 	if err != nil {
@@ -328,9 +411,9 @@ func (r *Ruleset) Fork(ctx context.Context, db database.Provider, newUserId data
 
 // EditName amends this Ruleset by producing a new revision with the given
 // name and marking this Ruleset as superseded by the new revision. The new
-// revision inherits this Ruleset's owner and sections. Returns the new
-// superseding Ruleset.
-func (r *Ruleset) EditName(ctx context.Context, db database.Provider, newName string) (*Ruleset, error) {
+// revision inherits this Ruleset's owner and sections, and is authored by the
+// given user. Returns the new superseding Ruleset.
+func (r *Ruleset) EditName(ctx context.Context, db database.Provider, newName string, author database.UserId) (*Ruleset, error) {
 	newName = strings.TrimSpace(newName)
 	if newName == "" {
 		return nil, fmt.Errorf("name must not be empty")
@@ -352,6 +435,8 @@ func (r *Ruleset) EditName(ctx context.Context, db database.Provider, newName st
 	copied := r.Copy(newSectionIds)
 	copied.Name = newName
 	copied.Revision += 1
+	copied.ChangeKind = RulesetChangeRenamed
+	copied.Author = author
 
 	newRuleset, err := database.CreateOne(ctx, db, copied)
 	if err != nil {
@@ -368,10 +453,7 @@ func (r *Ruleset) EditName(ctx context.Context, db database.Provider, newName st
 		return nil, err
 	}
 
-	// delete the old section relations and re-link them to the new revision
-	for _, oldRel := range sectionRelations {
-		_, _, _ = database.DeleteOneById(ctx, db, &RulesetSection{}, oldRel.ID)
-	}
+	// link the sections to the new revision (this revision keeps its own)
 	for i, sr := range sectionRelations {
 		newRuleSectionRelation := &RulesetSection{
 			RulesetId:    newRuleset.ID,
@@ -420,6 +502,12 @@ type RuleSection struct {
 	Title    string          `json:"title"`
 	Markdown string          `json:"markdown"`
 	Owner    database.UserId `json:"owner"`
+
+	// Replaces is the RuleSection this one was amended from by a
+	// RuleAmendmentTypeModifySection, or empty for an added section.
+	// Following Replaces back identifies the same section across
+	// revisions.
+	Replaces RuleSectionId `json:"replaces"`
 }
 
 func (section *RuleSection) Type() string {
@@ -504,3 +592,48 @@ func (section *RuleSection) Equals(other *RuleSection) bool {
 func (section *RuleSection) NewRecord() database.CrudRecord {
 	return new(RuleSection)
 }
+
+func (section *RuleSection) SearchDocument() database.SearchDocument {
+	return database.SearchDocument{
+		Kind:    section.Type(),
+		Id:      section.GetId(),
+		Title:   section.Title,
+		Content: section.Markdown,
+	}
+}
+
+// Superseded returns true if the section is only listed by revisions that
+// have been superseded, i.e. it is no longer part of any current ruleset. A
+// section not yet listed by any revision isn't superseded.
+func (section *RuleSection) Superseded(ctx context.Context, db database.Provider) (bool, error) {
+	relations, err := database.GetAllWhere[*RulesetSection](ctx, db, func(_ context.Context, rs *RulesetSection) bool {
+		return rs.SectionId == section.ID
+	})
+	if err != nil || len(relations) == 0 {
+		return false, err
+	}
+	for _, relation := range relations {
+		ruleset, exists, err := database.GetOneById(ctx, db, &Ruleset{}, relation.RulesetId.RecordId())
+		if err != nil {
+			return false, err
+		}
+		if exists && !ruleset.Archived() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// PostCreate, PostUpdate and PostDelete keep the section's text in the
+// full-text index (see Search).
+func (section *RuleSection) PostCreate(ctx context.Context, db database.Provider) error {
+	return database.IndexRecord(ctx, db, section)
+}
+
+func (section *RuleSection) PostUpdate(ctx context.Context, db database.Provider) error {
+	return database.IndexRecord(ctx, db, section)
+}
+
+func (section *RuleSection) PostDelete(ctx context.Context, db database.Provider) error {
+	return database.UnindexRecord(ctx, db, section)
+}
@@ -0,0 +1,349 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"intraclub/database"
+)
+
+// DiffLineOp is whether a line of a RuleSection's markdown is unchanged,
+// added or removed between two revisions.
+type DiffLineOp string
+
+const (
+	DiffLineContext DiffLineOp = "context"
+	DiffLineAdded   DiffLineOp = "added"
+	DiffLineRemoved DiffLineOp = "removed"
+)
+
+// prefix is the line prefix for the op in a unified diff.
+func (op DiffLineOp) prefix() string {
+	switch op {
+	case DiffLineAdded:
+		return "+"
+	case DiffLineRemoved:
+		return "-"
+	}
+	return " "
+}
+
+// DiffLine is one line of a line-level markdown diff.
+type DiffLine struct {
+	Op   DiffLineOp `json:"op"`
+	Text string     `json:"text"`
+}
+
+// RuleSectionChange is one section that differs between two revisions of a
+// Ruleset. From is the section in the older revision and To in the newer; an
+// added section has no From and a removed one no To. Indexes are positions in
+// each revision's section list, -1 where the section is absent.
+type RuleSectionChange struct {
+	From      *RuleSection `json:"from,omitempty"`
+	To        *RuleSection `json:"to,omitempty"`
+	FromIndex int          `json:"from_index"`
+	ToIndex   int          `json:"to_index"`
+
+	// Lines is the line-level diff of the section's markdown, set for
+	// modified sections
+	Lines []DiffLine `json:"lines,omitempty"`
+}
+
+// label names the section for a text rendering.
+func (c *RuleSectionChange) label() string {
+	section := c.To
+	if section == nil {
+		section = c.From
+	}
+	if section.Title != "" {
+		return fmt.Sprintf("%q", section.Title)
+	}
+	return fmt.Sprintf("section %s", section.ID)
+}
+
+// RulesetDiff is the difference between two revisions of the same Ruleset
+// lineage. A section is the same across revisions when one was amended from
+// the other (see RuleSection.Replaces). Reordered sections are those that
+// moved relative to the sections kept in both revisions, not those merely
+// shifted by an addition or removal.
+type RulesetDiff struct {
+	From      *Ruleset             `json:"from"`
+	To        *Ruleset             `json:"to"`
+	Added     []*RuleSectionChange `json:"added"`
+	Removed   []*RuleSectionChange `json:"removed"`
+	Reordered []*RuleSectionChange `json:"reordered"`
+	Modified  []*RuleSectionChange `json:"modified"`
+}
+
+// InSameLineage reports whether other is this Ruleset or one of its earlier
+// or later revisions, following SupersededBy.
+func (r *Ruleset) InSameLineage(ctx context.Context, db database.Provider, other *Ruleset) (bool, error) {
+	for _, pair := range [][2]*Ruleset{{r, other}, {other, r}} {
+		found, err := pair[0].supersededInto(ctx, db, pair[1].ID)
+		if err != nil || found {
+			return found, err
+		}
+	}
+	return false, nil
+}
+
+// supersededInto reports whether following SupersededBy from this Ruleset
+// reaches the target revision.
+func (r *Ruleset) supersededInto(ctx context.Context, db database.Provider, target RulesetId) (bool, error) {
+	seen := map[RulesetId]bool{}
+	for current := r; !seen[current.ID]; {
+		if current.ID == target {
+			return true, nil
+		}
+		seen[current.ID] = true
+		if !current.Archived() {
+			return false, nil
+		}
+		next, err := database.GetExistingRecordById(ctx, db, &Ruleset{}, current.SupersededBy.RecordId())
+		if err != nil {
+			return false, err
+		}
+		current = next
+	}
+	return false, nil
+}
+
+// getSectionRecords returns this Ruleset's RuleSections in order.
+func (r *Ruleset) getSectionRecords(ctx context.Context, db database.Provider) ([]*RuleSection, error) {
+	ids, err := r.GetSections(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	sections := make([]*RuleSection, 0, len(ids))
+	for _, id := range ids {
+		section, err := database.GetExistingRecordById(ctx, db, &RuleSection{}, id.RecordId())
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+// sectionOrigin follows Replaces back to the section's first version, which
+// identifies it across revisions. A missing predecessor ends the chain.
+func sectionOrigin(ctx context.Context, db database.Provider, section *RuleSection) RuleSectionId {
+	seen := map[RuleSectionId]bool{}
+	for !section.Replaces.Empty() && !seen[section.ID] {
+		seen[section.ID] = true
+		previous, err := database.GetExistingRecordById(ctx, db, &RuleSection{}, section.Replaces.RecordId())
+		if err != nil {
+			break
+		}
+		section = previous
+	}
+	return section.ID
+}
+
+// Diff compares this revision of a Ruleset with another revision in the same
+// lineage, usually a later one.
+func (r *Ruleset) Diff(ctx context.Context, db database.Provider, other *Ruleset) (*RulesetDiff, error) {
+	same, err := r.InSameLineage(ctx, db, other)
+	if err != nil {
+		return nil, err
+	}
+	if !same {
+		return nil, fmt.Errorf("rulesets %s and %s are not revisions of the same ruleset", r.ID, other.ID)
+	}
+	fromSections, err := r.getSectionRecords(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	toSections, err := other.getSectionRecords(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RulesetDiff{
+		From:      r,
+		To:        other,
+		Added:     []*RuleSectionChange{},
+		Removed:   []*RuleSectionChange{},
+		Reordered: []*RuleSectionChange{},
+		Modified:  []*RuleSectionChange{},
+	}
+	fromIndex := make(map[RuleSectionId]int, len(fromSections))
+	fromOrigins := make([]RuleSectionId, len(fromSections))
+	for i, section := range fromSections {
+		fromOrigins[i] = sectionOrigin(ctx, db, section)
+		fromIndex[fromOrigins[i]] = i
+	}
+	toIndex := make(map[RuleSectionId]int, len(toSections))
+	toOrigins := make([]RuleSectionId, len(toSections))
+	for i, section := range toSections {
+		toOrigins[i] = sectionOrigin(ctx, db, section)
+		toIndex[toOrigins[i]] = i
+	}
+
+	// sections kept in both revisions, in each revision's order
+	var keptFrom, keptTo []RuleSectionId
+	for i, origin := range fromOrigins {
+		if _, ok := toIndex[origin]; ok {
+			keptFrom = append(keptFrom, origin)
+		} else {
+			diff.Removed = append(diff.Removed, &RuleSectionChange{From: fromSections[i], FromIndex: i, ToIndex: -1})
+		}
+	}
+	for i, origin := range toOrigins {
+		if _, ok := fromIndex[origin]; ok {
+			keptTo = append(keptTo, origin)
+		} else {
+			diff.Added = append(diff.Added, &RuleSectionChange{To: toSections[i], FromIndex: -1, ToIndex: i})
+		}
+	}
+
+	// the longest run of kept sections in the same relative order stays put;
+	// every other kept section was reordered
+	inOrder := map[RuleSectionId]bool{}
+	for _, pair := range longestCommonSubsequence(len(keptFrom), len(keptTo), func(i, j int) bool {
+		return keptFrom[i] == keptTo[j]
+	}) {
+		inOrder[keptFrom[pair[0]]] = true
+	}
+	for _, origin := range keptTo {
+		i, j := fromIndex[origin], toIndex[origin]
+		change := &RuleSectionChange{From: fromSections[i], To: toSections[j], FromIndex: i, ToIndex: j}
+		if !inOrder[origin] {
+			diff.Reordered = append(diff.Reordered, change)
+		}
+		if !fromSections[i].Equals(toSections[j]) {
+			modified := *change
+			modified.Lines = diffLines(fromSections[i].Markdown, toSections[j].Markdown)
+			diff.Modified = append(diff.Modified, &modified)
+		}
+	}
+	return diff, nil
+}
+
+// longestCommonSubsequence returns the index pairs of a longest common
+// subsequence of two sequences of lengths n and m, in order.
+func longestCommonSubsequence(n, m int, equal func(i, j int) bool) [][2]int {
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if equal(i, j) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	var pairs [][2]int
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case equal(i, j):
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+// splitLines splits markdown into lines, with no lines for empty markdown.
+func splitLines(markdown string) []string {
+	if markdown == "" {
+		return nil
+	}
+	return strings.Split(markdown, "\n")
+}
+
+// diffLines is the line-level diff between two versions of a section's
+// markdown, keeping every unchanged line as context.
+func diffLines(from, to string) []DiffLine {
+	a, b := splitLines(from), splitLines(to)
+	lines := []DiffLine{}
+	i, j := 0, 0
+	for _, pair := range longestCommonSubsequence(len(a), len(b), func(i, j int) bool { return a[i] == b[j] }) {
+		for ; i < pair[0]; i++ {
+			lines = append(lines, DiffLine{Op: DiffLineRemoved, Text: a[i]})
+		}
+		for ; j < pair[1]; j++ {
+			lines = append(lines, DiffLine{Op: DiffLineAdded, Text: b[j]})
+		}
+		lines = append(lines, DiffLine{Op: DiffLineContext, Text: a[i]})
+		i++
+		j++
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, DiffLine{Op: DiffLineRemoved, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, DiffLine{Op: DiffLineAdded, Text: b[j]})
+	}
+	return lines
+}
+
+// hunkRange is the "start,count" of a whole-section hunk header, which starts
+// at line 0 when the side is empty.
+func hunkRange(count int) string {
+	if count == 0 {
+		return "0,0"
+	}
+	return fmt.Sprintf("1,%d", count)
+}
+
+// writeHunk writes a unified diff hunk covering a whole section.
+func writeHunk(b *strings.Builder, heading string, lines []DiffLine) {
+	var from, to int
+	for _, line := range lines {
+		if line.Op != DiffLineAdded {
+			from++
+		}
+		if line.Op != DiffLineRemoved {
+			to++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@ %s\n", hunkRange(from), hunkRange(to), heading)
+	for _, line := range lines {
+		b.WriteString(line.Op.prefix() + line.Text + "\n")
+	}
+}
+
+// wholeSection is the diff of a section that was added or removed outright.
+func wholeSection(op DiffLineOp, markdown string) []DiffLine {
+	lines := []DiffLine{}
+	for _, line := range splitLines(markdown) {
+		lines = append(lines, DiffLine{Op: op, Text: line})
+	}
+	return lines
+}
+
+// Unified renders the diff as unified-diff text (e.g. for email): a hunk for
+// every removed, added or modified section, and a note for every reordered
+// one. Positions in the text are 1-based.
+func (d *RulesetDiff) Unified() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s (revision %d, %s)\n", d.From.Name, d.From.Revision, d.From.Date.Format("2006-01-02"))
+	fmt.Fprintf(&b, "+++ %s (revision %d, %s)\n", d.To.Name, d.To.Revision, d.To.Date.Format("2006-01-02"))
+	for _, c := range d.Reordered {
+		fmt.Fprintf(&b, "# moved %s from position %d to %d\n", c.label(), c.FromIndex+1, c.ToIndex+1)
+	}
+	for _, c := range d.Removed {
+		writeHunk(&b, fmt.Sprintf("removed %s (position %d)", c.label(), c.FromIndex+1), wholeSection(DiffLineRemoved, c.From.Markdown))
+	}
+	for _, c := range d.Added {
+		writeHunk(&b, fmt.Sprintf("added %s (position %d)", c.label(), c.ToIndex+1), wholeSection(DiffLineAdded, c.To.Markdown))
+	}
+	for _, c := range d.Modified {
+		heading := fmt.Sprintf("modified %s (position %d)", c.label(), c.ToIndex+1)
+		if c.From.Title != c.To.Title {
+			heading = fmt.Sprintf("modified %q, now %q (position %d)", c.From.Title, c.To.Title, c.ToIndex+1)
+		}
+		writeHunk(&b, heading, c.Lines)
+	}
+	return b.String()
+}
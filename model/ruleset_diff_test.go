@@ -0,0 +1,153 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"intraclub/database"
+)
+
+func TestRulesetDiffAcrossRevisions(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	start := newValidStoredRulesetWithXSections(t, db, 4)
+	sections, err := start.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second, fourth := sections[0], sections[1], sections[3]
+
+	modified, err := start.Amend(ctx, db, &RuleAmendment{
+		Type:          RuleAmendmentTypeModifySection,
+		TargetSection: second,
+		NewSection:    RuleSection{Title: "Scoring", Markdown: "line one\nline two changed\nline three"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified.ID == start.ID {
+		t.Fatal("expected modifying a section's markdown to produce a new revision")
+	}
+	removed, err := modified.Amend(ctx, db, &RuleAmendment{Type: RuleAmendmentTypeRemoveSection, TargetSection: first})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reordered, err := removed.Amend(ctx, db, &RuleAmendment{Type: RuleAmendmentTypeReorderSection, TargetSection: fourth})
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := addSectionRevisionToEndOfExistingRuleset(t, db, reordered)
+
+	// the archived starting revision keeps the sections it had
+	archived, err := start.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archived) != 4 || archived[0] != first || archived[3] != fourth {
+		t.Fatalf("expected archived revision to keep sections %v, got %v", sections, archived)
+	}
+
+	diff, err := start.Diff(ctx, db, latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].From.ID != first || diff.Removed[0].FromIndex != 0 {
+		t.Fatalf("expected section %s to be removed, got %+v", first, diff.Removed)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].ToIndex != 3 {
+		t.Fatalf("expected one section added at the end, got %+v", diff.Added)
+	}
+	if len(diff.Reordered) != 1 || diff.Reordered[0].To.ID != fourth || diff.Reordered[0].ToIndex != 0 {
+		t.Fatalf("expected section %s to be reordered to the front, got %+v", fourth, diff.Reordered)
+	}
+	if len(diff.Modified) != 1 || diff.Modified[0].From.ID != second || diff.Modified[0].To.Replaces != second {
+		t.Fatalf("expected section %s to be modified, got %+v", second, diff.Modified)
+	}
+
+	unified := diff.Unified()
+	for _, want := range []string{
+		"--- " + start.Name + " (revision 4,",
+		"+++ " + latest.Name + " (revision 8,",
+		"# moved section " + fourth.String() + " from position 4 to 1",
+		"@@ -1,1 +0,0 @@ removed section " + first.String(),
+		"@@ -0,0 +1,1 @@ added section",
+		"@@ -1,1 +1,3 @@ modified ",
+		"+line two changed\n",
+	} {
+		if !strings.Contains(unified, want) {
+			t.Fatalf("expected unified diff to contain %q, got:\n%s", want, unified)
+		}
+	}
+
+	// diffing two rulesets from different lineages is refused
+	other := newValidStoredRulesetWithOneSection(t, db)
+	if _, err := start.Diff(ctx, db, other); err == nil {
+		t.Fatal("expected error diffing rulesets of different lineages")
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	lines := diffLines("a\nb\nc", "a\nx\nc\nd")
+	want := []DiffLine{
+		{Op: DiffLineContext, Text: "a"},
+		{Op: DiffLineRemoved, Text: "b"},
+		{Op: DiffLineAdded, Text: "x"},
+		{Op: DiffLineContext, Text: "c"},
+		{Op: DiffLineAdded, Text: "d"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %v, got %v", want, lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, lines)
+		}
+	}
+}
+
+func TestRulesetDiffShowsTitleChanges(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	start := newValidStoredRulesetWithXSections(t, db, 2)
+	sections, err := start.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, err := database.GetExistingRecordById(ctx, db, &RuleSection{}, sections[0].RecordId())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renamed, err := start.Amend(ctx, db, &RuleAmendment{
+		Type:          RuleAmendmentTypeModifySection,
+		TargetSection: sections[0],
+		NewSection:    RuleSection{Title: original.Title + " (renamed)", Markdown: original.Markdown},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.ID == start.ID {
+		t.Fatal("expected renaming a section to produce a new revision")
+	}
+
+	// the archived revision keeps the old title
+	kept, err := database.GetExistingRecordById(ctx, db, &RuleSection{}, sections[0].RecordId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept.Title != original.Title {
+		t.Fatalf("expected the archived section to keep its title, got %q", kept.Title)
+	}
+
+	diff, err := start.Diff(ctx, db, renamed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Modified) != 1 || diff.Modified[0].From.Title != original.Title || diff.Modified[0].To.Replaces != sections[0] {
+		t.Fatalf("expected the title change to show as a modification, got %+v", diff.Modified)
+	}
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Fatalf("expected nothing added or removed, got %+v / %+v", diff.Added, diff.Removed)
+	}
+}
@@ -0,0 +1,219 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"intraclub/database"
+)
+
+// RulesetExportFormat is the kind of document a Ruleset is exported as.
+type RulesetExportFormat string
+
+const (
+	RulesetExportMarkdown RulesetExportFormat = "markdown"
+	RulesetExportHTML     RulesetExportFormat = "html"
+)
+
+func (f RulesetExportFormat) StaticallyValid() error {
+	switch f {
+	case RulesetExportMarkdown, RulesetExportHTML:
+		return nil
+	}
+	return fmt.Errorf("invalid ruleset export format %q (must be %q or %q)", f, RulesetExportMarkdown, RulesetExportHTML)
+}
+
+// ContentType is the MIME type of a document in the format.
+func (f RulesetExportFormat) ContentType() string {
+	if f == RulesetExportHTML {
+		return "text/html; charset=utf-8"
+	}
+	return "text/markdown; charset=utf-8"
+}
+
+// exportedSection is a section of an exported Ruleset with how it changed
+// since the revision it is compared with, if any.
+type exportedSection struct {
+	*RuleSection
+	Number int
+	Change string
+}
+
+// anchor is the id the table of contents links the section by.
+func (s *exportedSection) anchor() string {
+	return fmt.Sprintf("section-%d", s.Number)
+}
+
+// label is the section's entry in the table of contents.
+func (s *exportedSection) label() string {
+	if s.Title == "" {
+		return fmt.Sprintf("Section %d", s.Number)
+	}
+	return s.Title
+}
+
+// heading is the section's numbered heading text.
+func (s *exportedSection) heading() string {
+	if s.Title == "" {
+		return s.label()
+	}
+	return fmt.Sprintf("%d. %s", s.Number, s.Title)
+}
+
+// Export renders this Ruleset revision as a single document in the given
+// format: a header with its name, revision number and date, a table of
+// contents, and its sections in order, numbered from 1. If previous is given
+// (an earlier revision in the same lineage), sections added, modified or moved
+// since it are highlighted and those removed are listed in the header.
+func (r *Ruleset) Export(ctx context.Context, db database.Provider, format RulesetExportFormat, previous *Ruleset) (string, error) {
+	if err := format.StaticallyValid(); err != nil {
+		return "", err
+	}
+	records, err := r.getSectionRecords(ctx, db)
+	if err != nil {
+		return "", err
+	}
+	sections := make([]*exportedSection, len(records))
+	for i, section := range records {
+		sections[i] = &exportedSection{RuleSection: section, Number: i + 1}
+	}
+
+	var diff *RulesetDiff
+	if previous != nil {
+		if previous.Revision >= r.Revision {
+			return "", fmt.Errorf("revision %d is not earlier than revision %d", previous.Revision, r.Revision)
+		}
+		diff, err = previous.Diff(ctx, db, r)
+		if err != nil {
+			return "", err
+		}
+		for _, c := range diff.Reordered {
+			sections[c.ToIndex].Change = fmt.Sprintf("Moved from section %d.", c.FromIndex+1)
+		}
+		for _, c := range diff.Modified {
+			sections[c.ToIndex].Change = "Changed in this revision."
+		}
+		for _, c := range diff.Added {
+			sections[c.ToIndex].Change = "New in this revision."
+		}
+	}
+
+	if format == RulesetExportHTML {
+		return r.exportHTML(sections, diff), nil
+	}
+	return r.exportMarkdown(sections, diff), nil
+}
+
+// exportTitle is the document title, the Ruleset's name if it has one.
+func (r *Ruleset) exportTitle() string {
+	if r.Name == "" {
+		return "Rules"
+	}
+	return r.Name
+}
+
+// exportRevision is the header line with the revision number and date.
+func (r *Ruleset) exportRevision() string {
+	return fmt.Sprintf("Revision %d, %s", r.Revision, r.Date.Format("January 2, 2006"))
+}
+
+// removedSummary describes the sections removed since the compared revision.
+func removedSummary(diff *RulesetDiff) []string {
+	removed := make([]string, 0, len(diff.Removed))
+	for _, c := range diff.Removed {
+		removed = append(removed, fmt.Sprintf("section %d, %s", c.FromIndex+1, c.label()))
+	}
+	return removed
+}
+
+func (r *Ruleset) exportMarkdown(sections []*exportedSection, diff *RulesetDiff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n*%s*\n", r.exportTitle(), r.exportRevision())
+	if diff != nil {
+		fmt.Fprintf(&b, "\nChanges since revision %d are highlighted.\n", diff.From.Revision)
+		if removed := removedSummary(diff); len(removed) > 0 {
+			b.WriteString("\nRemoved since then:\n\n")
+			for _, line := range removed {
+				b.WriteString("- " + line + "\n")
+			}
+		}
+	}
+
+	b.WriteString("\n## Contents\n\n")
+	for _, s := range sections {
+		fmt.Fprintf(&b, "%d. [%s](#%s)\n", s.Number, s.label(), s.anchor())
+	}
+
+	for _, s := range sections {
+		fmt.Fprintf(&b, "\n<a id=\"%s\"></a>\n\n## %s\n\n", s.anchor(), s.heading())
+		if s.Change != "" {
+			fmt.Fprintf(&b, "> **%s**\n\n", s.Change)
+		}
+		b.WriteString(demoteHeadings(s.Markdown, 2) + "\n")
+	}
+	return b.String()
+}
+
+// demoteHeadings moves the ATX headings of markdown down by offset levels
+// (capped at 6) so they nest under the heading they're put under.
+func demoteHeadings(markdown string, offset int) string {
+	lines := splitLines(markdown)
+	fenced := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			fenced = !fenced
+		}
+		if m := markdownHeading.FindStringSubmatch(trimmed); m != nil && !fenced {
+			lines[i] = strings.Repeat("#", min(len(m[1])+offset, 6)) + " " + m[2]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *Ruleset) exportHTML(sections []*exportedSection, diff *RulesetDiff) string {
+	var b strings.Builder
+	title := html.EscapeString(r.exportTitle())
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", title)
+	b.WriteString("<style>\n" +
+		"  body { font-family: Arial, sans-serif; max-width: 800px; margin: auto; padding: 20px; }\n" +
+		"  .change { background: #fff3b0; padding: 4px 8px; font-weight: bold; }\n" +
+		"  section.changed { border-left: 4px solid #f0c000; padding-left: 12px; }\n" +
+		"</style>\n</head>\n<body>\n")
+	fmt.Fprintf(&b, "<header>\n<h1>%s</h1>\n<p><em>%s</em></p>\n", title, html.EscapeString(r.exportRevision()))
+	if diff != nil {
+		fmt.Fprintf(&b, "<p class=\"change\">Changes since revision %d are highlighted.</p>\n", diff.From.Revision)
+		if removed := removedSummary(diff); len(removed) > 0 {
+			b.WriteString("<p>Removed since then:</p>\n<ul>\n")
+			for _, line := range removed {
+				b.WriteString("<li>" + html.EscapeString(line) + "</li>\n")
+			}
+			b.WriteString("</ul>\n")
+		}
+	}
+	b.WriteString("</header>\n")
+
+	b.WriteString("<nav>\n<h2>Contents</h2>\n<ol>\n")
+	for _, s := range sections {
+		fmt.Fprintf(&b, "<li><a href=\"#%s\">%s</a></li>\n", s.anchor(), html.EscapeString(s.label()))
+	}
+	b.WriteString("</ol>\n</nav>\n")
+
+	for _, s := range sections {
+		class := ""
+		if s.Change != "" {
+			class = ` class="changed"`
+		}
+		fmt.Fprintf(&b, "<section id=\"%s\"%s>\n<h2>%s</h2>\n", s.anchor(), class, html.EscapeString(s.heading()))
+		if s.Change != "" {
+			fmt.Fprintf(&b, "<p class=\"change\">%s</p>\n", html.EscapeString(s.Change))
+		}
+		b.WriteString(markdownToHTML(s.Markdown, 2))
+		b.WriteString("</section>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
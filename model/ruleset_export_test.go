@@ -0,0 +1,104 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"intraclub/database"
+)
+
+func TestRulesetExportMarkdownWithChanges(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	start := newValidStoredRulesetWithXSections(t, db, 3)
+	sections, err := start.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modified, err := start.AmendAll(ctx, db, []*RuleAmendment{
+		{Type: RuleAmendmentTypeModifySection, TargetSection: sections[1], NewSection: RuleSection{Title: "Scoring", Markdown: "# Points\nA win is worth 2 points."}},
+		{Type: RuleAmendmentTypeRemoveSection, TargetSection: sections[0]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document, err := modified.Export(ctx, db, RulesetExportMarkdown, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# " + modified.Name + "\n",
+		fmt.Sprintf("*Revision %d, ", modified.Revision),
+		fmt.Sprintf("Changes since revision %d are highlighted.", start.Revision),
+		"- section 1, ",
+		"## Contents\n\n1. [Scoring](#section-1)\n2. [",
+		"<a id=\"section-1\"></a>\n\n## 1. Scoring\n\n> **Changed in this revision.**\n\n### Points\nA win is worth 2 points.\n",
+		"## Section 2\n",
+	} {
+		if !strings.Contains(document, want) {
+			t.Fatalf("expected export to contain %q, got:\n%s", want, document)
+		}
+	}
+	if strings.Count(document, "in this revision") != 1 {
+		t.Fatalf("expected only the modified section to be highlighted, got:\n%s", document)
+	}
+
+	// comparing with a later revision is refused
+	if _, err := start.Export(ctx, db, RulesetExportMarkdown, modified); err == nil {
+		t.Fatal("expected comparing with a later revision to fail")
+	}
+	if _, err := start.Export(ctx, db, RulesetExportFormat("pdf"), nil); err == nil {
+		t.Fatal("expected an unknown format to fail")
+	}
+}
+
+func TestRulesetExportHTMLIsSanitized(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	ruleset := newValidStoredRuleset(t, db)
+	amended, err := ruleset.Amend(ctx, db, &RuleAmendment{
+		Type: RuleAmendmentTypeAddSection,
+		NewSection: RuleSection{
+			Title:    "<b>Conduct</b>",
+			Markdown: "Be **nice**.\n<script>alert(1)</script>\n\n- [rules](https://example.com)\n- [bad](javascript:alert(1))",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document, err := amended.Export(ctx, db, RulesetExportHTML, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<li><a href=\"#section-1\">&lt;b&gt;Conduct&lt;/b&gt;</a></li>",
+		"<section id=\"section-1\">\n<h2>1. &lt;b&gt;Conduct&lt;/b&gt;</h2>",
+		"<p>Be <strong>nice</strong>.\n&lt;script&gt;alert(1)&lt;/script&gt;</p>",
+		"<li><a href=\"https://example.com\">rules</a></li>",
+		"<li>bad</li>",
+	} {
+		if !strings.Contains(document, want) {
+			t.Fatalf("expected export to contain %q, got:\n%s", want, document)
+		}
+	}
+	if strings.Contains(document, "<script>") || strings.Contains(document, "javascript:") {
+		t.Fatalf("expected export to be sanitized, got:\n%s", document)
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	got := markdownToHTML("## Heading\n\n1. one\n2. `two`\n\n> quoted *text*\n\n```\n<raw>\n```\n\n---", 1)
+	want := "<h3>Heading</h3>\n" +
+		"<ol>\n<li>one</li>\n<li><code>two</code></li>\n</ol>\n" +
+		"<blockquote>\n<p>quoted <em>text</em></p>\n</blockquote>\n" +
+		"<pre><code>&lt;raw&gt;\n</code></pre>\n" +
+		"<hr>\n"
+	if got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,184 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"intraclub/database"
+)
+
+// RulesetChangeKind is how a Ruleset revision was made from its parent.
+type RulesetChangeKind int
+
+const (
+	RulesetChangeCreated RulesetChangeKind = iota
+	// RulesetChangeAmended is an amendment of an unrecorded kind, for
+	// revisions made before change kinds were tracked
+	RulesetChangeAmended
+	RulesetChangeRenamed
+	RulesetChangeForked
+	RulesetChangeSectionAdded
+	RulesetChangeSectionRemoved
+	RulesetChangeSectionModified
+	RulesetChangeSectionReordered
+	RulesetChangeInvalid
+)
+
+func (k RulesetChangeKind) String() string {
+	switch k {
+	case RulesetChangeCreated:
+		return "created"
+	case RulesetChangeAmended:
+		return "amended"
+	case RulesetChangeRenamed:
+		return "renamed"
+	case RulesetChangeForked:
+		return "forked"
+	case RulesetChangeSectionAdded:
+		return "section added"
+	case RulesetChangeSectionRemoved:
+		return "section removed"
+	case RulesetChangeSectionModified:
+		return "section modified"
+	case RulesetChangeSectionReordered:
+		return "section reordered"
+	}
+	return "invalid"
+}
+
+func (k RulesetChangeKind) StaticallyValid() error {
+	if k < 0 || k >= RulesetChangeInvalid {
+		return fmt.Errorf("invalid ruleset change kind: %d", k)
+	}
+	return nil
+}
+
+// ChangeKind is the kind of revision an amendment of this type makes.
+func (r RuleAmendmentType) ChangeKind() RulesetChangeKind {
+	switch r {
+	case RuleAmendmentTypeAddSection:
+		return RulesetChangeSectionAdded
+	case RuleAmendmentTypeRemoveSection:
+		return RulesetChangeSectionRemoved
+	case RuleAmendmentTypeModifySection:
+		return RulesetChangeSectionModified
+	case RuleAmendmentTypeReorderSection:
+		return RulesetChangeSectionReordered
+	}
+	return RulesetChangeAmended
+}
+
+// RulesetHistoryNode is one revision in a Ruleset's history tree, with the
+// revisions made from it: its amendment (at most one, as amending supersedes
+// it) and any forks into other owners' copies.
+type RulesetHistoryNode struct {
+	*Ruleset
+	Change   string                `json:"change"`
+	Children []*RulesetHistoryNode `json:"children"`
+}
+
+// GetHistory returns the whole history tree this Ruleset belongs to, rooted
+// at the originally created Ruleset and including every fork.
+func (r *Ruleset) GetHistory(ctx context.Context, db database.Provider) (*RulesetHistoryNode, error) {
+	all, err := database.GetAllWhere[*Ruleset](ctx, db, func(_ context.Context, _ *Ruleset) bool {
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	byId := make(map[RulesetId]*Ruleset, len(all))
+	children := map[RulesetId][]*Ruleset{}
+	for _, x := range all {
+		byId[x.ID] = x
+		if !x.Parent.Empty() {
+			children[x.Parent] = append(children[x.Parent], x)
+		}
+	}
+
+	root := r
+	for seen := map[RulesetId]bool{}; !root.Parent.Empty() && !seen[root.ID]; {
+		seen[root.ID] = true
+		parent, ok := byId[root.Parent]
+		if !ok {
+			break
+		}
+		root = parent
+	}
+
+	var build func(x *Ruleset) *RulesetHistoryNode
+	build = func(x *Ruleset) *RulesetHistoryNode {
+		node := &RulesetHistoryNode{Ruleset: x, Change: x.ChangeKind.String(), Children: []*RulesetHistoryNode{}}
+		kids := children[x.ID]
+		slices.SortFunc(kids, func(a, b *Ruleset) int {
+			return a.Date.Compare(b.Date)
+		})
+		for _, kid := range kids {
+			if kid.ID != root.ID {
+				node.Children = append(node.Children, build(kid))
+			}
+		}
+		return node
+	}
+	return build(root), nil
+}
+
+// RulesetSnapshot is a Ruleset revision with its sections in order.
+type RulesetSnapshot struct {
+	*Ruleset
+	Sections []*RuleSection `json:"sections"`
+}
+
+// AsOf returns this Ruleset as it stood at the given time: the latest revision
+// dated at or before it among this Ruleset's later revisions and the revisions
+// it descends from (through a Fork, the forked ruleset's history up to the
+// fork). Returns an error if the Ruleset didn't exist yet.
+func (r *Ruleset) AsOf(ctx context.Context, db database.Provider, at time.Time) (*RulesetSnapshot, error) {
+	var candidates []*Ruleset
+	seen := map[RulesetId]bool{}
+	for current := r; !seen[current.ID]; {
+		seen[current.ID] = true
+		candidates = append(candidates, current)
+		if current.Parent.Empty() {
+			break
+		}
+		parent, err := database.GetExistingRecordById(ctx, db, &Ruleset{}, current.Parent.RecordId())
+		if err != nil {
+			return nil, err
+		}
+		current = parent
+	}
+	for current := r; current.Archived(); {
+		next, err := database.GetExistingRecordById(ctx, db, &Ruleset{}, current.SupersededBy.RecordId())
+		if err != nil {
+			return nil, err
+		}
+		if seen[next.ID] {
+			break
+		}
+		seen[next.ID] = true
+		candidates = append(candidates, next)
+		current = next
+	}
+
+	var found *Ruleset
+	for _, c := range candidates {
+		if !c.Date.After(at) && (found == nil || c.Date.After(found.Date)) {
+			found = c
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("ruleset %s did not exist at %s", r.ID, at.Format(time.RFC3339))
+	}
+	return found.Snapshot(ctx, db)
+}
+
+// Snapshot returns this Ruleset revision with its sections.
+func (r *Ruleset) Snapshot(ctx context.Context, db database.Provider) (*RulesetSnapshot, error) {
+	sections, err := r.getSectionRecords(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	return &RulesetSnapshot{Ruleset: r, Sections: sections}, nil
+}
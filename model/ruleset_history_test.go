@@ -0,0 +1,85 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"intraclub/database"
+)
+
+func TestRulesetHistoryTreeWithFork(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	editor := newStoredUser(t, db)
+	forker := newStoredUser(t, db)
+
+	root := newValidStoredRuleset(t, db)
+	added := addSectionRevisionToEndOfExistingRuleset(t, db, root)
+	if added.Parent != root.ID || added.ChangeKind != RulesetChangeSectionAdded || added.Author != root.Owner {
+		t.Fatalf("expected section added revision of %s by the owner, got %+v", root.ID, added)
+	}
+	renamed, err := added.EditName(ctx, db, "Renamed", editor.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.Parent != added.ID || renamed.ChangeKind != RulesetChangeRenamed || renamed.Author != editor.ID {
+		t.Fatalf("expected renamed revision of %s by %s, got %+v", added.ID, editor.ID, renamed)
+	}
+	forked, err := added.Fork(ctx, db, forker.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forked.Archived() || forked.Parent != added.ID || forked.ChangeKind != RulesetChangeForked || forked.Author != forker.ID {
+		t.Fatalf("expected an unarchived fork of %s by %s, got %+v", added.ID, forker.ID, forked)
+	}
+
+	// the same tree is returned from any revision, forks included
+	for _, from := range []*Ruleset{root, renamed, forked} {
+		history, err := from.GetHistory(ctx, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if history.ID != root.ID || history.Change != "created" || len(history.Children) != 1 {
+			t.Fatalf("expected history rooted at %s with one child, got %+v", root.ID, history)
+		}
+		next := history.Children[0]
+		if next.ID != added.ID || len(next.Children) != 2 {
+			t.Fatalf("expected %s to have a rename and a fork, got %+v", added.ID, next)
+		}
+		if next.Children[0].ID != renamed.ID || next.Children[1].ID != forked.ID || next.Children[1].Change != "forked" {
+			t.Fatalf("expected children [%s %s], got [%s %s]", renamed.ID, forked.ID, next.Children[0].ID, next.Children[1].ID)
+		}
+	}
+}
+
+func TestRulesetAsOf(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+
+	root := newValidStoredRuleset(t, db)
+	before := root.Date.Add(-time.Hour)
+	first := addSectionRevisionToEndOfExistingRuleset(t, db, root)
+	time.Sleep(time.Millisecond)
+	between := time.Now()
+	time.Sleep(time.Millisecond)
+	second := addSectionRevisionToEndOfExistingRuleset(t, db, first)
+
+	snapshot, err := root.AsOf(ctx, db, between)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.ID != first.ID || len(snapshot.Sections) != 1 {
+		t.Fatalf("expected %s with one section, got %s with %d", first.ID, snapshot.ID, len(snapshot.Sections))
+	}
+	snapshot, err = first.AsOf(ctx, db, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.ID != second.ID || len(snapshot.Sections) != 2 {
+		t.Fatalf("expected %s with two sections, got %s with %d", second.ID, snapshot.ID, len(snapshot.Sections))
+	}
+	if _, err := second.AsOf(ctx, db, before); err == nil {
+		t.Fatal("expected error viewing a ruleset before it existed")
+	}
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"intraclub/database"
+)
+
+// ImportedSection is a RuleSection parsed from an imported Markdown document.
+// Existing is an identical RuleSection (see RuleSection.Equals) of the
+// importing user's that will be reused instead of creating a duplicate, or
+// empty if the section is new.
+type ImportedSection struct {
+	Title    string        `json:"title"`
+	Markdown string        `json:"markdown"`
+	Existing RuleSectionId `json:"existing"`
+}
+
+// RulesetImport is a Markdown document split into the sections of a new
+// Ruleset. Headings at HeadingLevel (or above) start a new section, titled by
+// the heading; any text before the first of them is an untitled section.
+// Headings with no text under them can't make a section and are listed in
+// Skipped.
+type RulesetImport struct {
+	Name         string             `json:"name"`
+	HeadingLevel int                `json:"heading_level"`
+	Sections     []*ImportedSection `json:"sections"`
+	Skipped      []string           `json:"skipped"`
+}
+
+// ParseRulesetMarkdown splits a Markdown document into sections on headings
+// at the given level (1 for "#" through 6 for "######"), and above. A
+// document title (a heading above the split level, before any section) is
+// used for the name if no name is given.
+func ParseRulesetMarkdown(name, markdown string, headingLevel int) (*RulesetImport, error) {
+	if headingLevel < 1 || headingLevel > 6 {
+		return nil, fmt.Errorf("heading level must be between 1 and 6, got %d", headingLevel)
+	}
+	imp := &RulesetImport{
+		Name:         strings.TrimSpace(name),
+		HeadingLevel: headingLevel,
+		Sections:     []*ImportedSection{},
+		Skipped:      []string{},
+	}
+
+	var title string
+	var body []string
+	started := false
+	flush := func() {
+		section := &RuleSection{Title: title, Markdown: strings.Join(body, "\n")}
+		if err := section.StaticallyValid(); err != nil {
+			if started && section.Title != "" {
+				imp.Skipped = append(imp.Skipped, section.Title)
+			}
+		} else {
+			imp.Sections = append(imp.Sections, &ImportedSection{Title: section.Title, Markdown: section.Markdown})
+		}
+		title, body = "", nil
+	}
+
+	fenced := false
+	for _, line := range splitLines(strings.ReplaceAll(markdown, "\r\n", "\n")) {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			fenced = !fenced
+		}
+		m := markdownHeading.FindStringSubmatch(trimmed)
+		if fenced || m == nil || len(m[1]) > headingLevel {
+			body = append(body, line)
+			continue
+		}
+		heading := strings.TrimSpace(strings.TrimRight(m[2], "# "))
+		if len(m[1]) < headingLevel && !started && imp.Name == "" && strings.TrimSpace(strings.Join(body, "")) == "" {
+			imp.Name = heading
+			continue
+		}
+		flush()
+		title = heading
+		started = true
+	}
+	flush()
+
+	if imp.Name == "" {
+		return nil, errors.New("name must be set when the document has no title")
+	}
+	if len(imp.Sections) == 0 {
+		return nil, errors.New("no sections found in the document")
+	}
+	return imp, nil
+}
+
+// FindExisting looks up, for each section, an existing RuleSection identical
+// to it to reuse. Only sections owned by the importing user are reused, since
+// another user's sections go away with their ruleset; the earliest created
+// match is chosen.
+func (imp *RulesetImport) FindExisting(ctx context.Context, db database.Provider, owner database.UserId) error {
+	for _, s := range imp.Sections {
+		section := &RuleSection{Title: s.Title, Markdown: s.Markdown}
+		matches, err := database.GetAllWhere[*RuleSection](ctx, db, func(_ context.Context, existing *RuleSection) bool {
+			return existing.Owner == owner && existing.Equals(section)
+		})
+		if err != nil {
+			return err
+		}
+		s.Existing = RuleSectionId(database.InvalidRecordId)
+		for _, match := range matches {
+			if s.Existing.Empty() || match.ID < s.Existing {
+				s.Existing = match.ID
+			}
+		}
+	}
+	return nil
+}
+
+// ImportRuleset creates a new Ruleset owned by owner from the parsed import,
+// with its sections in document order. Sections identical to existing ones of
+// the owner's reuse them; the rest are created as sections of the new Ruleset.
+// Nothing is left behind if the import fails partway.
+func ImportRuleset(ctx context.Context, db database.Provider, owner database.UserId, imp *RulesetImport) (*Ruleset, error) {
+	if len(imp.Sections) == 0 {
+		return nil, errors.New("no sections to import")
+	}
+	if err := imp.FindExisting(ctx, db, owner); err != nil {
+		return nil, err
+	}
+
+	ruleset := NewRuleset()
+	ruleset.Name = imp.Name
+	ruleset.Owner = owner
+	ruleset.Author = owner
+	// the imported sections make up the first revision
+	ruleset.Revision = 1
+	created, err := database.CreateOne(ctx, db, ruleset)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, s := range imp.Sections {
+		sectionId := s.Existing
+		if sectionId.Empty() {
+			section, err := database.CreateOne(ctx, db, &RuleSection{
+				Parent:   created.ID,
+				Owner:    owner,
+				Title:    s.Title,
+				Markdown: s.Markdown,
+			})
+			if err != nil {
+				return nil, importFailed(ctx, db, created, i, err)
+			}
+			sectionId = section.ID
+		}
+		if err := created.AddSection(ctx, db, sectionId, i); err != nil {
+			return nil, importFailed(ctx, db, created, i, err)
+		}
+	}
+	return created, nil
+}
+
+// importFailed deletes a partially imported Ruleset, which cascades to the
+// sections created for it and its section relations, and describes the
+// failure.
+func importFailed(ctx context.Context, db database.Provider, ruleset *Ruleset, index int, err error) error {
+	if _, _, deleteErr := database.DeleteOneById(ctx, db, &Ruleset{}, ruleset.ID.RecordId()); deleteErr != nil {
+		return fmt.Errorf("section %d: %w (cleaning up: %s)", index+1, err, deleteErr)
+	}
+	return fmt.Errorf("section %d: %w", index+1, err)
+}
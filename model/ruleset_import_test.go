@@ -0,0 +1,198 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"intraclub/database"
+)
+
+const importedRules = `# Club Rules
+
+## Eligibility
+Players must be club members.
+
+### Guests
+Guests may play twice a season.
+
+## Withdrawn
+
+## Scoring
+` + "```" + `
+## not a heading
+` + "```" + `
+Matches are best of three.
+`
+
+func TestParseRulesetMarkdown(t *testing.T) {
+	imp, err := ParseRulesetMarkdown("", importedRules, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imp.Name != "Club Rules" {
+		t.Fatalf("expected the document title to name the ruleset, got %q", imp.Name)
+	}
+	if len(imp.Sections) != 2 {
+		t.Fatalf("expected two sections, got %+v", imp.Sections)
+	}
+	if s := imp.Sections[0]; s.Title != "Eligibility" || s.Markdown != "Players must be club members.\n\n### Guests\nGuests may play twice a season." {
+		t.Fatalf("unexpected first section %+v", s)
+	}
+	if s := imp.Sections[1]; s.Title != "Scoring" || s.Markdown != "```\n## not a heading\n```\nMatches are best of three." {
+		t.Fatalf("unexpected second section %+v", s)
+	}
+	if len(imp.Skipped) != 1 || imp.Skipped[0] != "Withdrawn" {
+		t.Fatalf("expected the empty section to be skipped, got %v", imp.Skipped)
+	}
+
+	// splitting on the top level makes the whole document one section
+	imp, err = ParseRulesetMarkdown("", importedRules, 1)
+	if err == nil {
+		t.Fatalf("expected a missing name to fail, got %+v", imp)
+	}
+	imp, err = ParseRulesetMarkdown("Imported", importedRules, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imp.Sections) != 1 || imp.Sections[0].Title != "Club Rules" {
+		t.Fatalf("expected one section, got %+v", imp.Sections)
+	}
+
+	if _, err := ParseRulesetMarkdown("x", importedRules, 7); err == nil {
+		t.Fatal("expected an invalid heading level to fail")
+	}
+}
+
+func TestImportRulesetReusesIdenticalSections(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	existing := newValidStoredRuleset(t, db)
+	existing, err := existing.Amend(ctx, db, &RuleAmendment{
+		Type:       RuleAmendmentTypeAddSection,
+		NewSection: RuleSection{Title: "Scoring", Markdown: "```\n## not a heading\n```\nMatches are best of three."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	existingSections, err := existing.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imp, err := ParseRulesetMarkdown("", importedRules, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := imp.FindExisting(ctx, db, existing.Owner); err != nil {
+		t.Fatal(err)
+	}
+	if !imp.Sections[0].Existing.Empty() || imp.Sections[1].Existing != existingSections[0] {
+		t.Fatalf("expected only the scoring section to match an existing one, got %+v", imp.Sections)
+	}
+
+	ruleset, err := ImportRuleset(ctx, db, existing.Owner, imp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ruleset.Owner != existing.Owner || ruleset.Name != "Club Rules" || ruleset.ChangeKind != RulesetChangeCreated {
+		t.Fatalf("unexpected imported ruleset %+v", ruleset)
+	}
+	snapshot, err := ruleset.Snapshot(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Sections) != 2 || snapshot.Sections[0].Title != "Eligibility" || snapshot.Sections[1].ID != existingSections[0] {
+		t.Fatalf("expected sections in document order with scoring reused, got %+v", snapshot.Sections)
+	}
+	if snapshot.Sections[0].Parent != ruleset.ID || snapshot.Sections[0].Owner != existing.Owner {
+		t.Fatalf("expected the new section to belong to the imported ruleset, got %+v", snapshot.Sections[0])
+	}
+}
+
+func TestImportRulesetKeepsReusedSectionsWhenSourceDeleted(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	source := newValidStoredRuleset(t, db)
+	source, err := source.Amend(ctx, db, &RuleAmendment{
+		Type:       RuleAmendmentTypeAddSection,
+		NewSection: RuleSection{Title: "Scoring", Markdown: "```\n## not a heading\n```\nMatches are best of three."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	imp, err := ParseRulesetMarkdown("", importedRules, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ruleset, err := ImportRuleset(ctx, db, source.Owner, imp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := ruleset.Snapshot(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reused, err := database.GetExistingRecordById(ctx, db, &RuleSection{}, snapshot.Sections[1].ID.RecordId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reused.Parent == ruleset.ID {
+		t.Fatalf("expected the scoring section to be reused from another ruleset, got %+v", reused)
+	}
+
+	// deleting the ruleset the reused section was created for leaves the
+	// imported ruleset whole
+	if _, _, err := database.DeleteOneById(ctx, db, &Ruleset{}, reused.Parent.RecordId()); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.ExistsById(ctx, db, &RuleSection{}, reused.ID.RecordId()); err != nil {
+		t.Fatalf("expected the reused section to be kept: %v", err)
+	}
+	after, err := ruleset.Snapshot(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after.Sections) != 2 || after.Sections[1].ID != reused.ID {
+		t.Fatalf("expected the imported ruleset to keep both sections, got %+v", after.Sections)
+	}
+}
+
+func TestImportRulesetCopiesOtherUsersSections(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	existing := newValidStoredRuleset(t, db)
+	existing, err := existing.Amend(ctx, db, &RuleAmendment{
+		Type:       RuleAmendmentTypeAddSection,
+		NewSection: RuleSection{Title: "Scoring", Markdown: "```\n## not a heading\n```\nMatches are best of three."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := newStoredUser(t, db)
+	imp, err := ParseRulesetMarkdown("", importedRules, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ruleset, err := ImportRuleset(ctx, db, user.ID, imp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !imp.Sections[1].Existing.Empty() {
+		t.Fatalf("expected another user's identical section not to be reused, got %+v", imp.Sections[1])
+	}
+
+	// so deleting the other user's ruleset leaves the import intact
+	for _, id := range []RulesetId{existing.ID, existing.Parent} {
+		if _, _, err := database.DeleteOneById(ctx, db, &Ruleset{}, id.RecordId()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshot, err := ruleset.Snapshot(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Sections) != 2 || snapshot.Sections[1].Owner != user.ID || snapshot.Sections[1].Parent != ruleset.ID {
+		t.Fatalf("expected the import to keep its own copy of the scoring section, got %+v", snapshot.Sections)
+	}
+}
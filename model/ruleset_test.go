@@ -37,6 +37,7 @@ func newValidRuleset(t *testing.T, owner database.UserId) *Ruleset {
 	x := NewRuleset()
 	x.Name = fmt.Sprintf("test ruleset #%d", rand.Int())
 	x.Owner = owner
+	x.Author = owner
 	return x
 }
 
@@ -128,7 +129,7 @@ func TestRulesetEditNameCreatesSupersedingRevision(t *testing.T) {
 	db := database.NewUnitTestDBProvider()
 	ruleset := newValidStoredRuleset(t, db)
 
-	newRevision, err := ruleset.EditName(context.Background(), db, "Amended Name")
+	newRevision, err := ruleset.EditName(context.Background(), db, "Amended Name", ruleset.Owner)
 	if err != nil {
 		t.Fatalf("unexpected error amending ruleset name: %s", err)
 	}
@@ -164,10 +165,10 @@ func TestRulesetEditNameRequiresDifferentNonEmptyName(t *testing.T) {
 	db := database.NewUnitTestDBProvider()
 	ruleset := newValidStoredRuleset(t, db)
 
-	if _, err := ruleset.EditName(context.Background(), db, ""); err == nil {
+	if _, err := ruleset.EditName(context.Background(), db, "", ruleset.Owner); err == nil {
 		t.Fatal("expected error amending ruleset with empty name")
 	}
-	if _, err := ruleset.EditName(context.Background(), db, ruleset.Name); err == nil {
+	if _, err := ruleset.EditName(context.Background(), db, ruleset.Name, ruleset.Owner); err == nil {
 		t.Fatal("expected error amending ruleset with unchanged name")
 	}
 }
@@ -378,3 +379,29 @@ func TestRulesetPostDeleteCascadesChildren(t *testing.T) {	db := database.NewUni
 		t.Fatalf("expected 0 after delete, got sections=%d relations=%d", sections, relations)
 	}
 }
+
+func TestRulesetCreateRequestCannotFakeLineage(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	parent := newValidStoredRuleset(t, db)
+	user := newStoredUser(t, db)
+
+	requested := newValidRuleset(t, user.ID)
+	requested.Parent = parent.ID
+	requested.ChangeKind = RulesetChangeForked
+	requested.Author = parent.Owner
+	requested.Revision = 3
+	if err := requested.PreCreateRequest(context.Background(), db, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	v, err := database.CreateOne(context.Background(), db, requested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Parent.Empty() || v.ChangeKind != RulesetChangeCreated || v.Author != user.ID || v.Revision != 0 {
+		t.Fatalf("expected a new ruleset authored by the requesting user, got %+v", v)
+	}
+
+	unauthored := newValidRuleset(t, user.ID)
+	unauthored.Author = database.InvalidUserId
+	assertRulesetIsStaticallyInvalid(t, unauthored, "author must be set")
+}
@@ -0,0 +1,546 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"intraclub/database"
+)
+
+type ScoreReportId database.RecordId
+
+func (id ScoreReportId) RecordId() database.RecordId {
+	return database.RecordId(id)
+}
+
+func (id ScoreReportId) String() string {
+	return id.RecordId().String()
+}
+
+func (id ScoreReportId) MarshalJSON() ([]byte, error) {
+	return id.RecordId().MarshalJSON()
+}
+
+func (id *ScoreReportId) UnmarshalJSON(bytes []byte) error {
+	rid := database.RecordId(0)
+	if err := (*database.RecordId)(&rid).UnmarshalJSON(bytes); err != nil {
+		return err
+	}
+	*id = ScoreReportId(rid)
+	return nil
+}
+
+type ScoreReportStatus int
+
+const (
+	ScoreReportPending ScoreReportStatus = iota
+	ScoreReportConfirmed
+	ScoreReportDisputed
+	ScoreReportResolved
+	ScoreReportInvalid
+)
+
+func (s ScoreReportStatus) String() string {
+	switch s {
+	case ScoreReportPending:
+		return "pending"
+	case ScoreReportConfirmed:
+		return "confirmed"
+	case ScoreReportDisputed:
+		return "disputed"
+	case ScoreReportResolved:
+		return "resolved"
+	default:
+		return "invalid"
+	}
+}
+
+func (s ScoreReportStatus) Valid() bool {
+	return s < ScoreReportInvalid
+}
+
+// Final reports whether a score in this status has been agreed by both teams
+// or settled by a commissioner, and so can no longer change.
+func (s ScoreReportStatus) Final() bool {
+	return s == ScoreReportConfirmed || s == ScoreReportResolved
+}
+
+var (
+	// ErrScoreFinal is returned when changing the score of a match whose
+	// ScoreReport has been confirmed or resolved.
+	ErrScoreFinal = errors.New("match score has been confirmed and can no longer change")
+	// ErrScoreDisputed is returned when a team tries to change the score of a
+	// match whose ScoreReport is awaiting a commissioner's resolution.
+	ErrScoreDisputed = errors.New("match score is disputed and awaiting a commissioner")
+	// ErrScoreAwaitingResponse is returned when a team tries to change the
+	// score of a match the other team has reported, rather than confirming or
+	// disputing it.
+	ErrScoreAwaitingResponse = errors.New("match score has been reported by the other team; confirm or dispute it instead")
+)
+
+// ScoreClaim is one team's account of a match's final score, from the point of
+// view of the ScoreReport's Team. A claim with NoWinner is a match neither side
+// won, i.e. a double default.
+type ScoreClaim struct {
+	MainValue              int  `json:"main_value"`
+	SecondaryValue         int  `json:"secondary_value"`
+	OpponentMainValue      int  `json:"opponent_main_value"`
+	OpponentSecondaryValue int  `json:"opponent_secondary_value"`
+	Won                    bool `json:"won"`
+	NoWinner               bool `json:"no_winner"`
+}
+
+// Reverse returns the same claim from the opponent's point of view.
+func (c ScoreClaim) Reverse() ScoreClaim {
+	return ScoreClaim{
+		MainValue:              c.OpponentMainValue,
+		SecondaryValue:         c.OpponentSecondaryValue,
+		OpponentMainValue:      c.MainValue,
+		OpponentSecondaryValue: c.SecondaryValue,
+		Won:                    !c.Won && !c.NoWinner,
+		NoWinner:               c.NoWinner,
+	}
+}
+
+func (c ScoreClaim) StaticallyValid() error {
+	if c.MainValue < 0 || c.SecondaryValue < 0 || c.OpponentMainValue < 0 || c.OpponentSecondaryValue < 0 {
+		return errors.New("scores cannot be negative")
+	}
+	if c.Won && c.NoWinner {
+		return errors.New("a claim with no winner cannot be won")
+	}
+	return nil
+}
+
+// scoreClaimFor reads the claim made by a completed match pair's current
+// scores, from the point of view of side.
+func scoreClaimFor(side, opp *IndividualMatch) ScoreClaim {
+	return ScoreClaim{
+		MainValue:              side.MainValue,
+		SecondaryValue:         side.SecondaryValue,
+		OpponentMainValue:      opp.MainValue,
+		OpponentSecondaryValue: opp.SecondaryValue,
+		Won:                    side.Status == MatchWon,
+		NoWinner:               side.Status == MatchLost && opp.Status == MatchLost,
+	}
+}
+
+// ScoreReport tracks the agreement of a team match line's final score. When
+// one team's captain enters a result the report is pending until the other
+// team's captain or co-captain confirms or disputes it; disputes are settled
+// by a season commissioner, whose resolution is final. A result entered by a
+// commissioner is resolved straight away. There is one report per match pair,
+// filed under the side with the lower ID (see scoreLogId), and both claims
+// are from the point of view of that side's Team.
+type ScoreReport struct {
+	ID            ScoreReportId     `json:"id"`
+	MatchId       IndividualMatchId `json:"match_id"` // side of the pair with the lower ID
+	TeamMatchId   TeamMatchId       `json:"team_match_id"`
+	Team          TeamId            `json:"team_id"`           // team playing the MatchId side
+	OpponentTeam  TeamId            `json:"opponent_team_id"`  // team playing the other side
+	ReportingTeam TeamId            `json:"reporting_team_id"` // team whose captain entered the result, unset for a commissioner
+	ReportedBy    database.UserId   `json:"reported_by"`
+	Reported      ScoreClaim        `json:"reported"`
+	Status        ScoreReportStatus `json:"status"`
+	RespondedBy   database.UserId   `json:"responded_by"` // opposing captain/co-captain who confirmed or disputed
+	Disputed      ScoreClaim        `json:"disputed"`     // the opposing team's claim, once disputed
+	DisputeNote   string            `json:"dispute_note"`
+	ResolvedBy    database.UserId   `json:"resolved_by"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+func NewScoreReport() *ScoreReport {
+	return &ScoreReport{}
+}
+
+func (r *ScoreReport) GetOwner() database.UserId {
+	return r.ReportedBy
+}
+
+func (r *ScoreReport) SetOwner(userId database.UserId) {
+	r.ReportedBy = userId
+}
+
+func (r *ScoreReport) GetTimeStamps() (created, updated time.Time) {
+	return r.CreatedAt, r.UpdatedAt
+}
+
+func (r *ScoreReport) SetCreateTimestamp(t time.Time) time.Time {
+	oldValue := r.CreatedAt
+	r.CreatedAt = t
+	return oldValue
+}
+
+func (r *ScoreReport) SetUpdateTimestamp(t time.Time) time.Time {
+	oldValue := r.UpdatedAt
+	r.UpdatedAt = t
+	return oldValue
+}
+
+func (r *ScoreReport) UniquenessEquivalent(other *ScoreReport) error {
+	if r.MatchId == other.MatchId {
+		return fmt.Errorf("match %s already has a score report", r.MatchId)
+	}
+	return nil
+}
+
+func (r *ScoreReport) Type() string {
+	return "score_report"
+}
+
+func (r *ScoreReport) GetId() database.RecordId {
+	return r.ID.RecordId()
+}
+
+func (r *ScoreReport) SetId(id database.RecordId) {
+	r.ID = ScoreReportId(id)
+}
+
+// EditableBy returns nobody but the system administrator, since reports are
+// only written by the score confirmation flow.
+func (r *ScoreReport) EditableBy(_ context.Context, _ database.Provider) []database.UserId {
+	return []database.UserId{database.SysAdminUserId}
+}
+
+func (r *ScoreReport) AccessibleTo(_ context.Context, _ database.Provider) []database.UserId {
+	return database.AccessibleToEveryone
+}
+
+func (r *ScoreReport) StaticallyValid() error {
+	if !r.Status.Valid() {
+		return fmt.Errorf("score report status %d is not valid", r.Status)
+	}
+	if r.Team == r.OpponentTeam {
+		return errors.New("score report teams must differ")
+	}
+	if r.ReportingTeam.RecordId() != database.InvalidRecordId && r.ReportingTeam != r.Team && r.ReportingTeam != r.OpponentTeam {
+		return fmt.Errorf("reporting team %s is not playing this match", r.ReportingTeam)
+	}
+	if err := r.Reported.StaticallyValid(); err != nil {
+		return err
+	}
+	return r.Disputed.StaticallyValid()
+}
+
+func (r *ScoreReport) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	if err := database.ExistsById(ctx, db, &IndividualMatch{}, r.MatchId.RecordId()); err != nil {
+		return err
+	}
+	return database.ExistsById(ctx, db, &TeamMatch{}, r.TeamMatchId.RecordId())
+}
+
+func (r *ScoreReport) PreUpdate(_ context.Context, _ database.Provider, existingValues database.CrudRecord) error {
+	existing := existingValues.(*ScoreReport)
+	if r.MatchId != existing.MatchId || r.TeamMatchId != existing.TeamMatchId {
+		return fmt.Errorf("score report %s cannot be moved", existing.ID)
+	}
+	if existing.Status.Final() {
+		return ErrScoreFinal
+	}
+	return nil
+}
+
+func (r *ScoreReport) NewRecord() database.CrudRecord {
+	return new(ScoreReport)
+}
+
+// ClaimFor returns a claim made about this report's match from the point of
+// view of the given side, e.g. so that it can be shown to the team playing it.
+func (r *ScoreReport) ClaimFor(side IndividualMatchId, claim ScoreClaim) ScoreClaim {
+	if side == r.MatchId {
+		return claim
+	}
+	return claim.Reverse()
+}
+
+// GetScoreReport returns the ScoreReport of this match pair, or nil if its
+// score hasn't been reported.
+func (s *IndividualMatch) GetScoreReport(ctx context.Context, db database.Provider) (*ScoreReport, error) {
+	logId := s.scoreLogId()
+	reports, err := database.GetAllWhere[*ScoreReport](ctx, db, func(_ context.Context, r *ScoreReport) bool {
+		return r.MatchId == logId
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, nil
+	}
+	return reports[0], nil
+}
+
+// checkEditableFor returns an error if the reported score can't be changed on
+// behalf of team: once final nobody may change it, and otherwise only the team
+// that reported it or a commissioner (an unset team) may, with disputes left
+// to the commissioner.
+func (r *ScoreReport) checkEditableFor(team TeamId) error {
+	switch {
+	case r.Status.Final():
+		return ErrScoreFinal
+	case team.RecordId() == database.InvalidRecordId:
+		return nil
+	case r.Status == ScoreReportDisputed:
+		return ErrScoreDisputed
+	case r.ReportingTeam != team:
+		return ErrScoreAwaitingResponse
+	}
+	return nil
+}
+
+// CheckScoreEditable returns an error if the score of this match can't be
+// changed on behalf of team (unset for a commissioner).
+func (s *IndividualMatch) CheckScoreEditable(ctx context.Context, db database.Provider, team TeamId) error {
+	report, err := s.GetScoreReport(ctx, db)
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		return nil
+	}
+	return report.checkEditableFor(team)
+}
+
+// IsScoreFinal reports whether this match's score has been confirmed by both
+// teams or resolved by a commissioner.
+func (s *IndividualMatch) IsScoreFinal(ctx context.Context, db database.Provider) (bool, error) {
+	report, err := s.GetScoreReport(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	return report != nil && report.Status.Final(), nil
+}
+
+// ReportScore files the completed result of this match, as entered by
+// reportedBy on behalf of reportingTeam. A result entered by a team's captain
+// or co-captain is pending until the other team responds; one entered by a
+// commissioner (an unset reportingTeam) is resolved straight away. Reporting
+// again replaces a pending report from the same team, or any unresolved report
+// when entered by a commissioner.
+func (s *IndividualMatch) ReportScore(ctx context.Context, db database.Provider, reportedBy database.UserId, reportingTeam TeamId) (*ScoreReport, error) {
+	if s.Status != MatchWon && s.Status != MatchLost {
+		return nil, fmt.Errorf("match %s has not been completed", s.ID)
+	}
+	logSide, err := database.GetExistingRecordById(ctx, db, &IndividualMatch{}, s.scoreLogId().RecordId())
+	if err != nil {
+		return nil, err
+	}
+	opp, err := logSide.GetOpponent(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	teamMatch, team, err := GetTeamMatchForIndividualMatch(ctx, db, logSide.ID)
+	if err != nil {
+		return nil, err
+	}
+	if teamMatch == nil {
+		return nil, fmt.Errorf("match %s is not part of a team match", s.ID)
+	}
+	oppTeam := teamMatch.HomeTeam
+	if team == teamMatch.HomeTeam {
+		oppTeam = teamMatch.AwayTeam
+	}
+
+	report := NewScoreReport()
+	existing, err := logSide.GetScoreReport(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if err := existing.checkEditableFor(reportingTeam); err != nil {
+			return nil, err
+		}
+		*report = *existing
+	}
+
+	report.MatchId = logSide.ID
+	report.TeamMatchId = teamMatch.ID
+	report.Team = team
+	report.OpponentTeam = oppTeam
+	report.ReportingTeam = reportingTeam
+	report.ReportedBy = reportedBy
+	report.Reported = scoreClaimFor(logSide, opp)
+	report.Status = ScoreReportPending
+	if reportingTeam.RecordId() == database.InvalidRecordId {
+		report.Status = ScoreReportResolved
+		report.ResolvedBy = reportedBy
+	}
+
+	if existing == nil {
+		return database.CreateOne(ctx, db, report)
+	}
+	if err := existing.updateTo(ctx, db, report); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// WithdrawScoreReport removes the unresolved report of this match, e.g. once
+// its live scoring log has been undone and the match is no longer decided.
+func (s *IndividualMatch) WithdrawScoreReport(ctx context.Context, db database.Provider) error {
+	report, err := s.GetScoreReport(ctx, db)
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		return nil
+	}
+	if report.Status.Final() {
+		return ErrScoreFinal
+	}
+	_, _, err = database.DeleteOneById(ctx, db, report, report.ID.RecordId())
+	return err
+}
+
+// Confirm accepts a pending report on behalf of team, which must be the
+// opponent of the team that reported it.
+func (r *ScoreReport) Confirm(ctx context.Context, db database.Provider, userId database.UserId, team TeamId) error {
+	if err := r.checkRespondent(team); err != nil {
+		return err
+	}
+	confirmed := *r
+	confirmed.Status = ScoreReportConfirmed
+	confirmed.RespondedBy = userId
+	return r.updateTo(ctx, db, &confirmed)
+}
+
+// Dispute rejects a pending report on behalf of team, which must be the
+// opponent of the team that reported it, recording the team's own claim (from
+// the point of view of the report's Team) for a commissioner to resolve.
+func (r *ScoreReport) Dispute(ctx context.Context, db database.Provider, userId database.UserId, team TeamId, claim ScoreClaim, note string) error {
+	if err := r.checkRespondent(team); err != nil {
+		return err
+	}
+	if err := claim.StaticallyValid(); err != nil {
+		return err
+	}
+	disputed := *r
+	disputed.Status = ScoreReportDisputed
+	disputed.RespondedBy = userId
+	disputed.Disputed = claim
+	disputed.DisputeNote = note
+	return r.updateTo(ctx, db, &disputed)
+}
+
+// checkRespondent ensures the report is pending and team may respond to it.
+func (r *ScoreReport) checkRespondent(team TeamId) error {
+	if r.Status != ScoreReportPending {
+		return fmt.Errorf("score report %s is %s, not %s", r.ID, r.Status, ScoreReportPending)
+	}
+	if team.RecordId() == database.InvalidRecordId || team == r.ReportingTeam {
+		return errors.New("only the opposing team may confirm or dispute a reported score")
+	}
+	if team != r.Team && team != r.OpponentTeam {
+		return fmt.Errorf("team %s is not playing this match", team)
+	}
+	return nil
+}
+
+// Resolve settles a pending or disputed report with the commissioner's claim
+// (from the point of view of the report's Team), writing it onto both sides
+// of the match. The match is then decided by the claim alone: a claim with no
+// winner is a double default, and any other is played out, replacing an
+// outcome marked before. The resolution is final.
+func (r *ScoreReport) Resolve(ctx context.Context, db database.Provider, commissioner database.UserId, claim ScoreClaim) error {
+	if r.Status.Final() {
+		return ErrScoreFinal
+	}
+	if err := claim.StaticallyValid(); err != nil {
+		return err
+	}
+	logSide, err := database.GetExistingRecordById(ctx, db, &IndividualMatch{}, r.MatchId.RecordId())
+	if err != nil {
+		return err
+	}
+	opp, err := logSide.GetOpponent(ctx, db)
+	if err != nil {
+		return err
+	}
+	logSide.MainValue, opp.MainValue = claim.MainValue, claim.OpponentMainValue
+	logSide.SecondaryValue, opp.SecondaryValue = claim.SecondaryValue, claim.OpponentSecondaryValue
+	logSide.WinOverride, opp.WinOverride = false, false
+	logSide.Outcome, opp.Outcome = OutcomePlayed, OutcomePlayed
+	switch {
+	case claim.NoWinner:
+		logSide.Status, opp.Status = MatchLost, MatchLost
+		logSide.Outcome, opp.Outcome = OutcomeDoubleDefault, OutcomeDoubleDefault
+	case claim.Won:
+		logSide.Status, opp.Status = MatchWon, MatchLost
+	default:
+		logSide.Status, opp.Status = MatchLost, MatchWon
+	}
+	if err := database.UpdateOne(ctx, db, logSide); err != nil {
+		return err
+	}
+	if err := database.UpdateOne(ctx, db, opp); err != nil {
+		return err
+	}
+
+	resolved := *r
+	resolved.Status = ScoreReportResolved
+	resolved.ResolvedBy = commissioner
+	resolved.Reported = claim
+	return r.updateTo(ctx, db, &resolved)
+}
+
+// updateTo stores the updated copy of this ScoreReport, only overwriting the
+// receiver once the update has succeeded.
+func (r *ScoreReport) updateTo(ctx context.Context, db database.Provider, updated *ScoreReport) error {
+	if err := database.UpdateOne(ctx, db, updated); err != nil {
+		return err
+	}
+	*r = *updated
+	return nil
+}
+
+// GetTeamMatchForIndividualMatch returns the TeamMatch an individual match
+// was generated for along with the team playing it, or a nil TeamMatch if the
+// match isn't part of one.
+func GetTeamMatchForIndividualMatch(ctx context.Context, db database.Provider, id IndividualMatchId) (*TeamMatch, TeamId, error) {
+	rows, err := database.GetAllWhere[*TeamMatchIndividualMatch](ctx, db, func(_ context.Context, r *TeamMatchIndividualMatch) bool {
+		return r.IndividualMatchId == id
+	})
+	if err != nil {
+		return nil, TeamId(database.InvalidRecordId), err
+	}
+	if len(rows) == 0 {
+		return nil, TeamId(database.InvalidRecordId), nil
+	}
+	teamMatch, err := database.GetExistingRecordById(ctx, db, &TeamMatch{}, rows[0].TeamMatchId.RecordId())
+	if err != nil {
+		return nil, TeamId(database.InvalidRecordId), err
+	}
+	pairing, err := database.GetExistingRecordById(ctx, db, &LineupPairing{}, rows[0].LineupPairingId.RecordId())
+	if err != nil {
+		return nil, TeamId(database.InvalidRecordId), err
+	}
+	return teamMatch, pairing.TeamId, nil
+}
+
+// GetDisputedScoreReports returns the season's disputed score reports, which
+// are awaiting a commissioner's resolution.
+func GetDisputedScoreReports(ctx context.Context, db database.Provider, season *Season) ([]*ScoreReport, error) {
+	weeks, err := GetWeeksForDraft(ctx, db, season.DraftId)
+	if err != nil {
+		return nil, err
+	}
+	weekIds := make(map[WeekId]bool, len(weeks))
+	for _, week := range weeks {
+		weekIds[week.ID] = true
+	}
+	teamMatches, err := database.GetAllWhere[*TeamMatch](ctx, db, func(_ context.Context, tm *TeamMatch) bool {
+		return weekIds[tm.WeekId]
+	})
+	if err != nil {
+		return nil, err
+	}
+	teamMatchIds := make(map[TeamMatchId]bool, len(teamMatches))
+	for _, tm := range teamMatches {
+		teamMatchIds[tm.ID] = true
+	}
+	return database.GetAllWhere[*ScoreReport](ctx, db, func(_ context.Context, r *ScoreReport) bool {
+		return r.Status == ScoreReportDisputed && teamMatchIds[r.TeamMatchId]
+	})
+}
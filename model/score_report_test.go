@@ -0,0 +1,183 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newStoredReportableMatch builds a team match with a single line, played by a
+// match pair between the home (match1) and away (match2) teams.
+func newStoredReportableMatch(t *testing.T, db database.Provider) (*TeamMatch, *IndividualMatch, *IndividualMatch) {
+	ctx := context.Background()
+	tm := newStoredTeamMatch(t, db)
+	match1, match2 := newStoredMatchPair(t, db, newDefaultStoredScoringStructure(t, db))
+
+	homeLineup, err := database.GetExistingRecordById(ctx, db, &Lineup{}, tm.Lineup.RecordId())
+	require.NoError(t, err)
+	awayLineup, err := database.CreateOne(ctx, db, &Lineup{TeamId: tm.AwayTeam, WeekId: tm.WeekId})
+	require.NoError(t, err)
+	for _, side := range []struct {
+		lineup *Lineup
+		match  *IndividualMatch
+	}{{homeLineup, match1}, {awayLineup, match2}} {
+		team, err := database.GetExistingRecordById(ctx, db, &Team{}, side.lineup.TeamId.RecordId())
+		require.NoError(t, err)
+		pairing := newStoredLineupPairing(t, db, side.lineup, team)
+		_, err = tm.AssignIndividualMatch(ctx, db, pairing.ID, side.match.ID)
+		require.NoError(t, err)
+	}
+	return tm, match1, match2
+}
+
+func TestScoreReportConfirmedByOpponent(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, match1, match2 := newStoredReportableMatch(t, db)
+	homeCaptain, awayCaptain := newStoredUser(t, db).ID, newStoredUser(t, db).ID
+
+	// a score can't be reported until the match is decided
+	_, err := match2.ReportScore(ctx, db, awayCaptain, tm.AwayTeam)
+	require.Error(t, err)
+
+	recordGames(t, db, match1, match2, sixZeroDustedFlow)
+	report, err := match2.ReportScore(ctx, db, awayCaptain, tm.AwayTeam)
+	require.NoError(t, err)
+	require.Equal(t, ScoreReportPending, report.Status)
+	require.Equal(t, tm.AwayTeam, report.ReportingTeam)
+
+	// claims are kept from the point of view of the lower side of the pair
+	claim := report.ClaimFor(match2.ID, report.Reported)
+	require.False(t, claim.Won)
+	require.Equal(t, 12, claim.OpponentSecondaryValue)
+
+	// the home team must respond rather than change the score
+	require.ErrorIs(t, match1.CheckScoreEditable(ctx, db, tm.HomeTeam), ErrScoreAwaitingResponse)
+	require.NoError(t, match1.CheckScoreEditable(ctx, db, tm.AwayTeam))
+	require.Error(t, report.Confirm(ctx, db, awayCaptain, tm.AwayTeam))
+
+	require.NoError(t, report.Confirm(ctx, db, homeCaptain, tm.HomeTeam))
+	require.Equal(t, ScoreReportConfirmed, report.Status)
+	require.Equal(t, homeCaptain, report.RespondedBy)
+	final, err := match1.IsScoreFinal(ctx, db)
+	require.NoError(t, err)
+	require.True(t, final)
+
+	// a confirmed score is final for everyone, commissioners included
+	require.ErrorIs(t, match2.CheckScoreEditable(ctx, db, TeamId(database.InvalidRecordId)), ErrScoreFinal)
+	_, err = match2.ReportScore(ctx, db, awayCaptain, TeamId(database.InvalidRecordId))
+	require.ErrorIs(t, err, ErrScoreFinal)
+	require.ErrorIs(t, match1.WithdrawScoreReport(ctx, db), ErrScoreFinal)
+}
+
+func TestScoreReportDisputeResolvedByCommissioner(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, match1, match2 := newStoredReportableMatch(t, db)
+	homeCaptain, awayCaptain, commissioner := newStoredUser(t, db).ID, newStoredUser(t, db).ID, newStoredUser(t, db).ID
+
+	recordGames(t, db, match1, match2, sixZeroDustedFlow)
+	report, err := match1.ReportScore(ctx, db, homeCaptain, tm.HomeTeam)
+	require.NoError(t, err)
+
+	// the away team claims they won in three sets
+	awayClaim := ScoreClaim{MainValue: 2, SecondaryValue: 16, OpponentMainValue: 1, OpponentSecondaryValue: 14, Won: true}
+	require.NoError(t, report.Dispute(ctx, db, awayCaptain, tm.AwayTeam, report.ClaimFor(match2.ID, awayClaim), "we took the last two sets"))
+	require.Equal(t, ScoreReportDisputed, report.Status)
+	require.Equal(t, awayClaim, report.ClaimFor(match2.ID, report.Disputed))
+	require.True(t, report.ClaimFor(match1.ID, report.Reported).Won)
+
+	// neither team can change a disputed score
+	require.ErrorIs(t, match1.CheckScoreEditable(ctx, db, tm.HomeTeam), ErrScoreDisputed)
+	_, err = match1.ReportScore(ctx, db, homeCaptain, tm.HomeTeam)
+	require.ErrorIs(t, err, ErrScoreDisputed)
+
+	// the commissioner sides with the away team
+	require.NoError(t, report.Resolve(ctx, db, commissioner, report.ClaimFor(match2.ID, awayClaim)))
+	require.Equal(t, ScoreReportResolved, report.Status)
+	require.Equal(t, commissioner, report.ResolvedBy)
+	match1, err = database.GetExistingRecordById(ctx, db, &IndividualMatch{}, match1.ID.RecordId())
+	require.NoError(t, err)
+	match2, err = database.GetExistingRecordById(ctx, db, &IndividualMatch{}, match2.ID.RecordId())
+	require.NoError(t, err)
+	require.Equal(t, MatchLost, match1.Status)
+	require.Equal(t, MatchWon, match2.Status)
+	require.Equal(t, 16, match2.SecondaryValue)
+	require.Equal(t, 14, match1.SecondaryValue)
+
+	require.ErrorIs(t, report.Resolve(ctx, db, commissioner, awayClaim), ErrScoreFinal)
+}
+
+func TestScoreReportByCommissionerIsFinal(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, match1, match2 := newStoredReportableMatch(t, db)
+	commissioner := newStoredUser(t, db).ID
+
+	recordGames(t, db, match1, match2, sixZeroDustedFlow)
+	report, err := match1.ReportScore(ctx, db, commissioner, TeamId(database.InvalidRecordId))
+	require.NoError(t, err)
+	require.Equal(t, ScoreReportResolved, report.Status)
+	require.Error(t, report.Confirm(ctx, db, newStoredUser(t, db).ID, tm.AwayTeam))
+
+	// deleting the match removes its report
+	_, _, err = database.DeleteOneById(ctx, db, match1, match1.ID.RecordId())
+	require.NoError(t, err)
+	_, exists, err := database.GetOneById(ctx, db, &ScoreReport{}, report.ID.RecordId())
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestScoreReportDoubleDefaultHasNoWinner(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, match1, match2 := newStoredReportableMatch(t, db)
+	homeCaptain, awayCaptain, commissioner := newStoredUser(t, db).ID, newStoredUser(t, db).ID, newStoredUser(t, db).ID
+
+	require.NoError(t, match1.MarkOutcome(ctx, db, OutcomeDoubleDefault, match2))
+	report, err := match1.ReportScore(ctx, db, homeCaptain, tm.HomeTeam)
+	require.NoError(t, err)
+
+	// neither side won, from either point of view
+	for _, side := range []IndividualMatchId{match1.ID, match2.ID} {
+		claim := report.ClaimFor(side, report.Reported)
+		require.True(t, claim.NoWinner)
+		require.False(t, claim.Won)
+	}
+	require.Error(t, ScoreClaim{Won: true, NoWinner: true}.StaticallyValid())
+
+	// the away team says the match was played, and the commissioner agrees:
+	// the match is no longer a double default
+	awayClaim := ScoreClaim{MainValue: 2, SecondaryValue: 12, OpponentSecondaryValue: 3, Won: true}
+	require.NoError(t, report.Dispute(ctx, db, awayCaptain, tm.AwayTeam, report.ClaimFor(match2.ID, awayClaim), "we played it"))
+	require.NoError(t, report.Resolve(ctx, db, commissioner, report.ClaimFor(match2.ID, awayClaim)))
+	match1, err = database.GetExistingRecordById(ctx, db, &IndividualMatch{}, match1.ID.RecordId())
+	require.NoError(t, err)
+	match2, err = database.GetExistingRecordById(ctx, db, &IndividualMatch{}, match2.ID.RecordId())
+	require.NoError(t, err)
+	require.Equal(t, OutcomePlayed, match1.Outcome)
+	require.Equal(t, OutcomePlayed, match2.Outcome)
+	require.Equal(t, MatchLost, match1.Status)
+	require.Equal(t, MatchWon, match2.Status)
+}
+
+func TestScoreReportResolvedAsDoubleDefault(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	tm, match1, match2 := newStoredReportableMatch(t, db)
+	homeCaptain, commissioner := newStoredUser(t, db).ID, newStoredUser(t, db).ID
+
+	recordGames(t, db, match1, match2, sixZeroDustedFlow)
+	report, err := match1.ReportScore(ctx, db, homeCaptain, tm.HomeTeam)
+	require.NoError(t, err)
+	require.NoError(t, report.Resolve(ctx, db, commissioner, ScoreClaim{NoWinner: true}))
+	for _, id := range []IndividualMatchId{match1.ID, match2.ID} {
+		m, err := database.GetExistingRecordById(ctx, db, &IndividualMatch{}, id.RecordId())
+		require.NoError(t, err)
+		require.Equal(t, MatchLost, m.Status)
+		require.Equal(t, OutcomeDoubleDefault, m.Outcome)
+	}
+}
@@ -0,0 +1,241 @@
+package model
+
+import (
+	"context"
+	"fmt"
+
+	"intraclub/database"
+)
+
+// ScoreRule names the ScoringStructure rule a submitted score breaks.
+type ScoreRule string
+
+const (
+	RuleNegative       ScoreRule = "non-negative"    // scores can't be below zero
+	RuleWinner         ScoreRule = "winner"          // a finished score needs exactly one winner
+	RuleWinThreshold   ScoreRule = "win threshold"   // the winner must reach the win threshold
+	RuleWinBy          ScoreRule = "win-by"          // the winner must clear the must-win-by margin
+	RuleInstantWin     ScoreRule = "instant-win"     // neither side can pass the instant-win threshold
+	RulePlayedPastWin  ScoreRule = "played past win" // play stops once a side has won
+	RuleTiebreak       ScoreRule = "tiebreak"        // a score decided by a tiebreak must carry a valid one
+	RuleSecondaryCount ScoreRule = "secondary count" // a match has a fixed number of secondary units
+)
+
+// ScoreValidationError reports which rule of a ScoringStructure a submitted
+// score breaks.
+type ScoreValidationError struct {
+	Rule    ScoreRule
+	Message string
+}
+
+func (e *ScoreValidationError) Error() string {
+	return e.Message
+}
+
+func scoreRuleError(rule ScoreRule, format string, args ...any) error {
+	return &ScoreValidationError{Rule: rule, Message: fmt.Sprintf(format, args...)}
+}
+
+// labelScoreError prefixes a ScoreValidationError's message with the unit it
+// refers to, e.g. "set 2", keeping its rule.
+func labelScoreError(label string, err error) error {
+	if v, ok := err.(*ScoreValidationError); ok {
+		return &ScoreValidationError{Rule: v.Rule, Message: label + ": " + v.Message}
+	}
+	return err
+}
+
+// TiebreakWinCondition is the win condition of the tiebreak played when a unit
+// reaches its instant-win threshold without the must-win-by margin, e.g. a
+// set won 7-6: first to seven points, win by two.
+var TiebreakWinCondition = WinCondition{WinThreshold: 7, MustWinBy: 2}
+
+var tiebreakScoringStructure = &ScoringStructure{
+	WinConditionCountingType: Point,
+	WinCondition:             TiebreakWinCondition,
+}
+
+// scoredName names what a structure counting in this type scores, e.g. a
+// structure counting games scores a set.
+func (s ScoreCountingType) scoredName() string {
+	switch s {
+	case Point:
+		return "game"
+	case Game:
+		return "set"
+	case Set:
+		return "match"
+	default:
+		return "unit"
+	}
+}
+
+// decided reports whether either side has won at this score.
+func (c *ScoringStructure) decided(us, them int) bool {
+	return c.WinningScore(us, them) || c.WinningScore(them, us)
+}
+
+// needsTiebreak reports whether a finished score was decided by a tiebreak:
+// the winner reached the instant-win threshold without the must-win-by
+// margin, as in a 7-6 set.
+func (c *ScoringStructure) needsTiebreak(winner, loser int) bool {
+	w := c.WinCondition
+	return w.HasInstantWinThreshold() && w.WinByTwoOrMore() && winner == w.InstantWinThreshold && winner-loser < w.MustWinBy
+}
+
+// ValidatePartialScore checks that a score could occur while playing under
+// this structure, whether or not it is finished: neither side has passed the
+// instant-win threshold, and play didn't carry on after a side had won.
+func (c *ScoringStructure) ValidatePartialScore(us, them int) error {
+	if us < 0 || them < 0 {
+		return scoreRuleError(RuleNegative, "%d-%d breaks the %s rule: scores can't be negative", us, them, RuleNegative)
+	}
+	winner, loser := max(us, them), min(us, them)
+	unit, scored := c.WinConditionCountingType, c.WinConditionCountingType.scoredName()
+	w := c.WinCondition
+	if w.HasInstantWinThreshold() && winner > w.InstantWinThreshold {
+		return scoreRuleError(RuleInstantWin, "%d-%d breaks the %s rule: a %s ends as soon as a side reaches %d %ss", us, them, RuleInstantWin, scored, w.InstantWinThreshold, unit)
+	}
+	if winner > 0 && c.decided(winner-1, loser) {
+		return scoreRuleError(RulePlayedPastWin, "%d-%d breaks the %s rule: the %s was already won at %d-%d", us, them, RulePlayedPastWin, scored, winner-1, loser)
+	}
+	return nil
+}
+
+// ValidateFinalScore checks that a score is a finished result under this
+// structure: exactly one side has won, by reaching the win threshold with the
+// must-win-by margin or by reaching the instant-win threshold, and the score
+// could have occurred (see ValidatePartialScore).
+func (c *ScoringStructure) ValidateFinalScore(us, them int) error {
+	if err := c.ValidatePartialScore(us, them); err != nil {
+		return err
+	}
+	winner, loser := max(us, them), min(us, them)
+	unit, scored := c.WinConditionCountingType, c.WinConditionCountingType.scoredName()
+	w := c.WinCondition
+	switch {
+	case c.WinningScore(winner, loser):
+		return nil
+	case winner == loser:
+		return scoreRuleError(RuleWinner, "%d-%d breaks the %s rule: a finished %s can't be tied", us, them, RuleWinner, scored)
+	case winner < w.WinThreshold:
+		return scoreRuleError(RuleWinThreshold, "%d-%d breaks the %s rule: the winner must reach %d %ss", us, them, RuleWinThreshold, w.WinThreshold, unit)
+	case w.HasInstantWinThreshold():
+		return scoreRuleError(RuleWinBy, "%d-%d breaks the %s-%d rule: the winner must lead by %d %ss or reach %d", us, them, RuleWinBy, w.MustWinBy, w.MustWinBy, unit, w.InstantWinThreshold)
+	default:
+		return scoreRuleError(RuleWinBy, "%d-%d breaks the %s-%d rule: the winner must lead by %d %ss", us, them, RuleWinBy, w.MustWinBy, w.MustWinBy, unit)
+	}
+}
+
+// SecondaryScore is the final score of one secondary unit of a match, e.g. a
+// set in games, from the submitting side's point of view. A unit decided by a
+// tiebreak (see TiebreakWinCondition) also carries the tiebreak's points.
+type SecondaryScore struct {
+	Us           int `json:"us"`
+	Them         int `json:"them"`
+	TiebreakUs   int `json:"tiebreak_us,omitempty"`
+	TiebreakThem int `json:"tiebreak_them,omitempty"`
+}
+
+func (s SecondaryScore) hasTiebreak() bool {
+	return s.TiebreakUs != 0 || s.TiebreakThem != 0
+}
+
+// ValidateSecondaryScore checks a finished unit scored under this structure,
+// including its tiebreak: a unit decided by a tiebreak must carry a finished
+// tiebreak won by the unit's winner, and any other unit must not carry one.
+func (c *ScoringStructure) ValidateSecondaryScore(score SecondaryScore) error {
+	if err := c.ValidateFinalScore(score.Us, score.Them); err != nil {
+		return err
+	}
+	scored := c.WinConditionCountingType.scoredName()
+	winner, loser := max(score.Us, score.Them), min(score.Us, score.Them)
+	if !c.needsTiebreak(winner, loser) {
+		w := c.WinCondition
+		if score.hasTiebreak() && w.HasInstantWinThreshold() && w.WinByTwoOrMore() {
+			return scoreRuleError(RuleTiebreak, "%d-%d breaks the %s rule: only a %s won %d-%d has a tiebreak", score.Us, score.Them, RuleTiebreak, scored, w.InstantWinThreshold, w.InstantWinThreshold-1)
+		}
+		if score.hasTiebreak() {
+			return scoreRuleError(RuleTiebreak, "%d-%d breaks the %s rule: a %s scored this way has no tiebreak", score.Us, score.Them, RuleTiebreak, scored)
+		}
+		return nil
+	}
+	if !score.hasTiebreak() {
+		return scoreRuleError(RuleTiebreak, "%d-%d breaks the %s rule: the %s went to a tiebreak, so its tiebreak score is required", score.Us, score.Them, RuleTiebreak, scored)
+	}
+	if err := tiebreakScoringStructure.ValidateFinalScore(score.TiebreakUs, score.TiebreakThem); err != nil {
+		return labelScoreError(fmt.Sprintf("%d-%d tiebreak", score.Us, score.Them), err)
+	}
+	if (score.TiebreakUs > score.TiebreakThem) != (score.Us > score.Them) {
+		return scoreRuleError(RuleTiebreak, "%d-%d (%d-%d) breaks the %s rule: the tiebreak must be won by the side that won the %s", score.Us, score.Them, score.TiebreakUs, score.TiebreakThem, RuleTiebreak, scored)
+	}
+	return nil
+}
+
+// ValidateScore checks this side's score against its opponent's under the
+// match's ScoringStructure, returning a ScoreValidationError naming the rule
+// that is broken. A final score must be a finished result, while any other
+// score need only be one that could occur in play. For a structure without
+// secondary structures, a result decided by a tiebreak (e.g. a 7-6 set) takes
+// the tiebreak's points from each side's SecondaryValue. A match with no
+// opponent is checked against a score of zero, and a WinOverride bypasses
+// validation altogether.
+func (s *IndividualMatch) ValidateScore(ctx context.Context, db database.Provider, opp *IndividualMatch, final bool) error {
+	if s.WinOverride || (opp != nil && opp.WinOverride) {
+		return nil
+	}
+	if err := s.Initialize(ctx, db); err != nil {
+		return err
+	}
+	if opp == nil {
+		return s._structure.ValidatePartialScore(s.MainValue, 0)
+	}
+	if !final {
+		return s._structure.ValidatePartialScore(s.MainValue, opp.MainValue)
+	}
+	if s._isComposite {
+		return s._structure.ValidateFinalScore(s.MainValue, opp.MainValue)
+	}
+	score := SecondaryScore{Us: s.MainValue, Them: opp.MainValue}
+	if s._structure.needsTiebreak(max(score.Us, score.Them), min(score.Us, score.Them)) {
+		score.TiebreakUs, score.TiebreakThem = s.SecondaryValue, opp.SecondaryValue
+	}
+	return s._structure.ValidateSecondaryScore(score)
+}
+
+// ScoreFromSecondaries validates the finished secondary units of a composite
+// match, e.g. its sets in games, in the order they were played, against each
+// unit's secondary structure and the match's own win condition. It returns the
+// resulting score from this side's point of view: main values count the units
+// won and secondary values the total secondary units won across them.
+func (s *IndividualMatch) ScoreFromSecondaries(ctx context.Context, db database.Provider, scores []SecondaryScore) (ScoreClaim, error) {
+	claim := ScoreClaim{}
+	if err := s.Initialize(ctx, db); err != nil {
+		return claim, err
+	}
+	unit := s._structure.WinConditionCountingType
+	if !s._isComposite {
+		return claim, scoreRuleError(RuleSecondaryCount, "the match breaks the %s rule: it isn't scored in %ss", RuleSecondaryCount, unit)
+	}
+	if len(scores) > len(s._subStructures) {
+		return claim, scoreRuleError(RuleSecondaryCount, "%d %ss break the %s rule: at most %d can be played", len(scores), unit, RuleSecondaryCount, len(s._subStructures))
+	}
+	for i, score := range scores {
+		label := fmt.Sprintf("%s %d", unit, i+1)
+		if s._structure.decided(claim.MainValue, claim.OpponentMainValue) {
+			return claim, scoreRuleError(RulePlayedPastWin, "%s breaks the %s rule: the match was already won %d-%d", label, RulePlayedPastWin, claim.MainValue, claim.OpponentMainValue)
+		}
+		if err := s._subStructures[i].ValidateSecondaryScore(score); err != nil {
+			return claim, labelScoreError(label, err)
+		}
+		if score.Us > score.Them {
+			claim.MainValue++
+		} else {
+			claim.OpponentMainValue++
+		}
+		claim.SecondaryValue += score.Us
+		claim.OpponentSecondaryValue += score.Them
+	}
+	claim.Won = s._structure.WinningScore(claim.MainValue, claim.OpponentMainValue)
+	return claim, nil
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+// requireScoreRule asserts that err is a ScoreValidationError for the rule.
+func requireScoreRule(t *testing.T, err error, rule ScoreRule) {
+	t.Helper()
+	var v *ScoreValidationError
+	require.True(t, errors.As(err, &v), "expected a score validation error, got %v", err)
+	require.Equal(t, rule, v.Rule, v.Message)
+}
+
+func TestValidateSecondaryScore(t *testing.T) {
+	set := &TennisSetScoringStructure
+
+	for _, score := range []SecondaryScore{
+		{Us: 6, Them: 4},
+		{Us: 7, Them: 5},
+		{Us: 3, Them: 6},
+		{Us: 7, Them: 6, TiebreakUs: 7, TiebreakThem: 5},
+		{Us: 6, Them: 7, TiebreakUs: 10, TiebreakThem: 12},
+	} {
+		require.NoError(t, set.ValidateSecondaryScore(score), "%+v", score)
+	}
+
+	for _, tc := range []struct {
+		score SecondaryScore
+		rule  ScoreRule
+	}{
+		{SecondaryScore{Us: 6, Them: 5}, RuleWinBy},
+		{SecondaryScore{Us: 5, Them: 3}, RuleWinThreshold},
+		{SecondaryScore{Us: 6, Them: 6}, RuleWinner},
+		{SecondaryScore{Us: 8, Them: 6}, RuleInstantWin},
+		{SecondaryScore{Us: 7, Them: 3}, RulePlayedPastWin},
+		{SecondaryScore{Us: 7, Them: 6}, RuleTiebreak},
+		{SecondaryScore{Us: 6, Them: 4, TiebreakUs: 7, TiebreakThem: 5}, RuleTiebreak},
+		{SecondaryScore{Us: 7, Them: 6, TiebreakUs: 4, TiebreakThem: 7}, RuleTiebreak},
+		{SecondaryScore{Us: 7, Them: 6, TiebreakUs: 7, TiebreakThem: 6}, RuleWinBy},
+		{SecondaryScore{Us: 7, Them: 6, TiebreakUs: 9, TiebreakThem: 5}, RulePlayedPastWin},
+	} {
+		requireScoreRule(t, set.ValidateSecondaryScore(tc.score), tc.rule)
+	}
+
+	err := set.ValidateSecondaryScore(SecondaryScore{Us: 6, Them: 5})
+	require.EqualError(t, err, "6-5 breaks the win-by-2 rule: the winner must lead by 2 games or reach 7")
+	err = set.ValidateSecondaryScore(SecondaryScore{Us: 8, Them: 6})
+	require.EqualError(t, err, "8-6 breaks the instant-win rule: a set ends as soon as a side reaches 7 games")
+
+	// a partial score only has to be one that could occur in play
+	require.NoError(t, set.ValidatePartialScore(6, 5))
+	require.NoError(t, set.ValidatePartialScore(6, 6))
+	requireScoreRule(t, set.ValidatePartialScore(7, 3), RulePlayedPastWin)
+}
+
+func TestScoreFromSecondaries(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	match1, _ := newStoredMatchPair(t, db, newThirdSetTiebreakScoringStructure(t, db))
+
+	claim, err := match1.ScoreFromSecondaries(ctx, db, []SecondaryScore{
+		{Us: 6, Them: 4},
+		{Us: 6, Them: 7, TiebreakUs: 3, TiebreakThem: 7},
+		{Us: 12, Them: 10},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScoreClaim{MainValue: 2, SecondaryValue: 24, OpponentMainValue: 1, OpponentSecondaryValue: 21, Won: true}, claim)
+
+	// the third set is a ten-point tiebreak rather than a set in games
+	_, err = match1.ScoreFromSecondaries(ctx, db, []SecondaryScore{{Us: 6, Them: 4}, {Us: 4, Them: 6}, {Us: 6, Them: 4}})
+	requireScoreRule(t, err, RuleWinThreshold)
+	require.EqualError(t, err, "set 3: 6-4 breaks the win threshold rule: the winner must reach 10 games")
+
+	_, err = match1.ScoreFromSecondaries(ctx, db, []SecondaryScore{{Us: 6, Them: 0}, {Us: 6, Them: 0}, {Us: 10, Them: 0}})
+	requireScoreRule(t, err, RulePlayedPastWin)
+
+	_, err = match1.ScoreFromSecondaries(ctx, db, []SecondaryScore{{Us: 6, Them: 5}})
+	requireScoreRule(t, err, RuleWinBy)
+	require.Contains(t, err.Error(), "set 1: ")
+
+	// a single set has no secondary units of its own to list
+	lone, _ := newStoredMatchPair(t, db, newDefaultStoredSetScoringStructure(t, db))
+	_, err = lone.ScoreFromSecondaries(ctx, db, []SecondaryScore{{Us: 6, Them: 4}})
+	requireScoreRule(t, err, RuleSecondaryCount)
+}
+
+func TestValidateScore(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	match1, match2 := newStoredMatchPair(t, db, newDefaultStoredScoringStructure(t, db))
+
+	match1.MainValue, match2.MainValue = 2, 1
+	require.NoError(t, match1.ValidateScore(ctx, db, match2, true))
+	match1.MainValue = 1
+	require.NoError(t, match1.ValidateScore(ctx, db, match2, false))
+	requireScoreRule(t, match1.ValidateScore(ctx, db, match2, true), RuleWinner)
+	match1.MainValue = 3
+	requireScoreRule(t, match1.ValidateScore(ctx, db, match2, false), RulePlayedPastWin)
+
+	// a win override bypasses the scoring structure
+	match1.WinOverride = true
+	require.NoError(t, match1.ValidateScore(ctx, db, match2, true))
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"context"
+	"strings"
+
+	"intraclub/database"
+)
+
+// searchableRecords maps each kind kept in the full-text index to a blank
+// record of that type, used to load hits back from the database.
+var searchableRecords = map[string]func() database.Searchable{
+	(&RuleSection{}).Type(): func() database.Searchable { return &RuleSection{} },
+	(&Blurb{}).Type():       func() database.Searchable { return &Blurb{} },
+	(&Comment{}).Type():     func() database.Searchable { return &Comment{} },
+}
+
+// SearchResult is one rule section, blurb or comment matching a full-text
+// search, along with the record itself.
+type SearchResult struct {
+	Kind   string              `json:"kind"`
+	Id     database.RecordId   `json:"id"`
+	Title  string              `json:"title"`
+	Record database.CrudRecord `json:"record"`
+}
+
+// Search returns up to limit rule sections, blurbs and comments containing
+// every word of the query (each word may be the start of a longer one), best
+// match first. Only records the user can access are returned, and rule
+// sections only from current revisions. A limit of zero or less returns every
+// match.
+func Search(ctx context.Context, db database.Provider, userId database.UserId, query string, limit int) ([]*SearchResult, error) {
+	hits, err := database.Search(ctx, db, strings.TrimSpace(query), 0)
+	if err != nil {
+		return nil, err
+	}
+	access := database.NewWithAccessControl[database.CrudRecord](ctx, db, userId)
+	results := make([]*SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if limit > 0 && len(results) == limit {
+			break
+		}
+		blank, ok := searchableRecords[hit.Kind]
+		if !ok {
+			continue
+		}
+		record, exists, err := database.GetOneById(ctx, db, blank(), hit.Id)
+		if err != nil {
+			return nil, err
+		}
+		// a record removed without its lifecycle hooks can leave a stale
+		// entry behind; skip it rather than fail the search
+		if !exists || !access.CanUserAccess(record) {
+			continue
+		}
+		// the sections of superseded revisions stay indexed for as long as
+		// they exist, but are outdated rules rather than current ones
+		if section, ok := record.(*RuleSection); ok {
+			superseded, err := section.Superseded(ctx, db)
+			if err != nil {
+				return nil, err
+			}
+			if superseded {
+				continue
+			}
+		}
+		results = append(results, &SearchResult{
+			Kind:   hit.Kind,
+			Id:     hit.Id,
+			Title:  record.SearchDocument().Title,
+			Record: record,
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,196 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"intraclub/database"
+)
+
+func searchKinds(t *testing.T, db database.Provider, user database.UserId, query string) map[string]int {
+	t.Helper()
+	results, err := Search(context.Background(), db, user, query, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]int{}
+	for _, result := range results {
+		kinds[result.Kind]++
+	}
+	return kinds
+}
+
+func TestSearchFollowsLifecycleHooks(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	blurb, season := newDefaultBlurb(t, db)
+	captain := getAnyTeamCaptain(t, db, season)
+
+	ruleset := newValidStoredRuleset(t, db)
+	section, err := database.CreateOne(ctx, db, &RuleSection{
+		Parent:   ruleset.ID,
+		Owner:    ruleset.Owner,
+		Title:    "Weather",
+		Markdown: "Matches rained out are rescheduled by the captains.",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blurb.Title = "Rain delay"
+	blurb.Content = "Week 3 was rained out."
+	if err := database.UpdateOne(ctx, db, blurb); err != nil {
+		t.Fatal(err)
+	}
+	comment := newValidComment(captain, blurb.ID)
+	comment.Content = "Hoping the rain holds off next week"
+	comment, err = database.CreateOne(ctx, db, comment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := searchKinds(t, db, captain, "rain")
+	if kinds["rule_section"] != 1 || kinds["blurb"] != 1 || kinds["comment"] != 1 {
+		t.Fatalf("expected one of each kind for 'rain', got %v", kinds)
+	}
+	if kinds := searchKinds(t, db, captain, "rained out"); len(kinds) != 2 || kinds["comment"] != 0 {
+		t.Fatalf("expected the section and blurb for 'rained out', got %v", kinds)
+	}
+
+	// updates replace the indexed text
+	comment.Content = "Sunny at last"
+	if err := database.UpdateOne(ctx, db, comment); err != nil {
+		t.Fatal(err)
+	}
+	if kinds := searchKinds(t, db, captain, "rain"); kinds["comment"] != 0 {
+		t.Fatalf("expected the edited comment to no longer match, got %v", kinds)
+	}
+	if kinds := searchKinds(t, db, captain, "sunny"); kinds["comment"] != 1 {
+		t.Fatalf("expected the edited comment to match 'sunny', got %v", kinds)
+	}
+
+	// deletes remove it
+	if _, _, err := database.DeleteOneById(ctx, db, &RuleSection{}, section.ID.RecordId()); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := database.DeleteOneById(ctx, db, &Blurb{}, blurb.ID.RecordId()); err != nil {
+		t.Fatal(err)
+	}
+	if kinds := searchKinds(t, db, captain, "rain"); len(kinds) != 0 {
+		t.Fatalf("expected no results after deleting, got %v", kinds)
+	}
+}
+
+func TestSearchSkipsStaleHitsAndRespectsLimit(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	blurb, season := newDefaultBlurb(t, db)
+	captain := getAnyTeamCaptain(t, db, season)
+	for i := 0; i < 3; i++ {
+		newStoredComment(t, db, captain, blurb)
+	}
+	if kinds := searchKinds(t, db, captain, "content"); kinds["comment"] != 3 || kinds["blurb"] != 1 {
+		t.Fatalf("expected 3 comments and the blurb, got %v", kinds)
+	}
+
+	// a raw delete skips PostDelete, leaving the index entry behind
+	if err := db.Delete(ctx, blurb); err != nil {
+		t.Fatal(err)
+	}
+	if kinds := searchKinds(t, db, captain, "content"); kinds["blurb"] != 0 {
+		t.Fatalf("expected the stale blurb hit to be skipped, got %v", kinds)
+	}
+
+	results, err := Search(ctx, db, captain, "content", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+}
+
+func TestSearchSkipsStaleCommentHits(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	blurb, season := newDefaultBlurb(t, db)
+	captain := getAnyTeamCaptain(t, db, season)
+	comments := make([]*Comment, 0)
+	for i := 0; i < 3; i++ {
+		comments = append(comments, newStoredComment(t, db, captain, blurb))
+	}
+
+	if err := db.Delete(ctx, comments[0]); err != nil {
+		t.Fatal(err)
+	}
+	if kinds := searchKinds(t, db, captain, "content"); kinds["comment"] != 2 || kinds["blurb"] != 1 {
+		t.Fatalf("expected the stale comment hit to be skipped, got %v", kinds)
+	}
+
+	// the comments left behind by a deleted blurb are still readable by
+	// everyone, while those left behind by a proposal are readable by nobody
+	if err := db.Delete(ctx, blurb); err != nil {
+		t.Fatal(err)
+	}
+	outsider := newStoredUser(t, db)
+	if kinds := searchKinds(t, db, outsider.ID, "content"); kinds["comment"] != 2 {
+		t.Fatalf("expected the orphaned blurb comments to stay visible, got %v", kinds)
+	}
+	proposalSeason, proposal := newStoredCommissionerProposal(t, db, false)
+	voter := getAnyTeamCaptain(t, db, proposalSeason)
+	orphan := newStoredProposalComment(t, db, voter, proposal, "orphaned")
+	if err := db.Delete(ctx, proposal); err != nil {
+		t.Fatal(err)
+	}
+	if kinds := searchKinds(t, db, voter, "orphaned"); len(kinds) != 0 {
+		t.Fatalf("expected the orphaned proposal comment %s to be hidden, got %v", orphan.ID, kinds)
+	}
+}
+
+func TestSearchFindsOnlyCurrentRules(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	user := newStoredUser(t, db)
+	ruleset, err := newValidStoredRuleset(t, db).Amend(ctx, db, &RuleAmendment{
+		Type:       RuleAmendmentTypeAddSection,
+		NewSection: RuleSection{Title: "Scoring", Markdown: "Matches are best of three sets."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections, err := ruleset.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a title-only change is searchable straight away
+	ruleset, err = ruleset.Amend(ctx, db, &RuleAmendment{
+		Type:          RuleAmendmentTypeModifySection,
+		TargetSection: sections[0],
+		NewSection:    RuleSection{Title: "Tiebreaks", Markdown: "Matches are best of three sets."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kinds := searchKinds(t, db, user.ID, "tiebreaks"); kinds["rule_section"] != 1 {
+		t.Fatalf("expected the renamed section to match its new title, got %v", kinds)
+	}
+
+	// the rule text of a superseded revision is not
+	sections, err = ruleset.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ruleset.Amend(ctx, db, &RuleAmendment{
+		Type:          RuleAmendmentTypeModifySection,
+		TargetSection: sections[0],
+		NewSection:    RuleSection{Title: "Tiebreaks", Markdown: "Matches are two sets and a match tiebreak."},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if kinds := searchKinds(t, db, user.ID, "three sets"); len(kinds) != 0 {
+		t.Fatalf("expected the superseded rule text not to match, got %v", kinds)
+	}
+	if kinds := searchKinds(t, db, user.ID, "match tiebreak"); kinds["rule_section"] != 1 {
+		t.Fatalf("expected the current rule text to match, got %v", kinds)
+	}
+}
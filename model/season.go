@@ -85,6 +85,9 @@ type Season struct {
 	ScheduleID       ScheduleId         `json:"schedule_id"`   // ID of the Schedule for this Season
 	PlayoffStructure PlayoffStructureId `json:"playoff_structure"` // ID of the PlayoffStructure for the Season
 	Owner            database.UserId    `json:"owner"`         // commissioner who owns this season
+	BlindLineups     bool               `json:"blind_lineups"` // hide each team's lineup from its opponent until both are confirmed
+	Ruleset          RulesetId          `json:"ruleset"`           // ruleset the season is played under; the exact revision in force once pinned
+	RulesetPinnedAt  time.Time          `json:"ruleset_pinned_at"` // when the ruleset revision was pinned at the season's start, zero until then
 }
 
 func (s *Season) GetOwner() database.UserId {
@@ -182,6 +185,13 @@ func (s *Season) DynamicallyValid(ctx context.Context, db database.Provider) err
 		}
 	}
 
+	if !s.Ruleset.Empty() {
+		err := database.ExistsById(ctx, db, &Ruleset{}, s.Ruleset.RecordId())
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"context"
+	"fmt"
+
+	"intraclub/database"
+)
+
+// SeasonLineupRule enables a registered LineupRule for a Season. Rules are
+// off unless enabled; a commissioner enables a rule by creating the record
+// and disables it again by deleting it.
+type SeasonLineupRule struct {
+	ID       database.RecordId `json:"id"`
+	SeasonId SeasonId          `json:"season_id"`
+	Rule     LineupRuleName    `json:"rule"`
+}
+
+func NewSeasonLineupRule() *SeasonLineupRule {
+	return &SeasonLineupRule{}
+}
+
+func (s *SeasonLineupRule) GetOwner() database.UserId {
+	return database.InvalidUserId
+}
+
+func (s *SeasonLineupRule) SetOwner(userId database.UserId) {
+	// ownership is enforced by season commissioner status
+}
+
+func (s *SeasonLineupRule) UniquenessEquivalent(other *SeasonLineupRule) error {
+	if s.SeasonId == other.SeasonId && s.Rule == other.Rule {
+		return fmt.Errorf("lineup rule %s is already enabled for season %s", s.Rule, s.SeasonId)
+	}
+	return nil
+}
+
+func (s *SeasonLineupRule) Type() string {
+	return "season_lineup_rule"
+}
+
+func (s *SeasonLineupRule) GetId() database.RecordId {
+	return s.ID
+}
+
+func (s *SeasonLineupRule) SetId(id database.RecordId) {
+	s.ID = id
+}
+
+func (s *SeasonLineupRule) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
+	return EditableBySeason(ctx, db, s.SeasonId)
+}
+
+// AccessibleTo returns everyone, so captains can see which rules their
+// lineups will be checked against.
+func (s *SeasonLineupRule) AccessibleTo(_ context.Context, _ database.Provider) []database.UserId {
+	return database.AccessibleToEveryone
+}
+
+func (s *SeasonLineupRule) StaticallyValid() error {
+	if _, ok := GetLineupRule(s.Rule); !ok {
+		return fmt.Errorf("unknown lineup rule '%s'", s.Rule)
+	}
+	return nil
+}
+
+func (s *SeasonLineupRule) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	return database.ExistsById(ctx, db, &Season{}, s.SeasonId.RecordId())
+}
+
+func (s *SeasonLineupRule) NewRecord() database.CrudRecord {
+	return new(SeasonLineupRule)
+}
+
+// GetEnabledLineupRules returns the LineupRules enabled for the Season, sorted
+// by name.
+func GetEnabledLineupRules(ctx context.Context, db database.Provider, seasonId SeasonId) ([]LineupRule, error) {
+	records, err := database.GetAllWhere[*SeasonLineupRule](ctx, db, func(_ context.Context, s *SeasonLineupRule) bool {
+		return s.SeasonId == seasonId
+	})
+	if err != nil {
+		return nil, err
+	}
+	enabled := make(map[LineupRuleName]bool, len(records))
+	for _, record := range records {
+		enabled[record.Rule] = true
+	}
+	rules := make([]LineupRule, 0, len(enabled))
+	for _, rule := range GetLineupRules() {
+		if enabled[rule.Name()] {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// EnableLineupRule enables the named LineupRule for the Season. Enabling an
+// already-enabled rule is a no-op.
+func EnableLineupRule(ctx context.Context, db database.Provider, seasonId SeasonId, name LineupRuleName) error {
+	existing, err := database.GetAllWhere[*SeasonLineupRule](ctx, db, func(_ context.Context, s *SeasonLineupRule) bool {
+		return s.SeasonId == seasonId && s.Rule == name
+	})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+	_, err = database.CreateOne(ctx, db, &SeasonLineupRule{SeasonId: seasonId, Rule: name})
+	return err
+}
+
+// DisableLineupRule disables the named LineupRule for the Season. Disabling a
+// rule that isn't enabled is a no-op.
+func DisableLineupRule(ctx context.Context, db database.Provider, seasonId SeasonId, name LineupRuleName) error {
+	existing, err := database.GetAllWhere[*SeasonLineupRule](ctx, db, func(_ context.Context, s *SeasonLineupRule) bool {
+		return s.SeasonId == seasonId && s.Rule == name
+	})
+	if err != nil {
+		return err
+	}
+	for _, record := range existing {
+		if _, _, err := database.DeleteOneById(ctx, db, record, record.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
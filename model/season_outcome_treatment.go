@@ -0,0 +1,122 @@
+package model
+
+import (
+	"context"
+	"fmt"
+
+	"intraclub/database"
+)
+
+// SeasonOutcomeTreatment overrides the OutcomeTreatment of a MatchOutcome for
+// a Season. Outcomes without a record use DefaultOutcomeTreatment; a
+// commissioner sets a treatment by creating or updating the record.
+type SeasonOutcomeTreatment struct {
+	ID                       database.RecordId `json:"id"`
+	SeasonId                 SeasonId          `json:"season_id"`
+	Outcome                  MatchOutcome      `json:"outcome"`
+	CountsTowardRecord       bool              `json:"counts_toward_record"`
+	CountsTowardDifferential bool              `json:"counts_toward_differential"`
+}
+
+func NewSeasonOutcomeTreatment() *SeasonOutcomeTreatment {
+	return &SeasonOutcomeTreatment{}
+}
+
+func (s *SeasonOutcomeTreatment) GetOwner() database.UserId {
+	return database.InvalidUserId
+}
+
+func (s *SeasonOutcomeTreatment) SetOwner(userId database.UserId) {
+	// ownership is enforced by season commissioner status
+}
+
+func (s *SeasonOutcomeTreatment) UniquenessEquivalent(other *SeasonOutcomeTreatment) error {
+	if s.SeasonId == other.SeasonId && s.Outcome == other.Outcome {
+		return fmt.Errorf("season %s already has a treatment for %s", s.SeasonId, s.Outcome)
+	}
+	return nil
+}
+
+func (s *SeasonOutcomeTreatment) Type() string {
+	return "season_outcome_treatment"
+}
+
+func (s *SeasonOutcomeTreatment) GetId() database.RecordId {
+	return s.ID
+}
+
+func (s *SeasonOutcomeTreatment) SetId(id database.RecordId) {
+	s.ID = id
+}
+
+func (s *SeasonOutcomeTreatment) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
+	return EditableBySeason(ctx, db, s.SeasonId)
+}
+
+// AccessibleTo returns everyone, so players can see how their results will
+// count.
+func (s *SeasonOutcomeTreatment) AccessibleTo(_ context.Context, _ database.Provider) []database.UserId {
+	return database.AccessibleToEveryone
+}
+
+func (s *SeasonOutcomeTreatment) StaticallyValid() error {
+	if !s.Outcome.Valid() {
+		return fmt.Errorf("match outcome %d is not valid", s.Outcome)
+	}
+	if s.Outcome == OutcomePlayed {
+		return fmt.Errorf("the treatment of %s matches can't be changed", OutcomePlayed)
+	}
+	return nil
+}
+
+func (s *SeasonOutcomeTreatment) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	return database.ExistsById(ctx, db, &Season{}, s.SeasonId.RecordId())
+}
+
+func (s *SeasonOutcomeTreatment) NewRecord() database.CrudRecord {
+	return new(SeasonOutcomeTreatment)
+}
+
+// GetOutcomeTreatments returns the OutcomeTreatments configured for the
+// Season. Use OutcomeTreatments.For to fall back to the defaults.
+func GetOutcomeTreatments(ctx context.Context, db database.Provider, seasonId SeasonId) (OutcomeTreatments, error) {
+	records, err := database.GetAllWhere[*SeasonOutcomeTreatment](ctx, db, func(_ context.Context, s *SeasonOutcomeTreatment) bool {
+		return s.SeasonId == seasonId
+	})
+	if err != nil {
+		return nil, err
+	}
+	treatments := make(OutcomeTreatments, len(records))
+	for _, record := range records {
+		treatments[record.Outcome] = OutcomeTreatment{
+			CountsTowardRecord:       record.CountsTowardRecord,
+			CountsTowardDifferential: record.CountsTowardDifferential,
+		}
+	}
+	return treatments, nil
+}
+
+// SetOutcomeTreatment configures the treatment of an outcome for the Season,
+// replacing any treatment set before.
+func SetOutcomeTreatment(ctx context.Context, db database.Provider, seasonId SeasonId, outcome MatchOutcome, treatment OutcomeTreatment) error {
+	existing, err := database.GetAllWhere[*SeasonOutcomeTreatment](ctx, db, func(_ context.Context, s *SeasonOutcomeTreatment) bool {
+		return s.SeasonId == seasonId && s.Outcome == outcome
+	})
+	if err != nil {
+		return err
+	}
+	record := &SeasonOutcomeTreatment{
+		SeasonId:                 seasonId,
+		Outcome:                  outcome,
+		CountsTowardRecord:       treatment.CountsTowardRecord,
+		CountsTowardDifferential: treatment.CountsTowardDifferential,
+	}
+	// career statistics count outcomes as the season treats them
+	defer InvalidateCareerStats()
+	if len(existing) == 0 {
+		_, err = database.CreateOne(ctx, db, record)
+		return err
+	}
+	record.ID = existing[0].ID
+	return database.UpdateOne(ctx, db, record)
+}
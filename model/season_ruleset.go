@@ -0,0 +1,214 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"intraclub/database"
+)
+
+// A Season is played under a Ruleset. Until the season starts its
+// commissioners may assign any ruleset, and the latest revision of the
+// assigned ruleset applies. When the season starts that exact revision is
+// pinned: later revisions of the ruleset, however they are made, only become
+// binding for the season when an accepted commissioner proposal applies its
+// amendments to the pinned lineage (see AdoptRevision).
+
+// ErrSeasonRulesetPinned is returned when assigning a ruleset to a season
+// whose ruleset revision has already been pinned.
+var ErrSeasonRulesetPinned = errors.New("the season has started; its ruleset can only change through an accepted commissioner proposal")
+
+// RulesetPinned reports whether the season's ruleset revision has been pinned.
+func (s *Season) RulesetPinned() bool {
+	return !s.RulesetPinnedAt.IsZero()
+}
+
+// StartsAt returns when the season's first week kicks off: the earliest Week
+// date at the season's StartTime. The bool is false if the season has no
+// weeks scheduled yet.
+func (s *Season) StartsAt(ctx context.Context, db database.Provider) (time.Time, bool, error) {
+	if s.DraftId.RecordId() == database.InvalidRecordId {
+		return time.Time{}, false, nil
+	}
+	weeks, err := database.GetAllWhere[*Week](ctx, db, func(_ context.Context, w *Week) bool {
+		return w.DraftId == s.DraftId
+	})
+	if err != nil || len(weeks) == 0 {
+		return time.Time{}, false, err
+	}
+	first := weeks[0].Date
+	for _, w := range weeks[1:] {
+		if w.Date.Before(first) {
+			first = w.Date
+		}
+	}
+	kickoff := time.Time(s.StartTime)
+	return time.Date(first.Year(), first.Month(), first.Day(), kickoff.Hour(), kickoff.Minute(), 0, 0, first.Location()), true, nil
+}
+
+// Started reports whether the season's first week has kicked off by now.
+func (s *Season) Started(ctx context.Context, db database.Provider, now time.Time) (bool, error) {
+	start, scheduled, err := s.StartsAt(ctx, db)
+	if err != nil || !scheduled {
+		return false, err
+	}
+	return !now.Before(start), nil
+}
+
+// seasonRulesetMu serializes changes to season rulesets. Seasons are pinned
+// in the background (see PinStartedSeasonRulesets) while commissioners assign
+// rulesets and accepted proposals adopt revisions.
+var seasonRulesetMu sync.Mutex
+
+// changeRuleset re-reads the season and applies change to the current copy,
+// storing it if change reports that it changed anything, so a change made
+// from a stale copy of the season never overwrites another. s is refreshed
+// with the result.
+func (s *Season) changeRuleset(ctx context.Context, db database.Provider, change func(current *Season) (bool, error)) (bool, error) {
+	seasonRulesetMu.Lock()
+	defer seasonRulesetMu.Unlock()
+	current, err := database.GetExistingRecordById(ctx, db, &Season{}, s.ID.RecordId())
+	if err != nil {
+		return false, err
+	}
+	updated := *current
+	changed, err := change(&updated)
+	if err != nil {
+		return false, err
+	}
+	if !changed {
+		*s = *current
+		return false, nil
+	}
+	if err := database.UpdateOne(ctx, db, &updated); err != nil {
+		return false, err
+	}
+	*s = updated
+	return true, nil
+}
+
+// AssignRuleset sets the ruleset the season is played under, or clears it
+// with an empty RulesetId. It is refused once the season has started.
+func (s *Season) AssignRuleset(ctx context.Context, db database.Provider, rulesetId RulesetId, now time.Time) error {
+	if !rulesetId.Empty() {
+		if err := database.ExistsById(ctx, db, &Ruleset{}, rulesetId.RecordId()); err != nil {
+			return err
+		}
+	}
+	_, err := s.changeRuleset(ctx, db, func(current *Season) (bool, error) {
+		if current.RulesetPinned() {
+			return false, ErrSeasonRulesetPinned
+		}
+		started, err := current.Started(ctx, db, now)
+		if err != nil {
+			return false, err
+		}
+		if started {
+			return false, ErrSeasonRulesetPinned
+		}
+		current.Ruleset = rulesetId
+		return true, nil
+	})
+	return err
+}
+
+// PinRuleset pins the latest revision of the season's assigned ruleset once
+// the season has started. It reports whether it pinned anything: not if no
+// ruleset is assigned, it is already pinned, or the season hasn't started.
+func (s *Season) PinRuleset(ctx context.Context, db database.Provider, now time.Time) (bool, error) {
+	if s.Ruleset.Empty() || s.RulesetPinned() {
+		return false, nil
+	}
+	return s.changeRuleset(ctx, db, func(current *Season) (bool, error) {
+		if current.Ruleset.Empty() || current.RulesetPinned() {
+			return false, nil
+		}
+		started, err := current.Started(ctx, db, now)
+		if err != nil || !started {
+			return false, err
+		}
+		latest, err := current.latestRevision(ctx, db)
+		if err != nil {
+			return false, err
+		}
+		current.Ruleset = latest.ID
+		current.RulesetPinnedAt = now
+		return true, nil
+	})
+}
+
+func (s *Season) latestRevision(ctx context.Context, db database.Provider) (*Ruleset, error) {
+	assigned, err := database.GetExistingRecordById(ctx, db, &Ruleset{}, s.Ruleset.RecordId())
+	if err != nil {
+		return nil, err
+	}
+	return assigned.Latest(ctx, db)
+}
+
+// RulesetInForce returns the revision of the season's ruleset that applies:
+// the pinned revision once the season has been pinned, otherwise the latest
+// revision of the assigned ruleset, which is also what a season that has
+// started but not yet been pinned will pin. It returns nil if no ruleset is
+// assigned. It never writes; pinning is left to PinStartedSeasonRulesets.
+func (s *Season) RulesetInForce(ctx context.Context, db database.Provider) (*Ruleset, error) {
+	if s.Ruleset.Empty() {
+		return nil, nil
+	}
+	if s.RulesetPinned() {
+		return database.GetExistingRecordById(ctx, db, &Ruleset{}, s.Ruleset.RecordId())
+	}
+	return s.latestRevision(ctx, db)
+}
+
+// AdoptRevision makes revision binding for the season, as the result of an
+// accepted commissioner proposal whose amendments were applied to target. It
+// only applies to a season with a pinned ruleset whose lineage leads to
+// target, so the revisions made between the pinned one and target are adopted
+// along with the proposal; CommissionerProposal.UnadoptedChanges shows them to
+// voters. Reports whether the season's revision changed.
+func (s *Season) AdoptRevision(ctx context.Context, db database.Provider, target RulesetId, revision *Ruleset) (bool, error) {
+	return s.changeRuleset(ctx, db, func(current *Season) (bool, error) {
+		if !current.RulesetPinned() {
+			return false, nil
+		}
+		pinned, err := database.GetExistingRecordById(ctx, db, &Ruleset{}, current.Ruleset.RecordId())
+		if err != nil {
+			return false, err
+		}
+		inLineage, err := pinned.supersededInto(ctx, db, target)
+		if err != nil || !inLineage {
+			return false, err
+		}
+		current.Ruleset = revision.ID
+		return true, nil
+	})
+}
+
+// PinStartedSeasonRulesets pins the ruleset revision of every season that has
+// started since the last call, returning the seasons pinned. A season that
+// fails to pin doesn't stop the others from being pinned; the failures are
+// returned joined.
+func PinStartedSeasonRulesets(ctx context.Context, db database.Provider, now time.Time) ([]*Season, error) {
+	seasons, err := database.GetAllWhere[*Season](ctx, db, func(_ context.Context, s *Season) bool {
+		return !s.Ruleset.Empty() && !s.RulesetPinned()
+	})
+	if err != nil {
+		return nil, err
+	}
+	pinned := make([]*Season, 0)
+	var errs []error
+	for _, season := range seasons {
+		ok, err := season.PinRuleset(ctx, db, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("season %s: %w", season.ID, err))
+			continue
+		}
+		if ok {
+			pinned = append(pinned, season)
+		}
+	}
+	return pinned, errors.Join(errs...)
+}
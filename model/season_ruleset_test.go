@@ -0,0 +1,203 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"intraclub/database"
+)
+
+func assertRulesetInForce(t *testing.T, db database.Provider, season *Season, expected RulesetId) {
+	t.Helper()
+	ruleset, err := season.RulesetInForce(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ruleset == nil || ruleset.ID != expected {
+		t.Fatalf("expected revision %s in force, got %v", expected, ruleset)
+	}
+}
+
+func TestSeasonRulesetPinnedAtStart(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, _ := newDefaultSeason(t, db)
+	start := time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC)
+	newStoredWeekAt(t, db, season, start.AddDate(0, 0, 7))
+	newStoredWeekAt(t, db, season, start)
+	before := start.Add(8 * time.Hour)
+	after := start.Add(9 * time.Hour)
+
+	startsAt, scheduled, err := season.StartsAt(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !scheduled || !startsAt.Equal(start.Add(8*time.Hour+30*time.Minute)) {
+		t.Fatalf("expected the season to start at the first week's kickoff, got %s", startsAt)
+	}
+
+	ruleset := newValidStoredRulesetWithOneSection(t, db)
+	if err := season.AssignRuleset(ctx, db, ruleset.ID, before); err != nil {
+		t.Fatal(err)
+	}
+	assertRulesetInForce(t, db, season, ruleset.ID)
+
+	// before the start, later revisions apply as they're made
+	revised := addSectionRevisionToEndOfExistingRuleset(t, db, ruleset)
+	assertRulesetInForce(t, db, season, revised.ID)
+	if season.RulesetPinned() {
+		t.Fatal("expected the ruleset not to be pinned before the season starts")
+	}
+
+	// reading the ruleset of a started season doesn't pin it
+	stored, err := database.GetExistingRecordById(ctx, db, &Season{}, season.ID.RecordId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	unpinned := *stored
+	assertRulesetInForce(t, db, &unpinned, revised.ID)
+	if stored.RulesetPinned() {
+		t.Fatal("expected reading the ruleset in force not to pin it")
+	}
+
+	pinned, err := PinStartedSeasonRulesets(ctx, db, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pinned) != 1 || pinned[0].Ruleset != revised.ID {
+		t.Fatalf("expected the season to be pinned to %s, got %v", revised.ID, pinned)
+	}
+	season, err = database.GetExistingRecordById(ctx, db, &Season{}, season.ID.RecordId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !season.RulesetPinned() {
+		t.Fatal("expected the ruleset to be pinned once the season started")
+	}
+
+	// afterwards revisions made directly are not binding
+	addSectionRevisionToEndOfExistingRuleset(t, db, revised)
+	assertRulesetInForce(t, db, season, revised.ID)
+
+	other := newValidStoredRuleset(t, db)
+	if err := season.AssignRuleset(ctx, db, other.ID, after); !errors.Is(err, ErrSeasonRulesetPinned) {
+		t.Fatalf("expected assigning a ruleset to be refused once pinned, got %v", err)
+	}
+}
+
+func TestSeasonRulesetPinningContinuesPastFailures(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	start := time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC)
+	before := start.Add(-time.Hour)
+	after := start.AddDate(0, 0, 1)
+
+	seasons := make([]*Season, 2)
+	rulesets := make([]*Ruleset, 2)
+	for i := range seasons {
+		seasons[i], _ = newDefaultSeason(t, db)
+		newStoredWeekAt(t, db, seasons[i], start)
+		rulesets[i] = newValidStoredRuleset(t, db)
+		if err := seasons[i].AssignRuleset(ctx, db, rulesets[i].ID, before); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the first season's ruleset is gone, so it can't be pinned
+	if err := db.Delete(ctx, rulesets[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	pinned, err := PinStartedSeasonRulesets(ctx, db, after)
+	if err == nil {
+		t.Fatal("expected the season without its ruleset to fail to pin")
+	}
+	if len(pinned) != 1 || pinned[0].ID != seasons[1].ID || pinned[0].Ruleset != rulesets[1].ID {
+		t.Fatalf("expected the other season to be pinned, got %v", pinned)
+	}
+}
+
+func TestSeasonRulesetCannotBeAssignedAfterStart(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, _ := newDefaultSeason(t, db)
+	start := time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC)
+	newStoredWeekAt(t, db, season, start)
+
+	ruleset := newValidStoredRuleset(t, db)
+	if err := season.AssignRuleset(ctx, db, ruleset.ID, start.AddDate(0, 0, 1)); !errors.Is(err, ErrSeasonRulesetPinned) {
+		t.Fatalf("expected assigning a ruleset to a started season to be refused, got %v", err)
+	}
+
+	// a season without weeks hasn't started and pins nothing
+	unscheduled, _ := newDefaultSeason(t, db)
+	if err := unscheduled.AssignRuleset(ctx, db, ruleset.ID, start.AddDate(1, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	ok, err := unscheduled.PinRuleset(ctx, db, start.AddDate(1, 0, 0))
+	if err != nil || ok {
+		t.Fatalf("expected nothing pinned for an unscheduled season, got %v, %v", ok, err)
+	}
+}
+
+func TestSeasonAdoptsRevisionFromAcceptedProposal(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	season, proposal := newStoredCommissionerProposal(t, db, false)
+	start := time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC)
+	newStoredWeekAt(t, db, season, start)
+	before := start.Add(-time.Hour)
+	after := start.AddDate(0, 0, 1)
+
+	ruleset := newValidStoredRulesetWithXSections(t, db, 2)
+	if err := season.AssignRuleset(ctx, db, ruleset.ID, before); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := season.PinRuleset(ctx, db, after); err != nil {
+		t.Fatal(err)
+	}
+
+	// a revision made directly mid-season, which the proposal builds on
+	direct := addSectionRevisionToEndOfExistingRuleset(t, db, ruleset)
+	assertRulesetInForce(t, db, season, ruleset.ID)
+
+	sections, err := direct.GetSections(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = proposal.SetAmendments(ctx, db, direct.ID, []*RuleAmendment{
+		{Type: RuleAmendmentTypeRemoveSection, TargetSection: sections[0]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// voters are shown the direct revision the proposal would adopt too
+	unadopted, err := proposal.UnadoptedChanges(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unadopted == nil || unadopted.From.ID != ruleset.ID || unadopted.To.ID != direct.ID || len(unadopted.Added) != 1 {
+		t.Fatalf("expected the direct revision's added section to be shown, got %+v", unadopted)
+	}
+	acceptProposal(t, db, season, proposal)
+	revision, err := proposal.ApplyAmendments(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	season, err = database.GetExistingRecordById(ctx, db, &Season{}, season.ID.RecordId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRulesetInForce(t, db, season, revision.ID)
+	if unadopted, err := proposal.UnadoptedChanges(ctx, db); err != nil || unadopted != nil {
+		t.Fatalf("expected nothing left to adopt once applied, got %+v, %v", unadopted, err)
+	}
+
+	// a proposal amending an unrelated ruleset leaves the season alone
+	other := newValidStoredRuleset(t, db)
+	if ok, err := season.AdoptRevision(ctx, db, other.ID, other); err != nil || ok {
+		t.Fatalf("expected an unrelated revision not to be adopted, got %v, %v", ok, err)
+	}
+}
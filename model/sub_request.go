@@ -0,0 +1,354 @@
+package model
+
+import (
+	"context"
+	"fmt"
+
+	"intraclub/database"
+)
+
+type SubRequestId database.RecordId
+
+func (id SubRequestId) RecordId() database.RecordId {
+	return database.RecordId(id)
+}
+
+func (id SubRequestId) String() string {
+	return id.RecordId().String()
+}
+
+func (id SubRequestId) MarshalJSON() ([]byte, error) {
+	return id.RecordId().MarshalJSON()
+}
+
+func (id *SubRequestId) UnmarshalJSON(bytes []byte) error {
+	rid := database.RecordId(0)
+	if err := (*database.RecordId)(&rid).UnmarshalJSON(bytes); err != nil {
+		return err
+	}
+	*id = SubRequestId(rid)
+	return nil
+}
+
+type SubRequestStatus int
+
+const (
+	SubRequestOpen SubRequestStatus = iota
+	SubRequestClaimed
+	SubRequestApproved
+	SubRequestInvalid
+)
+
+func (s SubRequestStatus) String() string {
+	switch s {
+	case SubRequestOpen:
+		return "open"
+	case SubRequestClaimed:
+		return "claimed"
+	case SubRequestApproved:
+		return "approved"
+	default:
+		return "invalid"
+	}
+}
+
+func (s SubRequestStatus) Valid() bool {
+	return s < SubRequestInvalid
+}
+
+// SubRequest is posted by a team captain/co-captain who needs a fill-in for a
+// particular format line in a Week. A User in the Week's substitute pool
+// claims the request, and the captain then approves the claim. Once approved,
+// the claiming User may be placed in a LineupPairing for the requesting team
+// in that Week only, carrying the requested RatingId.
+type SubRequest struct {
+	ID              SubRequestId     `json:"id"`
+	WeekId          WeekId           `json:"week_id"`
+	TeamId          TeamId           `json:"team_id"`           // team that needs the substitute
+	FormatLineIndex int              `json:"format_line_index"` // line in the Format that needs filling
+	RatingId        RatingId         `json:"rating_id"`         // rating the substitute will play at
+	RequestedBy     database.UserId  `json:"requested_by"`      // captain/co-captain who posted the request
+	ClaimedBy       database.UserId  `json:"claimed_by"`        // substitute who claimed the request, if any
+	Status          SubRequestStatus `json:"status"`
+	Note            string           `json:"note"`
+}
+
+func NewSubRequest() *SubRequest {
+	return &SubRequest{}
+}
+
+func (r *SubRequest) GetOwner() database.UserId {
+	return r.RequestedBy
+}
+
+func (r *SubRequest) SetOwner(userId database.UserId) {
+	r.RequestedBy = userId
+}
+
+func (r *SubRequest) Type() string {
+	return "sub_request"
+}
+
+func (r *SubRequest) GetId() database.RecordId {
+	return r.ID.RecordId()
+}
+
+func (r *SubRequest) SetId(id database.RecordId) {
+	r.ID = SubRequestId(id)
+}
+
+func (r *SubRequest) EditableBy(ctx context.Context, db database.Provider) []database.UserId {
+	return EditableByTeamCaptainOrCoCaptains(ctx, db, r.TeamId)
+}
+
+// AccessibleTo returns everyone, as the substitute pool needs to be able to
+// browse open requests.
+func (r *SubRequest) AccessibleTo(_ context.Context, _ database.Provider) []database.UserId {
+	return database.AccessibleToEveryone
+}
+
+func (r *SubRequest) StaticallyValid() error {
+	if !r.Status.Valid() {
+		return fmt.Errorf("sub request status %d is not valid", r.Status)
+	}
+	if r.FormatLineIndex < 0 {
+		return fmt.Errorf("format line index %d is negative", r.FormatLineIndex)
+	}
+	if r.Status == SubRequestOpen && r.ClaimedBy != database.InvalidUserId {
+		return fmt.Errorf("open sub request cannot have a claiming user")
+	}
+	if r.Status != SubRequestOpen && r.ClaimedBy == database.InvalidUserId {
+		return fmt.Errorf("%s sub request must have a claiming user", r.Status)
+	}
+	return nil
+}
+
+func (r *SubRequest) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	week, err := database.GetExistingRecordById(ctx, db, &Week{}, r.WeekId.RecordId())
+	if err != nil {
+		return err
+	}
+	season, err := week.GetSeason(ctx, db)
+	if err != nil {
+		return err
+	}
+	if season == nil {
+		return fmt.Errorf("week %s is not assigned to a season", r.WeekId)
+	}
+	if !season.IsTeamAssignedToSeason(ctx, db, r.TeamId) {
+		return fmt.Errorf("team %s is not assigned to season %s", r.TeamId, season.ID)
+	}
+
+	// the requested rating must be one of the ratings on the requested line
+	draft, err := database.GetExistingRecordById(ctx, db, &Draft{}, week.DraftId.RecordId())
+	if err != nil {
+		return err
+	}
+	format, err := database.GetExistingRecordById(ctx, db, &Format{}, draft.Format.RecordId())
+	if err != nil {
+		return err
+	}
+	lines, err := format.GetLines(ctx, db)
+	if err != nil {
+		return err
+	}
+	if r.FormatLineIndex >= len(lines) {
+		return fmt.Errorf("format line index out of range: %d, max %d", r.FormatLineIndex, len(lines)-1)
+	}
+	line := lines[r.FormatLineIndex]
+	if r.RatingId != line.Player1Rating && r.RatingId != line.Player2Rating {
+		return fmt.Errorf("rating %s is not used by format line index %d", r.RatingId, r.FormatLineIndex)
+	}
+
+	if r.ClaimedBy != database.InvalidUserId {
+		return r.validateClaimant(ctx, db, season)
+	}
+	return nil
+}
+
+// validateClaimant checks that the claiming User is in the substitute pool for
+// the Week, is not a member of the requesting team, plays at the requested
+// rating if they are rated on another team, and does not hold another claim
+// in the same Week.
+func (r *SubRequest) validateClaimant(ctx context.Context, db database.Provider, season *Season) error {
+	inPool, err := IsSubstituteForWeek(ctx, db, r.WeekId, r.ClaimedBy)
+	if err != nil {
+		return err
+	}
+	if !inPool {
+		return fmt.Errorf("user %s is not in the substitute pool for week %s", r.ClaimedBy, r.WeekId)
+	}
+
+	ownTeam, err := GetSeasonTeamForUser(ctx, db, season, r.ClaimedBy)
+	if err != nil {
+		return err
+	}
+	if ownTeam != nil {
+		if ownTeam.ID == r.TeamId {
+			return fmt.Errorf("user %s is already a member of team %s", r.ClaimedBy, r.TeamId)
+		}
+		rating, err := ownTeam.GetRating(ctx, db, r.ClaimedBy)
+		if err != nil {
+			return err
+		}
+		if rating != r.RatingId {
+			return fmt.Errorf("user %s has rating %s, expected %s", r.ClaimedBy, rating, r.RatingId)
+		}
+	}
+
+	others, err := GetSubRequestsClaimedBy(ctx, db, r.WeekId, r.ClaimedBy)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID != r.ID {
+			return fmt.Errorf("user %s has already claimed sub request %s for week %s", r.ClaimedBy, other.ID, r.WeekId)
+		}
+	}
+	return nil
+}
+
+// PreCreateRequest opens the request, whatever the client sent: it is only
+// claimed and approved through Claim and Approve.
+func (r *SubRequest) PreCreateRequest(_ context.Context, _ database.Provider, _ database.UserId) error {
+	r.Status = SubRequestOpen
+	r.ClaimedBy = database.InvalidUserId
+	return nil
+}
+
+// PreUpdateRequest keeps a generic update from claiming, approving or
+// releasing the request, which go through Claim, Approve and Release.
+func (r *SubRequest) PreUpdateRequest(_ context.Context, _ database.Provider, _ database.UserId, existingValues database.CrudRecord) error {
+	existing := existingValues.(*SubRequest)
+	if r.Status != existing.Status || r.ClaimedBy != existing.ClaimedBy {
+		return fmt.Errorf("sub request %s can only be claimed, approved or released through its own routes", existing.ID)
+	}
+	return nil
+}
+
+func (r *SubRequest) PreUpdate(_ context.Context, _ database.Provider, existingValues database.CrudRecord) error {
+	existing := existingValues.(*SubRequest)
+	if r.WeekId != existing.WeekId {
+		return fmt.Errorf("week ID %s cannot be changed", existing.WeekId)
+	}
+	if r.TeamId != existing.TeamId {
+		return fmt.Errorf("team ID %s cannot be changed", existing.TeamId)
+	}
+	return nil
+}
+
+// PreDelete prevents an approved SubRequest from being removed while its
+// substitute is still placed in one of the team's pairings for the Week.
+func (r *SubRequest) PreDelete(ctx context.Context, db database.Provider) error {
+	if r.Status != SubRequestApproved {
+		return nil
+	}
+	inLineup, err := isPlayerInTeamLineup(ctx, db, r.TeamId, r.WeekId, r.ClaimedBy)
+	if err != nil {
+		return err
+	}
+	if inLineup {
+		return fmt.Errorf("substitute %s is in the lineup for team %s; remove them before deleting the request", r.ClaimedBy, r.TeamId)
+	}
+	return nil
+}
+
+// Claim assigns an open SubRequest to the given User. The claimant's
+// eligibility is enforced by DynamicallyValid when the record is updated.
+func (r *SubRequest) Claim(ctx context.Context, db database.Provider, userId database.UserId) error {
+	if r.Status != SubRequestOpen {
+		return fmt.Errorf("sub request %s is %s, not %s", r.ID, r.Status, SubRequestOpen)
+	}
+	claimed := *r
+	claimed.ClaimedBy = userId
+	claimed.Status = SubRequestClaimed
+	return r.updateTo(ctx, db, &claimed)
+}
+
+// Approve accepts the claimant of a claimed SubRequest, allowing them to be
+// placed in the team's lineup for the Week.
+func (r *SubRequest) Approve(ctx context.Context, db database.Provider) error {
+	if r.Status != SubRequestClaimed {
+		return fmt.Errorf("sub request %s is %s, not %s", r.ID, r.Status, SubRequestClaimed)
+	}
+	approved := *r
+	approved.Status = SubRequestApproved
+	return r.updateTo(ctx, db, &approved)
+}
+
+// Release drops the claimant from a SubRequest and re-opens it. An approved
+// claimant cannot be released while they are still in the team's lineup.
+func (r *SubRequest) Release(ctx context.Context, db database.Provider) error {
+	if r.Status == SubRequestOpen {
+		return fmt.Errorf("sub request %s has not been claimed", r.ID)
+	}
+	if err := r.PreDelete(ctx, db); err != nil {
+		return err
+	}
+	released := *r
+	released.ClaimedBy = database.InvalidUserId
+	released.Status = SubRequestOpen
+	return r.updateTo(ctx, db, &released)
+}
+
+// updateTo stores the updated copy of this SubRequest, only overwriting the
+// receiver once the update has passed validation.
+func (r *SubRequest) updateTo(ctx context.Context, db database.Provider, updated *SubRequest) error {
+	if err := database.UpdateOne(ctx, db, updated); err != nil {
+		return err
+	}
+	*r = *updated
+	return nil
+}
+
+func (r *SubRequest) NewRecord() database.CrudRecord {
+	return new(SubRequest)
+}
+
+// GetSubRequestsClaimedBy returns the claimed or approved SubRequest records
+// held by the User in the given Week.
+func GetSubRequestsClaimedBy(ctx context.Context, db database.Provider, weekId WeekId, userId database.UserId) ([]*SubRequest, error) {
+	return database.GetAllWhere[*SubRequest](ctx, db, func(_ context.Context, r *SubRequest) bool {
+		return r.WeekId == weekId && r.ClaimedBy == userId && r.Status != SubRequestOpen
+	})
+}
+
+// GetApprovedSubRequest returns the approved SubRequest allowing the User to
+// substitute for the team on the given format line in the given Week, or nil
+// if there is none.
+func GetApprovedSubRequest(ctx context.Context, db database.Provider, teamId TeamId, weekId WeekId, formatLineIndex int, userId database.UserId) (*SubRequest, error) {
+	requests, err := database.GetAllWhere[*SubRequest](ctx, db, func(_ context.Context, r *SubRequest) bool {
+		return r.TeamId == teamId && r.WeekId == weekId && r.FormatLineIndex == formatLineIndex &&
+			r.ClaimedBy == userId && r.Status == SubRequestApproved
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	return requests[0], nil
+}
+
+// isPlayerInTeamLineup checks whether the User is placed in any pairing of the
+// team's lineup for the Week.
+func isPlayerInTeamLineup(ctx context.Context, db database.Provider, teamId TeamId, weekId WeekId, userId database.UserId) (bool, error) {
+	lineups, err := database.GetAllWhere[*Lineup](ctx, db, func(_ context.Context, l *Lineup) bool {
+		return l.TeamId == teamId && l.WeekId == weekId
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, lineup := range lineups {
+		pairings, err := database.GetAllWhere[*LineupPairing](ctx, db, func(_ context.Context, p *LineupPairing) bool {
+			return p.LineupId == lineup.ID && (p.Player1 == userId || p.Player2 == userId)
+		})
+		if err != nil {
+			return false, err
+		}
+		if len(pairings) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,282 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+// subRequestFixture is a two-team season with a single week. The requesting
+// team's captain carries the first line's player 2 rating, so a substitute at
+// the player 1 rating completes the line. The other team's captain carries the
+// player 1 rating and is a candidate substitute.
+type subRequestFixture struct {
+	season     *Season
+	week       *Week
+	line       FormatLine
+	team       *Team
+	captain    database.UserId
+	otherTeam  *Team
+	otherCapt  database.UserId
+	lateUserId database.UserId
+}
+
+func newSubRequestFixture(t *testing.T, db database.Provider) *subRequestFixture {
+	ctx := context.Background()
+	season, _ := newDefaultSeasonWithTeams(t, db, 2)
+	teams, err := season.GetTeams(ctx, db)
+	require.NoError(t, err)
+
+	draft, err := season.GetDraft(ctx, db)
+	require.NoError(t, err)
+	format, err := database.GetExistingRecordById(ctx, db, &Format{}, draft.Format.RecordId())
+	require.NoError(t, err)
+	lines, err := format.GetLines(ctx, db)
+	require.NoError(t, err)
+
+	captain, err := teams[0].GetCaptain(ctx, db)
+	require.NoError(t, err)
+	otherCaptain, err := teams[1].GetCaptain(ctx, db)
+	require.NoError(t, err)
+
+	_, err = database.CreateOne(ctx, db, &TeamRating{TeamId: teams[0].ID, UserId: captain, RatingId: lines[0].Player2Rating})
+	require.NoError(t, err)
+	_, err = database.CreateOne(ctx, db, &TeamRating{TeamId: teams[1].ID, UserId: otherCaptain, RatingId: lines[0].Player1Rating})
+	require.NoError(t, err)
+
+	lateUser := newStoredUser(t, db)
+	require.NoError(t, season.AddLateAddition(ctx, db, lateUser.ID))
+
+	return &subRequestFixture{
+		season:     season,
+		week:       newStoredWeek(t, db, season),
+		line:       lines[0],
+		team:       teams[0],
+		captain:    captain,
+		otherTeam:  teams[1],
+		otherCapt:  otherCaptain,
+		lateUserId: lateUser.ID,
+	}
+}
+
+func (f *subRequestFixture) newSubstitute(t *testing.T, db database.Provider, userId database.UserId) {
+	_, err := database.CreateOne(context.Background(), db, &Substitute{WeekId: f.week.ID, UserId: userId})
+	require.NoError(t, err)
+}
+
+func (f *subRequestFixture) newOpenRequest(t *testing.T, db database.Provider) *SubRequest {
+	request := NewSubRequest()
+	request.WeekId = f.week.ID
+	request.TeamId = f.team.ID
+	request.FormatLineIndex = 0
+	request.RatingId = f.line.Player1Rating
+	request.RequestedBy = f.captain
+	v, err := database.CreateOne(context.Background(), db, request)
+	require.NoError(t, err)
+	return v
+}
+
+func setAvailability(t *testing.T, db database.Provider, userId database.UserId, weekId WeekId, option AvailabilityOption) {
+	_, err := database.CreateOne(context.Background(), db, &Availability{UserId: userId, WeekId: weekId, Available: option})
+	require.NoError(t, err)
+}
+
+func TestSubstituteLateAdditionIsEligible(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	f := newSubRequestFixture(t, db)
+
+	f.newSubstitute(t, db, f.lateUserId)
+
+	_, err := database.CreateOne(context.Background(), db, &Substitute{WeekId: f.week.ID, UserId: f.lateUserId})
+	require.Error(t, err, "duplicate opt-in for the same week should be rejected")
+}
+
+func TestSubstituteTeamMemberMustBeAvailable(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	f := newSubRequestFixture(t, db)
+
+	_, err := database.CreateOne(context.Background(), db, &Substitute{WeekId: f.week.ID, UserId: f.otherCapt})
+	require.Error(t, err, "team member without availability should not be eligible")
+
+	setAvailability(t, db, f.otherCapt, f.week.ID, AvailabilityMaybe)
+	_, err = database.CreateOne(context.Background(), db, &Substitute{WeekId: f.week.ID, UserId: f.otherCapt})
+	require.Error(t, err, "team member marked maybe should not be eligible")
+}
+
+func TestSubstituteNonParticipantIsNotEligible(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	f := newSubRequestFixture(t, db)
+	outsider := newStoredUser(t, db)
+
+	_, err := database.CreateOne(context.Background(), db, &Substitute{WeekId: f.week.ID, UserId: outsider.ID})
+	require.Error(t, err)
+}
+
+func TestSubRequestRatingMustBelongToLine(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	f := newSubRequestFixture(t, db)
+	other := newStoredRating(t, db)
+
+	request := NewSubRequest()
+	request.WeekId = f.week.ID
+	request.TeamId = f.team.ID
+	request.RatingId = other.ID
+	_, err := database.CreateOne(context.Background(), db, request)
+	require.Error(t, err)
+
+	request.RatingId = f.line.Player1Rating
+	request.FormatLineIndex = 5
+	_, err = database.CreateOne(context.Background(), db, request)
+	require.Error(t, err)
+}
+
+func TestSubRequestClaimRequiresSubstitutePool(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	f := newSubRequestFixture(t, db)
+	request := f.newOpenRequest(t, db)
+
+	require.Error(t, request.Claim(context.Background(), db, f.lateUserId))
+
+	stored, err := database.GetExistingRecordById(context.Background(), db, &SubRequest{}, request.ID.RecordId())
+	require.NoError(t, err)
+	require.Equal(t, SubRequestOpen, stored.Status)
+}
+
+func TestSubRequestClaimRejectsRatingMismatch(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	f := newSubRequestFixture(t, db)
+	request := f.newOpenRequest(t, db)
+
+	// re-rate the other captain so they no longer match the requested rating
+	ratings, err := database.GetAllWhere[*TeamRating](context.Background(), db, func(_ context.Context, r *TeamRating) bool {
+		return r.UserId == f.otherCapt
+	})
+	require.NoError(t, err)
+	ratings[0].RatingId = f.line.Player2Rating
+	require.NoError(t, database.UpdateOne(context.Background(), db, ratings[0]))
+
+	setAvailability(t, db, f.otherCapt, f.week.ID, AvailabilityAvailable)
+	f.newSubstitute(t, db, f.otherCapt)
+
+	require.Error(t, request.Claim(context.Background(), db, f.otherCapt))
+}
+
+func TestSubRequestClaimOncePerWeek(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	f := newSubRequestFixture(t, db)
+	first := f.newOpenRequest(t, db)
+	f.newSubstitute(t, db, f.lateUserId)
+
+	require.NoError(t, first.Claim(context.Background(), db, f.lateUserId))
+
+	second := NewSubRequest()
+	second.WeekId = f.week.ID
+	second.TeamId = f.otherTeam.ID
+	second.RatingId = f.line.Player1Rating
+	second, err := database.CreateOne(context.Background(), db, second)
+	require.NoError(t, err)
+	require.Error(t, second.Claim(context.Background(), db, f.lateUserId))
+
+	// the substitute cannot leave the pool while holding a claim
+	subs, err := database.GetAllWhere[*Substitute](context.Background(), db, func(_ context.Context, s *Substitute) bool {
+		return s.UserId == f.lateUserId
+	})
+	require.NoError(t, err)
+	_, _, err = database.DeleteOneById(context.Background(), db, &Substitute{}, subs[0].ID)
+	require.Error(t, err)
+}
+
+func TestApprovedSubCanBePlacedInLineup(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	f := newSubRequestFixture(t, db)
+
+	setAvailability(t, db, f.otherCapt, f.week.ID, AvailabilityAvailable)
+	f.newSubstitute(t, db, f.otherCapt)
+	request := f.newOpenRequest(t, db)
+	require.NoError(t, request.Claim(ctx, db, f.otherCapt))
+
+	lineup, err := database.CreateOne(ctx, db, &Lineup{TeamId: f.team.ID, WeekId: f.week.ID})
+	require.NoError(t, err)
+	pairing := &LineupPairing{LineupId: lineup.ID, TeamId: f.team.ID, Player1: f.otherCapt, Player2: f.captain, FormatLineIndex: 0}
+
+	// a claimed (but not yet approved) sub cannot be placed in the lineup
+	_, err = database.CreateOne(ctx, db, pairing)
+	require.Error(t, err)
+
+	require.NoError(t, request.Approve(ctx, db))
+	stored, err := database.CreateOne(ctx, db, pairing)
+	require.NoError(t, err)
+
+	// the approved sub cannot be released while they are in the lineup
+	require.Error(t, request.Release(ctx, db))
+	_, _, err = database.DeleteOneById(ctx, db, &SubRequest{}, request.ID.RecordId())
+	require.Error(t, err)
+
+	_, _, err = database.DeleteOneById(ctx, db, &LineupPairing{}, stored.ID.RecordId())
+	require.NoError(t, err)
+	require.NoError(t, request.Release(ctx, db))
+}
+
+func TestApprovedSubIsOnlyValidForTheirWeek(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	f := newSubRequestFixture(t, db)
+
+	f.newSubstitute(t, db, f.lateUserId)
+	request := f.newOpenRequest(t, db)
+	require.NoError(t, request.Claim(ctx, db, f.lateUserId))
+	require.NoError(t, request.Approve(ctx, db))
+
+	nextWeek := newStoredWeek(t, db, f.season)
+	lineup, err := database.CreateOne(ctx, db, &Lineup{TeamId: f.team.ID, WeekId: nextWeek.ID})
+	require.NoError(t, err)
+	_, err = database.CreateOne(ctx, db, &LineupPairing{LineupId: lineup.ID, TeamId: f.team.ID, Player1: f.lateUserId, Player2: f.captain, FormatLineIndex: 0})
+	require.Error(t, err)
+}
+
+func TestApprovedSubIsOnlyValidForTheirLine(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	f := newSubRequestFixture(t, db)
+
+	f.newSubstitute(t, db, f.lateUserId)
+	request := f.newOpenRequest(t, db)
+	require.NoError(t, request.Claim(ctx, db, f.lateUserId))
+	require.NoError(t, request.Approve(ctx, db))
+
+	sub, err := GetApprovedSubRequest(ctx, db, f.team.ID, f.week.ID, 0, f.lateUserId)
+	require.NoError(t, err)
+	require.NotNil(t, sub)
+	sub, err = GetApprovedSubRequest(ctx, db, f.team.ID, f.week.ID, 1, f.lateUserId)
+	require.NoError(t, err)
+	require.Nil(t, sub)
+}
+
+func TestSubRequestStatusOnlyChangedThroughWorkflow(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	f := newSubRequestFixture(t, db)
+	f.newSubstitute(t, db, f.lateUserId)
+
+	// a generic create request starts out open, whatever was sent
+	requested := &SubRequest{Status: SubRequestApproved, ClaimedBy: f.lateUserId}
+	require.NoError(t, requested.PreCreateRequest(ctx, db, f.captain))
+	require.Equal(t, SubRequestOpen, requested.Status)
+	require.Equal(t, database.InvalidUserId, requested.ClaimedBy)
+
+	// and a generic update can't approve it
+	request := f.newOpenRequest(t, db)
+	approved := *request
+	approved.Status = SubRequestApproved
+	approved.ClaimedBy = f.lateUserId
+	wac := database.NewWithAccessControl[*SubRequest](ctx, db, f.captain)
+	require.Error(t, wac.UpdateOneById(ctx, &approved))
+
+	noted := *request
+	noted.Note = "bring a spare racquet"
+	require.NoError(t, wac.UpdateOneById(ctx, &noted))
+}
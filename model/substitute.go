@@ -0,0 +1,167 @@
+package model
+
+import (
+	"context"
+	"fmt"
+
+	"intraclub/database"
+)
+
+// Substitute records that a User has opted in to the substitute pool for a
+// particular Week. Only late additions to the Week's Season, or members of a
+// Season team who have marked themselves as available for that Week, may opt
+// in. Captains looking for a fill-in post a SubRequest, which a Substitute may
+// then claim.
+type Substitute struct {
+	ID     database.RecordId `json:"id"`
+	WeekId WeekId            `json:"week_id"`
+	UserId database.UserId   `json:"user_id"`
+}
+
+func NewSubstitute() *Substitute {
+	return &Substitute{}
+}
+
+func (s *Substitute) GetOwner() database.UserId {
+	return s.UserId
+}
+
+func (s *Substitute) SetOwner(userId database.UserId) {
+	s.UserId = userId
+}
+
+func (s *Substitute) UniquenessEquivalent(other *Substitute) error {
+	if s.WeekId == other.WeekId && s.UserId == other.UserId {
+		return fmt.Errorf("user %s is already in the substitute pool for week %s", s.UserId, s.WeekId)
+	}
+	return nil
+}
+
+func (s *Substitute) Type() string {
+	return "substitute"
+}
+
+func (s *Substitute) GetId() database.RecordId {
+	return s.ID
+}
+
+func (s *Substitute) SetId(id database.RecordId) {
+	s.ID = id
+}
+
+func (s *Substitute) EditableBy(_ context.Context, _ database.Provider) []database.UserId {
+	return []database.UserId{s.UserId}
+}
+
+// AccessibleTo returns everyone, as captains in any team need to be able to
+// browse the substitute pool for a week.
+func (s *Substitute) AccessibleTo(_ context.Context, _ database.Provider) []database.UserId {
+	return database.AccessibleToEveryone
+}
+
+func (s *Substitute) StaticallyValid() error {
+	return nil
+}
+
+func (s *Substitute) DynamicallyValid(ctx context.Context, db database.Provider) error {
+	err := database.ExistsById(ctx, db, &User{}, s.UserId.RecordId())
+	if err != nil {
+		return err
+	}
+	week, err := database.GetExistingRecordById(ctx, db, &Week{}, s.WeekId.RecordId())
+	if err != nil {
+		return err
+	}
+	return ValidateSubstituteEligibility(ctx, db, week, s.UserId)
+}
+
+// PreDelete prevents a User from leaving the substitute pool for a week while
+// they hold a claimed or approved SubRequest for that week.
+func (s *Substitute) PreDelete(ctx context.Context, db database.Provider) error {
+	requests, err := GetSubRequestsClaimedBy(ctx, db, s.WeekId, s.UserId)
+	if err != nil {
+		return err
+	}
+	if len(requests) > 0 {
+		return fmt.Errorf("user %s holds sub request %s for week %s", s.UserId, requests[0].ID, s.WeekId)
+	}
+	return nil
+}
+
+func (s *Substitute) NewRecord() database.CrudRecord {
+	return new(Substitute)
+}
+
+// ValidateSubstituteEligibility checks that a User may act as a substitute for
+// the given Week. Late additions to the Week's Season are always eligible;
+// members of a Season team are eligible only if their Availability for the
+// Week is AvailabilityAvailable.
+func ValidateSubstituteEligibility(ctx context.Context, db database.Provider, week *Week, userId database.UserId) error {
+	season, err := week.GetSeason(ctx, db)
+	if err != nil {
+		return err
+	}
+	if season == nil {
+		return fmt.Errorf("week %s is not assigned to a season", week.ID)
+	}
+
+	lateAdditions, err := season.GetLateAdditions(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, lateAddition := range lateAdditions {
+		if lateAddition == userId {
+			return nil
+		}
+	}
+
+	team, err := GetSeasonTeamForUser(ctx, db, season, userId)
+	if err != nil {
+		return err
+	}
+	if team == nil {
+		return fmt.Errorf("user %s is neither a late addition nor a team member in season %s", userId, season.ID)
+	}
+
+	availability, err := database.GetAllWhere[*Availability](ctx, db, func(_ context.Context, a *Availability) bool {
+		return a.UserId == userId && a.WeekId == week.ID
+	})
+	if err != nil {
+		return err
+	}
+	if len(availability) == 0 || availability[0].Available != AvailabilityAvailable {
+		return fmt.Errorf("user %s must be marked %s for week %s to substitute", userId, AvailabilityAvailable, week.ID)
+	}
+	return nil
+}
+
+// GetSeasonTeamForUser returns the Team in the Season that the User is a
+// member of, or nil if the User is not on any of the Season's teams.
+func GetSeasonTeamForUser(ctx context.Context, db database.Provider, season *Season, userId database.UserId) (*Team, error) {
+	teams, err := season.GetTeams(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range teams {
+		isMember, err := team.IsTeamMember(ctx, db, userId)
+		if err != nil {
+			return nil, err
+		}
+		if isMember {
+			return team, nil
+		}
+	}
+	return nil, nil
+}
+
+// IsSubstituteForWeek checks whether the User has opted in to the substitute
+// pool for the given Week.
+func IsSubstituteForWeek(ctx context.Context, db database.Provider, weekId WeekId, userId database.UserId) (bool, error) {
+	subs, err := database.GetAllWhere[*Substitute](ctx, db, func(_ context.Context, s *Substitute) bool {
+		return s.WeekId == weekId && s.UserId == userId
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(subs) > 0, nil
+}
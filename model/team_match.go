@@ -173,6 +173,19 @@ func (m *TeamMatchIndividualMatch) UniquenessEquivalent(other *TeamMatchIndividu
 	return nil
 }
 
+// PostCreate invalidates cached career statistics, which count a match only
+// once it is assigned to a pairing.
+func (m *TeamMatchIndividualMatch) PostCreate(ctx context.Context, db database.Provider) error {
+	InvalidateCareerStats()
+	return nil
+}
+
+// PostDelete invalidates cached career statistics.
+func (m *TeamMatchIndividualMatch) PostDelete(ctx context.Context, db database.Provider) error {
+	InvalidateCareerStats()
+	return nil
+}
+
 // getIndividualMatchRows returns all TeamMatchIndividualMatch relationship
 // rows assigned to this team match.
 func (t *TeamMatch) getIndividualMatchRows(ctx context.Context, db database.Provider) ([]*TeamMatchIndividualMatch, error) {
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"context"
+
+	"intraclub/database"
+)
+
+// TeamMatchPoints is how a team match is scored under its Format: each line
+// won is worth that line's points, and the team with more points wins the
+// match and earns the match win bonus on top. A tie on points earns neither
+// team the bonus.
+type TeamMatchPoints struct {
+	Lines         []int `json:"lines"`           // points for a win, by format line index
+	MatchWinBonus int   `json:"match_win_bonus"` // extra points for winning the team match
+}
+
+// ForLine returns the points a win on the format line is worth. A line the
+// format doesn't define is worth 1, like an unweighted line.
+func (p TeamMatchPoints) ForLine(index int) int {
+	if index < 0 || index >= len(p.Lines) {
+		return 1
+	}
+	return p.Lines[index]
+}
+
+// GetTeamMatchPoints returns how the format scores a team match.
+func (f *Format) GetTeamMatchPoints(ctx context.Context, db database.Provider) (TeamMatchPoints, error) {
+	points := TeamMatchPoints{MatchWinBonus: f.MatchWinBonus}
+	lines, err := f.GetLines(ctx, db)
+	if err != nil {
+		return points, err
+	}
+	points.Lines = make([]int, 0, len(lines))
+	for _, line := range lines {
+		points.Lines = append(points.Lines, line.WinPoints())
+	}
+	return points, nil
+}
+
+// GetTeamMatchPoints returns how the team match is scored, under the Format of
+// the draft its week belongs to.
+func (t *TeamMatch) GetTeamMatchPoints(ctx context.Context, db database.Provider) (TeamMatchPoints, error) {
+	week, err := database.GetExistingRecordById(ctx, db, &Week{}, t.WeekId.RecordId())
+	if err != nil {
+		return TeamMatchPoints{}, err
+	}
+	draft, err := database.GetExistingRecordById(ctx, db, &Draft{}, week.DraftId.RecordId())
+	if err != nil {
+		return TeamMatchPoints{}, err
+	}
+	format, err := database.GetExistingRecordById(ctx, db, &Format{}, draft.Format.RecordId())
+	if err != nil {
+		return TeamMatchPoints{}, err
+	}
+	return format.GetTeamMatchPoints(ctx, db)
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"intraclub/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTeamMatchPoints(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	format := newDefaultStoredFormat(t, db)
+
+	// unweighted lines are worth a point each
+	points, err := format.GetTeamMatchPoints(ctx, db)
+	require.NoError(t, err)
+	require.NotEmpty(t, points.Lines)
+	for i := range points.Lines {
+		require.Equal(t, 1, points.ForLine(i))
+	}
+	require.Zero(t, points.MatchWinBonus)
+
+	lines, err := format.GetLines(ctx, db)
+	require.NoError(t, err)
+	lines[0].Points = 2
+	require.NoError(t, format.SetLines(ctx, db, lines))
+	format.MatchWinBonus = 1
+	require.NoError(t, database.UpdateOne(ctx, db, format))
+
+	points, err = format.GetTeamMatchPoints(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, 2, points.ForLine(0))
+	require.Equal(t, 1, points.ForLine(1))
+	require.Equal(t, 1, points.ForLine(len(lines)))
+	require.Equal(t, 1, points.MatchWinBonus)
+
+	// a team match is scored under the format of its week's draft
+	tm := newStoredTeamMatch(t, db)
+	points, err = tm.GetTeamMatchPoints(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, 1, points.ForLine(0))
+
+	format.MatchWinBonus = -1
+	require.Error(t, database.UpdateOne(ctx, db, format))
+	lines[0].Points = -1
+	require.Error(t, format.SetLines(ctx, db, lines))
+	stored, err := format.GetLines(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, stored, len(lines))
+}
@@ -127,6 +127,16 @@ func (w *Week) EditableBy(ctx context.Context, db database.Provider) []database.
 	return []database.UserId{draft.Owner}
 }
 
+// GetSeason resolves the Season this Week belongs to via its Draft. It returns
+// nil (and no error) if the Draft has not been assigned to a Season yet.
+func (w *Week) GetSeason(ctx context.Context, db database.Provider) (*Season, error) {
+	draft, err := database.GetExistingRecordById(ctx, db, &Draft{}, w.DraftId.RecordId())
+	if err != nil {
+		return nil, err
+	}
+	return draft.GetSeason(ctx, db)
+}
+
 func (w *Week) AccessibleTo(_ context.Context, _ database.Provider) []database.UserId {
 	return []database.UserId{database.EveryoneUserId}
 }
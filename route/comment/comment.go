@@ -5,7 +5,8 @@
 // of comments and the comment_reaction child rows. The routes in this package
 // handle reacting/unreacting to a comment, which is deduplicated per
 // (comment, user, type) via model.Comment.React/Unreact and requires the
-// acting user to be a participant of the comment's blurb's season.
+// acting user to be a participant of the comment's blurb's season, or an
+// eligible voter on the comment's commissioner proposal.
 package comment
 
 import (
@@ -52,12 +53,9 @@ func applyReaction(req api.Request[*ReactionBody], unreact bool) (any, int, erro
 	}
 
 	// The acting user must be a participant of the comment's blurb's season
-	// (mirrors the participant check enforced for blurb reactions).
-	blurb, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Blurb{}, commentRec.Blurb.RecordId())
-	if err != nil {
-		return nil, http.StatusBadRequest, err
-	}
-	if err := blurb.CanUserCommentOrReact(req.Context, req.DatabaseProvider, req.Token.UserId); err != nil {
+	// (mirrors the participant check enforced for blurb reactions), or an
+	// eligible voter on the comment's proposal.
+	if err := commentRec.CanUserCommentOrReact(req.Context, req.DatabaseProvider, req.Token.UserId); err != nil {
 		return nil, http.StatusForbidden, err
 	}
 
@@ -0,0 +1,104 @@
+package format
+
+import (
+	"errors"
+	"net/http"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetLinesBody is the request body for setting a Format's lines. The `lines`
+// list replaces the format's entire set of lines (as FormatLine join
+// records), preserving order; only each line's ratings and points are read,
+// and its position in the list becomes its FormatIndex.
+type SetLinesBody struct {
+	Lines []model.FormatLine `json:"lines"`
+}
+
+// StaticallyValid ensures the request body has the fields required to set
+// lines.
+func (b *SetLinesBody) StaticallyValid() error {
+	if len(b.Lines) == 0 {
+		return errors.New("lines must not be empty")
+	}
+	return nil
+}
+
+// GetLines returns a Format's lines, ordered by FormatIndex, with the points
+// each is worth.
+type GetLines struct{}
+
+func (c GetLines) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, api.AppendPathId(BaseRoute) + "/lines"
+}
+
+func (c GetLines) RequestBody() (*SetLinesBody, bool) {
+	return &SetLinesBody{}, false
+}
+
+func (c GetLines) Handler(req api.Request[*SetLinesBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required to get lines")
+	}
+
+	format, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Format{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	lines, err := format.GetLines(req.Context, req.DatabaseProvider)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return gin.H{api.ResourceKey: lines}, http.StatusOK, nil
+}
+
+// SetLines replaces a Format's lines (the FormatLine join records), including
+// the points each line is worth, with the provided list. Only the format's
+// owner (or a SysAdmin) may do so, and not once the format is assigned to a
+// draft, since that would change how played matches are scored.
+type SetLines struct{}
+
+func (c SetLines) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPut, api.AppendPathId(BaseRoute) + "/lines"
+}
+
+func (c SetLines) RequestBody() (*SetLinesBody, bool) {
+	return &SetLinesBody{}, true
+}
+
+func (c SetLines) Handler(req api.Request[*SetLinesBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required to set lines")
+	}
+
+	format, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Format{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// enforce per-record edit authorization (owner / sysadmin)
+	wac := database.NewWithAccessControl[*model.Format](req.Context, req.DatabaseProvider, req.Token.UserId)
+	if !wac.CanUserEdit(format) {
+		return nil, http.StatusForbidden, errors.New("not authorized to set lines for this format")
+	}
+	if err := format.CheckHasAssignedDrafts(req.Context, req.DatabaseProvider, true); err != nil {
+		return nil, http.StatusConflict, err
+	}
+
+	if err := format.SetLines(req.Context, req.DatabaseProvider, req.Body.Lines); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	lines, err := format.GetLines(req.Context, req.DatabaseProvider)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return gin.H{api.ResourceKey: lines}, http.StatusOK, nil
+}
@@ -0,0 +1,158 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// test setup helpers (mirror route/scoringstructure/set_secondary_scoring_structures_test.go)
+// ---------------------------------------------------------------------------
+
+func newStoredUser(t *testing.T, db database.Provider) *model.User {
+	t.Helper()
+	user := model.NewUser()
+	user.Email = model.EmailAddress(fmt.Sprintf("user%d@email.com", rand.Uint64()))
+	user.FirstName = fmt.Sprintf("Test %d", rand.Uint64())
+	user.LastName = "User"
+	user.PhoneNumber = model.PhoneNumber(fmt.Sprintf("%d", 100_000_0000+rand.Uint32N(999_999_999)))
+	v, err := database.CreateOne(context.Background(), db, user)
+	require.NoError(t, err)
+	return v
+}
+
+// newTestRouter builds a gin engine with the format CRUD surface and the
+// possible ratings and lines routes registered (as in main.go) with the auth
+// globals wired up so real tokens validate.
+func newTestRouter(t *testing.T, db database.Provider) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	api.UserType = &model.User{}
+	database.SysAdminCheck = model.IsUserSystemAdministrator
+
+	router := gin.New()
+	group := router.Group("/api")
+
+	formats := api.NewCrudCommon(model.NewFormat, false, db)
+	formats.HandleRouteTypes(group, api.CrudWrapperFunctionAll...)
+
+	ratings := api.RouteFamily[*SetPossibleRatingsBody]{DatabaseProvider: db}
+	ratings.Handle(group, GetPossibleRatings{}, SetPossibleRatings{})
+
+	lines := api.RouteFamily[*SetLinesBody]{DatabaseProvider: db}
+	lines.Handle(group, GetLines{}, SetLines{})
+
+	return router
+}
+
+// testKeyOnce generates a single JWT keypair shared by every token minted in a
+// test process, so tokens issued by different helpers all verify.
+var (
+	testKeyOnce sync.Once
+	testPubKey  *ecdsa.PublicKey
+	testPrivKey *ecdsa.PrivateKey
+)
+
+// newToken returns a signed JWT for the given user ID without touching key
+// files on disk.
+func newToken(t *testing.T, userId database.UserId) string {
+	t.Helper()
+	testKeyOnce.Do(func() {
+		pub, priv, err := api.GenerateKeyPair()
+		require.NoError(t, err)
+		testPubKey = pub
+		testPrivKey = priv
+	})
+	api.JwtPublicKey = testPubKey
+	api.JwtPrivateKey = testPrivKey
+	token, err := api.GenerateToken(userId.RecordId())
+	require.NoError(t, err)
+	return token
+}
+
+// doJSON performs an authenticated HTTP request against the router.
+func doJSON(t *testing.T, router *gin.Engine, method, path string, body any, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, path, reader)
+	require.NoError(t, err)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set(api.AuthTokenHeaderValue, token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSetLinesWithPoints(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	router := newTestRouter(t, db)
+	owner := newStoredUser(t, db)
+	token := newToken(t, owner.ID)
+
+	ratingIds := make([]string, 0, 2)
+	for _, name := range []string{"1", "2"} {
+		rating, err := database.CreateOne(context.Background(), db, &model.Rating{UserId: owner.ID, Name: name, Description: "rating " + name})
+		require.NoError(t, err)
+		ratingIds = append(ratingIds, rating.ID.String())
+	}
+	w := doJSON(t, router, http.MethodPost, "/api/format", map[string]any{"name": "1/2", "match_win_bonus": 1}, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var created struct {
+		Resource *model.Format `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	path := "/api/format/" + created.Resource.ID.String()
+
+	w = doJSON(t, router, http.MethodPut, path+"/possible_ratings", map[string]any{"ratings": ratingIds}, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// the top line is worth two points, the other the default one
+	body := map[string]any{"lines": []map[string]any{
+		{"player_1_rating": ratingIds[0], "player_2_rating": ratingIds[0], "points": 2},
+		{"player_1_rating": ratingIds[0], "player_2_rating": ratingIds[1]},
+	}}
+	w = doJSON(t, router, http.MethodPut, path+"/lines", body, newToken(t, newStoredUser(t, db).ID))
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doJSON(t, router, http.MethodPut, path+"/lines", body, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doJSON(t, router, http.MethodGet, path+"/lines", nil, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Resource []model.FormatLine `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Resource, 2)
+	require.Equal(t, 2, resp.Resource[0].WinPoints())
+	require.Equal(t, 1, resp.Resource[1].WinPoints())
+
+	// negative points are refused
+	body["lines"].([]map[string]any)[1]["points"] = -1
+	w = doJSON(t, router, http.MethodPut, path+"/lines", body, token)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
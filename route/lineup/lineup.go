@@ -39,6 +39,20 @@ func isSeasonCommissioner(ctx context.Context, db database.Provider, userId data
 	return false
 }
 
+// isLineupCommissioner reports whether the requesting user is a commissioner
+// of the season that the lineup's week belongs to.
+func isLineupCommissioner(ctx context.Context, db database.Provider, userId database.UserId, lineup *model.Lineup) bool {
+	week, exists, err := database.GetOneById(ctx, db, &model.Week{}, lineup.WeekId.RecordId())
+	if err != nil || !exists {
+		return false
+	}
+	season, err := week.GetSeason(ctx, db)
+	if err != nil || season == nil {
+		return false
+	}
+	return isSeasonCommissioner(ctx, db, userId, season.ID)
+}
+
 // lineupDetailForTeamWeek builds the LineupDetail for a team + week: the
 // lineup record (if any), the format's lines (in index order), and the
 // lineup's pairings.
@@ -97,6 +111,7 @@ type LineupDetail struct {
 	Lineup   *model.Lineup           `json:"lineup"`
 	Lines    []model.FormatLine      `json:"lines"`
 	Pairings []*model.LineupPairing  `json:"pairings"`
+	Hidden   bool                    `json:"hidden"` // pairings withheld until both blind lineups are confirmed
 }
 
 // LineupQuery holds the query parameters for GetLineupDetail.
@@ -117,7 +132,9 @@ func (q *LineupQuery) StaticallyValid() error {
 }
 
 // GetLineupDetail returns the LineupDetail for a team + week. It is viewable by
-// everyone (team members and commissioners alike).
+// everyone (team members and commissioners alike), except that in a blind
+// lineup season the pairings are withheld from anyone outside the team until
+// both teams in the matchup have confirmed their lineups.
 type GetLineupDetail struct{}
 
 func (c GetLineupDetail) Path() (api.HttpMethod, string) {
@@ -160,6 +177,20 @@ func (c GetLineupDetail) Handler(req api.Request[*LineupQuery]) (any, int, error
 	if err != nil {
 		return nil, http.StatusBadRequest, err
 	}
+	if detail.Lineup != nil {
+		userId := database.InvalidUserId
+		if req.Token != nil {
+			userId = req.Token.UserId
+		}
+		canView, err := detail.Lineup.CanUserViewPairings(req.Context, req.DatabaseProvider, userId)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		if !canView {
+			detail.Pairings = []*model.LineupPairing{}
+			detail.Hidden = true
+		}
+	}
 	return gin.H{api.ResourceKey: detail}, http.StatusOK, nil
 }
 
@@ -175,6 +206,7 @@ type SetLineupBody struct {
 	TeamId   model.TeamId     `json:"team_id"`
 	WeekId   model.WeekId     `json:"week_id"`
 	Pairings []PairingInput   `json:"pairings"`
+	Reason   string           `json:"reason"` // required once the lineup is confirmed
 }
 
 // StaticallyValid ensures both a team and a week are specified.
@@ -191,6 +223,12 @@ func (b *SetLineupBody) StaticallyValid() error {
 // SetLineup creates (or updates) a team's weekly lineup and replaces its
 // pairings. Only the team's captain/co-captains may build a lineup. Each
 // pairing is validated against team membership and format ratings by the model.
+//
+// Once the lineup is confirmed a reason is required and every change is
+// recorded as a LineupRevision. Changes to an official lineup, or to a blind
+// lineup once both teams' lineups are revealed, are not applied immediately:
+// they are stored as a pending revision, returned with 202 Accepted, and wait
+// for a season commissioner to approve them.
 type SetLineup struct{}
 
 func (c SetLineup) Path() (api.HttpMethod, string) {
@@ -229,20 +267,7 @@ func (c SetLineup) Handler(req api.Request[*SetLineupBody]) (any, int, error) {
 		}
 	}
 
-	// Replace the lineup's pairings. Deleting the existing rows first means an
-	// empty set clears the lineup; creating fresh rows re-validates each one.
-	oldPairings, err := database.GetAllWhere[*model.LineupPairing](req.Context, req.DatabaseProvider, func(_ context.Context, p *model.LineupPairing) bool {
-		return p.LineupId == lineup.ID
-	})
-	if err != nil {
-		return nil, http.StatusBadRequest, err
-	}
-	for _, p := range oldPairings {
-		if _, _, err := database.DeleteOneById(req.Context, req.DatabaseProvider, p, p.ID.RecordId()); err != nil {
-			return nil, http.StatusBadRequest, err
-		}
-	}
-
+	pairings := make([]*model.LineupPairing, 0, len(req.Body.Pairings))
 	for _, in := range req.Body.Pairings {
 		pairing := model.NewLineupPairing()
 		pairing.LineupId = lineup.ID
@@ -250,9 +275,15 @@ func (c SetLineup) Handler(req api.Request[*SetLineupBody]) (any, int, error) {
 		pairing.Player1 = in.Player1
 		pairing.Player2 = in.Player2
 		pairing.FormatLineIndex = in.FormatLineIndex
-		if _, err := database.CreateOne(req.Context, req.DatabaseProvider, pairing); err != nil {
-			return nil, http.StatusBadRequest, err
-		}
+		pairings = append(pairings, pairing)
+	}
+	revision, err := model.ChangeLineupPairings(req.Context, req.DatabaseProvider, lineup, req.Token.UserId, req.Body.Reason, pairings)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if revision != nil && revision.Status == model.LineupRevisionPending {
+		// changes to an official lineup wait for a commissioner
+		return gin.H{api.ResourceKey: revision}, http.StatusAccepted, nil
 	}
 
 	detail, err := lineupDetailForTeamWeek(req.Context, req.DatabaseProvider, req.Body.TeamId, req.Body.WeekId)
@@ -285,11 +316,20 @@ func (c ConfirmLineup) Handler(req api.Request[*EmptyBody]) (any, int, error) {
 	if !canEditTeamLineup(req.Context, req.DatabaseProvider, req.Token.UserId, lineup.TeamId) {
 		return nil, http.StatusForbidden, errors.New("only a team captain or co-captain may confirm the lineup")
 	}
-	lineup.Confirmed = true
-	if err := database.UpdateOne(req.Context, req.DatabaseProvider, lineup); err != nil {
+	// update a copy so the lineup's PreUpdate sees the confirmation and runs
+	// the season's lineup rules
+	confirmed := *lineup
+	confirmed.Confirmed = true
+	if err := database.UpdateOne(req.Context, req.DatabaseProvider, &confirmed); err != nil {
 		return nil, http.StatusBadRequest, err
 	}
-	return gin.H{api.ResourceKey: lineup}, http.StatusOK, nil
+	if !lineup.Confirmed {
+		// the confirmed pairings are the first revision in the lineup's history
+		if _, err := model.RecordLineupRevision(req.Context, req.DatabaseProvider, &confirmed, req.Token.UserId, "confirmed"); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	return gin.H{api.ResourceKey: &confirmed}, http.StatusOK, nil
 }
 
 // MarkOfficial marks a confirmed lineup as official. Only a season
@@ -316,27 +356,16 @@ func (c MarkOfficial) Handler(req api.Request[*EmptyBody]) (any, int, error) {
 		return nil, http.StatusBadRequest, errors.New("lineup must be confirmed before it can be marked official")
 	}
 
-	week, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Week{}, lineup.WeekId.RecordId())
-	if err != nil {
-		return nil, http.StatusBadRequest, err
-	}
-	draft, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Draft{}, week.DraftId.RecordId())
-	if err != nil {
-		return nil, http.StatusBadRequest, err
-	}
-	season, err := draft.GetSeason(req.Context, req.DatabaseProvider)
-	if err != nil {
-		return nil, http.StatusBadRequest, err
-	}
-	if !isSeasonCommissioner(req.Context, req.DatabaseProvider, req.Token.UserId, season.ID) {
+	if !isLineupCommissioner(req.Context, req.DatabaseProvider, req.Token.UserId, lineup) {
 		return nil, http.StatusForbidden, errors.New("only a season commissioner may mark the lineup official")
 	}
 
-	lineup.Official = true
-	if err := database.UpdateOne(req.Context, req.DatabaseProvider, lineup); err != nil {
+	official := *lineup
+	official.Official = true
+	if err := database.UpdateOne(req.Context, req.DatabaseProvider, &official); err != nil {
 		return nil, http.StatusBadRequest, err
 	}
-	return gin.H{api.ResourceKey: lineup}, http.StatusOK, nil
+	return gin.H{api.ResourceKey: &official}, http.StatusOK, nil
 }
 
 // EmptyBody is a placeholder request body for routes that take no body.
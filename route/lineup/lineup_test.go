@@ -338,3 +338,262 @@ func TestConfirmRequiresCaptain(t *testing.T) {
 	w = doJSON(t, router, http.MethodPost, "/api/lineup/"+created.Resource.Lineup.ID.String()+"/official", nil, newToken(t, fx.commissioner))
 	require.Equal(t, http.StatusBadRequest, w.Code, "official before confirm: %s", w.Body.String())
 }
+
+func TestLineupRulesEnforcedOnConfirm(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	router := newTestRouter(t, db)
+	fx := newLineupFixture(t, db)
+
+	w := doJSON(t, router, http.MethodPost, "/api/lineup/set",
+		map[string]any{
+			"team_id": fx.team.ID.String(),
+			"week_id": fx.week.ID.String(),
+			"pairings": []map[string]any{{
+				"player1":           fx.captain.String(),
+				"player2":           fx.member.String(),
+				"format_line_index": 0,
+			}},
+		}, newToken(t, fx.captain))
+	require.Equal(t, http.StatusOK, w.Code, "set: %s", w.Body.String())
+	var created struct {
+		Resource *LineupDetail `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	confirmPath := "/api/lineup/" + created.Resource.Lineup.ID.String() + "/confirm"
+
+	// Only a commissioner may enable a rule.
+	enable := map[string]any{"season_id": fx.season.ID.String(), "rule": model.LineupRuleAvailability, "enabled": true}
+	w = doJSON(t, router, http.MethodPost, "/api/lineup/rules", enable, newToken(t, fx.captain))
+	require.Equal(t, http.StatusForbidden, w.Code, "captain enable: %s", w.Body.String())
+	w = doJSON(t, router, http.MethodPost, "/api/lineup/rules", enable, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusOK, w.Code, "commissioner enable: %s", w.Body.String())
+
+	w = doJSON(t, router, http.MethodGet, "/api/lineup/rules?season_id="+fx.season.ID.String(), nil, "")
+	require.Equal(t, http.StatusOK, w.Code, "list: %s", w.Body.String())
+	var listed struct {
+		Resource []*LineupRuleStatus `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Resource, len(model.GetLineupRules()))
+	for _, status := range listed.Resource {
+		require.Equal(t, status.Name == model.LineupRuleAvailability, status.Enabled, status.Name)
+	}
+
+	// Neither player has marked themselves available, so confirming fails.
+	w = doJSON(t, router, http.MethodPost, confirmPath, nil, newToken(t, fx.captain))
+	require.Equal(t, http.StatusBadRequest, w.Code, "confirm unavailable: %s", w.Body.String())
+	lineup, err := database.GetExistingRecordById(context.Background(), db, &model.Lineup{}, created.Resource.Lineup.ID.RecordId())
+	require.NoError(t, err)
+	require.False(t, lineup.Confirmed)
+
+	for _, player := range []database.UserId{fx.captain, fx.member} {
+		_, err := database.CreateOne(context.Background(), db, &model.Availability{UserId: player, WeekId: fx.week.ID, Available: model.AvailabilityAvailable})
+		require.NoError(t, err)
+	}
+	w = doJSON(t, router, http.MethodPost, confirmPath, nil, newToken(t, fx.captain))
+	require.Equal(t, http.StatusOK, w.Code, "confirm available: %s", w.Body.String())
+}
+
+func TestBlindLineupHiddenUntilBothConfirmed(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	router := newTestRouter(t, db)
+	fx := newLineupFixture(t, db)
+	ctx := context.Background()
+
+	fx.season.BlindLineups = true
+	require.NoError(t, database.UpdateOne(ctx, db, fx.season))
+
+	// Schedule an opponent for the week.
+	opponentCaptain := newStoredUser(t, db)
+	opponent, err := database.CreateOne(ctx, db, model.NewDefaultTeam(opponentCaptain.ID, "Team B"))
+	require.NoError(t, err)
+	require.NoError(t, fx.season.AddTeam(ctx, db, opponent.ID))
+	wm := model.NewWeeklyMatchup()
+	wm.WeekId = fx.week.ID
+	wm.SeasonId = fx.season.ID
+	wmV, err := database.CreateOne(ctx, db, wm)
+	require.NoError(t, err)
+	require.NoError(t, wmV.SetMatchups(ctx, db, []*model.TeamMatchup{{HomeTeam: fx.team.ID, AwayTeam: opponent.ID}}))
+
+	w := doJSON(t, router, http.MethodPost, "/api/lineup/set",
+		map[string]any{
+			"team_id": fx.team.ID.String(),
+			"week_id": fx.week.ID.String(),
+			"pairings": []map[string]any{{
+				"player1":           fx.captain.String(),
+				"player2":           fx.member.String(),
+				"format_line_index": 0,
+			}},
+		}, newToken(t, fx.captain))
+	require.Equal(t, http.StatusOK, w.Code, "set: %s", w.Body.String())
+	var created struct {
+		Resource *LineupDetail `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.Len(t, created.Resource.Pairings, 1)
+	pairingPath := "/api/lineup_pairing/" + created.Resource.Pairings[0].ID.String()
+	w = doJSON(t, router, http.MethodPost, "/api/lineup/"+created.Resource.Lineup.ID.String()+"/confirm", nil, newToken(t, fx.captain))
+	require.Equal(t, http.StatusOK, w.Code, "confirm: %s", w.Body.String())
+
+	detailPath := "/api/lineup/detail?team_id=" + fx.team.ID.String() + "&week_id=" + fx.week.ID.String()
+	getDetail := func(token string) *LineupDetail {
+		w := doJSON(t, router, http.MethodGet, detailPath, nil, token)
+		require.Equal(t, http.StatusOK, w.Code, "detail: %s", w.Body.String())
+		var body struct {
+			Resource *LineupDetail `json:"resource"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body.Resource
+	}
+
+	// The opponent has not confirmed yet: only Team A's members see the lineup.
+	detail := getDetail(newToken(t, fx.member))
+	require.False(t, detail.Hidden)
+	require.Len(t, detail.Pairings, 1)
+	detail = getDetail(newToken(t, opponentCaptain.ID))
+	require.True(t, detail.Hidden)
+	require.Empty(t, detail.Pairings)
+	require.True(t, getDetail("").Hidden)
+
+	w = doJSON(t, router, http.MethodGet, pairingPath, nil, newToken(t, opponentCaptain.ID))
+	require.Equal(t, http.StatusNotFound, w.Code, "opponent get pairing: %s", w.Body.String())
+	w = doJSON(t, router, http.MethodGet, "/api/lineup_pairing", nil, newToken(t, opponentCaptain.ID))
+	require.Equal(t, http.StatusOK, w.Code, "opponent list pairings: %s", w.Body.String())
+	var listed struct {
+		Resource []*model.LineupPairing `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Empty(t, listed.Resource)
+
+	// Once the opponent confirms, both lineups are revealed.
+	_, err = database.CreateOne(ctx, db, &model.Lineup{TeamId: opponent.ID, WeekId: fx.week.ID, Confirmed: true})
+	require.NoError(t, err)
+	detail = getDetail(newToken(t, opponentCaptain.ID))
+	require.False(t, detail.Hidden)
+	require.Len(t, detail.Pairings, 1)
+	w = doJSON(t, router, http.MethodGet, pairingPath, nil, newToken(t, opponentCaptain.ID))
+	require.Equal(t, http.StatusOK, w.Code, "opponent get pairing after reveal: %s", w.Body.String())
+}
+
+func TestLineupRevisionsAfterOfficial(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	router := newTestRouter(t, db)
+	fx := newLineupFixture(t, db)
+	ctx := context.Background()
+
+	// A second member who can stand in for the captain on line 0.
+	standIn := newStoredUser(t, db)
+	addAssignment(t, db, fx.team, standIn, model.TeamRoleMember)
+	_, err := database.CreateOne(ctx, db, &model.TeamRating{TeamId: fx.team.ID, UserId: standIn.ID, RatingId: fx.line.Player1Rating})
+	require.NoError(t, err)
+
+	setBody := func(player1 database.UserId, reason string) map[string]any {
+		return map[string]any{
+			"team_id": fx.team.ID.String(),
+			"week_id": fx.week.ID.String(),
+			"reason":  reason,
+			"pairings": []map[string]any{{
+				"player1":           player1.String(),
+				"player2":           fx.member.String(),
+				"format_line_index": 0,
+			}},
+		}
+	}
+	w := doJSON(t, router, http.MethodPost, "/api/lineup/set", setBody(fx.captain, ""), newToken(t, fx.captain))
+	require.Equal(t, http.StatusOK, w.Code, "set: %s", w.Body.String())
+	var created struct {
+		Resource *LineupDetail `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	lineupIDStr := created.Resource.Lineup.ID.String()
+
+	w = doJSON(t, router, http.MethodPost, "/api/lineup/"+lineupIDStr+"/confirm", nil, newToken(t, fx.captain))
+	require.Equal(t, http.StatusOK, w.Code, "confirm: %s", w.Body.String())
+
+	// The generic pairing routes can't change a confirmed lineup, which would
+	// skip its revision history.
+	pairing := created.Resource.Pairings[0]
+	pairingPath := "/api/lineup_pairing/" + pairing.ID.String()
+	pairingBody := map[string]any{
+		"lineup_id":         lineupIDStr,
+		"team_id":           fx.team.ID.String(),
+		"player1":           standIn.ID.String(),
+		"player2":           fx.member.String(),
+		"format_line_index": 0,
+	}
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		path := pairingPath
+		if method == http.MethodPost {
+			path = "/api/lineup_pairing"
+		}
+		w = doJSON(t, router, method, path, pairingBody, newToken(t, fx.captain))
+		require.Equal(t, http.StatusBadRequest, w.Code, "%s pairing: %s", method, w.Body.String())
+		require.Contains(t, w.Body.String(), "/lineup/set")
+	}
+	unchanged, err := database.GetExistingRecordById(ctx, db, &model.LineupPairing{}, pairing.ID.RecordId())
+	require.NoError(t, err)
+	require.Equal(t, fx.captain, unchanged.Player1)
+
+	// Changing a confirmed lineup needs a reason, and is recorded straight away.
+	w = doJSON(t, router, http.MethodPost, "/api/lineup/set", setBody(fx.captain, ""), newToken(t, fx.captain))
+	require.Equal(t, http.StatusBadRequest, w.Code, "set without reason: %s", w.Body.String())
+	w = doJSON(t, router, http.MethodPost, "/api/lineup/set", setBody(standIn.ID, "captain is travelling"), newToken(t, fx.captain))
+	require.Equal(t, http.StatusOK, w.Code, "set after confirm: %s", w.Body.String())
+
+	w = doJSON(t, router, http.MethodPost, "/api/lineup/"+lineupIDStr+"/official", nil, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusOK, w.Code, "official: %s", w.Body.String())
+
+	// After official, the change is held for the commissioner.
+	w = doJSON(t, router, http.MethodPost, "/api/lineup/set", setBody(fx.captain, "captain is back"), newToken(t, fx.captain))
+	require.Equal(t, http.StatusAccepted, w.Code, "set after official: %s", w.Body.String())
+	var pending struct {
+		Resource *model.LineupRevision `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pending))
+	require.Equal(t, model.LineupRevisionPending, pending.Resource.Status)
+	require.Equal(t, 3, pending.Resource.Revision)
+	revisionIDStr := pending.Resource.ID.String()
+
+	lineup, err := database.GetExistingRecordById(ctx, db, &model.Lineup{}, created.Resource.Lineup.ID.RecordId())
+	require.NoError(t, err)
+	live, err := lineup.GetLineupPairings(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, standIn.ID, live[0].Player1)
+
+	w = doJSON(t, router, http.MethodPost, "/api/lineup/revision/"+revisionIDStr+"/approve", nil, newToken(t, fx.captain))
+	require.Equal(t, http.StatusForbidden, w.Code, "captain approve: %s", w.Body.String())
+	w = doJSON(t, router, http.MethodPost, "/api/lineup/revision/"+revisionIDStr+"/approve", nil, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusOK, w.Code, "approve: %s", w.Body.String())
+
+	live, err = lineup.GetLineupPairings(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, fx.captain, live[0].Player1)
+
+	w = doJSON(t, router, http.MethodGet, "/api/lineup/"+lineupIDStr+"/revisions", nil, newToken(t, fx.member))
+	require.Equal(t, http.StatusOK, w.Code, "revisions: %s", w.Body.String())
+	var history struct {
+		Resource []*LineupRevisionDetail `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	require.Len(t, history.Resource, 3)
+	require.Equal(t, "captain is travelling", history.Resource[1].Reason)
+	require.Equal(t, fx.captain, history.Resource[1].EditedBy)
+	require.Equal(t, fx.commissioner, history.Resource[2].ReviewedBy)
+	require.Equal(t, standIn.ID, history.Resource[1].Pairings[0].Player1)
+
+	// The diff between the confirmed lineup and the stand-in shows line 0.
+	w = doJSON(t, router, http.MethodGet, "/api/lineup/"+lineupIDStr+"/diff?from=1&to=2", nil, newToken(t, fx.member))
+	require.Equal(t, http.StatusOK, w.Code, "diff: %s", w.Body.String())
+	var diff struct {
+		Resource *LineupRevisionDiff `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	require.Len(t, diff.Resource.Changes, 1)
+	require.Equal(t, fx.captain, diff.Resource.Changes[0].Before.Player1)
+	require.Equal(t, standIn.ID, diff.Resource.Changes[0].After.Player1)
+
+	w = doJSON(t, router, http.MethodGet, "/api/lineup/"+lineupIDStr+"/diff?from=1&to=3", nil, newToken(t, fx.member))
+	require.Equal(t, http.StatusOK, w.Code, "diff: %s", w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	require.Empty(t, diff.Resource.Changes)
+}
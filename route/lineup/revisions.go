@@ -0,0 +1,221 @@
+package lineup
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LineupRevisionDetail is the wire representation of a LineupRevision along
+// with the pairings it captured.
+type LineupRevisionDetail struct {
+	*model.LineupRevision
+	Pairings []*model.LineupRevisionPairing `json:"pairings"`
+}
+
+// LineupRevisionDiff is the wire representation of the lines that changed
+// between two revisions of a lineup.
+type LineupRevisionDiff struct {
+	LineupId model.LineupId            `json:"lineup_id"`
+	From     int                       `json:"from"`
+	To       int                       `json:"to"`
+	Changes  []*model.LineupLineChange `json:"changes"`
+}
+
+// getViewableLineup loads the lineup from the request path and checks that
+// the requesting user may see its pairings: the team's members, the season's
+// commissioners, and everyone else once the lineup is revealed.
+func getViewableLineup(req api.Request[*EmptyBody]) (*model.Lineup, int, error) {
+	lineup, exists, err := database.GetOneById(req.Context, req.DatabaseProvider, &model.Lineup{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if !exists {
+		return nil, http.StatusNotFound, errors.New("lineup not found")
+	}
+	userId := database.InvalidUserId
+	if req.Token != nil {
+		userId = req.Token.UserId
+	}
+	if isLineupCommissioner(req.Context, req.DatabaseProvider, userId, lineup) {
+		return lineup, http.StatusOK, nil
+	}
+	canView, err := lineup.CanUserViewPairings(req.Context, req.DatabaseProvider, userId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if !canView {
+		return nil, http.StatusForbidden, errors.New("lineup pairings are hidden until both teams have confirmed")
+	}
+	return lineup, http.StatusOK, nil
+}
+
+// ListLineupRevisions returns a lineup's revision history, oldest first, with
+// each revision's pairings.
+type ListLineupRevisions struct{}
+
+func (c ListLineupRevisions) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, api.AppendPathId(BaseRoute) + "/revisions"
+}
+
+func (c ListLineupRevisions) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c ListLineupRevisions) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	lineup, status, err := getViewableLineup(req)
+	if err != nil {
+		return nil, status, err
+	}
+	revisions, err := lineup.GetRevisions(req.Context, req.DatabaseProvider)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	details := make([]*LineupRevisionDetail, 0, len(revisions))
+	for _, revision := range revisions {
+		pairings, err := revision.GetPairings(req.Context, req.DatabaseProvider)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		details = append(details, &LineupRevisionDetail{LineupRevision: revision, Pairings: pairings})
+	}
+	return gin.H{api.ResourceKey: details}, http.StatusOK, nil
+}
+
+// DiffLineupRevisions returns the lines that changed between two revisions of
+// a lineup, given by revision number in the from and to query parameters. If
+// to is omitted the latest revision is used, and if from is omitted the
+// revision before to is used.
+type DiffLineupRevisions struct{}
+
+func (c DiffLineupRevisions) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, api.AppendPathId(BaseRoute) + "/diff"
+}
+
+func (c DiffLineupRevisions) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c DiffLineupRevisions) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	lineup, status, err := getViewableLineup(req)
+	if err != nil {
+		return nil, status, err
+	}
+	revisions, err := lineup.GetRevisions(req.Context, req.DatabaseProvider)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if len(revisions) == 0 {
+		return nil, http.StatusBadRequest, errors.New("lineup has no revisions")
+	}
+
+	query := req.HTTPRequest().URL.Query()
+	to := revisions[len(revisions)-1].Revision
+	if toStr := query.Get("to"); toStr != "" {
+		to, err = strconv.Atoi(toStr)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	from := to - 1
+	if fromStr := query.Get("from"); fromStr != "" {
+		from, err = strconv.Atoi(fromStr)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+
+	fromRevision, err := lineup.GetRevision(req.Context, req.DatabaseProvider, from)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if fromRevision == nil {
+		return nil, http.StatusBadRequest, errors.New("from revision not found")
+	}
+	toRevision, err := lineup.GetRevision(req.Context, req.DatabaseProvider, to)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if toRevision == nil {
+		return nil, http.StatusBadRequest, errors.New("to revision not found")
+	}
+
+	changes, err := model.DiffLineupRevisions(req.Context, req.DatabaseProvider, fromRevision, toRevision)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	diff := &LineupRevisionDiff{LineupId: lineup.ID, From: from, To: to, Changes: changes}
+	return gin.H{api.ResourceKey: diff}, http.StatusOK, nil
+}
+
+// getReviewableRevision loads the revision from the request path and checks
+// that the requesting user is a commissioner of its lineup's season.
+func getReviewableRevision(req api.Request[*EmptyBody]) (*model.LineupRevision, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	revision, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.LineupRevision{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	lineup, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Lineup{}, revision.LineupId.RecordId())
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if !isLineupCommissioner(req.Context, req.DatabaseProvider, req.Token.UserId, lineup) {
+		return nil, http.StatusForbidden, errors.New("only a season commissioner may review lineup changes")
+	}
+	return revision, http.StatusOK, nil
+}
+
+// ApproveLineupRevision applies a pending change to an official lineup. Only
+// a season commissioner may approve a change.
+type ApproveLineupRevision struct{}
+
+func (c ApproveLineupRevision) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, api.AppendPathId(BaseRoute+"/revision") + "/approve"
+}
+
+func (c ApproveLineupRevision) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c ApproveLineupRevision) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	revision, status, err := getReviewableRevision(req)
+	if err != nil {
+		return nil, status, err
+	}
+	if err := revision.Approve(req.Context, req.DatabaseProvider, req.Token.UserId); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return gin.H{api.ResourceKey: revision}, http.StatusOK, nil
+}
+
+// RejectLineupRevision discards a pending change to an official lineup. Only
+// a season commissioner may reject a change.
+type RejectLineupRevision struct{}
+
+func (c RejectLineupRevision) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, api.AppendPathId(BaseRoute+"/revision") + "/reject"
+}
+
+func (c RejectLineupRevision) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c RejectLineupRevision) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	revision, status, err := getReviewableRevision(req)
+	if err != nil {
+		return nil, status, err
+	}
+	if err := revision.Reject(req.Context, req.DatabaseProvider, req.Token.UserId); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return gin.H{api.ResourceKey: revision}, http.StatusOK, nil
+}
@@ -20,6 +20,29 @@ import (
 //	POST /lineup/set                        -> build/replace a weekly lineup
 //	POST /lineup/:id/confirm                -> captain confirms the lineup
 //	POST /lineup/:id/official               -> commissioner marks it official
+//	GET  /lineup/rules?season_id=           -> lineup rules and their enablement
+//	POST /lineup/rules                      -> commissioner enables/disables a rule
+//	GET  /lineup/:id/revisions              -> revision history with pairings
+//	GET  /lineup/:id/diff?from=&to=         -> lines changed between two revisions
+//	POST /lineup/revision/:id/approve       -> commissioner applies a pending change
+//	POST /lineup/revision/:id/reject        -> commissioner discards a pending change
+//
+// Confirming a lineup or marking it official fails if the lineup breaks any
+// of the lineup rules the season's commissioners have enabled.
+//
+// Confirming a lineup records its pairings as revision 1, and every later
+// /lineup/set call (which then needs a reason) adds a revision. Once the
+// lineup is official, changes are held as pending revisions until a
+// commissioner approves them; match generation uses the latest approved
+// revision. The generic lineup_pairing routes refuse to write the pairings of
+// a confirmed lineup, so every change after confirmation goes through this
+// flow.
+//
+// In a season with blind_lineups set, a team's pairings are visible only to its
+// own members (on both the generic lineup_pairing routes and /lineup/detail)
+// until both teams in the week's matchup have confirmed their lineups. From
+// then on, changes wait for a commissioner's approval as for an official
+// lineup.
 func RegisterRoutes(e *gin.RouterGroup, db database.Provider) {
 	lineups := api.NewCrudCommon(model.NewLineup, false, db)
 	lineups.HandleRouteTypes(e, api.CrudWrapperFunctionAll...)
@@ -38,4 +61,10 @@ func RegisterRoutes(e *gin.RouterGroup, db database.Provider) {
 
 	official := api.RouteFamily[*EmptyBody]{DatabaseProvider: db}
 	official.Handle(e, MarkOfficial{})
+
+	rules := api.RouteFamily[*SetLineupRuleBody]{DatabaseProvider: db}
+	rules.Handle(e, GetLineupRules{}, SetLineupRule{})
+
+	revisions := api.RouteFamily[*EmptyBody]{DatabaseProvider: db}
+	revisions.Handle(e, ListLineupRevisions{}, DiffLineupRevisions{}, ApproveLineupRevision{}, RejectLineupRevision{})
 }
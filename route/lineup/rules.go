@@ -0,0 +1,136 @@
+package lineup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LineupRuleStatus is the wire representation of a registered lineup rule and
+// whether it is enabled for a season.
+type LineupRuleStatus struct {
+	Name        model.LineupRuleName `json:"name"`
+	Description string               `json:"description"`
+	Enabled     bool                 `json:"enabled"`
+}
+
+// lineupRuleStatuses lists every registered lineup rule with its enablement
+// for the season.
+func lineupRuleStatuses(ctx context.Context, db database.Provider, seasonId model.SeasonId) ([]*LineupRuleStatus, error) {
+	enabled, err := model.GetEnabledLineupRules(ctx, db, seasonId)
+	if err != nil {
+		return nil, err
+	}
+	isEnabled := make(map[model.LineupRuleName]bool, len(enabled))
+	for _, rule := range enabled {
+		isEnabled[rule.Name()] = true
+	}
+	rules := model.GetLineupRules()
+	statuses := make([]*LineupRuleStatus, 0, len(rules))
+	for _, rule := range rules {
+		statuses = append(statuses, &LineupRuleStatus{
+			Name:        rule.Name(),
+			Description: rule.Description(),
+			Enabled:     isEnabled[rule.Name()],
+		})
+	}
+	return statuses, nil
+}
+
+// SetLineupRuleBody is the request body for SetLineupRule. GetLineupRules
+// shares it but reads season_id from the query string instead.
+type SetLineupRuleBody struct {
+	SeasonId model.SeasonId       `json:"season_id"`
+	Rule     model.LineupRuleName `json:"rule"`
+	Enabled  bool                 `json:"enabled"`
+}
+
+// StaticallyValid ensures a season and a known rule are specified.
+func (b *SetLineupRuleBody) StaticallyValid() error {
+	if b.SeasonId.RecordId() == database.InvalidRecordId {
+		return errors.New("season_id must be set")
+	}
+	if _, ok := model.GetLineupRule(b.Rule); !ok {
+		return errors.New("rule must be a registered lineup rule")
+	}
+	return nil
+}
+
+// GetLineupRules lists every lineup rule and whether it is enabled for the
+// season. It is viewable by everyone.
+type GetLineupRules struct{}
+
+func (c GetLineupRules) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, BaseRoute + "/rules"
+}
+
+func (c GetLineupRules) RequestBody() (*SetLineupRuleBody, bool) {
+	return &SetLineupRuleBody{}, false
+}
+
+func (c GetLineupRules) Handler(req api.Request[*SetLineupRuleBody]) (any, int, error) {
+	seasonStr := req.HTTPRequest().URL.Query().Get("season_id")
+	if seasonStr == "" {
+		return nil, http.StatusBadRequest, errors.New("season_id must be set")
+	}
+	rid, err := database.RecordIdFromString(seasonStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	season, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Season{}, rid)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	statuses, err := lineupRuleStatuses(req.Context, req.DatabaseProvider, season.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: statuses}, http.StatusOK, nil
+}
+
+// SetLineupRule enables or disables a lineup rule for a season. Only a season
+// commissioner may change the season's rules. Enabled rules are checked when a
+// lineup is confirmed and when it is marked official.
+type SetLineupRule struct{}
+
+func (c SetLineupRule) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, BaseRoute + "/rules"
+}
+
+func (c SetLineupRule) RequestBody() (*SetLineupRuleBody, bool) {
+	return &SetLineupRuleBody{}, true
+}
+
+func (c SetLineupRule) Handler(req api.Request[*SetLineupRuleBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	season, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Season{}, req.Body.SeasonId.RecordId())
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if !isSeasonCommissioner(req.Context, req.DatabaseProvider, req.Token.UserId, season.ID) {
+		return nil, http.StatusForbidden, errors.New("only a season commissioner may change the season's lineup rules")
+	}
+
+	if req.Body.Enabled {
+		err = model.EnableLineupRule(req.Context, req.DatabaseProvider, season.ID, req.Body.Rule)
+	} else {
+		err = model.DisableLineupRule(req.Context, req.DatabaseProvider, season.ID, req.Body.Rule)
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	statuses, err := lineupRuleStatuses(req.Context, req.DatabaseProvider, season.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: statuses}, http.StatusOK, nil
+}
@@ -0,0 +1,274 @@
+package match
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// isTeamCaptain reports whether the user is the captain or a co-captain of the
+// team.
+func isTeamCaptain(ctx context.Context, db database.Provider, userId database.UserId, teamId model.TeamId) bool {
+	if userId == database.InvalidUserId {
+		return false
+	}
+	for _, uid := range model.EditableByTeamCaptainOrCoCaptains(ctx, db, teamId) {
+		if uid == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// captainedTeamForMatch returns the TeamMatch an individual match belongs to
+// and which of its teams the user captains or co-captains, if either. The
+// TeamMatch is nil for a match that isn't part of one, and the team is unset
+// when the user captains neither side.
+func captainedTeamForMatch(ctx context.Context, db database.Provider, userId database.UserId, matchId model.IndividualMatchId) (*model.TeamMatch, model.TeamId, error) {
+	tm, _, err := model.GetTeamMatchForIndividualMatch(ctx, db, matchId)
+	if err != nil || tm == nil {
+		return nil, model.TeamId(database.InvalidRecordId), err
+	}
+	for _, teamId := range []model.TeamId{tm.HomeTeam, tm.AwayTeam} {
+		if isTeamCaptain(ctx, db, userId, teamId) {
+			return tm, teamId, nil
+		}
+	}
+	return tm, model.TeamId(database.InvalidRecordId), nil
+}
+
+// teamMatchSeasonId resolves the season a team match is played in.
+func teamMatchSeasonId(ctx context.Context, db database.Provider, tm *model.TeamMatch) (model.SeasonId, error) {
+	week, err := database.GetExistingRecordById(ctx, db, &model.Week{}, tm.WeekId.RecordId())
+	if err != nil {
+		return model.SeasonId(database.InvalidRecordId), err
+	}
+	season, err := weekSeason(ctx, db, week)
+	if err != nil {
+		return model.SeasonId(database.InvalidRecordId), err
+	}
+	return season.ID, nil
+}
+
+// scoreConflictStatus maps the errors returned by the score confirmation flow
+// onto a response status: 409 when the report's state forbids the change, 400
+// otherwise.
+func scoreConflictStatus(err error) int {
+	if errors.Is(err, model.ErrScoreFinal) || errors.Is(err, model.ErrScoreDisputed) || errors.Is(err, model.ErrScoreAwaitingResponse) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// checkScoreEditable ensures the user may still change the score of an
+// individual match given its ScoreReport, returning the team the user is
+// acting for (unset when acting as a commissioner or match editor).
+func checkScoreEditable(ctx context.Context, db database.Provider, userId database.UserId, im *model.IndividualMatch) (model.TeamId, int, error) {
+	_, team, err := captainedTeamForMatch(ctx, db, userId, im.ID)
+	if err != nil {
+		return team, http.StatusBadRequest, err
+	}
+	if err := im.CheckScoreEditable(ctx, db, team); err != nil {
+		return team, scoreConflictStatus(err), err
+	}
+	return team, http.StatusOK, nil
+}
+
+// reportMatchScore files the completed result of an individual match on behalf
+// of team (unset for a commissioner), so that the opposing captain can confirm
+// it. Matches outside a TeamMatch need no confirmation.
+func reportMatchScore(ctx context.Context, db database.Provider, userId database.UserId, im *model.IndividualMatch, team model.TeamId) error {
+	tm, _, err := model.GetTeamMatchForIndividualMatch(ctx, db, im.ID)
+	if err != nil || tm == nil {
+		return err
+	}
+	_, err = im.ReportScore(ctx, db, userId, team)
+	return err
+}
+
+// getRespondingCaptain loads the individual match from the request path along
+// with its ScoreReport and the team the requesting user captains in it.
+func getRespondingCaptain(ctx context.Context, db database.Provider, userId database.UserId, matchId model.IndividualMatchId) (*model.ScoreReport, model.TeamId, int, error) {
+	im, err := database.GetExistingRecordById(ctx, db, &model.IndividualMatch{}, matchId.RecordId())
+	if err != nil {
+		return nil, model.TeamId(database.InvalidRecordId), http.StatusBadRequest, err
+	}
+	_, team, err := captainedTeamForMatch(ctx, db, userId, im.ID)
+	if err != nil {
+		return nil, team, http.StatusBadRequest, err
+	}
+	if team.RecordId() == database.InvalidRecordId {
+		return nil, team, http.StatusForbidden, errors.New("only a captain or co-captain of the opposing team may respond to a reported score")
+	}
+	report, err := im.GetScoreReport(ctx, db)
+	if err != nil {
+		return nil, team, http.StatusBadRequest, err
+	}
+	if report == nil {
+		return nil, team, http.StatusNotFound, errors.New("match score has not been reported")
+	}
+	return report, team, http.StatusOK, nil
+}
+
+// ConfirmScore accepts the score reported by the other team for an individual
+// match, making it final. Only a captain or co-captain of the opposing team may
+// confirm a score.
+type ConfirmScore struct{}
+
+func (c ConfirmScore) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, api.AppendPathId(BaseRoute) + "/confirm"
+}
+
+func (c ConfirmScore) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c ConfirmScore) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	report, team, status, err := getRespondingCaptain(req.Context, req.DatabaseProvider, req.Token.UserId, model.IndividualMatchId(req.PathId))
+	if err != nil {
+		return nil, status, err
+	}
+	if err := report.Confirm(req.Context, req.DatabaseProvider, req.Token.UserId, team); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	publishMatchUpdate(req.Context, req.DatabaseProvider, model.IndividualMatchId(req.PathId))
+	return gin.H{api.ResourceKey: report}, http.StatusOK, nil
+}
+
+// ScoreClaimBody is the request body for DisputeScore and ResolveScore: a
+// match's final score from the point of view of the side in the request path.
+type ScoreClaimBody struct {
+	model.ScoreClaim
+	Note string `json:"note"`
+}
+
+// StaticallyValid ensures the claimed scores are non-negative.
+func (b *ScoreClaimBody) StaticallyValid() error {
+	return b.ScoreClaim.StaticallyValid()
+}
+
+// DisputeScore rejects the score reported by the other team for an individual
+// match, attaching the disputing team's own claim for the season commissioners
+// to resolve. Only a captain or co-captain of the opposing team may dispute a
+// score.
+type DisputeScore struct{}
+
+func (c DisputeScore) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, api.AppendPathId(BaseRoute) + "/dispute"
+}
+
+func (c DisputeScore) RequestBody() (*ScoreClaimBody, bool) {
+	return &ScoreClaimBody{}, true
+}
+
+func (c DisputeScore) Handler(req api.Request[*ScoreClaimBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	matchId := model.IndividualMatchId(req.PathId)
+	report, team, status, err := getRespondingCaptain(req.Context, req.DatabaseProvider, req.Token.UserId, matchId)
+	if err != nil {
+		return nil, status, err
+	}
+	claim := report.ClaimFor(matchId, req.Body.ScoreClaim)
+	if err := report.Dispute(req.Context, req.DatabaseProvider, req.Token.UserId, team, claim, req.Body.Note); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	publishMatchUpdate(req.Context, req.DatabaseProvider, matchId)
+	return gin.H{api.ResourceKey: report}, http.StatusOK, nil
+}
+
+// ResolveScore settles the reported score of an individual match with the
+// score given in the body, writing it onto both sides. Only a season
+// commissioner may resolve a score, and the resolution is final.
+type ResolveScore struct{}
+
+func (c ResolveScore) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, api.AppendPathId(BaseRoute) + "/resolve"
+}
+
+func (c ResolveScore) RequestBody() (*ScoreClaimBody, bool) {
+	return &ScoreClaimBody{}, true
+}
+
+func (c ResolveScore) Handler(req api.Request[*ScoreClaimBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	matchId := model.IndividualMatchId(req.PathId)
+	im, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.IndividualMatch{}, matchId.RecordId())
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	report, err := im.GetScoreReport(req.Context, req.DatabaseProvider)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if report == nil {
+		return nil, http.StatusNotFound, errors.New("match score has not been reported")
+	}
+	tm, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.TeamMatch{}, report.TeamMatchId.RecordId())
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	seasonId, err := teamMatchSeasonId(req.Context, req.DatabaseProvider, tm)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if !isSeasonCommissioner(req.Context, req.DatabaseProvider, req.Token.UserId, seasonId) {
+		return nil, http.StatusForbidden, errors.New("only a season commissioner may resolve a score")
+	}
+	claim := report.ClaimFor(matchId, req.Body.ScoreClaim)
+	if err := report.Resolve(req.Context, req.DatabaseProvider, req.Token.UserId, claim); err != nil {
+		return nil, scoreConflictStatus(err), err
+	}
+	publishMatchUpdate(req.Context, req.DatabaseProvider, matchId)
+	return gin.H{api.ResourceKey: report}, http.StatusOK, nil
+}
+
+// ListDisputes returns the season's disputed score reports, each with both
+// teams' claimed scores, for the season commissioners to resolve.
+type ListDisputes struct{}
+
+func (c ListDisputes) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, BaseRoute + "/disputes"
+}
+
+func (c ListDisputes) RequestBody() (*StandingsQuery, bool) {
+	return &StandingsQuery{}, false
+}
+
+func (c ListDisputes) Handler(req api.Request[*StandingsQuery]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	seasonStr := req.HTTPRequest().URL.Query().Get("season_id")
+	if seasonStr == "" {
+		return nil, http.StatusBadRequest, errors.New("season_id must be set")
+	}
+	rid, err := database.RecordIdFromString(seasonStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	season, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Season{}, rid)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if !isSeasonCommissioner(req.Context, req.DatabaseProvider, req.Token.UserId, season.ID) {
+		return nil, http.StatusForbidden, errors.New("only a season commissioner may view disputes")
+	}
+	reports, err := model.GetDisputedScoreReports(req.Context, req.DatabaseProvider, season)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return gin.H{api.ResourceKey: reports}, http.StatusOK, nil
+}
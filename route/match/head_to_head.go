@@ -0,0 +1,205 @@
+package match
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeadToHeadMeeting is one TeamMatch between the two captains' teams, with
+// the line score from the first captain's side.
+type HeadToHeadMeeting struct {
+	Date     time.Time     `json:"date"`
+	SeasonId string        `json:"season_id"` // empty if the week's draft has no season
+	TeamA    string        `json:"team_a_id"` // the first captain's team
+	TeamB    string        `json:"team_b_id"` // the second captain's team
+	WinsA    int           `json:"wins_a"`    // lines won by TeamA
+	WinsB    int           `json:"wins_b"`    // lines won by TeamB
+	Match    *TeamMatchDTO `json:"match"`     // score and line-by-line results
+}
+
+// HeadToHead is the history between two captains' teams across every season,
+// with the overall series record from the first captain's side. Only complete
+// team matches count toward the record.
+type HeadToHead struct {
+	CaptainA string               `json:"captain_a"`
+	CaptainB string               `json:"captain_b"`
+	Wins     int                  `json:"wins"`
+	Losses   int                  `json:"losses"`
+	Ties     int                  `json:"ties"`
+	Meetings []*HeadToHeadMeeting `json:"meetings"` // oldest first
+}
+
+// HeadToHeadQuery holds the query parameters for GetHeadToHead: either two
+// teams (team_a, team_b) or two captains (captain_a, captain_b).
+type HeadToHeadQuery struct {
+	TeamA    model.TeamId    `json:"team_a"`
+	TeamB    model.TeamId    `json:"team_b"`
+	CaptainA database.UserId `json:"captain_a"`
+	CaptainB database.UserId `json:"captain_b"`
+}
+
+// StaticallyValid has no static constraints; the parameters are checked by
+// the handler.
+func (q *HeadToHeadQuery) StaticallyValid() error { return nil }
+
+// GetHeadToHead lists every TeamMatch in which two rivals met, across all
+// seasons, with its score, its line-by-line results and the overall series
+// record. Teams are rebuilt every season, so rivals are identified by their
+// captains: given two teams, their captains (from Team.GetCaptain) are used,
+// and every team either captain has led counts as theirs. It is viewable by
+// everyone.
+type GetHeadToHead struct{}
+
+func (c GetHeadToHead) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, BaseRoute + "/head_to_head"
+}
+
+func (c GetHeadToHead) RequestBody() (*HeadToHeadQuery, bool) {
+	return &HeadToHeadQuery{}, false
+}
+
+func (c GetHeadToHead) Handler(req api.Request[*HeadToHeadQuery]) (any, int, error) {
+	captainA, captainB, err := headToHeadCaptains(req.Context, req.DatabaseProvider, req.HTTPRequest().URL.Query())
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	h2h, err := computeHeadToHead(req.Context, req.DatabaseProvider, captainA, captainB)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: h2h}, http.StatusOK, nil
+}
+
+// headToHeadCaptains resolves the two captains named by the query, either
+// directly or as the captains of two teams.
+func headToHeadCaptains(ctx context.Context, db database.Provider, query url.Values) (database.UserId, database.UserId, error) {
+	byTeam := query.Get("team_a") != "" || query.Get("team_b") != ""
+	byCaptain := query.Get("captain_a") != "" || query.Get("captain_b") != ""
+	if byTeam == byCaptain {
+		return database.InvalidUserId, database.InvalidUserId, errors.New("either team_a and team_b or captain_a and captain_b must be set")
+	}
+	names := [2]string{"captain_a", "captain_b"}
+	if byTeam {
+		names = [2]string{"team_a", "team_b"}
+	}
+	var captains [2]database.UserId
+	for i, name := range names {
+		value := query.Get(name)
+		if value == "" {
+			return database.InvalidUserId, database.InvalidUserId, errors.New(name + " must be set")
+		}
+		rid, err := database.RecordIdFromString(value)
+		if err != nil {
+			return database.InvalidUserId, database.InvalidUserId, err
+		}
+		if byTeam {
+			team, err := database.GetExistingRecordById(ctx, db, &model.Team{}, rid)
+			if err != nil {
+				return database.InvalidUserId, database.InvalidUserId, err
+			}
+			captains[i], err = team.GetCaptain(ctx, db)
+			if err != nil {
+				return database.InvalidUserId, database.InvalidUserId, err
+			}
+		} else {
+			user, err := database.GetExistingRecordById(ctx, db, &model.User{}, rid)
+			if err != nil {
+				return database.InvalidUserId, database.InvalidUserId, err
+			}
+			captains[i] = user.ID
+		}
+	}
+	if captains[0] == captains[1] {
+		return database.InvalidUserId, database.InvalidUserId, errors.New("head-to-head needs two different captains")
+	}
+	return captains[0], captains[1], nil
+}
+
+// computeHeadToHead finds every team match between a team captained by
+// captainA and one captained by captainB.
+func computeHeadToHead(ctx context.Context, db database.Provider, captainA, captainB database.UserId) (*HeadToHead, error) {
+	teams, err := database.GetAll[*model.Team](ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	captainOf := make(map[model.TeamId]database.UserId, len(teams))
+	for _, team := range teams {
+		// a team without a captain can't be matched to a rival
+		if captain, err := team.GetCaptain(ctx, db); err == nil {
+			captainOf[team.ID] = captain
+		}
+	}
+	teamMatches, err := database.GetAllWhere[*model.TeamMatch](ctx, db, func(_ context.Context, tm *model.TeamMatch) bool {
+		home, away := captainOf[tm.HomeTeam], captainOf[tm.AwayTeam]
+		return (home == captainA && away == captainB) || (home == captainB && away == captainA)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h2h := &HeadToHead{
+		CaptainA: captainA.String(),
+		CaptainB: captainB.String(),
+		Meetings: make([]*HeadToHeadMeeting, 0, len(teamMatches)),
+	}
+	for _, tm := range teamMatches {
+		week, err := database.GetExistingRecordById(ctx, db, &model.Week{}, tm.WeekId.RecordId())
+		if err != nil {
+			return nil, err
+		}
+		season, err := weekSeason(ctx, db, week)
+		if err != nil {
+			return nil, err
+		}
+		var treatments model.OutcomeTreatments
+		meeting := &HeadToHeadMeeting{Date: week.Date}
+		if season != nil {
+			meeting.SeasonId = season.ID.RecordId().String()
+			treatments, err = model.GetOutcomeTreatments(ctx, db, season.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		meeting.Match, err = buildTeamMatchDTO(ctx, db, tm, treatments)
+		if err != nil {
+			return nil, err
+		}
+
+		teamA, teamB := tm.HomeTeam, tm.AwayTeam
+		meeting.WinsA, meeting.WinsB = meeting.Match.HomeWins, meeting.Match.AwayWins
+		if captainOf[tm.HomeTeam] != captainA {
+			teamA, teamB = tm.AwayTeam, tm.HomeTeam
+			meeting.WinsA, meeting.WinsB = meeting.Match.AwayWins, meeting.Match.HomeWins
+		}
+		meeting.TeamA, meeting.TeamB = teamA.RecordId().String(), teamB.RecordId().String()
+		h2h.Meetings = append(h2h.Meetings, meeting)
+
+		switch {
+		case !meeting.Match.Complete:
+		case meeting.Match.Winner == meeting.TeamA:
+			h2h.Wins++
+		case meeting.Match.Winner == meeting.TeamB:
+			h2h.Losses++
+		default:
+			h2h.Ties++
+		}
+	}
+	sort.Slice(h2h.Meetings, func(i, j int) bool {
+		a, b := h2h.Meetings[i], h2h.Meetings[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.Match.ID < b.Match.ID
+	})
+	return h2h, nil
+}
@@ -0,0 +1,149 @@
+package match
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScoreEventBody is the request body for RecordScoreEvent.
+type ScoreEventBody struct {
+	IndividualMatchId string                  `json:"individual_match_id"` // side that won the unit
+	Unit              model.ScoreCountingType `json:"unit"`                // point, game or set
+}
+
+// StaticallyValid ensures an individual match and a valid unit are specified.
+func (b *ScoreEventBody) StaticallyValid() error {
+	if b.IndividualMatchId == "" {
+		return errors.New("individual_match_id must be set")
+	}
+	return b.Unit.StaticallyValid()
+}
+
+// RecordScoreEvent appends a point, game or set won by one side to the match's
+// live scoring log, returning the replayed LiveScore. Both sides' aggregate
+// scores (and the winner, once decided) are kept in step with the log, and the
+// score is reported for confirmation as soon as the match is decided. Events
+// are refused once the match is completed, or if it was scored through
+// /match/score before any event was recorded.
+type RecordScoreEvent struct{}
+
+func (c RecordScoreEvent) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, BaseRoute + "/event"
+}
+
+func (c RecordScoreEvent) RequestBody() (*ScoreEventBody, bool) {
+	return &ScoreEventBody{}, true
+}
+
+func (c RecordScoreEvent) Handler(req api.Request[*ScoreEventBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	matchRid, err := database.RecordIdFromString(req.Body.IndividualMatchId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	matchId := model.IndividualMatchId(matchRid)
+	if !canEditMatch(req.Context, req.DatabaseProvider, req.Token.UserId, matchId) {
+		return nil, http.StatusForbidden, errors.New("you are not an editor of this match")
+	}
+	im, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.IndividualMatch{}, matchId.RecordId())
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	team, status, err := checkScoreEditable(req.Context, req.DatabaseProvider, req.Token.UserId, im)
+	if err != nil {
+		return nil, status, err
+	}
+	score, err := im.RecordScoreEvent(req.Context, req.DatabaseProvider, req.Body.Unit, req.Token.UserId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if score.Status == model.MatchWon || score.Status == model.MatchLost {
+		if err := reportMatchScore(req.Context, req.DatabaseProvider, req.Token.UserId, im, team); err != nil {
+			return nil, scoreConflictStatus(err), err
+		}
+	}
+	publishMatchUpdate(req.Context, req.DatabaseProvider, im.ID)
+	return gin.H{api.ResourceKey: score}, http.StatusOK, nil
+}
+
+// GetLiveScore returns the LiveScore of an individual match, replayed from its
+// event log. It is viewable by everyone.
+type GetLiveScore struct{}
+
+func (c GetLiveScore) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, api.AppendPathId(BaseRoute) + "/live"
+}
+
+func (c GetLiveScore) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c GetLiveScore) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	im, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.IndividualMatch{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	score, err := im.ReplayMatchScore(req.Context, req.DatabaseProvider)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return gin.H{api.ResourceKey: score}, http.StatusOK, nil
+}
+
+// UndoScoreEvents removes the most recent events from an individual match's
+// live scoring log and returns the replayed LiveScore. The number of events to
+// remove is given by the count query parameter, defaulting to 1. Undoing the
+// deciding event withdraws the match's reported score.
+type UndoScoreEvents struct{}
+
+func (c UndoScoreEvents) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, api.AppendPathId(BaseRoute) + "/undo"
+}
+
+func (c UndoScoreEvents) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c UndoScoreEvents) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	matchId := model.IndividualMatchId(req.PathId)
+	if !canEditMatch(req.Context, req.DatabaseProvider, req.Token.UserId, matchId) {
+		return nil, http.StatusForbidden, errors.New("you are not an editor of this match")
+	}
+	im, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.IndividualMatch{}, matchId.RecordId())
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if _, status, err := checkScoreEditable(req.Context, req.DatabaseProvider, req.Token.UserId, im); err != nil {
+		return nil, status, err
+	}
+	count := 1
+	if countStr := req.HTTPRequest().URL.Query().Get("count"); countStr != "" {
+		count, err = strconv.Atoi(countStr)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	score, err := im.UndoScoreEvents(req.Context, req.DatabaseProvider, count)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if score.Status != model.MatchWon && score.Status != model.MatchLost {
+		if err := im.WithdrawScoreReport(req.Context, req.DatabaseProvider); err != nil {
+			return nil, scoreConflictStatus(err), err
+		}
+	}
+	publishMatchUpdate(req.Context, req.DatabaseProvider, im.ID)
+	return gin.H{api.ResourceKey: score}, http.StatusOK, nil
+}
@@ -33,8 +33,8 @@ func isSeasonCommissioner(ctx context.Context, db database.Provider, userId data
 
 // canEditMatch reports whether the requesting user may record/complete a score
 // on an individual match: they are a listed match editor (the season
-// commissioners are added as editors when matches are generated), or a
-// sysadmin.
+// commissioners are added as editors when matches are generated), a captain or
+// co-captain of either team in the match's TeamMatch, or a sysadmin.
 func canEditMatch(ctx context.Context, db database.Provider, userId database.UserId, matchId model.IndividualMatchId) bool {
 	if userId == database.InvalidUserId {
 		return false
@@ -45,6 +45,9 @@ func canEditMatch(ctx context.Context, db database.Provider, userId database.Use
 	if err == nil && len(editors) > 0 {
 		return true
 	}
+	if _, team, err := captainedTeamForMatch(ctx, db, userId, matchId); err == nil && team.RecordId() != database.InvalidRecordId {
+		return true
+	}
 	if isAdmin, err := model.IsUserSystemAdministrator(ctx, db, userId); err == nil && isAdmin {
 		return true
 	}
@@ -75,20 +78,6 @@ func officialLineupForTeamWeek(ctx context.Context, db database.Provider, teamId
 	return lineups[0], nil
 }
 
-// pairingsForLineup returns a lineup's pairings sorted by format line index.
-func pairingsForLineup(ctx context.Context, db database.Provider, lineupId model.LineupId) ([]*model.LineupPairing, error) {
-	pairings, err := database.GetAllWhere[*model.LineupPairing](ctx, db, func(_ context.Context, p *model.LineupPairing) bool {
-		return p.LineupId == lineupId
-	})
-	if err != nil {
-		return nil, err
-	}
-	sort.Slice(pairings, func(i, j int) bool {
-		return pairings[i].FormatLineIndex < pairings[j].FormatLineIndex
-	})
-	return pairings, nil
-}
-
 // hasScore reports whether a side of an individual match has been scored yet.
 func hasScore(m *model.IndividualMatch) bool {
 	return m.WinOverride || m.MainValue > 0 || m.SecondaryValue > 0
@@ -103,26 +92,30 @@ type IndividualMatchDTO struct {
 	SecondaryValue  int    `json:"secondary_value"`
 	WinOverride     bool   `json:"win_override"`
 	Status          int    `json:"status"`
+	Outcome         int    `json:"outcome"`
 	TeamId          string `json:"team_id"`
 	Player1         string `json:"player1"`
 	Player2         string `json:"player2"`
 	FormatLineIndex int    `json:"format_line_index"`
 	Opponent        string `json:"opponent"`
 	OpponentStatus  int    `json:"opponent_status"`
+	ScoreStatus     string `json:"score_status"` // ScoreReport status, empty until reported
 }
 
 // TeamMatchDTO is the wire representation of one head-to-head team match with
-// its individual matches and a running win tally.
+// its individual matches and a running tally of lines won and points earned.
 type TeamMatchDTO struct {
-	ID       string              `json:"id"`
-	WeekId   string              `json:"week_id"`
-	HomeTeam string              `json:"home_team_id"`
-	AwayTeam string              `json:"away_team_id"`
-	Complete bool                `json:"complete"`
-	HomeWins int                 `json:"home_wins"`
-	AwayWins int                 `json:"away_wins"`
-	Winner   string              `json:"winner_team_id"`
-	Matches  []*IndividualMatchDTO `json:"matches"`
+	ID         string                `json:"id"`
+	WeekId     string                `json:"week_id"`
+	HomeTeam   string                `json:"home_team_id"`
+	AwayTeam   string                `json:"away_team_id"`
+	Complete   bool                  `json:"complete"`
+	HomeWins   int                   `json:"home_wins"`
+	AwayWins   int                   `json:"away_wins"`
+	HomePoints int                   `json:"home_points"` // weighted line points, plus the match win bonus once complete
+	AwayPoints int                   `json:"away_points"`
+	Winner     string                `json:"winner_team_id"`
+	Matches    []*IndividualMatchDTO `json:"matches"`
 }
 
 // WeekMatchDetail is the wire representation of a week's score sheet.
@@ -143,6 +136,7 @@ func buildIndividualMatchDTO(ctx context.Context, db database.Provider, im *mode
 		SecondaryValue:  im.SecondaryValue,
 		WinOverride:     im.WinOverride,
 		Status:          int(im.Status),
+		Outcome:         int(im.Outcome),
 		TeamId:          pairing.TeamId.RecordId().String(),
 		Player1:         database.RecordId(pairing.Player1).String(),
 		Player2:         database.RecordId(pairing.Player2).String(),
@@ -160,14 +154,23 @@ func buildIndividualMatchDTO(ctx context.Context, db database.Provider, im *mode
 }
 
 // buildTeamMatchDTO assembles a TeamMatch with its individual matches and win
-// tally from the join table and each assigned lineup pairing.
-func buildTeamMatchDTO(ctx context.Context, db database.Provider, tm *model.TeamMatch) (*TeamMatchDTO, error) {
+// tally from the join table and each assigned lineup pairing. The team match is
+// only complete once every line's score has been confirmed by the opposing
+// team or resolved by a commissioner. Lines decided by an outcome the season
+// doesn't count toward the record are left out of the tally. Each line won
+// earns its format line's points, and the team with more points wins the team
+// match and its match win bonus.
+func buildTeamMatchDTO(ctx context.Context, db database.Provider, tm *model.TeamMatch, treatments model.OutcomeTreatments) (*TeamMatchDTO, error) {
 	rows, err := database.GetAllWhere[*model.TeamMatchIndividualMatch](ctx, db, func(_ context.Context, r *model.TeamMatchIndividualMatch) bool {
 		return r.TeamMatchId == tm.ID
 	})
 	if err != nil {
 		return nil, err
 	}
+	points, err := tm.GetTeamMatchPoints(ctx, db)
+	if err != nil {
+		return nil, err
+	}
 	dto := &TeamMatchDTO{
 		ID:       tm.ID.RecordId().String(),
 		WeekId:   tm.WeekId.RecordId().String(),
@@ -190,22 +193,36 @@ func buildTeamMatchDTO(ctx context.Context, db database.Provider, tm *model.Team
 			return nil, err
 		}
 		dto.Matches = append(dto.Matches, matchDTO)
-		if im.Status == model.MatchWon {
+		if im.Status == model.MatchWon && treatments.For(im.Outcome).CountsTowardRecord {
 			if pairing.TeamId == tm.HomeTeam {
 				dto.HomeWins++
+				dto.HomePoints += points.ForLine(pairing.FormatLineIndex)
 			} else if pairing.TeamId == tm.AwayTeam {
 				dto.AwayWins++
+				dto.AwayPoints += points.ForLine(pairing.FormatLineIndex)
 			}
 		}
+		report, err := im.GetScoreReport(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+		if report != nil {
+			matchDTO.ScoreStatus = report.Status.String()
+		}
 		if im.Status != model.MatchWon && im.Status != model.MatchLost {
 			complete = false
 		}
+		if report == nil || !report.Status.Final() {
+			complete = false
+		}
 	}
 	dto.Complete = complete
-	if complete && dto.HomeWins > dto.AwayWins {
+	if complete && dto.HomePoints > dto.AwayPoints {
 		dto.Winner = tm.HomeTeam.RecordId().String()
-	} else if complete && dto.AwayWins > dto.HomeWins {
+		dto.HomePoints += points.MatchWinBonus
+	} else if complete && dto.AwayPoints > dto.HomePoints {
 		dto.Winner = tm.AwayTeam.RecordId().String()
+		dto.AwayPoints += points.MatchWinBonus
 	}
 	return dto, nil
 }
@@ -216,6 +233,13 @@ func weekDetailForWeek(ctx context.Context, db database.Provider, week *model.We
 	if err != nil {
 		return nil, err
 	}
+	if season == nil {
+		return nil, errors.New("week is not part of a season")
+	}
+	treatments, err := model.GetOutcomeTreatments(ctx, db, season.ID)
+	if err != nil {
+		return nil, err
+	}
 	detail := &WeekMatchDetail{WeekId: week.ID.RecordId().String(), SeasonId: season.ID.RecordId().String(), Closed: week.Closed, TeamMatches: []*TeamMatchDTO{}}
 	teamMatches, err := database.GetAllWhere[*model.TeamMatch](ctx, db, func(_ context.Context, tm *model.TeamMatch) bool {
 		return tm.WeekId == week.ID
@@ -224,7 +248,7 @@ func weekDetailForWeek(ctx context.Context, db database.Provider, week *model.We
 		return nil, err
 	}
 	for _, tm := range teamMatches {
-		dto, err := buildTeamMatchDTO(ctx, db, tm)
+		dto, err := buildTeamMatchDTO(ctx, db, tm, treatments)
 		if err != nil {
 			return nil, err
 		}
@@ -259,8 +283,9 @@ func (b *GenerateBody) StaticallyValid() error {
 }
 
 // GenerateMatches creates the week's TeamMatches (and their individual matches)
-// from the scheduled weekly matchups and both teams' official lineups. Only a
-// season commissioner may generate matches.
+// from the scheduled weekly matchups and both teams' official lineups, using
+// each lineup's latest approved revision. Only a season commissioner may
+// generate matches.
 type GenerateMatches struct{}
 
 func (c GenerateMatches) Path() (api.HttpMethod, string) {
@@ -358,17 +383,19 @@ func generateWeekMatches(ctx context.Context, db database.Provider, week *model.
 			return nil, errors.New("away team has no official lineup for this week")
 		}
 
-		tm := &model.TeamMatch{WeekId: week.ID, HomeTeam: entry.HomeTeam, AwayTeam: entry.AwayTeam, Lineup: homeLineup.ID}
-		tmCreated, err := database.CreateOne(ctx, db, tm)
+		// pairings come from each lineup's latest approved revision, so a
+		// pending change to an official lineup is not played until approved
+		homePairings, err := model.GetApprovedLineupPairings(ctx, db, homeLineup)
 		if err != nil {
 			return nil, err
 		}
-
-		homePairings, err := pairingsForLineup(ctx, db, homeLineup.ID)
+		awayPairings, err := model.GetApprovedLineupPairings(ctx, db, awayLineup)
 		if err != nil {
 			return nil, err
 		}
-		awayPairings, err := pairingsForLineup(ctx, db, awayLineup.ID)
+
+		tm := &model.TeamMatch{WeekId: week.ID, HomeTeam: entry.HomeTeam, AwayTeam: entry.AwayTeam, Lineup: homeLineup.ID}
+		tmCreated, err := database.CreateOne(ctx, db, tm)
 		if err != nil {
 			return nil, err
 		}
@@ -420,7 +447,7 @@ func generateWeekMatches(ctx context.Context, db database.Provider, week *model.
 			}
 		}
 
-		tmDTO, err := buildTeamMatchDTO(ctx, db, tmCreated)
+		tmDTO, err := buildTeamMatchDTO(ctx, db, tmCreated, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -474,12 +501,17 @@ func (c GetWeekMatches) Handler(req api.Request[*MatchQuery]) (any, int, error)
 	return gin.H{api.ResourceKey: detail}, http.StatusOK, nil
 }
 
-// ScoreBody is the request body for RecordScore.
+// ScoreBody is the request body for RecordScore. For a match whose scoring
+// structure has secondary structures, SecondaryScores may list each finished
+// secondary unit (e.g. each set in games) instead of the aggregate values. For
+// one without, a result decided by a tiebreak (e.g. 7-6) carries the tiebreak
+// points in SecondaryValue.
 type ScoreBody struct {
-	IndividualMatchId string `json:"individual_match_id"`
-	MainValue         int    `json:"main_value"`
-	SecondaryValue    int    `json:"secondary_value"`
-	WinOverride       bool   `json:"win_override"`
+	IndividualMatchId string                 `json:"individual_match_id"`
+	MainValue         int                    `json:"main_value"`
+	SecondaryValue    int                    `json:"secondary_value"`
+	WinOverride       bool                   `json:"win_override"`
+	SecondaryScores   []model.SecondaryScore `json:"secondary_scores"`
 }
 
 // StaticallyValid ensures an individual match is specified and scores are
@@ -491,12 +523,21 @@ func (b *ScoreBody) StaticallyValid() error {
 	if b.MainValue < 0 || b.SecondaryValue < 0 {
 		return errors.New("scores cannot be negative")
 	}
+	for _, score := range b.SecondaryScores {
+		if score.Us < 0 || score.Them < 0 || score.TiebreakUs < 0 || score.TiebreakThem < 0 {
+			return errors.New("scores cannot be negative")
+		}
+	}
 	return nil
 }
 
 // RecordScore updates the score on one side of an individual match. It does not
 // complete the match; use CompleteMatch to determine the winner once both sides
-// have been scored.
+// have been scored. The score can't be changed once it has been confirmed or
+// resolved, or by one team while the other team's report awaits a response.
+// The score must be one that could occur under the match's scoring structure;
+// secondary scores, which cover both sides, are validated unit by unit and
+// written onto both sides.
 type RecordScore struct{}
 
 func (c RecordScore) Path() (api.HttpMethod, string) {
@@ -523,18 +564,91 @@ func (c RecordScore) Handler(req api.Request[*ScoreBody]) (any, int, error) {
 	if err != nil {
 		return nil, http.StatusBadRequest, err
 	}
-	im.MainValue = req.Body.MainValue
-	im.SecondaryValue = req.Body.SecondaryValue
-	im.WinOverride = req.Body.WinOverride
+	live, err := im.HasScoreEvents(req.Context, req.DatabaseProvider)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if live {
+		return nil, http.StatusConflict, errors.New("match is being scored live; record score events instead")
+	}
+	if _, status, err := checkScoreEditable(req.Context, req.DatabaseProvider, req.Token.UserId, im); err != nil {
+		return nil, status, err
+	}
+	var opp *model.IndividualMatch
+	if im.Opponent != model.IndividualMatchId(database.InvalidRecordId) {
+		opp, err = im.GetOpponent(req.Context, req.DatabaseProvider)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	if len(req.Body.SecondaryScores) > 0 {
+		return recordSecondaryScores(req, im, opp)
+	}
+
+	scored := *im
+	scored.MainValue = req.Body.MainValue
+	scored.SecondaryValue = req.Body.SecondaryValue
+	scored.WinOverride = req.Body.WinOverride
+	if err := scored.ValidateScore(req.Context, req.DatabaseProvider, opp, false); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	im.MainValue = scored.MainValue
+	im.SecondaryValue = scored.SecondaryValue
+	im.WinOverride = scored.WinOverride
+	if im.WinOverride && opp != nil && opp.WinOverride {
+		opp.WinOverride = false
+		if err := database.UpdateOne(req.Context, req.DatabaseProvider, opp); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
 	if err := database.UpdateOne(req.Context, req.DatabaseProvider, im); err != nil {
 		return nil, http.StatusBadRequest, err
 	}
+	publishMatchUpdate(req.Context, req.DatabaseProvider, im.ID)
+	return gin.H{api.ResourceKey: im}, http.StatusOK, nil
+}
+
+// recordSecondaryScores writes the score given as finished secondary units
+// onto both sides of an individual match, once they have been validated
+// against the match's scoring structure. Units that decide the match are
+// validated as a final score. As with a score for one side, a WinOverride is
+// set on this side and cleared from the opponent.
+func recordSecondaryScores(req api.Request[*ScoreBody], im, opp *model.IndividualMatch) (any, int, error) {
+	if opp == nil {
+		return nil, http.StatusBadRequest, errors.New("secondary scores can only be recorded on a match with an opponent")
+	}
+	claim, err := im.ScoreFromSecondaries(req.Context, req.DatabaseProvider, req.Body.SecondaryScores)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	scored, scoredOpp := *im, *opp
+	scored.MainValue, scored.SecondaryValue, scored.WinOverride = claim.MainValue, claim.SecondaryValue, req.Body.WinOverride
+	scoredOpp.MainValue, scoredOpp.SecondaryValue, scoredOpp.WinOverride = claim.OpponentMainValue, claim.OpponentSecondaryValue, false
+	if err := scoredOpp.Initialize(req.Context, req.DatabaseProvider); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	final := scored.Victorious(&scoredOpp) || scoredOpp.Victorious(&scored)
+	if err := scored.ValidateScore(req.Context, req.DatabaseProvider, &scoredOpp, final); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	im.MainValue, im.SecondaryValue, im.WinOverride = scored.MainValue, scored.SecondaryValue, scored.WinOverride
+	opp.MainValue, opp.SecondaryValue, opp.WinOverride = scoredOpp.MainValue, scoredOpp.SecondaryValue, scoredOpp.WinOverride
+	if err := database.UpdateOne(req.Context, req.DatabaseProvider, opp); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if err := database.UpdateOne(req.Context, req.DatabaseProvider, im); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	publishMatchUpdate(req.Context, req.DatabaseProvider, im.ID)
 	return gin.H{api.ResourceKey: im}, http.StatusOK, nil
 }
 
 // CompleteMatch marks an individual match complete, determining the winner
 // against its opponent by the scoring structure once both sides have scores. A
-// match with no opponent is recorded as won once it has a score.
+// match with no opponent is recorded as won once it has a score. Completing a
+// team match line reports its score: when completed by a team's captain or
+// co-captain the opposing team must confirm or dispute it, while a
+// commissioner's result is final.
 type CompleteMatch struct{}
 
 func (c CompleteMatch) Path() (api.HttpMethod, string) {
@@ -557,22 +671,36 @@ func (c CompleteMatch) Handler(req api.Request[*EmptyBody]) (any, int, error) {
 	if err != nil {
 		return nil, http.StatusBadRequest, err
 	}
+	team, status, err := checkScoreEditable(req.Context, req.DatabaseProvider, req.Token.UserId, im)
+	if err != nil {
+		return nil, status, err
+	}
 	if err := determineWinnerAndMark(req.Context, req.DatabaseProvider, im); err != nil {
 		return nil, http.StatusBadRequest, err
 	}
+	if err := reportMatchScore(req.Context, req.DatabaseProvider, req.Token.UserId, im, team); err != nil {
+		return nil, scoreConflictStatus(err), err
+	}
+	publishMatchUpdate(req.Context, req.DatabaseProvider, im.ID)
 	return gin.H{api.ResourceKey: im}, http.StatusOK, nil
 }
 
 // determineWinnerAndMark completes an individual match: when it has an
-// opponent, both sides must be scored and the scoring structure decides the
-// winner; the winner is marked Won and the loser Lost. A lone match (no
-// opponent) is marked Won once it has a score.
+// opponent, both sides must be scored with a finished result under the scoring
+// structure, which decides the winner; the winner is marked Won and the loser
+// Lost. A lone match (no opponent) is marked Won once it has a score. Either
+// way the match is recorded as played out, replacing any earlier retirement or
+// default.
 func determineWinnerAndMark(ctx context.Context, db database.Provider, im *model.IndividualMatch) error {
 	if im.Opponent == model.IndividualMatchId(database.InvalidRecordId) {
 		if !hasScore(im) {
 			return errors.New("match must be scored before completing")
 		}
+		if err := im.ValidateScore(ctx, db, nil, true); err != nil {
+			return err
+		}
 		im.Status = model.MatchWon
+		im.Outcome = model.OutcomePlayed
 		return database.UpdateOne(ctx, db, im)
 	}
 	opp, err := database.GetExistingRecordById(ctx, db, &model.IndividualMatch{}, im.Opponent.RecordId())
@@ -588,6 +716,9 @@ func determineWinnerAndMark(ctx context.Context, db database.Provider, im *model
 	if err := opp.Initialize(ctx, db); err != nil {
 		return err
 	}
+	if err := im.ValidateScore(ctx, db, opp, true); err != nil {
+		return err
+	}
 	if im.Victorious(opp) {
 		im.Status = model.MatchWon
 		opp.Status = model.MatchLost
@@ -597,6 +728,7 @@ func determineWinnerAndMark(ctx context.Context, db database.Provider, im *model
 	} else {
 		return errors.New("match is not decided by the current scores")
 	}
+	im.Outcome, opp.Outcome = model.OutcomePlayed, model.OutcomePlayed
 	if err := database.UpdateOne(ctx, db, opp); err != nil {
 		return err
 	}
@@ -617,16 +749,25 @@ func (q *StandingsQuery) StaticallyValid() error {
 }
 
 // StandingsEntry is a team's cumulative win/loss/tie record across the season's
-// completed team matches.
+// completed team matches, along with the sets and games its lines won and lost.
+// Lines decided by an outcome the season doesn't count toward differentials
+// are left out of the set and game totals.
 type StandingsEntry struct {
-	TeamId string `json:"team_id"`
-	Wins   int    `json:"wins"`
-	Losses int    `json:"losses"`
-	Ties   int    `json:"ties"`
+	TeamId           string `json:"team_id"`
+	Points           int    `json:"points"` // weighted line points and match win bonuses
+	Wins             int    `json:"wins"`
+	Losses           int    `json:"losses"`
+	Ties             int    `json:"ties"`
+	SetsWon          int    `json:"sets_won"`
+	SetsLost         int    `json:"sets_lost"`
+	GamesWon         int    `json:"games_won"`
+	GamesLost        int    `json:"games_lost"`
+	GameDifferential int    `json:"game_differential"`
 }
 
 // GetStandings computes the season's weekly standings from completed team
-// matches, sorted by wins (then ties). It is viewable by everyone.
+// matches, sorted by points (then wins, ties and game differential). It is
+// viewable by everyone.
 type GetStandings struct{}
 
 func (c GetStandings) Path() (api.HttpMethod, string) {
@@ -658,13 +799,18 @@ func (c GetStandings) Handler(req api.Request[*StandingsQuery]) (any, int, error
 	return gin.H{api.ResourceKey: entries}, http.StatusOK, nil
 }
 
-// computeStandings tallies each team's record across every completed team match
-// in the season's weeks, sorted by wins then ties.
+// computeStandings tallies each team's points and record across every completed
+// team match in the season's weeks, sorted by points, then wins, then ties,
+// then game differential.
 func computeStandings(ctx context.Context, db database.Provider, season *model.Season) ([]*StandingsEntry, error) {
 	weeks, err := model.GetWeeksForDraft(ctx, db, season.DraftId)
 	if err != nil {
 		return nil, err
 	}
+	treatments, err := model.GetOutcomeTreatments(ctx, db, season.ID)
+	if err != nil {
+		return nil, err
+	}
 	records := make(map[model.TeamId]*StandingsEntry)
 	for _, week := range weeks {
 		teamMatches, err := database.GetAllWhere[*model.TeamMatch](ctx, db, func(_ context.Context, tm *model.TeamMatch) bool {
@@ -674,13 +820,21 @@ func computeStandings(ctx context.Context, db database.Provider, season *model.S
 			return nil, err
 		}
 		for _, tm := range teamMatches {
-			dto, err := buildTeamMatchDTO(ctx, db, tm)
+			dto, err := buildTeamMatchDTO(ctx, db, tm, treatments)
 			if err != nil {
 				return nil, err
 			}
 			if !dto.Complete {
 				continue
 			}
+			for _, m := range dto.Matches {
+				if !treatments.For(model.MatchOutcome(m.Outcome)).CountsTowardDifferential {
+					continue
+				}
+				tallyLine(records, tm, m)
+			}
+			entryFor(records, tm.HomeTeam).Points += dto.HomePoints
+			entryFor(records, tm.AwayTeam).Points += dto.AwayPoints
 			if dto.Winner == tm.HomeTeam.RecordId().String() {
 				bump(records, tm.HomeTeam, boolPtr(true))
 				bump(records, tm.AwayTeam, boolPtr(false))
@@ -698,10 +852,16 @@ func computeStandings(ctx context.Context, db database.Provider, season *model.S
 		out = append(out, e)
 	}
 	sort.Slice(out, func(i, j int) bool {
+		if out[i].Points != out[j].Points {
+			return out[i].Points > out[j].Points
+		}
 		if out[i].Wins != out[j].Wins {
 			return out[i].Wins > out[j].Wins
 		}
-		return out[i].Ties > out[j].Ties
+		if out[i].Ties != out[j].Ties {
+			return out[i].Ties > out[j].Ties
+		}
+		return out[i].GameDifferential > out[j].GameDifferential
 	})
 	return out, nil
 }
@@ -709,14 +869,20 @@ func computeStandings(ctx context.Context, db database.Provider, season *model.S
 // boolPtr returns a pointer to a bool literal (Go has no &true syntax).
 func boolPtr(v bool) *bool { return &v }
 
-// bump updates a team's win/loss/tie record. won is true for a win, false for a
-// loss, and nil for a tie.
-func bump(records map[model.TeamId]*StandingsEntry, teamId model.TeamId, won *bool) {
+// entryFor returns the team's standings entry, creating it on first use.
+func entryFor(records map[model.TeamId]*StandingsEntry, teamId model.TeamId) *StandingsEntry {
 	e := records[teamId]
 	if e == nil {
 		e = &StandingsEntry{TeamId: teamId.RecordId().String()}
 		records[teamId] = e
 	}
+	return e
+}
+
+// bump updates a team's win/loss/tie record. won is true for a win, false for a
+// loss, and nil for a tie.
+func bump(records map[model.TeamId]*StandingsEntry, teamId model.TeamId, won *bool) {
+	e := entryFor(records, teamId)
 	if won == nil {
 		e.Ties++
 	} else if *won {
@@ -725,3 +891,20 @@ func bump(records map[model.TeamId]*StandingsEntry, teamId model.TeamId, won *bo
 		e.Losses++
 	}
 }
+
+// tallyLine adds one side of a completed line to the team match's set and game
+// totals: its main value counts as sets and its secondary value as games, won
+// by the side's team and lost by the other.
+func tallyLine(records map[model.TeamId]*StandingsEntry, tm *model.TeamMatch, m *IndividualMatchDTO) {
+	team, other := tm.HomeTeam, tm.AwayTeam
+	if m.TeamId == tm.AwayTeam.RecordId().String() {
+		team, other = tm.AwayTeam, tm.HomeTeam
+	}
+	e, o := entryFor(records, team), entryFor(records, other)
+	e.SetsWon += m.MainValue
+	e.GamesWon += m.SecondaryValue
+	o.SetsLost += m.MainValue
+	o.GamesLost += m.SecondaryValue
+	e.GameDifferential = e.GamesWon - e.GamesLost
+	o.GameDifferential = o.GamesWon - o.GamesLost
+}
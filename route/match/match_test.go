@@ -1,6 +1,7 @@
 package match
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/ecdsa"
@@ -10,6 +11,7 @@ import (
 	"math/rand/v2"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -285,22 +287,26 @@ type weekMatchDetail struct {
 }
 
 type teamMatchDTO struct {
-	ID       string                `json:"id"`
-	HomeTeam string                `json:"home_team_id"`
-	AwayTeam string                `json:"away_team_id"`
-	Complete bool                  `json:"complete"`
-	HomeWins int                   `json:"home_wins"`
-	AwayWins int                   `json:"away_wins"`
-	Winner   string                `json:"winner_team_id"`
-	Matches  []*individualMatchDTO `json:"matches"`
+	ID         string                `json:"id"`
+	HomeTeam   string                `json:"home_team_id"`
+	AwayTeam   string                `json:"away_team_id"`
+	Complete   bool                  `json:"complete"`
+	HomeWins   int                   `json:"home_wins"`
+	AwayWins   int                   `json:"away_wins"`
+	HomePoints int                   `json:"home_points"`
+	AwayPoints int                   `json:"away_points"`
+	Winner     string                `json:"winner_team_id"`
+	Matches    []*individualMatchDTO `json:"matches"`
 }
 
 type individualMatchDTO struct {
-	ID       string `json:"id"`
-	TeamId   string `json:"team_id"`
-	Status   int    `json:"status"`
-	Main     int    `json:"main_value"`
-	Opponent string `json:"opponent"`
+	ID          string `json:"id"`
+	TeamId      string `json:"team_id"`
+	Status      int    `json:"status"`
+	Main        int    `json:"main_value"`
+	Opponent    string `json:"opponent"`
+	ScoreStatus string `json:"score_status"`
+	Outcome     int    `json:"outcome"`
 }
 
 func generateMatches(t *testing.T, router *gin.Engine, fx *matchFixture, token string) *httptest.ResponseRecorder {
@@ -467,6 +473,9 @@ func TestStandings(t *testing.T) {
 	detail := getWeekDetail(t, router, fx)
 	tm := detail.TeamMatches[0]
 	homeMatch, awayMatch := tm.Matches[0], tm.Matches[1]
+	if homeMatch.TeamId != fx.homeTeam.ID.String() {
+		homeMatch, awayMatch = awayMatch, homeMatch
+	}
 
 	// Home wins 6-3.
 	doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{"individual_match_id": homeMatch.ID, "main_value": 6}, newToken(t, fx.commissioner))
@@ -494,8 +503,701 @@ func TestStandings(t *testing.T) {
 }
 
 type standingsEntry struct {
-	TeamId string `json:"team_id"`
-	Wins   int    `json:"wins"`
-	Losses int    `json:"losses"`
-	Ties   int    `json:"ties"`
+	TeamId           string `json:"team_id"`
+	Points           int    `json:"points"`
+	Wins             int    `json:"wins"`
+	Losses           int    `json:"losses"`
+	Ties             int    `json:"ties"`
+	SetsWon          int    `json:"sets_won"`
+	SetsLost         int    `json:"sets_lost"`
+	GameDifferential int    `json:"game_differential"`
+}
+
+// getStandings fetches the fixture season's standings keyed by team id.
+func getStandings(t *testing.T, router *gin.Engine, fx *matchFixture) map[string]*standingsEntry {
+	w := doJSON(t, router, http.MethodGet, "/api/match/standings?season_id="+fx.season.ID.String(), nil, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var body struct {
+		Resource []*standingsEntry `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	out := make(map[string]*standingsEntry, len(body.Resource))
+	for _, e := range body.Resource {
+		out[e.TeamId] = e
+	}
+	return out
+}
+
+func TestRecordOutcomeAndStandingsTreatment(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	router := newTestRouter(t, db)
+	fx := newMatchFixture(t, db)
+
+	w := generateMatches(t, router, fx, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	tm := getWeekDetail(t, router, fx).TeamMatches[0]
+	homeMatch, awayMatch := tm.Matches[0], tm.Matches[1]
+	if homeMatch.TeamId != fx.homeTeam.ID.String() {
+		homeMatch, awayMatch = awayMatch, homeMatch
+	}
+
+	// The home side leads 3-1 when the away side retires.
+	doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{"individual_match_id": homeMatch.ID, "main_value": 3}, newToken(t, fx.commissioner))
+	doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{"individual_match_id": awayMatch.ID, "main_value": 1}, newToken(t, fx.commissioner))
+	outcomePath := "/api/match/" + awayMatch.ID + "/outcome"
+	w = doJSON(t, router, http.MethodPost, outcomePath, map[string]any{"outcome": int(model.OutcomeRetired)}, newToken(t, fx.outsider))
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doJSON(t, router, http.MethodPost, outcomePath, map[string]any{"outcome": int(model.OutcomePlayed)}, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w = doJSON(t, router, http.MethodPost, outcomePath, map[string]any{"outcome": int(model.OutcomeRetired)}, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// The commissioner's result is final.
+	w = doJSON(t, router, http.MethodPost, outcomePath, map[string]any{"outcome": int(model.OutcomeDefault)}, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	tm = getWeekDetail(t, router, fx).TeamMatches[0]
+	require.True(t, tm.Complete)
+	require.Equal(t, fx.homeTeam.ID.String(), tm.Winner)
+	for _, m := range tm.Matches {
+		require.Equal(t, int(model.OutcomeRetired), m.Outcome)
+	}
+
+	// By default a retirement counts toward the record and the differentials.
+	standings := getStandings(t, router, fx)
+	home, away := standings[fx.homeTeam.ID.String()], standings[fx.awayTeam.ID.String()]
+	require.Equal(t, 1, home.Wins)
+	require.Equal(t, 3, home.SetsWon)
+	require.Equal(t, 1, home.SetsLost)
+	require.Equal(t, 1, away.Losses)
+	require.Equal(t, 3, away.SetsLost)
+
+	// Only a commissioner may change how retirements count.
+	treatment := map[string]any{
+		"season_id":                  fx.season.ID.String(),
+		"outcome":                    int(model.OutcomeRetired),
+		"counts_toward_record":       false,
+		"counts_toward_differential": false,
+	}
+	w = doJSON(t, router, http.MethodPost, "/api/match/outcomes", treatment, newToken(t, fx.outsider))
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = doJSON(t, router, http.MethodPost, "/api/match/outcomes", treatment, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doJSON(t, router, http.MethodGet, "/api/match/outcomes?season_id="+fx.season.ID.String(), nil, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var treatments struct {
+		Resource []struct {
+			Outcome            int  `json:"outcome"`
+			CountsTowardRecord bool `json:"counts_toward_record"`
+		} `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &treatments))
+	require.Len(t, treatments.Resource, int(model.OutcomeInvalid)-1)
+	for _, tr := range treatments.Resource {
+		require.Equal(t, tr.Outcome != int(model.OutcomeRetired), tr.CountsTowardRecord)
+	}
+
+	// The line no longer counts, so the team match is tied.
+	standings = getStandings(t, router, fx)
+	home, away = standings[fx.homeTeam.ID.String()], standings[fx.awayTeam.ID.String()]
+	require.Equal(t, 0, home.Wins)
+	require.Equal(t, 1, home.Ties)
+	require.Equal(t, 1, away.Ties)
+	require.Zero(t, home.SetsWon)
+	require.Zero(t, away.SetsLost)
+}
+
+func TestLiveScoringEventsAndUndo(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	router := newTestRouter(t, db)
+	fx := newMatchFixture(t, db)
+
+	w := generateMatches(t, router, fx, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	tm := getWeekDetail(t, router, fx).TeamMatches[0]
+	var homeMatch, awayMatch *individualMatchDTO
+	for _, m := range tm.Matches {
+		if m.TeamId == fx.homeTeam.ID.String() {
+			homeMatch = m
+		} else {
+			awayMatch = m
+		}
+	}
+
+	recordGame := func(side *individualMatchDTO, token string) *httptest.ResponseRecorder {
+		return doJSON(t, router, http.MethodPost, "/api/match/event", map[string]any{
+			"individual_match_id": side.ID,
+			"unit":                model.Game,
+		}, token)
+	}
+	w = recordGame(homeMatch, newToken(t, fx.outsider))
+	require.Equal(t, http.StatusForbidden, w.Code, "outsider event: %s", w.Body.String())
+
+	commissioner := newToken(t, fx.commissioner)
+	w = recordGame(awayMatch, commissioner)
+	require.Equal(t, http.StatusOK, w.Code, "away game: %s", w.Body.String())
+	for i := 0; i < 5; i++ {
+		w = recordGame(homeMatch, commissioner)
+		require.Equal(t, http.StatusOK, w.Code, "home game: %s", w.Body.String())
+	}
+
+	// Once scored live, aggregate scores can't be set directly.
+	w = doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{
+		"individual_match_id": homeMatch.ID,
+		"main_value":          6,
+	}, commissioner)
+	require.Equal(t, http.StatusConflict, w.Code, "score while live: %s", w.Body.String())
+
+	// A mistaken game for the away side is undone and given to the home side.
+	w = recordGame(awayMatch, commissioner)
+	require.Equal(t, http.StatusOK, w.Code, "away game: %s", w.Body.String())
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+homeMatch.ID+"/undo?count=1", nil, commissioner)
+	require.Equal(t, http.StatusOK, w.Code, "undo: %s", w.Body.String())
+	w = recordGame(homeMatch, commissioner)
+	require.Equal(t, http.StatusOK, w.Code, "home game: %s", w.Body.String())
+
+	w = doJSON(t, router, http.MethodGet, "/api/match/"+awayMatch.ID+"/live", nil, "")
+	require.Equal(t, http.StatusOK, w.Code, "live: %s", w.Body.String())
+	var live struct {
+		Resource *model.LiveScore `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &live))
+	require.Equal(t, model.MatchLost, live.Resource.Status)
+	require.Equal(t, []model.LiveScoreLevel{{Unit: model.Game, Us: 1, Them: 6}}, live.Resource.Levels)
+	require.Len(t, live.Resource.Events, 7)
+
+	// The aggregate scores and statuses drive the score sheet as before.
+	tm = getWeekDetail(t, router, fx).TeamMatches[0]
+	require.True(t, tm.Complete)
+	require.Equal(t, fx.homeTeam.ID.String(), tm.Winner)
+}
+
+func TestCaptainScoreConfirmationAndDispute(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	router := newTestRouter(t, db)
+	fx := newMatchFixture(t, db)
+
+	w := generateMatches(t, router, fx, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	tm := getWeekDetail(t, router, fx).TeamMatches[0]
+	var homeMatch, awayMatch *individualMatchDTO
+	for _, m := range tm.Matches {
+		if m.TeamId == fx.homeTeam.ID.String() {
+			homeMatch = m
+		} else {
+			awayMatch = m
+		}
+	}
+	homeCaptain, awayCaptain := newToken(t, fx.homeCaptain), newToken(t, fx.awayCaptain)
+
+	// The home captain enters a 6-3 win.
+	for _, score := range []struct {
+		id   string
+		main int
+	}{{homeMatch.ID, 6}, {awayMatch.ID, 3}} {
+		w = doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{
+			"individual_match_id": score.id,
+			"main_value":          score.main,
+		}, homeCaptain)
+		require.Equal(t, http.StatusOK, w.Code, "captain score: %s", w.Body.String())
+	}
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+homeMatch.ID+"/complete", nil, homeCaptain)
+	require.Equal(t, http.StatusOK, w.Code, "captain complete: %s", w.Body.String())
+
+	// The result is reported, but the team match isn't complete until the away
+	// team responds.
+	tm = getWeekDetail(t, router, fx).TeamMatches[0]
+	require.False(t, tm.Complete)
+	require.Equal(t, model.ScoreReportPending.String(), tm.Matches[0].ScoreStatus)
+
+	// The away captain can't overwrite the reported score, and only the away
+	// team may respond to it.
+	w = doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{
+		"individual_match_id": awayMatch.ID,
+		"main_value":          6,
+	}, awayCaptain)
+	require.Equal(t, http.StatusConflict, w.Code, "away overwrite: %s", w.Body.String())
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+homeMatch.ID+"/confirm", nil, newToken(t, fx.outsider))
+	require.Equal(t, http.StatusForbidden, w.Code, "outsider confirm: %s", w.Body.String())
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+homeMatch.ID+"/confirm", nil, homeCaptain)
+	require.Equal(t, http.StatusBadRequest, w.Code, "self confirm: %s", w.Body.String())
+
+	// The away captain disputes it, claiming a 6-4 win.
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+awayMatch.ID+"/dispute", map[string]any{
+		"main_value":          6,
+		"opponent_main_value": 4,
+		"won":                 true,
+		"note":                "we won the last game",
+	}, awayCaptain)
+	require.Equal(t, http.StatusOK, w.Code, "dispute: %s", w.Body.String())
+
+	// The dispute reaches the season commissioners with both claims.
+	path := "/api/match/disputes?season_id=" + fx.season.ID.String()
+	w = doJSON(t, router, http.MethodGet, path, nil, homeCaptain)
+	require.Equal(t, http.StatusForbidden, w.Code, "captain disputes: %s", w.Body.String())
+	w = doJSON(t, router, http.MethodGet, path, nil, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusOK, w.Code, "disputes: %s", w.Body.String())
+	var disputes struct {
+		Resource []*model.ScoreReport `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &disputes))
+	require.Len(t, disputes.Resource, 1)
+	dispute := disputes.Resource[0]
+	require.Equal(t, "we won the last game", dispute.DisputeNote)
+	homeId := model.IndividualMatchId(mustRecordId(t, homeMatch.ID))
+	require.Equal(t, 6, dispute.ClaimFor(homeId, dispute.Reported).MainValue)
+	require.Equal(t, 4, dispute.ClaimFor(homeId, dispute.Disputed).MainValue)
+	require.False(t, dispute.ClaimFor(homeId, dispute.Disputed).Won)
+
+	// Only a commissioner may resolve it, and the resolution is final.
+	resolution := map[string]any{"main_value": 4, "opponent_main_value": 6, "won": false}
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+homeMatch.ID+"/resolve", resolution, awayCaptain)
+	require.Equal(t, http.StatusForbidden, w.Code, "captain resolve: %s", w.Body.String())
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+homeMatch.ID+"/resolve", resolution, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusOK, w.Code, "resolve: %s", w.Body.String())
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+homeMatch.ID+"/complete", nil, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusConflict, w.Code, "complete after resolve: %s", w.Body.String())
+
+	tm = getWeekDetail(t, router, fx).TeamMatches[0]
+	require.True(t, tm.Complete)
+	require.Equal(t, fx.awayTeam.ID.String(), tm.Winner)
+	require.Equal(t, model.ScoreReportResolved.String(), tm.Matches[0].ScoreStatus)
+}
+
+func mustRecordId(t *testing.T, s string) database.RecordId {
+	t.Helper()
+	rid, err := database.RecordIdFromString(s)
+	require.NoError(t, err)
+	return rid
+}
+
+func TestScoreValidatedAgainstScoringStructure(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	router := newTestRouter(t, db)
+	fx := newMatchFixture(t, db)
+
+	w := generateMatches(t, router, fx, newToken(t, fx.commissioner))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	tm := getWeekDetail(t, router, fx).TeamMatches[0]
+	homeMatch, awayMatch := tm.Matches[0], tm.Matches[1]
+	if homeMatch.TeamId != fx.homeTeam.ID.String() {
+		homeMatch, awayMatch = awayMatch, homeMatch
+	}
+	token := newToken(t, fx.commissioner)
+	score := func(id string, main, secondary int) *httptest.ResponseRecorder {
+		return doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{
+			"individual_match_id": id,
+			"main_value":          main,
+			"secondary_value":     secondary,
+		}, token)
+	}
+
+	// The fixture's matches are single sets: first to 6 games, win by 2, or 7.
+	w = score(homeMatch.ID, 8, 0)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "instant-win rule")
+
+	// Secondary scores only apply to a match made of secondary units.
+	w = doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{
+		"individual_match_id": awayMatch.ID,
+		"secondary_scores":    []map[string]int{{"us": 6, "them": 4}},
+	}, token)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "secondary count rule")
+
+	// 6-5 can be scored while the set is in progress, but can't complete it.
+	require.Equal(t, http.StatusOK, score(homeMatch.ID, 6, 0).Code)
+	require.Equal(t, http.StatusOK, score(awayMatch.ID, 5, 0).Code)
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+homeMatch.ID+"/complete", nil, token)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "win-by-2 rule")
+
+	// 7-6 needs its tiebreak score.
+	require.Equal(t, http.StatusOK, score(homeMatch.ID, 7, 0).Code)
+	require.Equal(t, http.StatusOK, score(awayMatch.ID, 6, 0).Code)
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+homeMatch.ID+"/complete", nil, token)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "tiebreak rule")
+
+	require.Equal(t, http.StatusOK, score(homeMatch.ID, 7, 7).Code)
+	require.Equal(t, http.StatusOK, score(awayMatch.ID, 6, 5).Code)
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+homeMatch.ID+"/complete", nil, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestWinOverrideClearedFromOpponent(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	router := newTestRouter(t, db)
+	fx := newMatchFixture(t, db)
+	token := newToken(t, fx.commissioner)
+
+	// A best-of-three-sets structure made of the fixture's single sets.
+	match, err := database.CreateOne(ctx, db, &model.ScoringStructure{
+		Owner:                    fx.commissioner,
+		Name:                     fmt.Sprintf("scoring %d", rand.Uint64()),
+		WinConditionCountingType: model.Set,
+		WinCondition:             model.WinCondition{WinThreshold: 2, MustWinBy: 1},
+	})
+	require.NoError(t, err)
+	require.NoError(t, match.SetSecondaryScoringStructures(ctx, db, model.ScoringStructureList{fx.scoring.ID, fx.scoring.ID, fx.scoring.ID}))
+	w := doJSON(t, router, http.MethodPost, "/api/match/generate", map[string]any{
+		"week_id":              fx.week.ID.String(),
+		"scoring_structure_id": match.ID.String(),
+	}, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	tm := getWeekDetail(t, router, fx).TeamMatches[0]
+	homeMatch, awayMatch := tm.Matches[0], tm.Matches[1]
+	overridden := func(id string) bool {
+		t.Helper()
+		im, err := database.GetExistingRecordById(ctx, db, &model.IndividualMatch{}, mustRecordId(t, id))
+		require.NoError(t, err)
+		return im.WinOverride
+	}
+
+	// Overriding one side takes the override away from the other.
+	for _, id := range []string{homeMatch.ID, awayMatch.ID} {
+		w = doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{
+			"individual_match_id": id,
+			"win_override":        true,
+		}, token)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	require.False(t, overridden(homeMatch.ID))
+	require.True(t, overridden(awayMatch.ID))
+
+	// Secondary scores do the same, and a decided match is validated as final.
+	w = doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{
+		"individual_match_id": homeMatch.ID,
+		"win_override":        true,
+		"secondary_scores":    []map[string]int{{"us": 6, "them": 4}, {"us": 3, "them": 6}, {"us": 6, "them": 2}},
+	}, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.True(t, overridden(homeMatch.ID))
+	require.False(t, overridden(awayMatch.ID))
+
+	w = doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{
+		"individual_match_id": awayMatch.ID,
+		"secondary_scores":    []map[string]int{{"us": 4, "them": 6}, {"us": 6, "them": 3}, {"us": 2, "them": 6}},
+	}, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.False(t, overridden(homeMatch.ID))
+	require.False(t, overridden(awayMatch.ID))
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+awayMatch.ID+"/complete", nil, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+// playLine generates the fixture week's team match and has the commissioner
+// score its single line 6-3 for the winning team.
+func playLine(t *testing.T, router *gin.Engine, fx *matchFixture, winner model.TeamId) {
+	t.Helper()
+	token := newToken(t, fx.commissioner)
+	w := generateMatches(t, router, fx, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	tm := getWeekDetail(t, router, fx).TeamMatches[0]
+	winning, losing := tm.Matches[0], tm.Matches[1]
+	if winning.TeamId != winner.String() {
+		winning, losing = losing, winning
+	}
+	doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{"individual_match_id": winning.ID, "main_value": 6}, token)
+	doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{"individual_match_id": losing.ID, "main_value": 3}, token)
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+winning.ID+"/complete", nil, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+// setCaptains hands the fixture's teams to the given captains, as if they had
+// been rebuilt for a later season, and moves its week to date.
+func setCaptains(t *testing.T, db database.Provider, fx *matchFixture, home, away database.UserId, date time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	fx.week.Date = date
+	require.NoError(t, database.UpdateOne(ctx, db, fx.week))
+	for team, captain := range map[model.TeamId]database.UserId{fx.homeTeam.ID: home, fx.awayTeam.ID: away} {
+		assignments, err := database.GetAllWhere[*model.TeamAssignment](ctx, db, func(_ context.Context, a *model.TeamAssignment) bool {
+			return a.TeamId == team && a.Role == model.TeamRoleCaptain
+		})
+		require.NoError(t, err)
+		require.Len(t, assignments, 1)
+		assignment := *assignments[0]
+		assignment.UserId = captain
+		require.NoError(t, database.UpdateOne(ctx, db, &assignment))
+	}
+}
+
+// moveFacility gives the fixture's facility a new name and address, so another
+// fixture can be built in the same database.
+func moveFacility(t *testing.T, db database.Provider, fx *matchFixture, name, address string) {
+	t.Helper()
+	ctx := context.Background()
+	facility, err := database.GetExistingRecordById(ctx, db, &model.Facility{}, fx.season.Facility.RecordId())
+	require.NoError(t, err)
+	moved := *facility
+	moved.Name, moved.Address = name, address
+	require.NoError(t, database.UpdateOne(ctx, db, &moved))
+}
+
+type headToHead struct {
+	CaptainA string `json:"captain_a"`
+	CaptainB string `json:"captain_b"`
+	Wins     int    `json:"wins"`
+	Losses   int    `json:"losses"`
+	Ties     int    `json:"ties"`
+	Meetings []*struct {
+		SeasonId string        `json:"season_id"`
+		TeamA    string        `json:"team_a_id"`
+		TeamB    string        `json:"team_b_id"`
+		WinsA    int           `json:"wins_a"`
+		WinsB    int           `json:"wins_b"`
+		Match    *teamMatchDTO `json:"match"`
+	} `json:"meetings"`
+}
+
+func getHeadToHead(t *testing.T, router *gin.Engine, query string) *headToHead {
+	t.Helper()
+	w := doJSON(t, router, http.MethodGet, "/api/match/head_to_head?"+query, nil, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var body struct {
+		Resource *headToHead `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Resource
+}
+
+func TestHeadToHeadAcrossSeasons(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	router := newTestRouter(t, db)
+	fx := newMatchFixture(t, db)
+	playLine(t, router, fx, fx.homeTeam.ID)
+	moveFacility(t, db, fx, "First facility", "1 Test Rd.")
+
+	// The next season's teams are rebuilt, with the same two captains leading
+	// them but with home and away swapped.
+	next := newMatchFixture(t, db)
+	setCaptains(t, db, next, fx.awayCaptain, fx.homeCaptain, fx.week.Date.AddDate(1, 0, 0))
+	playLine(t, router, next, next.homeTeam.ID)
+	moveFacility(t, db, next, "Second facility", "2 Test Rd.")
+
+	// A third season's meeting hasn't been played yet.
+	unplayed := newMatchFixture(t, db)
+	setCaptains(t, db, unplayed, fx.homeCaptain, fx.awayCaptain, fx.week.Date.AddDate(2, 0, 0))
+	w := generateMatches(t, router, unplayed, newToken(t, unplayed.commissioner))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	h2h := getHeadToHead(t, router, "captain_a="+fx.homeCaptain.String()+"&captain_b="+fx.awayCaptain.String())
+	require.Equal(t, fx.homeCaptain.String(), h2h.CaptainA)
+	require.Equal(t, 1, h2h.Wins)
+	require.Equal(t, 1, h2h.Losses)
+	require.Zero(t, h2h.Ties)
+	require.Len(t, h2h.Meetings, 3)
+	require.Equal(t, fx.season.ID.String(), h2h.Meetings[0].SeasonId)
+	require.Equal(t, fx.homeTeam.ID.String(), h2h.Meetings[0].TeamA)
+	require.Equal(t, 1, h2h.Meetings[0].WinsA)
+	require.True(t, h2h.Meetings[0].Match.Complete)
+	require.Len(t, h2h.Meetings[0].Match.Matches, 2)
+	require.Equal(t, next.awayTeam.ID.String(), h2h.Meetings[1].TeamA)
+	require.Equal(t, 1, h2h.Meetings[1].WinsB)
+	require.False(t, h2h.Meetings[2].Match.Complete)
+
+	// Naming the teams finds their captains' whole history, from the first
+	// team's side.
+	byTeam := getHeadToHead(t, router, "team_a="+next.homeTeam.ID.String()+"&team_b="+fx.homeTeam.ID.String())
+	require.Equal(t, fx.awayCaptain.String(), byTeam.CaptainA)
+	require.Equal(t, 1, byTeam.Wins)
+	require.Equal(t, 1, byTeam.Losses)
+	require.Len(t, byTeam.Meetings, 3)
+
+	for _, query := range []string{
+		"",
+		"team_a=" + fx.homeTeam.ID.String(),
+		"team_a=" + fx.homeTeam.ID.String() + "&captain_b=" + fx.awayCaptain.String(),
+		"captain_a=" + fx.homeCaptain.String() + "&captain_b=" + fx.homeCaptain.String(),
+	} {
+		w := doJSON(t, router, http.MethodGet, "/api/match/head_to_head?"+query, nil, "")
+		require.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestWeightedLinePointsInStandings(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	ctx := context.Background()
+	router := newTestRouter(t, db)
+	fx := newMatchFixture(t, db)
+
+	// The fixture's only line is worth 2 points, with a bonus point for
+	// winning the team match. A format can't be edited once it is assigned to
+	// a draft, so the fixture's stored format is changed directly.
+	draft, err := database.GetExistingRecordById(ctx, db, &model.Draft{}, fx.season.DraftId.RecordId())
+	require.NoError(t, err)
+	format, err := database.GetExistingRecordById(ctx, db, &model.Format{}, draft.Format.RecordId())
+	require.NoError(t, err)
+	lines, err := format.GetLines(ctx, db)
+	require.NoError(t, err)
+	lines[0].Points = 2
+	require.NoError(t, format.SetLines(ctx, db, lines))
+	format.MatchWinBonus = 1
+	require.NoError(t, db.Update(ctx, format))
+
+	playLine(t, router, fx, fx.awayTeam.ID)
+	tm := getWeekDetail(t, router, fx).TeamMatches[0]
+	require.Equal(t, fx.awayTeam.ID.String(), tm.Winner)
+	require.Equal(t, 1, tm.AwayWins)
+	require.Equal(t, 3, tm.AwayPoints)
+	require.Zero(t, tm.HomePoints)
+
+	standings := getStandings(t, router, fx)
+	require.Equal(t, 3, standings[fx.awayTeam.ID.String()].Points)
+	require.Zero(t, standings[fx.homeTeam.ID.String()].Points)
+
+	// Points are the primary sort.
+	w := doJSON(t, router, http.MethodGet, "/api/match/standings?season_id="+fx.season.ID.String(), nil, "")
+	var body struct {
+		Resource []*standingsEntry `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, fx.awayTeam.ID.String(), body.Resource[0].TeamId)
+}
+
+// scoreboardEvent is one server-sent event read from the scoreboard stream.
+type scoreboardEvent struct {
+	Type string
+	Data json.RawMessage
+}
+
+// readScoreboardEvents parses the server-sent events of a scoreboard stream
+// onto a channel until the stream closes.
+func readScoreboardEvents(body io.Reader) <-chan scoreboardEvent {
+	events := make(chan scoreboardEvent, 64)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		var event scoreboardEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				event.Type = strings.TrimPrefix(line, "event:")
+			case strings.HasPrefix(line, "data:"):
+				event.Data = json.RawMessage(strings.TrimPrefix(line, "data:"))
+			case line == "" && event.Type != "":
+				events <- event
+				event = scoreboardEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+func nextScoreboardEvent(t *testing.T, events <-chan scoreboardEvent) scoreboardEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "scoreboard stream closed")
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a scoreboard event")
+		return scoreboardEvent{}
+	}
+}
+
+func TestWeekScoreboardStream(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	fx := newMatchFixture(t, db)
+	router := newTestRouter(t, db)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	token := newToken(t, fx.commissioner)
+	w := generateMatches(t, router, fx, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	tm := getWeekDetail(t, router, fx).TeamMatches[0]
+	home, away := tm.Matches[0], tm.Matches[1]
+	if home.TeamId != fx.homeTeam.ID.String() {
+		home, away = away, home
+	}
+
+	resp, err := http.Get(server.URL + "/api/match/week/stream?week_id=bad")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+	// Two subscribers share the same updates.
+	var streams []<-chan scoreboardEvent
+	for range 2 {
+		resp, err := http.Get(server.URL + "/api/match/week/stream?week_id=" + fx.week.ID.String())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		events := readScoreboardEvents(resp.Body)
+		snapshot := nextScoreboardEvent(t, events)
+		require.Equal(t, "snapshot", snapshot.Type)
+		var detail weekMatchDetail
+		require.NoError(t, json.Unmarshal(snapshot.Data, &detail))
+		require.Len(t, detail.TeamMatches, 1)
+		streams = append(streams, events)
+	}
+
+	w = doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{"individual_match_id": home.ID, "main_value": 6}, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	for _, events := range streams {
+		var match individualMatchDTO
+		event := nextScoreboardEvent(t, events)
+		require.Equal(t, "match", event.Type)
+		require.NoError(t, json.Unmarshal(event.Data, &match))
+		require.Equal(t, home.ID, match.ID)
+		require.Equal(t, 6, match.Main)
+		require.Equal(t, "match", nextScoreboardEvent(t, events).Type) // the opponent
+		require.Equal(t, "team_match", nextScoreboardEvent(t, events).Type)
+	}
+
+	w = doJSON(t, router, http.MethodPost, "/api/match/score", map[string]any{"individual_match_id": away.ID, "main_value": 3}, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doJSON(t, router, http.MethodPost, "/api/match/"+home.ID+"/complete", nil, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	for _, events := range streams {
+		var tally teamMatchDTO
+		for range 2 {
+			require.Equal(t, "match", nextScoreboardEvent(t, events).Type)
+			require.Equal(t, "match", nextScoreboardEvent(t, events).Type)
+			event := nextScoreboardEvent(t, events)
+			require.Equal(t, "team_match", event.Type)
+			require.NoError(t, json.Unmarshal(event.Data, &tally))
+		}
+		require.Equal(t, 1, tally.HomeWins)
+		require.True(t, tally.Complete)
+		event := nextScoreboardEvent(t, events)
+		require.Equal(t, "complete", event.Type)
+		require.NoError(t, json.Unmarshal(event.Data, &tally))
+		require.Equal(t, fx.homeTeam.ID.String(), tally.Winner)
+	}
+}
+
+func TestScoreboardCompletionTracking(t *testing.T) {
+	board := &scoreboard{
+		subscribers: map[model.WeekId]map[chan ScoreboardEvent]struct{}{},
+		completed:   map[model.WeekId]map[model.TeamMatchId]bool{},
+	}
+	weekId, tmId := model.WeekId(database.NewRecordId()), model.TeamMatchId(database.NewRecordId())
+
+	// nothing is tracked for a week nobody watches
+	require.False(t, board.setComplete(weekId, tmId, true))
+	require.Empty(t, board.completed)
+
+	_, unsubscribe := board.subscribe(weekId)
+	require.True(t, board.setComplete(weekId, tmId, true))
+	require.False(t, board.setComplete(weekId, tmId, true))
+
+	// a team match reopened and completed again is announced again
+	require.False(t, board.setComplete(weekId, tmId, false))
+	require.True(t, board.setComplete(weekId, tmId, true))
+
+	// one already complete when the stream starts is not announced
+	seeded := model.TeamMatchId(database.NewRecordId())
+	board.seedCompleted(weekId, &WeekMatchDetail{TeamMatches: []*TeamMatchDTO{{ID: seeded.RecordId().String(), Complete: true}}})
+	require.False(t, board.setComplete(weekId, seeded, true))
+
+	// the week's state goes with its last subscriber
+	unsubscribe()
+	require.Empty(t, board.completed)
 }
@@ -0,0 +1,196 @@
+package match
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OutcomeBody is the request body for RecordOutcome.
+type OutcomeBody struct {
+	Outcome model.MatchOutcome `json:"outcome"`
+}
+
+// StaticallyValid ensures the outcome is a retirement, default or walkover.
+func (b *OutcomeBody) StaticallyValid() error {
+	if !b.Outcome.Valid() || b.Outcome == model.OutcomePlayed {
+		return errors.New("outcome must be a retirement, default, double default or walkover")
+	}
+	return nil
+}
+
+// RecordOutcome decides an individual match without it being played out. The
+// side in the request path is the one that retired, defaulted or withdrew, and
+// its opponent wins; for a double default both sides lose. A retirement keeps
+// the partial score, so it is the only outcome allowed on a match scored live.
+// Like CompleteMatch, the result is reported for the opposing team to confirm
+// unless it was entered by a commissioner.
+type RecordOutcome struct{}
+
+func (c RecordOutcome) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, api.AppendPathId(BaseRoute) + "/outcome"
+}
+
+func (c RecordOutcome) RequestBody() (*OutcomeBody, bool) {
+	return &OutcomeBody{}, true
+}
+
+func (c RecordOutcome) Handler(req api.Request[*OutcomeBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	matchId := model.IndividualMatchId(req.PathId)
+	if !canEditMatch(req.Context, req.DatabaseProvider, req.Token.UserId, matchId) {
+		return nil, http.StatusForbidden, errors.New("you are not an editor of this match")
+	}
+	im, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.IndividualMatch{}, matchId.RecordId())
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if im.Opponent == model.IndividualMatchId(database.InvalidRecordId) {
+		return nil, http.StatusBadRequest, errors.New("an outcome can only be recorded on a match with an opponent")
+	}
+	team, status, err := checkScoreEditable(req.Context, req.DatabaseProvider, req.Token.UserId, im)
+	if err != nil {
+		return nil, status, err
+	}
+	if req.Body.Outcome != model.OutcomeRetired {
+		live, err := im.HasScoreEvents(req.Context, req.DatabaseProvider)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		if live {
+			return nil, http.StatusConflict, errors.New("match is being scored live; only a retirement can end it early")
+		}
+	}
+	opp, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.IndividualMatch{}, im.Opponent.RecordId())
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if err := im.MarkOutcome(req.Context, req.DatabaseProvider, req.Body.Outcome, opp); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if err := reportMatchScore(req.Context, req.DatabaseProvider, req.Token.UserId, im, team); err != nil {
+		return nil, scoreConflictStatus(err), err
+	}
+	publishMatchUpdate(req.Context, req.DatabaseProvider, im.ID)
+	return gin.H{api.ResourceKey: im}, http.StatusOK, nil
+}
+
+// OutcomeTreatmentStatus is the wire representation of how a season counts
+// matches decided by an outcome.
+type OutcomeTreatmentStatus struct {
+	Outcome model.MatchOutcome `json:"outcome"`
+	Name    string             `json:"name"`
+	model.OutcomeTreatment
+}
+
+// outcomeTreatmentStatuses lists the treatment of every outcome other than
+// played for the season, defaults included.
+func outcomeTreatmentStatuses(ctx context.Context, db database.Provider, seasonId model.SeasonId) ([]*OutcomeTreatmentStatus, error) {
+	treatments, err := model.GetOutcomeTreatments(ctx, db, seasonId)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]*OutcomeTreatmentStatus, 0, int(model.OutcomeInvalid))
+	for outcome := model.OutcomeRetired; outcome < model.OutcomeInvalid; outcome++ {
+		statuses = append(statuses, &OutcomeTreatmentStatus{
+			Outcome:          outcome,
+			Name:             outcome.String(),
+			OutcomeTreatment: treatments.For(outcome),
+		})
+	}
+	return statuses, nil
+}
+
+// SetOutcomeTreatmentBody is the request body for SetOutcomeTreatment.
+// GetOutcomeTreatments shares it but reads season_id from the query string
+// instead.
+type SetOutcomeTreatmentBody struct {
+	SeasonId model.SeasonId     `json:"season_id"`
+	Outcome  model.MatchOutcome `json:"outcome"`
+	model.OutcomeTreatment
+}
+
+// StaticallyValid ensures a season and a configurable outcome are specified.
+func (b *SetOutcomeTreatmentBody) StaticallyValid() error {
+	if b.SeasonId.RecordId() == database.InvalidRecordId {
+		return errors.New("season_id must be set")
+	}
+	if !b.Outcome.Valid() || b.Outcome == model.OutcomePlayed {
+		return errors.New("outcome must be a retirement, default, double default or walkover")
+	}
+	return nil
+}
+
+// GetOutcomeTreatments lists how the season counts each retirement, default
+// and walkover outcome toward standings. It is viewable by everyone.
+type GetOutcomeTreatments struct{}
+
+func (c GetOutcomeTreatments) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, BaseRoute + "/outcomes"
+}
+
+func (c GetOutcomeTreatments) RequestBody() (*SetOutcomeTreatmentBody, bool) {
+	return &SetOutcomeTreatmentBody{}, false
+}
+
+func (c GetOutcomeTreatments) Handler(req api.Request[*SetOutcomeTreatmentBody]) (any, int, error) {
+	seasonStr := req.HTTPRequest().URL.Query().Get("season_id")
+	if seasonStr == "" {
+		return nil, http.StatusBadRequest, errors.New("season_id must be set")
+	}
+	rid, err := database.RecordIdFromString(seasonStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	season, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Season{}, rid)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	statuses, err := outcomeTreatmentStatuses(req.Context, req.DatabaseProvider, season.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: statuses}, http.StatusOK, nil
+}
+
+// SetOutcomeTreatment configures whether matches decided by an outcome count
+// toward the season's win/loss records and set/game differentials. Only a
+// season commissioner may change the treatments.
+type SetOutcomeTreatment struct{}
+
+func (c SetOutcomeTreatment) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, BaseRoute + "/outcomes"
+}
+
+func (c SetOutcomeTreatment) RequestBody() (*SetOutcomeTreatmentBody, bool) {
+	return &SetOutcomeTreatmentBody{}, true
+}
+
+func (c SetOutcomeTreatment) Handler(req api.Request[*SetOutcomeTreatmentBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	season, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Season{}, req.Body.SeasonId.RecordId())
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if !isSeasonCommissioner(req.Context, req.DatabaseProvider, req.Token.UserId, season.ID) {
+		return nil, http.StatusForbidden, errors.New("only a season commissioner may change how match outcomes count")
+	}
+	if err := model.SetOutcomeTreatment(req.Context, req.DatabaseProvider, season.ID, req.Body.Outcome, req.Body.OutcomeTreatment); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	statuses, err := outcomeTreatmentStatuses(req.Context, req.DatabaseProvider, season.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: statuses}, http.StatusOK, nil
+}
@@ -17,11 +17,60 @@ import (
 // TeamMatch, TeamMatchIndividualMatch, MatchEditor) are also exposed via
 // generic CRUD in main.go.
 //
+// Matches may instead be scored live, a point or game at a time. Each event is
+// appended to the match's log and the score is replayed from the log against
+// the scoring structure, keeping the aggregate main/secondary values and the
+// match status in step. Once a match has a log, /match/score is refused so
+// the two can't disagree.
+//
+// Scores of team match lines need agreement from both teams. When one team's
+// captain or co-captain completes a line, the other team's captain or
+// co-captain must confirm or dispute it; disputes carry both claimed scores to
+// the season commissioners, whose resolution (like any result they enter) is
+// final. A team match only counts as complete for standings once every line
+// has been confirmed or resolved, and a final score can no longer be changed.
+//
+// Scores entered with /match/score are checked against the match's scoring
+// structure: win threshold, must-win-by margin, instant-win threshold, and
+// each secondary structure when the score is given unit by unit. Completing a
+// match requires a finished result, including the tiebreak score of a unit
+// decided by one (e.g. a 7-6 set).
+//
+// A match can also be decided without being played out: one side retires
+// (keeping the partial score), defaults or gives a walkover, or both sides
+// default. Each season's commissioners choose whether matches decided that way
+// count toward the win/loss records and set/game differentials in standings.
+//
+// A team match is decided on points: each line won is worth its format line's
+// points (one by default), and the team with more points wins the match and
+// the format's match win bonus. Standings rank teams by points first.
+//
+// A week's scoreboard can be followed live as server-sent events. Every route
+// that changes a score publishes the changed lines and their team match's
+// running tally to the week's subscribers, plus a completion event once the
+// team match completes, so watchers never poll the database.
+//
+// Teams are rebuilt every season, so head-to-head history is kept between
+// captains: every team match between a team one captain led and a team the
+// other led, across all seasons, counts toward their series.
+//
 //	POST /match/generate     body: { week_id, scoring_structure_id }
 //	GET  /match/week?week_id=              -> WeekMatchDetail (score sheet)
-//	POST /match/score        body: { individual_match_id, main_value, secondary_value, win_override }
+//	GET  /match/week/stream?week_id=       -> server-sent scoreboard events
+//	POST /match/score        body: { individual_match_id, main_value, secondary_value, win_override, secondary_scores }
 //	POST /match/:id/complete -> mark an individual match complete (determines winner)
+//	POST /match/event        body: { individual_match_id, unit } -> append to the live scoring log
+//	GET  /match/:id/live                   -> LiveScore replayed from the log
+//	POST /match/:id/undo?count=            -> remove the last count events (default 1)
+//	POST /match/:id/outcome  body: { outcome } -> the path side retired, defaulted or withdrew
+//	GET  /match/outcomes?season_id=        -> how each outcome counts in standings
+//	POST /match/outcomes     body: { season_id, outcome, counts_toward_record, counts_toward_differential }
+//	POST /match/:id/confirm                -> accept the other team's reported score
+//	POST /match/:id/dispute  body: { main_value, secondary_value, opponent_main_value, opponent_secondary_value, won, note }
+//	POST /match/:id/resolve  body: { main_value, secondary_value, opponent_main_value, opponent_secondary_value, won }
+//	GET  /match/disputes?season_id=        -> disputed ScoreReports (commissioners)
 //	GET  /match/standings?season_id=       -> Standings
+//	GET  /match/head_to_head?team_a=&team_b= or ?captain_a=&captain_b= -> HeadToHead
 func RegisterRoutes(e *gin.RouterGroup, db database.Provider) {
 	generate := api.RouteFamily[*GenerateBody]{DatabaseProvider: db}
 	generate.Handle(e, GenerateMatches{})
@@ -33,8 +82,28 @@ func RegisterRoutes(e *gin.RouterGroup, db database.Provider) {
 	score.Handle(e, RecordScore{})
 
 	complete := api.RouteFamily[*EmptyBody]{DatabaseProvider: db}
-	complete.Handle(e, CompleteMatch{})
+	complete.Handle(e, CompleteMatch{}, ConfirmScore{})
+
+	event := api.RouteFamily[*ScoreEventBody]{DatabaseProvider: db}
+	event.Handle(e, RecordScoreEvent{})
+
+	live := api.RouteFamily[*EmptyBody]{DatabaseProvider: db}
+	live.Handle(e, GetLiveScore{}, UndoScoreEvents{})
+
+	claim := api.RouteFamily[*ScoreClaimBody]{DatabaseProvider: db}
+	claim.Handle(e, DisputeScore{}, ResolveScore{})
+
+	outcome := api.RouteFamily[*OutcomeBody]{DatabaseProvider: db}
+	outcome.Handle(e, RecordOutcome{})
+
+	treatment := api.RouteFamily[*SetOutcomeTreatmentBody]{DatabaseProvider: db}
+	treatment.Handle(e, GetOutcomeTreatments{}, SetOutcomeTreatment{})
 
 	standings := api.RouteFamily[*StandingsQuery]{DatabaseProvider: db}
-	standings.Handle(e, GetStandings{})
+	standings.Handle(e, GetStandings{}, ListDisputes{})
+
+	headToHead := api.RouteFamily[*HeadToHeadQuery]{DatabaseProvider: db}
+	headToHead.Handle(e, GetHeadToHead{})
+
+	e.GET(BaseRoute+"/week/stream", StreamWeekScoreboard(db))
 }
@@ -0,0 +1,232 @@
+package match
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scoreboard event types, sent as the SSE event name.
+const (
+	ScoreboardSnapshot  = "snapshot"   // Data is the week's WeekMatchDetail, sent once on connect
+	ScoreboardMatch     = "match"      // Data is an IndividualMatchDTO whose score changed
+	ScoreboardTeamMatch = "team_match" // Data is the TeamMatchDTO with its running tally
+	ScoreboardComplete  = "complete"   // Data is the TeamMatchDTO, sent once when it completes
+)
+
+// scoreboardBuffer is how many events a subscriber may fall behind by before
+// events to it are dropped.
+const scoreboardBuffer = 64
+
+// scoreboardKeepalive is how often an idle stream is sent a keepalive comment
+// so proxies don't close it.
+const scoreboardKeepalive = 15 * time.Second
+
+// ScoreboardEvent is one update pushed to a week's scoreboard stream.
+type ScoreboardEvent struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// scoreboard fans score updates out to the subscribers of each week. Updates
+// are built once by the handler that changed the score and shared by every
+// subscriber, so watching a week costs no database reads per client. The
+// completion state of each watched week's team matches is kept so that the
+// completion event fires once per completion; it is dropped with the week's
+// last subscriber.
+type scoreboard struct {
+	mu          sync.Mutex
+	subscribers map[model.WeekId]map[chan ScoreboardEvent]struct{}
+	completed   map[model.WeekId]map[model.TeamMatchId]bool
+}
+
+// liveScoreboard is the process-wide scoreboard fed by the scoring routes.
+var liveScoreboard = &scoreboard{
+	subscribers: map[model.WeekId]map[chan ScoreboardEvent]struct{}{},
+	completed:   map[model.WeekId]map[model.TeamMatchId]bool{},
+}
+
+// subscribe registers a new subscriber to the week's updates, returning its
+// event channel and a function that unsubscribes it.
+func (s *scoreboard) subscribe(weekId model.WeekId) (<-chan ScoreboardEvent, func()) {
+	ch := make(chan ScoreboardEvent, scoreboardBuffer)
+	s.mu.Lock()
+	if s.subscribers[weekId] == nil {
+		s.subscribers[weekId] = map[chan ScoreboardEvent]struct{}{}
+	}
+	s.subscribers[weekId][ch] = struct{}{}
+	s.mu.Unlock()
+	return ch, func() {
+		s.mu.Lock()
+		delete(s.subscribers[weekId], ch)
+		if len(s.subscribers[weekId]) == 0 {
+			delete(s.subscribers, weekId)
+			delete(s.completed, weekId)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// watched reports whether anyone is subscribed to the week.
+func (s *scoreboard) watched(weekId model.WeekId) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers[weekId]) > 0
+}
+
+// publish sends events to every subscriber of the week. A subscriber whose
+// buffer is full misses the events rather than holding up the scoring route.
+func (s *scoreboard) publish(weekId model.WeekId, events ...ScoreboardEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subscribers[weekId] {
+		for _, event := range events {
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+	}
+}
+
+// seedCompleted records which of a newly watched week's team matches are
+// already complete, so that an update to one doesn't announce it again. Team
+// matches already tracked are left alone.
+func (s *scoreboard) seedCompleted(weekId model.WeekId, detail *WeekMatchDetail) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribers[weekId] == nil {
+		return
+	}
+	if s.completed[weekId] == nil {
+		s.completed[weekId] = map[model.TeamMatchId]bool{}
+	}
+	for _, tm := range detail.TeamMatches {
+		rid, err := database.RecordIdFromString(tm.ID)
+		if err != nil {
+			continue
+		}
+		if _, tracked := s.completed[weekId][model.TeamMatchId(rid)]; !tracked {
+			s.completed[weekId][model.TeamMatchId(rid)] = tm.Complete
+		}
+	}
+}
+
+// setComplete records whether the team match is complete, reporting whether
+// it has just completed. A team match reopened, e.g. by a dispute, is no
+// longer complete, so completing it again is reported again.
+func (s *scoreboard) setComplete(weekId model.WeekId, id model.TeamMatchId, complete bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribers[weekId] == nil {
+		return false
+	}
+	if s.completed[weekId] == nil {
+		s.completed[weekId] = map[model.TeamMatchId]bool{}
+	}
+	was := s.completed[weekId][id]
+	s.completed[weekId][id] = complete
+	return complete && !was
+}
+
+// publishMatchUpdate pushes the current state of an individual match, then its
+// opponent, and its team match's running tally to the week's scoreboard, along
+// with a completion event when the team match completes. It is
+// called by every route that changes a score after the change is stored, and
+// does nothing when the week has no subscribers or the match isn't part of a
+// team match. A failure to build the update is not the scoring route's error,
+// so it is dropped.
+func publishMatchUpdate(ctx context.Context, db database.Provider, matchId model.IndividualMatchId) {
+	tm, _, err := model.GetTeamMatchForIndividualMatch(ctx, db, matchId)
+	if err != nil || tm == nil || !liveScoreboard.watched(tm.WeekId) {
+		return
+	}
+	week, err := database.GetExistingRecordById(ctx, db, &model.Week{}, tm.WeekId.RecordId())
+	if err != nil {
+		return
+	}
+	season, err := weekSeason(ctx, db, week)
+	if err != nil || season == nil {
+		return
+	}
+	treatments, err := model.GetOutcomeTreatments(ctx, db, season.ID)
+	if err != nil {
+		return
+	}
+	dto, err := buildTeamMatchDTO(ctx, db, tm, treatments)
+	if err != nil {
+		return
+	}
+	var events, opponent []ScoreboardEvent
+	for _, m := range dto.Matches {
+		switch matchId.RecordId().String() {
+		case m.ID:
+			events = append(events, ScoreboardEvent{Type: ScoreboardMatch, Data: m})
+		case m.Opponent:
+			opponent = append(opponent, ScoreboardEvent{Type: ScoreboardMatch, Data: m})
+		}
+	}
+	events = append(events, opponent...)
+	events = append(events, ScoreboardEvent{Type: ScoreboardTeamMatch, Data: dto})
+	if liveScoreboard.setComplete(tm.WeekId, tm.ID, dto.Complete) {
+		events = append(events, ScoreboardEvent{Type: ScoreboardComplete, Data: dto})
+	}
+	liveScoreboard.publish(tm.WeekId, events...)
+}
+
+// StreamWeekScoreboard streams a week's scoreboard as server-sent events: a
+// snapshot of the week's score sheet on connect, then an event for every
+// score change until the client disconnects. It is viewable by everyone.
+//
+//	GET /match/week/stream?week_id=
+func StreamWeekScoreboard(db database.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rid, err := database.RecordIdFromString(c.Query("week_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "week_id must be set"})
+			return
+		}
+		ctx := c.Request.Context()
+		week, err := database.GetExistingRecordById(ctx, db, &model.Week{}, rid)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// Subscribe before taking the snapshot so no update between the two
+		// is missed; a client may see one twice, which is harmless.
+		events, unsubscribe := liveScoreboard.subscribe(week.ID)
+		defer unsubscribe()
+		detail, err := weekDetailForWeek(ctx, db, week)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		liveScoreboard.seedCompleted(week.ID, detail)
+		c.SSEvent(ScoreboardSnapshot, detail)
+		c.Writer.Flush()
+
+		keepalive := time.NewTicker(scoreboardKeepalive)
+		defer keepalive.Stop()
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case event := <-events:
+				c.SSEvent(event.Type, event.Data)
+				return true
+			case <-keepalive.C:
+				_, err := w.Write([]byte(": keepalive\n\n"))
+				return err == nil
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package proposal
+
+import (
+	"errors"
+	"net/http"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetOptionsBody is the request body for SetProposalOptions.
+type SetOptionsBody struct {
+	Options []string `json:"options"`
+}
+
+func (b *SetOptionsBody) StaticallyValid() error {
+	return nil
+}
+
+// SetProposalOptions replaces the options of a plurality or ranked-choice
+// proposal. Only a commissioner of the proposal's season may set them, and
+// only before anyone has cast a ballot.
+type SetProposalOptions struct{}
+
+func (c SetProposalOptions) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPut, api.AppendPathId(BaseRoute) + "/options"
+}
+
+func (c SetProposalOptions) RequestBody() (*SetOptionsBody, bool) {
+	return &SetOptionsBody{}, true
+}
+
+func (c SetProposalOptions) Handler(req api.Request[*SetOptionsBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+
+	proposal, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.CommissionerProposal{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	wac := database.NewWithAccessControl[*model.CommissionerProposal](req.Context, req.DatabaseProvider, req.Token.UserId)
+	if !wac.CanUserEdit(proposal) {
+		return nil, http.StatusForbidden, errors.New("only a season commissioner may set a proposal's options")
+	}
+	if err := proposal.SetOptions(req.Context, req.DatabaseProvider, req.Body.Options); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	detail, status, err := buildDetail(req.Context, req.DatabaseProvider, proposal, req.Token.UserId)
+	if err != nil {
+		return nil, status, err
+	}
+	return gin.H{api.ResourceKey: detail}, http.StatusOK, nil
+}
+
+// CastBallotBody is the request body for CastBallot.
+type CastBallotBody struct {
+	// Ranking is the voter's choice of option ids, first choice first. A
+	// plurality ballot has exactly one.
+	Ranking []database.RecordId `json:"ranking"`
+}
+
+func (b *CastBallotBody) StaticallyValid() error {
+	if len(b.Ranking) == 0 {
+		return errors.New("ranking must not be empty")
+	}
+	return nil
+}
+
+// CastBallot records the authenticated user's ballot on a plurality or
+// ranked-choice proposal, replacing any ballot they cast before. Voters must
+// be season participants, as for CastVote.
+type CastBallot struct{}
+
+func (c CastBallot) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, api.AppendPathId(BaseRoute) + "/ballot"
+}
+
+func (c CastBallot) RequestBody() (*CastBallotBody, bool) {
+	return &CastBallotBody{}, true
+}
+
+func (c CastBallot) Handler(req api.Request[*CastBallotBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+
+	proposal, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.CommissionerProposal{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	if err := proposal.CastBallot(req.Context, req.DatabaseProvider, req.Token.UserId, req.Body.Ranking); err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	detail, status, err := buildDetail(req.Context, req.DatabaseProvider, proposal, req.Token.UserId)
+	if err != nil {
+		return nil, status, err
+	}
+	return gin.H{api.ResourceKey: detail}, http.StatusOK, nil
+}
+
+// GetProposalTally returns the count of a plurality or ranked-choice
+// proposal's ballots, round by round, to any eligible voter.
+type GetProposalTally struct{}
+
+func (c GetProposalTally) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, api.AppendPathId(BaseRoute) + "/tally"
+}
+
+func (c GetProposalTally) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c GetProposalTally) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+
+	proposal, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.CommissionerProposal{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	wac := database.NewWithAccessControl[*model.CommissionerProposal](req.Context, req.DatabaseProvider, req.Token.UserId)
+	if !wac.CanUserAccess(proposal) {
+		return nil, http.StatusNotFound, errors.New("proposal not found")
+	}
+
+	tally, err := proposal.Tally(req.Context, req.DatabaseProvider)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return gin.H{api.ResourceKey: tally}, http.StatusOK, nil
+}
@@ -0,0 +1,98 @@
+package proposal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"intraclub/database"
+	"intraclub/mailer"
+	"intraclub/model"
+)
+
+// Notifier tells the participants of a proposal whose voting has closed what
+// its outcome was.
+type Notifier func(ctx context.Context, proposal *model.CommissionerProposal, recipients []model.EmailAddress) error
+
+// LogNotifier is a Notifier that only logs the outcome, for when no mail
+// domain is configured.
+func LogNotifier(_ context.Context, proposal *model.CommissionerProposal, recipients []model.EmailAddress) error {
+	log.Printf("proposal %s closed %s; would notify %v", proposal.ID, proposal.Outcome, recipients)
+	return nil
+}
+
+// MailNotifier returns a Notifier that emails the participants from the given
+// domain, one message per participant so that none sees the others'
+// addresses. A failed message doesn't stop the rest from being sent.
+func MailNotifier(domain string) (Notifier, error) {
+	m, err := mailer.New(mailer.Config{
+		FromDomain: domain,
+		Hostname:   "mail." + domain,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, proposal *model.CommissionerProposal, recipients []model.EmailAddress) error {
+		var errs []error
+		for _, r := range recipients {
+			if err := m.Send(ctx, newOutcomeMessage(r, proposal, domain)); err != nil {
+				errs = append(errs, fmt.Errorf("failed to email %s: %w", r, err))
+			}
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+func newOutcomeMessage(addr model.EmailAddress, proposal *model.CommissionerProposal, domain string) mailer.Message {
+	return mailer.Message{
+		From:    "noreply@" + domain,
+		To:      []string{string(addr)},
+		Subject: fmt.Sprintf("Proposal %s", proposal.Outcome),
+		Text:    outcomeText(proposal),
+	}
+}
+
+// outcomeText is the plain-text notification of a closed proposal's outcome.
+func outcomeText(proposal *model.CommissionerProposal) string {
+	return fmt.Sprintf("Voting on the proposal below closed on %s.\n\n%s\n\nOutcome: %s\n",
+		proposal.ClosedAt.Format(time.RFC1123), proposal.Description, proposal.Outcome)
+}
+
+// CloseExpired closes every proposal whose voting deadline has passed as of
+// now, applies the rule amendments of those accepted, and notifies each closed
+// proposal's participants. Failures to apply or notify are logged rather than
+// returned so that one proposal can't hold up the rest.
+func CloseExpired(ctx context.Context, db database.Provider, now time.Time, notify Notifier) error {
+	closed, err := model.CloseExpiredProposals(ctx, db, now)
+	for _, proposal := range closed {
+		if _, applyErr := proposal.ApplyAmendments(ctx, db); applyErr != nil {
+			log.Printf("failed to apply amendments of proposal %s: %s", proposal.ID, applyErr)
+		}
+		recipients, notifyErr := proposal.GetParticipantEmails(ctx, db)
+		if notifyErr == nil {
+			notifyErr = notify(ctx, proposal, recipients)
+		}
+		if notifyErr != nil {
+			log.Printf("failed to notify participants of proposal %s: %s", proposal.ID, notifyErr)
+		}
+	}
+	return err
+}
+
+// RunCloser calls CloseExpired every interval until ctx is done.
+func RunCloser(ctx context.Context, db database.Provider, interval time.Duration, notify Notifier) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := CloseExpired(ctx, db, now, notify); err != nil {
+				log.Printf("failed to close expired proposals: %s", err)
+			}
+		}
+	}
+}
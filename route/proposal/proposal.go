@@ -1,7 +1,13 @@
 // Package proposal implements the custom REST surface for
-// model.CommissionerProposal: casting a vote (validating the voter is a season
-// participant) and fetching a proposal's detail/tally. The generic CRUD create
-// / list / update / delete endpoints are registered in main.go.
+// model.CommissionerProposal: casting a vote or, on a multi-option proposal, a
+// ballot (validating the voter is a season participant), fetching a
+// proposal's detail/tally, setting a multi-option proposal's options (see
+// ballot.go), and attaching structured
+// rule amendments that are applied to their ruleset once the proposal is
+// accepted. It also runs the background job that closes proposals whose voting
+// deadline has passed and notifies their participants (see closer.go). The
+// generic CRUD create / list / update / delete endpoints are registered in
+// main.go.
 package proposal
 
 import (
@@ -32,6 +38,40 @@ type ProposalDetail struct {
 	Rejected     bool                        `json:"rejected"`
 	// MyVote is the requesting user's vote, if they have cast one yet.
 	MyVote *bool `json:"my_vote,omitempty"`
+	// History is every vote cast on the proposal, including changed votes,
+	// oldest first.
+	History []*model.CommissionerProposalVoteChange `json:"history"`
+	// Options are the choices of a multi-option proposal, in order.
+	Options []*model.CommissionerProposalOption `json:"options"`
+	// MyRanking is the requesting user's ballot on a multi-option proposal,
+	// first choice first, if they have cast one yet.
+	MyRanking []database.RecordId `json:"my_ranking,omitempty"`
+	// BallotHistory is every ballot cast on a multi-option proposal,
+	// including changed ballots, one entry per ranked choice, oldest first.
+	BallotHistory []*model.CommissionerProposalBallotChange `json:"ballot_history"`
+	// Amendments are the rule amendments the proposal carries, in order.
+	Amendments []*model.CommissionerProposalAmendment `json:"amendments"`
+	// UnadoptedChanges are the changes made to the season's pinned ruleset
+	// since it was pinned, without a vote, that the season adopts along with
+	// the amendments if the proposal passes.
+	UnadoptedChanges *model.RulesetDiff `json:"unadopted_changes,omitempty"`
+	// ApplyError is why the amendments of an accepted proposal could not be
+	// applied, if they could not.
+	ApplyError string `json:"apply_error,omitempty"`
+	// Thread is the proposal's discussion, oldest comment first.
+	Thread []*ThreadComment `json:"thread"`
+}
+
+// ThreadComment is a comment in a proposal's discussion thread along with
+// its reactions and how its author has voted so far.
+type ThreadComment struct {
+	*model.Comment
+	Reactions model.ReactionList `json:"reactions"`
+	// Vote is the author's current vote on a yes/no proposal, if cast.
+	Vote *bool `json:"vote,omitempty"`
+	// Ranking is the author's current ballot on a multi-option proposal,
+	// first choice first, if cast.
+	Ranking []database.RecordId `json:"ranking,omitempty"`
 }
 
 // RegisterRoutes wires up the custom proposal endpoints.
@@ -40,7 +80,16 @@ func RegisterRoutes(e *gin.RouterGroup, db database.Provider) {
 	voteFamily.Handle(e, CastVote{})
 
 	detailFamily := api.RouteFamily[*EmptyBody]{DatabaseProvider: db}
-	detailFamily.Handle(e, GetProposalDetail{})
+	detailFamily.Handle(e, GetProposalDetail{}, ApplyProposalAmendments{}, GetProposalTally{})
+
+	ballotFamily := api.RouteFamily[*CastBallotBody]{DatabaseProvider: db}
+	ballotFamily.Handle(e, CastBallot{})
+
+	optionsFamily := api.RouteFamily[*SetOptionsBody]{DatabaseProvider: db}
+	optionsFamily.Handle(e, SetProposalOptions{})
+
+	amendmentsFamily := api.RouteFamily[*SetAmendmentsBody]{DatabaseProvider: db}
+	amendmentsFamily.Handle(e, SetProposalAmendments{})
 }
 
 // EmptyBody is used by the detail route, which accepts no request body.
@@ -63,7 +112,9 @@ func (b *CastVoteBody) StaticallyValid() error {
 // CastVote records the authenticated user's vote on a proposal. The model's
 // Vote method enforces that the voter is a season participant (commissioner or
 // team captain) and that each voter casts at most one vote (subsequent votes
-// update the existing row).
+// update the existing row). The vote that gets a proposal accepted applies its
+// rule amendments; if they can't be applied the vote still stands and the
+// reason is reported in the detail's apply_error.
 type CastVote struct{}
 
 func (c CastVote) Path() (api.HttpMethod, string) {
@@ -87,16 +138,21 @@ func (c CastVote) Handler(req api.Request[*CastVoteBody]) (any, int, error) {
 	if err := proposal.Vote(req.Context, req.DatabaseProvider, req.Token.UserId, req.Body.Vote); err != nil {
 		return nil, http.StatusForbidden, err
 	}
+	_, applyErr := proposal.ApplyAmendments(req.Context, req.DatabaseProvider)
 
 	detail, status, err := buildDetail(req.Context, req.DatabaseProvider, proposal, req.Token.UserId)
 	if err != nil {
 		return nil, status, err
 	}
+	if applyErr != nil {
+		detail.ApplyError = applyErr.Error()
+	}
 	return gin.H{api.ResourceKey: detail}, http.StatusOK, nil
 }
 
-// GetProposalDetail returns a proposal's detail and vote tally to any eligible
-// voter (a commissioner or team captain of the proposal's season).
+// GetProposalDetail returns a proposal's detail, vote tally and discussion
+// thread to any eligible voter (a commissioner or team captain of the
+// proposal's season).
 type GetProposalDetail struct{}
 
 func (c GetProposalDetail) Path() (api.HttpMethod, string) {
@@ -163,13 +219,172 @@ func buildDetail(ctx context.Context, db database.Provider, proposal *model.Comm
 		}
 	}
 
+	history, err := proposal.GetVoteChanges(ctx, db)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	amendments, err := proposal.GetAmendments(ctx, db)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	unadopted, err := proposal.UnadoptedChanges(ctx, db)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	options, err := proposal.GetOptions(ctx, db)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	ballots, err := proposal.GetBallots(ctx, db)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	ballotHistory, err := proposal.GetBallotChanges(ctx, db)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	comments, err := proposal.GetComments(ctx, db)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	thread := make([]*ThreadComment, 0, len(comments))
+	for _, comment := range comments {
+		reactions, err := comment.GetReactions(ctx, db)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		entry := &ThreadComment{Comment: comment, Reactions: reactions, Ranking: ballots[comment.Owner]}
+		if v, ok := votes[comment.Owner]; ok {
+			entry.Vote = &v
+		}
+		thread = append(thread, entry)
+	}
+
 	return &ProposalDetail{
-		Proposal:     proposal,
-		VotesFor:     votesFor,
-		VotesAgainst: votesAgainst,
-		Voters:       voters,
-		Accepted:     accepted,
-		Rejected:     rejected,
-		MyVote:       myVote,
+		Proposal:         proposal,
+		VotesFor:         votesFor,
+		VotesAgainst:     votesAgainst,
+		Voters:           voters,
+		Accepted:         accepted,
+		Rejected:         rejected,
+		MyVote:           myVote,
+		History:          history,
+		Options:          options,
+		MyRanking:        ballots[userId],
+		BallotHistory:    ballotHistory,
+		Amendments:       amendments,
+		UnadoptedChanges: unadopted,
+		Thread:           thread,
 	}, http.StatusOK, nil
 }
+
+// SetAmendmentsBody is the request body for SetProposalAmendments: the
+// ruleset to amend and the amendments to apply to it, in order. An empty
+// ruleset and list make the proposal free text again.
+type SetAmendmentsBody struct {
+	RulesetId  model.RulesetId        `json:"ruleset_id"`
+	Amendments []*model.RuleAmendment `json:"amendments"`
+}
+
+func (b *SetAmendmentsBody) StaticallyValid() error {
+	for _, a := range b.Amendments {
+		if a == nil {
+			return errors.New("amendments must not be null")
+		}
+	}
+	return nil
+}
+
+// SetProposalAmendments replaces the rule amendments a proposal carries. Only
+// a commissioner of the proposal's season who may also edit the target
+// ruleset may set them, and only before anyone has voted.
+type SetProposalAmendments struct{}
+
+func (c SetProposalAmendments) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPut, api.AppendPathId(BaseRoute) + "/amendments"
+}
+
+func (c SetProposalAmendments) RequestBody() (*SetAmendmentsBody, bool) {
+	return &SetAmendmentsBody{}, true
+}
+
+func (c SetProposalAmendments) Handler(req api.Request[*SetAmendmentsBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+
+	proposal, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.CommissionerProposal{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	wac := database.NewWithAccessControl[*model.CommissionerProposal](req.Context, req.DatabaseProvider, req.Token.UserId)
+	if !wac.CanUserEdit(proposal) {
+		return nil, http.StatusForbidden, errors.New("only a season commissioner may set a proposal's amendments")
+	}
+	if !req.Body.RulesetId.Empty() {
+		ruleset, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Ruleset{}, req.Body.RulesetId.RecordId())
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		rwac := database.NewWithAccessControl[*model.Ruleset](req.Context, req.DatabaseProvider, req.Token.UserId)
+		if !rwac.CanUserEdit(ruleset) {
+			return nil, http.StatusForbidden, errors.New("not authorized to amend this ruleset")
+		}
+	}
+
+	if err := proposal.SetAmendments(req.Context, req.DatabaseProvider, req.Body.RulesetId, req.Body.Amendments); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	detail, status, err := buildDetail(req.Context, req.DatabaseProvider, proposal, req.Token.UserId)
+	if err != nil {
+		return nil, status, err
+	}
+	return gin.H{api.ResourceKey: detail}, http.StatusOK, nil
+}
+
+// ApplyProposalAmendments retries applying an accepted proposal's rule
+// amendments, e.g. after a failure reported in apply_error. Only a
+// commissioner of the proposal's season may apply them. Applying is refused
+// if the target ruleset has been superseded since the amendments were set.
+type ApplyProposalAmendments struct{}
+
+func (c ApplyProposalAmendments) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, api.AppendPathId(BaseRoute) + "/apply"
+}
+
+func (c ApplyProposalAmendments) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c ApplyProposalAmendments) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+
+	proposal, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.CommissionerProposal{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	wac := database.NewWithAccessControl[*model.CommissionerProposal](req.Context, req.DatabaseProvider, req.Token.UserId)
+	if !wac.CanUserEdit(proposal) {
+		return nil, http.StatusForbidden, errors.New("only a season commissioner may apply a proposal's amendments")
+	}
+	accepted, _, err := proposal.Status(req.Context, req.DatabaseProvider)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if !accepted {
+		return nil, http.StatusConflict, errors.New("proposal has not been accepted")
+	}
+	if _, err := proposal.ApplyAmendments(req.Context, req.DatabaseProvider); err != nil {
+		return nil, http.StatusConflict, err
+	}
+
+	detail, status, err := buildDetail(req.Context, req.DatabaseProvider, proposal, req.Token.UserId)
+	if err != nil {
+		return nil, status, err
+	}
+	return gin.H{api.ResourceKey: detail}, http.StatusOK, nil
+}
@@ -57,7 +57,7 @@ func (c AmendRulesetName) Handler(req api.Request[*AmendNameBody]) (any, int, er
 		return nil, http.StatusForbidden, errors.New("not authorized to amend this ruleset")
 	}
 
-	newRuleset, err := ruleset.EditName(req.Context, req.DatabaseProvider, req.Body.Name)
+	newRuleset, err := ruleset.EditName(req.Context, req.DatabaseProvider, req.Body.Name, req.Token.UserId)
 	if err != nil {
 		return nil, http.StatusBadRequest, err
 	}
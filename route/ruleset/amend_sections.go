@@ -14,9 +14,9 @@ import (
 // AmendSections applies a RuleAmendment (add / remove / modify / reorder a
 // section) to a Ruleset. Rulesets may not be directly modified
 // (Ruleset.PreUpdate forbids it), so section edits go through the
-// Ruleset.Amend flow: add/remove/reorder produce a new superseding revision,
-// while a pure content edit updates the section in place. Returns the (possibly
-// new) Ruleset revision.
+// Ruleset.Amend flow: add/remove/reorder and markdown edits produce a new
+// superseding revision, while a title-only edit updates the section in place.
+// Returns the (possibly new) Ruleset revision.
 type AmendSections struct{}
 
 func (c AmendSections) Path() (api.HttpMethod, string) {
@@ -43,6 +43,7 @@ func (c AmendSections) Handler(req api.Request[*model.RuleAmendment]) (any, int,
 		return nil, http.StatusForbidden, errors.New("not authorized to amend this ruleset")
 	}
 
+	req.Body.Author = req.Token.UserId
 	newRuleset, err := ruleset.Amend(req.Context, req.DatabaseProvider, req.Body)
 	if err != nil {
 		return nil, http.StatusBadRequest, err
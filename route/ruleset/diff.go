@@ -0,0 +1,67 @@
+package ruleset
+
+import (
+	"errors"
+	"net/http"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiffQuery holds the query parameters for DiffRulesets.
+type DiffQuery struct {
+	From model.RulesetId `json:"from"`
+	To   model.RulesetId `json:"to"`
+}
+
+// StaticallyValid has no static constraints (the query is read in the handler).
+func (q *DiffQuery) StaticallyValid() error {
+	return nil
+}
+
+// RulesetDiffResponse is a RulesetDiff along with its unified-diff text
+// rendering, suitable for email.
+type RulesetDiffResponse struct {
+	*model.RulesetDiff
+	Unified string `json:"unified"`
+}
+
+// DiffRulesets compares two revisions of the same ruleset lineage given by the
+// from and to query parameters, reporting the sections added, removed,
+// reordered and modified (with a line-level diff of each modified section's
+// markdown). It is viewable by everyone.
+type DiffRulesets struct{}
+
+func (c DiffRulesets) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, BaseRoute + "/diff"
+}
+
+func (c DiffRulesets) RequestBody() (*DiffQuery, bool) {
+	return &DiffQuery{}, false
+}
+
+func (c DiffRulesets) Handler(req api.Request[*DiffQuery]) (any, int, error) {
+	query := req.HTTPRequest().URL.Query()
+	if query.Get("from") == "" || query.Get("to") == "" {
+		return nil, http.StatusBadRequest, errors.New("from and to must be set")
+	}
+	var rulesets [2]*model.Ruleset
+	for i, key := range []string{"from", "to"} {
+		rid, err := database.RecordIdFromString(query.Get(key))
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		rulesets[i], err = database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Ruleset{}, rid)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	diff, err := rulesets[0].Diff(req.Context, req.DatabaseProvider, rulesets[1])
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return gin.H{api.ResourceKey: &RulesetDiffResponse{RulesetDiff: diff, Unified: diff.Unified()}}, http.StatusOK, nil
+}
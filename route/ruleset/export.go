@@ -0,0 +1,68 @@
+package ruleset
+
+import (
+	"fmt"
+	"net/http"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportRuleset renders the ruleset revision in the path as a single document
+// for publishing, rather than JSON: Markdown by default, or sanitized HTML
+// with format=html. With compare set to an earlier revision of the same
+// ruleset, the changes since that revision are highlighted. It is viewable by
+// everyone. Append download=1 to have it served as an attachment.
+//
+//	GET /ruleset/:id/export?format=markdown|html&compare=&download=
+func ExportRuleset(db database.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rid, err := database.RecordIdFromString(c.Param(api.PathIdField))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx := c.Request.Context()
+		ruleset, err := database.GetExistingRecordById(ctx, db, &model.Ruleset{}, rid)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		format := model.RulesetExportFormat(c.DefaultQuery("format", string(model.RulesetExportMarkdown)))
+		if err := format.StaticallyValid(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var previous *model.Ruleset
+		if compare := c.Query("compare"); compare != "" {
+			pid, err := database.RecordIdFromString(compare)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			previous, err = database.GetExistingRecordById(ctx, db, &model.Ruleset{}, pid)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		document, err := ruleset.Export(ctx, db, format, previous)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if c.Query("download") != "" {
+			extension := "md"
+			if format == model.RulesetExportHTML {
+				extension = "html"
+			}
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="ruleset-%s-r%d.%s"`, ruleset.ID, ruleset.Revision, extension))
+		}
+		c.Data(http.StatusOK, format.ContentType(), []byte(document))
+	}
+}
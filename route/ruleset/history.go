@@ -0,0 +1,86 @@
+package ruleset
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HistoryQuery holds the query parameter for GetRulesetAsOf.
+type HistoryQuery struct {
+	Date string `json:"date"`
+}
+
+// StaticallyValid has no static constraints (the query is read in the handler).
+func (q *HistoryQuery) StaticallyValid() error {
+	return nil
+}
+
+// GetRulesetHistory returns the whole history tree of the ruleset in the
+// path: every revision from the originally created ruleset on, each with the
+// kind of change that made it, its author and the revisions made from it,
+// including forks into other owners' copies. It is viewable by everyone.
+type GetRulesetHistory struct{}
+
+func (c GetRulesetHistory) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, api.AppendPathId(BaseRoute) + "/history"
+}
+
+func (c GetRulesetHistory) RequestBody() (*HistoryQuery, bool) {
+	return &HistoryQuery{}, false
+}
+
+func (c GetRulesetHistory) Handler(req api.Request[*HistoryQuery]) (any, int, error) {
+	ruleset, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Ruleset{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	history, err := ruleset.GetHistory(req.Context, req.DatabaseProvider)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return gin.H{api.ResourceKey: history}, http.StatusOK, nil
+}
+
+// GetRulesetAsOf returns the ruleset in the path, with its sections, as it
+// stood at the date query parameter: an RFC 3339 time, or a YYYY-MM-DD date
+// meaning the end of that day (UTC). It is viewable by everyone.
+type GetRulesetAsOf struct{}
+
+func (c GetRulesetAsOf) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, api.AppendPathId(BaseRoute) + "/as_of"
+}
+
+func (c GetRulesetAsOf) RequestBody() (*HistoryQuery, bool) {
+	return &HistoryQuery{}, false
+}
+
+func (c GetRulesetAsOf) Handler(req api.Request[*HistoryQuery]) (any, int, error) {
+	raw := req.HTTPRequest().URL.Query().Get("date")
+	if raw == "" {
+		return nil, http.StatusBadRequest, errors.New("date must be set")
+	}
+	at, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		day, dayErr := time.Parse("2006-01-02", raw)
+		if dayErr != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		at = day.Add(24*time.Hour - time.Nanosecond)
+	}
+	ruleset, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Ruleset{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	snapshot, err := ruleset.AsOf(req.Context, req.DatabaseProvider, at)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	return gin.H{api.ResourceKey: snapshot}, http.StatusOK, nil
+}
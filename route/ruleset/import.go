@@ -0,0 +1,95 @@
+package ruleset
+
+import (
+	"errors"
+	"net/http"
+
+	"intraclub/api"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportBody is the request body for PreviewImport and ImportRuleset: a
+// Markdown document to split into sections on headings at HeadingLevel (1 by
+// default), and the name of the new ruleset (the document's title if empty).
+type ImportBody struct {
+	Name         string `json:"name"`
+	Markdown     string `json:"markdown"`
+	HeadingLevel int    `json:"heading_level"`
+}
+
+func (b *ImportBody) StaticallyValid() error {
+	if b.Markdown == "" {
+		return errors.New("markdown must not be empty")
+	}
+	if b.HeadingLevel == 0 {
+		b.HeadingLevel = 1
+	}
+	return nil
+}
+
+// ImportResponse is a ruleset created by ImportRuleset, with its sections.
+type ImportResponse struct {
+	*model.RulesetSnapshot
+	Skipped []string `json:"skipped"`
+}
+
+// PreviewImport parses a Markdown document into the sections ImportRuleset
+// would create, marking those identical to an existing section of the
+// caller's (which would be reused) without creating anything.
+type PreviewImport struct{}
+
+func (c PreviewImport) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, BaseRoute + "/import/preview"
+}
+
+func (c PreviewImport) RequestBody() (*ImportBody, bool) {
+	return &ImportBody{}, true
+}
+
+func (c PreviewImport) Handler(req api.Request[*ImportBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required to import a ruleset")
+	}
+	imp, err := model.ParseRulesetMarkdown(req.Body.Name, req.Body.Markdown, req.Body.HeadingLevel)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if err := imp.FindExisting(req.Context, req.DatabaseProvider, req.Token.UserId); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: imp}, http.StatusOK, nil
+}
+
+// ImportRuleset creates a new ruleset owned by the caller from a Markdown
+// document, split into sections as PreviewImport shows. Sections identical to
+// existing ones of the caller's are reused rather than duplicated.
+type ImportRuleset struct{}
+
+func (c ImportRuleset) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, BaseRoute + "/import"
+}
+
+func (c ImportRuleset) RequestBody() (*ImportBody, bool) {
+	return &ImportBody{}, true
+}
+
+func (c ImportRuleset) Handler(req api.Request[*ImportBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required to import a ruleset")
+	}
+	imp, err := model.ParseRulesetMarkdown(req.Body.Name, req.Body.Markdown, req.Body.HeadingLevel)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	ruleset, err := model.ImportRuleset(req.Context, req.DatabaseProvider, req.Token.UserId, imp)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	snapshot, err := ruleset.Snapshot(req.Context, req.DatabaseProvider)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: &ImportResponse{RulesetSnapshot: snapshot, Skipped: imp.Skipped}}, http.StatusCreated, nil
+}
@@ -0,0 +1,76 @@
+// Package search exposes full-text search over rule sections, blurbs and
+// comments (see model.Search).
+package search
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"intraclub/api"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BaseRoute is the API route for full-text search.
+var BaseRoute = "/search"
+
+// DefaultLimit and MaxLimit bound the number of results returned by Search.
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// SearchQuery holds the query parameters for Search.
+type SearchQuery struct {
+	Q     string `json:"q"`
+	Limit int    `json:"limit"`
+}
+
+// StaticallyValid has no static constraints (the query is read in the handler).
+func (q *SearchQuery) StaticallyValid() error {
+	return nil
+}
+
+// Search returns the rule sections, blurbs and comments containing every word
+// of the q query parameter, best match first, leaving out any the caller
+// cannot access. limit caps the number of results (default DefaultLimit, at
+// most MaxLimit).
+//
+//	GET /search?q=&limit=
+type Search struct{}
+
+func (s Search) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, BaseRoute
+}
+
+func (s Search) RequestBody() (*SearchQuery, bool) {
+	return &SearchQuery{}, false
+}
+
+func (s Search) Handler(req api.Request[*SearchQuery]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("authentication required")
+	}
+	query := req.HTTPRequest().URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		return nil, http.StatusBadRequest, errors.New("q must be set")
+	}
+	limit := DefaultLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, http.StatusBadRequest, errors.New("limit must be a positive integer")
+		}
+		limit = min(n, MaxLimit)
+	}
+
+	results, err := model.Search(req.Context, req.DatabaseProvider, req.Token.UserId, q, limit)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: results}, http.StatusOK, nil
+}
@@ -0,0 +1,159 @@
+// Package season exposes the ruleset a Season is played under, which the
+// read-only generic season CRUD in main.go doesn't cover.
+//
+//   - GetSeasonRuleset: the ruleset revision currently in force for the
+//     season, for the season page.
+//   - AssignSeasonRuleset: lets a commissioner choose the ruleset before the
+//     season starts. Once it starts the revision is pinned (see RunPinner) and
+//     only an accepted commissioner proposal can move it on.
+package season
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BaseRoute is the base API route for Season records. It matches the model's
+// Type() ("season").
+var BaseRoute = "/season"
+
+// RegisterRoutes wires the season ruleset routes onto the given router group.
+func RegisterRoutes(e *gin.RouterGroup, db database.Provider) {
+	getFamily := api.RouteFamily[*EmptyBody]{DatabaseProvider: db}
+	getFamily.Handle(e, GetSeasonRuleset{})
+
+	assignFamily := api.RouteFamily[*AssignRulesetBody]{DatabaseProvider: db}
+	assignFamily.Handle(e, AssignSeasonRuleset{})
+}
+
+// EmptyBody is used by GetSeasonRuleset, which accepts no request body.
+type EmptyBody struct{}
+
+func (b *EmptyBody) StaticallyValid() error {
+	return nil
+}
+
+// AssignRulesetBody is the request body for AssignSeasonRuleset. An empty
+// ruleset clears the assignment.
+type AssignRulesetBody struct {
+	RulesetId model.RulesetId `json:"ruleset_id"`
+}
+
+func (b *AssignRulesetBody) StaticallyValid() error {
+	return nil
+}
+
+// SeasonRuleset is the ruleset a season is played under: the revision in
+// force (with its sections), or nil if none is assigned, and when it was
+// pinned (zero until the season starts).
+type SeasonRuleset struct {
+	Season   model.SeasonId         `json:"season"`
+	PinnedAt time.Time              `json:"pinned_at"`
+	InForce  *model.RulesetSnapshot `json:"in_force"`
+}
+
+func buildSeasonRuleset(ctx context.Context, db database.Provider, season *model.Season) (*SeasonRuleset, int, error) {
+	ruleset, err := season.RulesetInForce(ctx, db)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	out := &SeasonRuleset{Season: season.ID, PinnedAt: season.RulesetPinnedAt}
+	if ruleset != nil {
+		out.InForce, err = ruleset.Snapshot(ctx, db)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+	return out, http.StatusOK, nil
+}
+
+// GetSeasonRuleset returns the ruleset revision currently in force for the
+// season. It is viewable by everyone and never pins; a season that has just
+// started shows the revision RunPinner will pin.
+type GetSeasonRuleset struct{}
+
+func (c GetSeasonRuleset) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, api.AppendPathId(BaseRoute) + "/ruleset"
+}
+
+func (c GetSeasonRuleset) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c GetSeasonRuleset) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	season, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Season{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	out, status, err := buildSeasonRuleset(req.Context, req.DatabaseProvider, season)
+	if err != nil {
+		return nil, status, err
+	}
+	return gin.H{api.ResourceKey: out}, http.StatusOK, nil
+}
+
+// AssignSeasonRuleset sets the ruleset the season is played under. Only a
+// commissioner of the season may assign it, and only before the season
+// starts; afterwards it is refused with 409 Conflict.
+type AssignSeasonRuleset struct{}
+
+func (c AssignSeasonRuleset) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPut, api.AppendPathId(BaseRoute) + "/ruleset"
+}
+
+func (c AssignSeasonRuleset) RequestBody() (*AssignRulesetBody, bool) {
+	return &AssignRulesetBody{}, true
+}
+
+func (c AssignSeasonRuleset) Handler(req api.Request[*AssignRulesetBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	season, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Season{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	wac := database.NewWithAccessControl[*model.Season](req.Context, req.DatabaseProvider, req.Token.UserId)
+	if !wac.CanUserEdit(season) {
+		return nil, http.StatusForbidden, errors.New("only a season commissioner may assign its ruleset")
+	}
+
+	err = season.AssignRuleset(req.Context, req.DatabaseProvider, req.Body.RulesetId, time.Now())
+	if errors.Is(err, model.ErrSeasonRulesetPinned) {
+		return nil, http.StatusConflict, err
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	out, status, err := buildSeasonRuleset(req.Context, req.DatabaseProvider, season)
+	if err != nil {
+		return nil, status, err
+	}
+	return gin.H{api.ResourceKey: out}, http.StatusOK, nil
+}
+
+// RunPinner pins the ruleset revision of each season as it starts, checking
+// every interval until ctx is done. It is the only place seasons are pinned.
+func RunPinner(ctx context.Context, db database.Provider, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := model.PinStartedSeasonRulesets(ctx, db, now); err != nil {
+				log.Printf("failed to pin season rulesets: %s", err)
+			}
+		}
+	}
+}
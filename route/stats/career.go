@@ -0,0 +1,44 @@
+package stats
+
+import (
+	"net/http"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CareerStatsQuery is the (empty) request body for GetCareerStats; the user
+// is taken from the request path.
+type CareerStatsQuery struct{}
+
+// StaticallyValid has no static constraints.
+func (q *CareerStatsQuery) StaticallyValid() error { return nil }
+
+// GetCareerStats reports a player's history across every season: their
+// record overall and by format line, partner and season, set and game totals,
+// streaks and attendance rate. Results are cached until a match result or
+// the player's availability changes.
+type GetCareerStats struct{}
+
+func (c GetCareerStats) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, api.AppendPathId(BaseRoute + "/players")
+}
+
+func (c GetCareerStats) RequestBody() (*CareerStatsQuery, bool) {
+	return &CareerStatsQuery{}, false
+}
+
+func (c GetCareerStats) Handler(req api.Request[*CareerStatsQuery]) (any, int, error) {
+	user, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.User{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	stats, err := model.GetCareerStats(req.Context, req.DatabaseProvider, user.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: stats}, http.StatusOK, nil
+}
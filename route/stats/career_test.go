@@ -0,0 +1,79 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// careerStats mirrors the wire shape returned by GET /stats/players/:id.
+type careerStats struct {
+	UserId    string `json:"user_id"`
+	Matches   int    `json:"matches"`
+	Wins      int    `json:"wins"`
+	GamesWon  int    `json:"games_won"`
+	GamesLost int    `json:"games_lost"`
+	ByLine    []struct {
+		Line    int `json:"line"`
+		Matches int `json:"matches"`
+	} `json:"by_line"`
+	ByPartner []struct {
+		PartnerId string `json:"partner_id"`
+		Wins      int    `json:"wins"`
+	} `json:"by_partner"`
+	BySeason []struct {
+		SeasonId string `json:"season_id"`
+		Matches  int    `json:"matches"`
+	} `json:"by_season"`
+	CurrentStreak  int     `json:"current_streak"`
+	WeeksAvailable int     `json:"weeks_available"`
+	AttendanceRate float64 `json:"attendance_rate"`
+}
+
+func getCareerStats(t *testing.T, router *gin.Engine, userId database.UserId) *careerStats {
+	t.Helper()
+	w := doRequest(t, router, http.MethodGet, "/api/stats/players/"+userId.String(), "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var body struct {
+		Resource *careerStats `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Resource
+}
+
+func TestCareerStats(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	fx := newPartnersFixture(t, db)
+	router := newTestRouter(t, db)
+
+	stats := getCareerStats(t, router, fx.homeCaptain)
+	require.Equal(t, fx.homeCaptain.String(), stats.UserId)
+	require.Equal(t, 1, stats.Matches)
+	require.Equal(t, 1, stats.Wins)
+	require.Equal(t, 14, stats.GamesWon)
+	require.Equal(t, 10, stats.GamesLost)
+	require.Len(t, stats.ByLine, 1)
+	require.Len(t, stats.ByPartner, 1)
+	require.Equal(t, fx.homeMember.String(), stats.ByPartner[0].PartnerId)
+	require.Len(t, stats.BySeason, 1)
+	require.Equal(t, fx.season.ID.String(), stats.BySeason[0].SeasonId)
+	require.Equal(t, 1, stats.CurrentStreak)
+	require.Zero(t, stats.AttendanceRate)
+
+	// marking availability is reflected straight away despite the cache
+	_, err := database.CreateOne(context.Background(), db, &model.Availability{UserId: fx.homeCaptain, WeekId: fx.week.ID, Available: model.AvailabilityAvailable})
+	require.NoError(t, err)
+	stats = getCareerStats(t, router, fx.homeCaptain)
+	require.Equal(t, 1, stats.WeeksAvailable)
+	require.Equal(t, 1.0, stats.AttendanceRate)
+
+	w := doRequest(t, router, http.MethodGet, "/api/stats/players/00000000000004d2", "")
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"net/http"
+	"net/url"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BaseRoute is the base path for the Stats REST surface.
+const BaseRoute = "/stats"
+
+// PartnerStatsQuery holds the (optional) query parameters for GetPartnerStats.
+type PartnerStatsQuery struct {
+	SeasonId model.SeasonId  `json:"season_id"`
+	TeamId   model.TeamId    `json:"team_id"`
+	UserId   database.UserId `json:"user_id"`
+}
+
+// StaticallyValid has no static constraints; every filter is optional.
+func (q *PartnerStatsQuery) StaticallyValid() error { return nil }
+
+// GetPartnerStats reports, for every pair of players who have completed a
+// match together, their record, set and game differentials and the lines they
+// played. Pairs are keyed by the two users, so history follows them across
+// teams and seasons unless filtered by season_id / team_id.
+type GetPartnerStats struct{}
+
+func (c GetPartnerStats) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, BaseRoute + "/partners"
+}
+
+func (c GetPartnerStats) RequestBody() (*PartnerStatsQuery, bool) {
+	return &PartnerStatsQuery{}, false
+}
+
+func (c GetPartnerStats) Handler(req api.Request[*PartnerStatsQuery]) (any, int, error) {
+	query := req.HTTPRequest().URL.Query()
+	filter := model.PartnerStatsFilter{}
+
+	seasonId, err := optionalRecordId(query, "season_id")
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if seasonId != database.InvalidRecordId {
+		season, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Season{}, seasonId)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		filter.SeasonId = season.ID
+	}
+
+	teamId, err := optionalRecordId(query, "team_id")
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if teamId != database.InvalidRecordId {
+		team, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Team{}, teamId)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		filter.TeamId = team.ID
+	}
+
+	userId, err := optionalRecordId(query, "user_id")
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	filter.UserId = database.UserId(userId)
+
+	stats, err := model.GetPartnerStats(req.Context, req.DatabaseProvider, filter)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: stats}, http.StatusOK, nil
+}
+
+// optionalRecordId parses the named query parameter as a RecordId, returning
+// InvalidRecordId if it is absent.
+func optionalRecordId(query url.Values, name string) (database.RecordId, error) {
+	value := query.Get(name)
+	if value == "" {
+		return database.InvalidRecordId, nil
+	}
+	return database.RecordIdFromString(value)
+}
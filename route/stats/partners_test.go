@@ -0,0 +1,256 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// test setup helpers (mirror route/match)
+// ---------------------------------------------------------------------------
+
+func newStoredUser(t *testing.T, db database.Provider) *model.User {
+	t.Helper()
+	user := model.NewUser()
+	user.Email = model.EmailAddress(fmt.Sprintf("user%d@email.com", rand.Uint64()))
+	user.FirstName = fmt.Sprintf("Test %d", rand.Uint64())
+	user.LastName = "User"
+	user.PhoneNumber = model.PhoneNumber(fmt.Sprintf("%d", 100_000_0000+rand.Uint32N(999_999_999)))
+	v, err := database.CreateOne(context.Background(), db, user)
+	require.NoError(t, err)
+	return v
+}
+
+func newStoredRating(t *testing.T, db database.Provider, owner database.UserId) model.RatingId {
+	t.Helper()
+	r := model.NewRating()
+	r.UserId = owner
+	r.Name = fmt.Sprintf("rating %d", rand.Uint64())
+	r.Description = "test rating"
+	v, err := database.CreateOne(context.Background(), db, r)
+	require.NoError(t, err)
+	return v.ID
+}
+
+// newScoringStructure builds a simple set-scoring structure (win at 6 games,
+// by 2).
+func newScoringStructure(t *testing.T, db database.Provider, owner database.UserId) *model.ScoringStructure {
+	t.Helper()
+	s := model.NewScoringStructure()
+	s.Owner = owner
+	s.Name = fmt.Sprintf("scoring %d", rand.Uint64())
+	s.WinConditionCountingType = model.Game
+	s.WinCondition = model.WinCondition{WinThreshold: 6, MustWinBy: 2, InstantWinThreshold: 7}
+	v, err := database.CreateOne(context.Background(), db, s)
+	require.NoError(t, err)
+	return v
+}
+
+// newRatedTeam creates a team with a captain (rating1) and a member (rating2)
+// and assigns it to the season.
+func newRatedTeam(t *testing.T, db database.Provider, season *model.Season, rating1, rating2 model.RatingId, name string) (*model.Team, database.UserId, database.UserId) {
+	t.Helper()
+	ctx := context.Background()
+	captain := newStoredUser(t, db)
+	member := newStoredUser(t, db)
+	teamV, err := database.CreateOne(ctx, db, model.NewDefaultTeam(captain.ID, name))
+	require.NoError(t, err)
+	require.NoError(t, season.AddTeam(ctx, db, teamV.ID))
+	_, err = database.CreateOne(ctx, db, &model.TeamAssignment{TeamId: teamV.ID, UserId: member.ID, Role: model.TeamRoleMember})
+	require.NoError(t, err)
+	_, err = database.CreateOne(ctx, db, &model.TeamRating{TeamId: teamV.ID, UserId: captain.ID, RatingId: rating1})
+	require.NoError(t, err)
+	_, err = database.CreateOne(ctx, db, &model.TeamRating{TeamId: teamV.ID, UserId: member.ID, RatingId: rating2})
+	require.NoError(t, err)
+	return teamV, captain.ID, member.ID
+}
+
+// partnersFixture is a season with one completed team match: the home
+// captain/member pairing beat the away pairing 2 sets to 1, 14 games to 10.
+type partnersFixture struct {
+	season      *model.Season
+	draft       *model.Draft
+	week        *model.Week
+	homeTeam    *model.Team
+	awayTeam    *model.Team
+	homeCaptain database.UserId
+	homeMember  database.UserId
+	awayCaptain database.UserId
+}
+
+func newPartnersFixture(t *testing.T, db database.Provider) *partnersFixture {
+	t.Helper()
+	ctx := context.Background()
+	commissioner := newStoredUser(t, db)
+
+	rating1 := newStoredRating(t, db, commissioner.ID)
+	rating2 := newStoredRating(t, db, commissioner.ID)
+	format := model.NewFormat()
+	format.UserId = commissioner.ID
+	format.Name = fmt.Sprintf("format %d", rand.Uint64())
+	formatV, err := database.CreateOne(ctx, db, format)
+	require.NoError(t, err)
+	require.NoError(t, formatV.SetPossibleRatings(ctx, db, model.RatingList{rating1, rating2}))
+	require.NoError(t, formatV.SetLines(ctx, db, []model.FormatLine{{Player1Rating: rating1, Player2Rating: rating2}}))
+
+	draft := model.NewDraft()
+	draft.Owner = commissioner.ID
+	draft.Format = formatV.ID
+	draftV, err := database.CreateOne(ctx, db, draft)
+	require.NoError(t, err)
+
+	facility := model.NewFacility()
+	facility.UserId = commissioner.ID
+	facility.Name = "Test facility"
+	facility.Address = "Test Rd."
+	facility.NumberOfCourts = 2
+	facilityV, err := database.CreateOne(ctx, db, facility)
+	require.NoError(t, err)
+
+	season := model.NewSeason()
+	season.Name = "Test Season"
+	season.StartTime = model.NewStartTime(8, 30)
+	season.DraftId = draftV.ID
+	season.Facility = facilityV.ID
+	seasonV, err := database.CreateOne(ctx, db, season)
+	require.NoError(t, err)
+	require.NoError(t, seasonV.AddCommissioner(ctx, db, commissioner.ID))
+
+	homeTeam, homeCaptain, homeMember := newRatedTeam(t, db, seasonV, rating1, rating2, "Home Team")
+	awayTeam, awayCaptain, awayMember := newRatedTeam(t, db, seasonV, rating1, rating2, "Away Team")
+
+	week := model.NewWeek()
+	week.DraftId = draftV.ID
+	week.Date = time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	weekV, err := database.CreateOne(ctx, db, week)
+	require.NoError(t, err)
+
+	pairings := make([]*model.LineupPairing, 0, 2)
+	lineups := make([]model.LineupId, 0, 2)
+	for _, side := range []struct {
+		team             model.TeamId
+		player1, player2 database.UserId
+	}{{homeTeam.ID, homeCaptain, homeMember}, {awayTeam.ID, awayCaptain, awayMember}} {
+		lineup, err := database.CreateOne(ctx, db, &model.Lineup{TeamId: side.team, WeekId: weekV.ID, Confirmed: true, Official: true})
+		require.NoError(t, err)
+		pairing, err := database.CreateOne(ctx, db, &model.LineupPairing{LineupId: lineup.ID, TeamId: side.team, Player1: side.player1, Player2: side.player2})
+		require.NoError(t, err)
+		lineups = append(lineups, lineup.ID)
+		pairings = append(pairings, pairing)
+	}
+	tm, err := database.CreateOne(ctx, db, &model.TeamMatch{WeekId: weekV.ID, HomeTeam: homeTeam.ID, AwayTeam: awayTeam.ID, Lineup: lineups[0]})
+	require.NoError(t, err)
+
+	scoring := newScoringStructure(t, db, commissioner.ID)
+	homeMatch, err := database.CreateOne(ctx, db, &model.IndividualMatch{Structure: scoring.ID, MainValue: 2, SecondaryValue: 14, Status: model.MatchWon})
+	require.NoError(t, err)
+	awayMatch, err := database.CreateOne(ctx, db, &model.IndividualMatch{Structure: scoring.ID, Opponent: homeMatch.ID, MainValue: 1, SecondaryValue: 10, Status: model.MatchLost})
+	require.NoError(t, err)
+	homeMatch.Opponent = awayMatch.ID
+	require.NoError(t, database.UpdateOne(ctx, db, homeMatch))
+	for i, match := range []*model.IndividualMatch{homeMatch, awayMatch} {
+		_, err := database.CreateOne(ctx, db, &model.TeamMatchIndividualMatch{TeamMatchId: tm.ID, LineupPairingId: pairings[i].ID, IndividualMatchId: match.ID})
+		require.NoError(t, err)
+	}
+
+	return &partnersFixture{
+		season:      seasonV,
+		draft:       draftV,
+		week:        weekV,
+		homeTeam:    homeTeam,
+		awayTeam:    awayTeam,
+		homeCaptain: homeCaptain,
+		homeMember:  homeMember,
+		awayCaptain: awayCaptain,
+	}
+}
+
+func newTestRouter(t *testing.T, db database.Provider) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	api.UserType = &model.User{}
+	database.SysAdminCheck = model.IsUserSystemAdministrator
+
+	router := gin.New()
+	group := router.Group("/api")
+	RegisterRoutes(group, db)
+	return router
+}
+
+// partnerStats mirrors the wire shape returned by GET /stats/partners.
+type partnerStats struct {
+	Player1          string `json:"player1"`
+	Player2          string `json:"player2"`
+	Matches          int    `json:"matches"`
+	Wins             int    `json:"wins"`
+	Losses           int    `json:"losses"`
+	SetDifferential  int    `json:"set_differential"`
+	GameDifferential int    `json:"game_differential"`
+	Lines            []int  `json:"lines"`
+}
+
+func getPartnerStats(t *testing.T, router *gin.Engine, query string) []*partnerStats {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, "/api/stats/partners"+query, nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var body struct {
+		Resource []*partnerStats `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Resource
+}
+
+// ---------------------------------------------------------------------------
+// tests
+// ---------------------------------------------------------------------------
+
+func TestPartnerStats(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	fx := newPartnersFixture(t, db)
+	router := newTestRouter(t, db)
+
+	all := getPartnerStats(t, router, "")
+	require.Len(t, all, 2)
+
+	home := getPartnerStats(t, router, "?season_id="+fx.season.ID.String()+"&team_id="+fx.homeTeam.ID.String())
+	require.Len(t, home, 1)
+	require.Equal(t, 1, home[0].Matches)
+	require.Equal(t, 1, home[0].Wins)
+	require.Equal(t, 1, home[0].SetDifferential)
+	require.Equal(t, 4, home[0].GameDifferential)
+	require.Equal(t, []int{0}, home[0].Lines)
+	require.ElementsMatch(t, []string{fx.homeCaptain.String(), fx.homeMember.String()}, []string{home[0].Player1, home[0].Player2})
+
+	away := getPartnerStats(t, router, "?user_id="+fx.awayCaptain.String())
+	require.Len(t, away, 1)
+	require.Equal(t, 1, away[0].Losses)
+	require.Equal(t, -4, away[0].GameDifferential)
+}
+
+func TestPartnerStatsUnknownSeason(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	newPartnersFixture(t, db)
+	router := newTestRouter(t, db)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/stats/partners?season_id=00000000000004d2", nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
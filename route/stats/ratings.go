@@ -0,0 +1,132 @@
+package stats
+
+import (
+	"errors"
+	"net/http"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PlayerRatingsQuery holds the query parameters for the player rating routes.
+type PlayerRatingsQuery struct {
+	UserId  database.UserId `json:"user_id"`
+	DraftId model.DraftId   `json:"draft_id"`
+}
+
+// StaticallyValid has no static constraints; the parameters are checked by
+// each route.
+func (q *PlayerRatingsQuery) StaticallyValid() error { return nil }
+
+// GetPlayerRatings reports dynamic player ratings. With user_id it returns the
+// user's rating history, one change per rated match in the order they were
+// applied; otherwise it returns every rated player's current rating, highest
+// first. The rating history is brought up to date with any newly completed
+// matches first.
+type GetPlayerRatings struct{}
+
+func (c GetPlayerRatings) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, BaseRoute + "/ratings"
+}
+
+func (c GetPlayerRatings) RequestBody() (*PlayerRatingsQuery, bool) {
+	return &PlayerRatingsQuery{}, false
+}
+
+func (c GetPlayerRatings) Handler(req api.Request[*PlayerRatingsQuery]) (any, int, error) {
+	userId, err := optionalRecordId(req.HTTPRequest().URL.Query(), "user_id")
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if err := model.UpdatePlayerRatings(req.Context, req.DatabaseProvider); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if userId == database.InvalidRecordId {
+		ratings, err := model.GetPlayerRatings(req.Context, req.DatabaseProvider)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		return gin.H{api.ResourceKey: ratings}, http.StatusOK, nil
+	}
+	user, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.User{}, userId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	history, err := model.GetPlayerRatingHistory(req.Context, req.DatabaseProvider, user.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: history}, http.StatusOK, nil
+}
+
+// SuggestPlayerRatings suggests a Rating tier for each of the draft's
+// available players from their dynamic ratings, to help a commissioner rate
+// players for the next draft.
+type SuggestPlayerRatings struct{}
+
+func (c SuggestPlayerRatings) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, BaseRoute + "/ratings/suggest"
+}
+
+func (c SuggestPlayerRatings) RequestBody() (*PlayerRatingsQuery, bool) {
+	return &PlayerRatingsQuery{}, false
+}
+
+func (c SuggestPlayerRatings) Handler(req api.Request[*PlayerRatingsQuery]) (any, int, error) {
+	draftId, err := optionalRecordId(req.HTTPRequest().URL.Query(), "draft_id")
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if draftId == database.InvalidRecordId {
+		return nil, http.StatusBadRequest, errors.New("draft_id must be set")
+	}
+	draft, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Draft{}, draftId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if err := model.UpdatePlayerRatings(req.Context, req.DatabaseProvider); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	suggestions, err := model.SuggestRatings(req.Context, req.DatabaseProvider, draft)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: suggestions}, http.StatusOK, nil
+}
+
+// RebuildPlayerRatings discards the rating history and replays every rated
+// match from scratch, returning the resulting current ratings. Only a system
+// administrator may rebuild the ratings.
+type RebuildPlayerRatings struct{}
+
+func (c RebuildPlayerRatings) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, BaseRoute + "/ratings/rebuild"
+}
+
+func (c RebuildPlayerRatings) RequestBody() (*PlayerRatingsQuery, bool) {
+	return &PlayerRatingsQuery{}, false
+}
+
+func (c RebuildPlayerRatings) Handler(req api.Request[*PlayerRatingsQuery]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	isSysAdmin, err := database.SysAdminCheck(req.Context, req.DatabaseProvider, req.Token.UserId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if !isSysAdmin {
+		return nil, http.StatusForbidden, errors.New("only a system administrator may rebuild player ratings")
+	}
+	if err := model.RebuildPlayerRatings(req.Context, req.DatabaseProvider); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	ratings, err := model.GetPlayerRatings(req.Context, req.DatabaseProvider)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return gin.H{api.ResourceKey: ratings}, http.StatusOK, nil
+}
@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testKeyOnce sync.Once
+	testPubKey  *ecdsa.PublicKey
+	testPrivKey *ecdsa.PrivateKey
+)
+
+// newToken returns a signed JWT for the given user ID without touching key
+// files on disk.
+func newToken(t *testing.T, userId database.UserId) string {
+	t.Helper()
+	testKeyOnce.Do(func() {
+		pub, priv, err := api.GenerateKeyPair()
+		require.NoError(t, err)
+		testPubKey = pub
+		testPrivKey = priv
+	})
+	api.JwtPublicKey = testPubKey
+	api.JwtPrivateKey = testPrivKey
+	token, err := api.GenerateToken(userId.RecordId())
+	require.NoError(t, err)
+	return token
+}
+
+func doRequest(t *testing.T, router *gin.Engine, method, path, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req, err := http.NewRequest(method, path, nil)
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set(api.AuthTokenHeaderValue, token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// playerRating mirrors the wire shape of the rating routes, covering current
+// ratings, rating changes and suggestions.
+type playerRating struct {
+	UserId   string  `json:"user_id"`
+	Rating   float64 `json:"rating"`
+	Matches  int     `json:"matches"`
+	Sequence int     `json:"sequence"`
+	Won      bool    `json:"won"`
+	Before   float64 `json:"before"`
+	After    float64 `json:"after"`
+	RatingId string  `json:"rating_id"`
+}
+
+func decodeRatings(t *testing.T, w *httptest.ResponseRecorder) []*playerRating {
+	t.Helper()
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var body struct {
+		Resource []*playerRating `json:"resource"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Resource
+}
+
+func TestPlayerRatingRoutes(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	fx := newPartnersFixture(t, db)
+	router := newTestRouter(t, db)
+
+	// reading the ratings brings them up to date with the completed match
+	ratings := decodeRatings(t, doRequest(t, router, http.MethodGet, "/api/stats/ratings", ""))
+	require.Len(t, ratings, 4)
+	require.Equal(t, 1, ratings[0].Matches)
+	require.Greater(t, ratings[0].Rating, float64(model.InitialPlayerRating))
+
+	history := decodeRatings(t, doRequest(t, router, http.MethodGet, "/api/stats/ratings?user_id="+fx.awayCaptain.String(), ""))
+	require.Len(t, history, 1)
+	require.Equal(t, 1, history[0].Sequence)
+	require.False(t, history[0].Won)
+	require.Equal(t, float64(model.InitialPlayerRating), history[0].Before)
+	require.Less(t, history[0].After, history[0].Before)
+
+	w := doRequest(t, router, http.MethodGet, "/api/stats/ratings?user_id=00000000000004d2", "")
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// every available player in the draft gets a suggested tier
+	for _, player := range []database.UserId{fx.homeCaptain, fx.awayCaptain} {
+		_, err := database.CreateOne(context.Background(), db, &model.DraftAvailablePlayer{DraftId: fx.draft.ID, PlayerId: player})
+		require.NoError(t, err)
+	}
+	suggestions := decodeRatings(t, doRequest(t, router, http.MethodGet, "/api/stats/ratings/suggest?draft_id="+fx.draft.ID.String(), ""))
+	require.Len(t, suggestions, 2)
+	require.Equal(t, fx.homeCaptain.String(), suggestions[0].UserId)
+	require.NotEqual(t, suggestions[0].RatingId, suggestions[1].RatingId)
+
+	w = doRequest(t, router, http.MethodGet, "/api/stats/ratings/suggest", "")
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// only a system administrator may rebuild the ratings
+	w = doRequest(t, router, http.MethodPost, "/api/stats/ratings/rebuild", "")
+	require.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	w = doRequest(t, router, http.MethodPost, "/api/stats/ratings/rebuild", newToken(t, fx.homeCaptain))
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	admin := newStoredUser(t, db)
+	_, err := database.CreateOne(context.Background(), db, &model.UserRoleAssignment{UserId: admin.ID, Role: model.SystemAdministrator})
+	require.NoError(t, err)
+	rebuilt := decodeRatings(t, doRequest(t, router, http.MethodPost, "/api/stats/ratings/rebuild", newToken(t, admin.ID)))
+	require.Equal(t, ratings, rebuilt)
+}
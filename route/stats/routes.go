@@ -0,0 +1,35 @@
+package stats
+
+import (
+	"intraclub/api"
+	"intraclub/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes wires up the Stats REST surface.
+//
+// Statistics are derived on request from completed individual matches, walked
+// through the team match / lineup pairing join table. A player's career stats
+// are cached in memory until a match result or their availability changes.
+// The only stats records are the dynamic player rating changes, which are
+// themselves derived from match results: they are brought up to date whenever
+// ratings are read, and can be rebuilt from scratch at any time. Everything
+// here is viewable by everyone, like the season standings.
+//
+//	GET  /stats/partners?season_id=&team_id=&user_id= -> []PartnerStats (all filters optional)
+//	GET  /stats/players/:id                           -> CareerStats (record by line / partner / season, streaks, attendance)
+//	GET  /stats/ratings                               -> []PlayerRating (current ratings, highest first)
+//	GET  /stats/ratings?user_id=                      -> []PlayerRatingChange (the user's rating history)
+//	GET  /stats/ratings/suggest?draft_id=             -> []SuggestedRating (a Rating tier per available player)
+//	POST /stats/ratings/rebuild                       -> []PlayerRating (sysadmin only)
+func RegisterRoutes(e *gin.RouterGroup, db database.Provider) {
+	partners := api.RouteFamily[*PartnerStatsQuery]{DatabaseProvider: db}
+	partners.Handle(e, GetPartnerStats{})
+
+	career := api.RouteFamily[*CareerStatsQuery]{DatabaseProvider: db}
+	career.Handle(e, GetCareerStats{})
+
+	ratings := api.RouteFamily[*PlayerRatingsQuery]{DatabaseProvider: db}
+	ratings.Handle(e, GetPlayerRatings{}, SuggestPlayerRatings{}, RebuildPlayerRatings{})
+}
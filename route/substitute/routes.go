@@ -0,0 +1,33 @@
+package substitute
+
+import (
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes wires up the Substitute and SubRequest REST surface.
+//
+// Generic CRUD (no update) is registered for both models: a user opts in to
+// (or out of) the substitute pool for a week by creating (or deleting) their
+// own Substitute record, and a team's captain/co-captains post (or withdraw) a
+// SubRequest for a format line and rating. Eligibility is enforced by each
+// model's DynamicallyValid. Custom routes drive the claim/approve flow:
+//
+//	POST /sub_request/:id/claim    -> a substitute claims an open request
+//	POST /sub_request/:id/approve  -> the captain approves the claim
+//	POST /sub_request/:id/release  -> the claimant or captain re-opens it
+func RegisterRoutes(e *gin.RouterGroup, db database.Provider) {
+	substitutes := api.NewCrudCommon(model.NewSubstitute, false, db)
+	substitutes.HandleRouteTypes(e, api.CrudWrapperFunctionGetOne, api.CrudWrapperFunctionGetMany,
+		api.CrudWrapperFunctionCreate, api.CrudWrapperFunctionDelete)
+
+	requests := api.NewCrudCommon(model.NewSubRequest, false, db)
+	requests.HandleRouteTypes(e, api.CrudWrapperFunctionGetOne, api.CrudWrapperFunctionGetMany,
+		api.CrudWrapperFunctionCreate, api.CrudWrapperFunctionDelete)
+
+	flow := api.RouteFamily[*EmptyBody]{DatabaseProvider: db}
+	flow.Handle(e, ClaimSubRequest{}, ApproveSubRequest{}, ReleaseSubRequest{})
+}
@@ -0,0 +1,120 @@
+package substitute
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BaseRoute is the base path for the SubRequest claim/approve flow. It matches
+// the route derived from SubRequest.Type() by the generic CRUD routes.
+const BaseRoute = "/sub_request"
+
+// canManageSubRequest reports whether the requesting user is a captain or
+// co-captain of the team that posted the request.
+func canManageSubRequest(ctx context.Context, db database.Provider, userId database.UserId, teamId model.TeamId) bool {
+	for _, uid := range model.EditableByTeamCaptainOrCoCaptains(ctx, db, teamId) {
+		if uid == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// ClaimSubRequest assigns an open SubRequest to the requesting user. The user
+// must be in the substitute pool for the request's week, must not already be
+// on the requesting team, and must play at the requested rating.
+type ClaimSubRequest struct{}
+
+func (c ClaimSubRequest) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, api.AppendPathId(BaseRoute) + "/claim"
+}
+
+func (c ClaimSubRequest) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c ClaimSubRequest) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	request, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.SubRequest{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if err := request.Claim(req.Context, req.DatabaseProvider, req.Token.UserId); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return gin.H{api.ResourceKey: request}, http.StatusOK, nil
+}
+
+// ApproveSubRequest accepts the claimant of a SubRequest. Only the requesting
+// team's captain/co-captains may approve; once approved, the claimant may be
+// placed in the team's lineup for that week.
+type ApproveSubRequest struct{}
+
+func (c ApproveSubRequest) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, api.AppendPathId(BaseRoute) + "/approve"
+}
+
+func (c ApproveSubRequest) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c ApproveSubRequest) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	request, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.SubRequest{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if !canManageSubRequest(req.Context, req.DatabaseProvider, req.Token.UserId, request.TeamId) {
+		return nil, http.StatusForbidden, errors.New("only a team captain or co-captain may approve a sub request")
+	}
+	if err := request.Approve(req.Context, req.DatabaseProvider); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return gin.H{api.ResourceKey: request}, http.StatusOK, nil
+}
+
+// ReleaseSubRequest drops the claimant from a SubRequest and re-opens it. The
+// claimant may back out, or the team's captain/co-captains may reject them.
+type ReleaseSubRequest struct{}
+
+func (c ReleaseSubRequest) Path() (api.HttpMethod, string) {
+	return api.HttpMethodPost, api.AppendPathId(BaseRoute) + "/release"
+}
+
+func (c ReleaseSubRequest) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c ReleaseSubRequest) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	if req.Token == nil {
+		return nil, http.StatusUnauthorized, errors.New("token is required")
+	}
+	request, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.SubRequest{}, req.PathId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if request.ClaimedBy != req.Token.UserId && !canManageSubRequest(req.Context, req.DatabaseProvider, req.Token.UserId, request.TeamId) {
+		return nil, http.StatusForbidden, errors.New("only the claimant or a team captain or co-captain may release a sub request")
+	}
+	if err := request.Release(req.Context, req.DatabaseProvider); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return gin.H{api.ResourceKey: request}, http.StatusOK, nil
+}
+
+// EmptyBody is a placeholder request body for routes that take no body.
+type EmptyBody struct{}
+
+// StaticallyValid has no static constraints.
+func (b *EmptyBody) StaticallyValid() error { return nil }
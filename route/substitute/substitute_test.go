@@ -0,0 +1,303 @@
+package substitute
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// test setup helpers (mirror route/lineup)
+// ---------------------------------------------------------------------------
+
+func newStoredUser(t *testing.T, db database.Provider) *model.User {
+	t.Helper()
+	user := model.NewUser()
+	user.Email = model.EmailAddress(fmt.Sprintf("user%d@email.com", rand.Uint64()))
+	user.FirstName = fmt.Sprintf("Test %d", rand.Uint64())
+	user.LastName = "User"
+	user.PhoneNumber = model.PhoneNumber(fmt.Sprintf("%d", 100_000_0000+rand.Uint32N(999_999_999)))
+	v, err := database.CreateOne(context.Background(), db, user)
+	require.NoError(t, err)
+	return v
+}
+
+// substituteFixture builds a season with two teams and a single week, whose
+// format has a single line (rating1 / rating2). The requesting team's captain
+// carries rating2, so a substitute at rating1 completes the line. A late
+// addition to the season is the candidate substitute.
+type substituteFixture struct {
+	week     *model.Week
+	team     *model.Team
+	rating1  model.RatingId
+	captain  database.UserId
+	member   database.UserId
+	lateUser database.UserId
+	outsider database.UserId
+}
+
+func newSubstituteFixture(t *testing.T, db database.Provider) *substituteFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	commissioner := newStoredUser(t, db)
+
+	rating1 := newStoredRating(t, db, commissioner.ID)
+	rating2 := newStoredRating(t, db, commissioner.ID)
+	format := model.NewFormat()
+	format.UserId = commissioner.ID
+	format.Name = fmt.Sprintf("format %d", rand.Uint64())
+	formatV, err := database.CreateOne(ctx, db, format)
+	require.NoError(t, err)
+	require.NoError(t, formatV.SetPossibleRatings(ctx, db, model.RatingList{rating1, rating2}))
+	require.NoError(t, formatV.SetLines(ctx, db, []model.FormatLine{{Player1Rating: rating1, Player2Rating: rating2}}))
+
+	draft := model.NewDraft()
+	draft.Owner = commissioner.ID
+	draft.Format = formatV.ID
+	draftV, err := database.CreateOne(ctx, db, draft)
+	require.NoError(t, err)
+
+	facility := model.NewFacility()
+	facility.UserId = commissioner.ID
+	facility.Name = "Test facility"
+	facility.Address = "Test Rd."
+	facility.NumberOfCourts = 2
+	facilityV, err := database.CreateOne(ctx, db, facility)
+	require.NoError(t, err)
+
+	season := model.NewSeason()
+	season.Name = "Test Season"
+	season.StartTime = model.NewStartTime(8, 30)
+	season.DraftId = draftV.ID
+	season.Facility = facilityV.ID
+	seasonV, err := database.CreateOne(ctx, db, season)
+	require.NoError(t, err)
+	require.NoError(t, seasonV.AddCommissioner(ctx, db, commissioner.ID))
+
+	captain := newStoredUser(t, db)
+	member := newStoredUser(t, db)
+	team := model.NewDefaultTeam(captain.ID, "Team A")
+	teamV, err := database.CreateOne(ctx, db, team)
+	require.NoError(t, err)
+	require.NoError(t, seasonV.AddTeam(ctx, db, teamV.ID))
+	addAssignment(t, db, teamV, member, model.TeamRoleMember)
+	_, err = database.CreateOne(ctx, db, &model.TeamRating{TeamId: teamV.ID, UserId: captain.ID, RatingId: rating2})
+	require.NoError(t, err)
+
+	lateUser := newStoredUser(t, db)
+	require.NoError(t, seasonV.AddLateAddition(ctx, db, lateUser.ID))
+
+	week := model.NewWeek()
+	week.DraftId = draftV.ID
+	week.Date = time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	weekV, err := database.CreateOne(ctx, db, week)
+	require.NoError(t, err)
+
+	return &substituteFixture{
+		week:     weekV,
+		team:     teamV,
+		rating1:  rating1,
+		captain:  captain.ID,
+		member:   member.ID,
+		lateUser: lateUser.ID,
+		outsider: newStoredUser(t, db).ID,
+	}
+}
+
+func newStoredRating(t *testing.T, db database.Provider, owner database.UserId) model.RatingId {
+	t.Helper()
+	r := model.NewRating()
+	r.UserId = owner
+	r.Name = fmt.Sprintf("rating %d", rand.Uint64())
+	r.Description = "test rating"
+	v, err := database.CreateOne(context.Background(), db, r)
+	require.NoError(t, err)
+	return v.ID
+}
+
+func addAssignment(t *testing.T, db database.Provider, team *model.Team, user *model.User, role model.TeamRole) {
+	t.Helper()
+	_, err := database.CreateOne(context.Background(), db, &model.TeamAssignment{TeamId: team.ID, UserId: user.ID, Role: role})
+	require.NoError(t, err)
+}
+
+func newTestRouter(t *testing.T, db database.Provider) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	api.UserType = &model.User{}
+	database.SysAdminCheck = model.IsUserSystemAdministrator
+
+	router := gin.New()
+	group := router.Group("/api")
+	RegisterRoutes(group, db)
+	return router
+}
+
+var (
+	substituteKeyOnce sync.Once
+	substitutePubKey  *ecdsa.PublicKey
+	substitutePrivKey *ecdsa.PrivateKey
+)
+
+func newToken(t *testing.T, userId database.UserId) string {
+	t.Helper()
+	substituteKeyOnce.Do(func() {
+		pub, priv, err := api.GenerateKeyPair()
+		require.NoError(t, err)
+		substitutePubKey = pub
+		substitutePrivKey = priv
+	})
+	api.JwtPublicKey = substitutePubKey
+	api.JwtPrivateKey = substitutePrivKey
+	token, err := api.GenerateToken(userId.RecordId())
+	require.NoError(t, err)
+	return token
+}
+
+func doJSON(t *testing.T, router *gin.Engine, method, path string, body any, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, path, reader)
+	require.NoError(t, err)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set(api.AuthTokenHeaderValue, token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// ---------------------------------------------------------------------------
+// tests
+// ---------------------------------------------------------------------------
+
+type subRequestResponse struct {
+	Resource *model.SubRequest `json:"resource"`
+}
+
+func TestSubRequestClaimAndApprove(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	router := newTestRouter(t, db)
+	fx := newSubstituteFixture(t, db)
+
+	// The late addition opts in to the substitute pool for the week.
+	w := doJSON(t, router, http.MethodPost, "/api/substitute",
+		map[string]any{"week_id": fx.week.ID.String()}, newToken(t, fx.lateUser))
+	require.Equal(t, http.StatusOK, w.Code, "opt in: %s", w.Body.String())
+
+	// An outsider is not eligible for the pool.
+	w = doJSON(t, router, http.MethodPost, "/api/substitute",
+		map[string]any{"week_id": fx.week.ID.String()}, newToken(t, fx.outsider))
+	require.Equal(t, http.StatusBadRequest, w.Code, "outsider opt in: %s", w.Body.String())
+
+	// The captain posts a sub request for line 0 at rating1.
+	w = doJSON(t, router, http.MethodPost, "/api/sub_request",
+		map[string]any{
+			"week_id":           fx.week.ID.String(),
+			"team_id":           fx.team.ID.String(),
+			"format_line_index": 0,
+			"rating_id":         fx.rating1.String(),
+		}, newToken(t, fx.captain))
+	require.Equal(t, http.StatusOK, w.Code, "post request: %s", w.Body.String())
+	var created subRequestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.Equal(t, model.SubRequestOpen, created.Resource.Status)
+	requestPath := "/api/sub_request/" + created.Resource.ID.String()
+
+	// The outsider (not in the pool) cannot claim it.
+	w = doJSON(t, router, http.MethodPost, requestPath+"/claim", nil, newToken(t, fx.outsider))
+	require.Equal(t, http.StatusBadRequest, w.Code, "outsider claim: %s", w.Body.String())
+
+	w = doJSON(t, router, http.MethodPost, requestPath+"/claim", nil, newToken(t, fx.lateUser))
+	require.Equal(t, http.StatusOK, w.Code, "claim: %s", w.Body.String())
+	var claimed subRequestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &claimed))
+	require.Equal(t, model.SubRequestClaimed, claimed.Resource.Status)
+	require.Equal(t, fx.lateUser, claimed.Resource.ClaimedBy)
+
+	// Only a captain/co-captain may approve.
+	w = doJSON(t, router, http.MethodPost, requestPath+"/approve", nil, newToken(t, fx.member))
+	require.Equal(t, http.StatusForbidden, w.Code, "member approve: %s", w.Body.String())
+
+	w = doJSON(t, router, http.MethodPost, requestPath+"/approve", nil, newToken(t, fx.captain))
+	require.Equal(t, http.StatusOK, w.Code, "approve: %s", w.Body.String())
+	var approved subRequestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &approved))
+	require.Equal(t, model.SubRequestApproved, approved.Resource.Status)
+
+	// The approved substitute can now be placed in the team's lineup.
+	ctx := context.Background()
+	lineup, err := database.CreateOne(ctx, db, &model.Lineup{TeamId: fx.team.ID, WeekId: fx.week.ID})
+	require.NoError(t, err)
+	_, err = database.CreateOne(ctx, db, &model.LineupPairing{
+		LineupId: lineup.ID,
+		TeamId:   fx.team.ID,
+		Player1:  fx.lateUser,
+		Player2:  fx.captain,
+	})
+	require.NoError(t, err)
+}
+
+func TestSubRequestRelease(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	router := newTestRouter(t, db)
+	fx := newSubstituteFixture(t, db)
+	ctx := context.Background()
+
+	_, err := database.CreateOne(ctx, db, &model.Substitute{WeekId: fx.week.ID, UserId: fx.lateUser})
+	require.NoError(t, err)
+	request, err := database.CreateOne(ctx, db, &model.SubRequest{
+		WeekId:      fx.week.ID,
+		TeamId:      fx.team.ID,
+		RatingId:    fx.rating1,
+		RequestedBy: fx.captain,
+	})
+	require.NoError(t, err)
+	requestPath := "/api/sub_request/" + request.ID.String()
+
+	w := doJSON(t, router, http.MethodPost, requestPath+"/release", nil, newToken(t, fx.captain))
+	require.Equal(t, http.StatusBadRequest, w.Code, "release unclaimed: %s", w.Body.String())
+
+	w = doJSON(t, router, http.MethodPost, requestPath+"/claim", nil, newToken(t, fx.lateUser))
+	require.Equal(t, http.StatusOK, w.Code, "claim: %s", w.Body.String())
+
+	// An unrelated user cannot release the claim.
+	w = doJSON(t, router, http.MethodPost, requestPath+"/release", nil, newToken(t, fx.outsider))
+	require.Equal(t, http.StatusForbidden, w.Code, "outsider release: %s", w.Body.String())
+
+	// The claimant may back out, re-opening the request.
+	w = doJSON(t, router, http.MethodPost, requestPath+"/release", nil, newToken(t, fx.lateUser))
+	require.Equal(t, http.StatusOK, w.Code, "release: %s", w.Body.String())
+	var released subRequestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &released))
+	require.Equal(t, model.SubRequestOpen, released.Resource.Status)
+	require.Equal(t, database.InvalidUserId, released.Resource.ClaimedBy)
+
+	w = doJSON(t, router, http.MethodPost, requestPath+"/claim", nil, "")
+	require.Equal(t, http.StatusUnauthorized, w.Code, "no token: %s", w.Body.String())
+}
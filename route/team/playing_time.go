@@ -0,0 +1,82 @@
+package team
+
+import (
+	"errors"
+	"net/http"
+
+	"intraclub/api"
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// canViewFullPlayingTime reports whether the user may see every row of a
+// team's playing-time report: the team's captain / co-captains, a commissioner
+// of the season, or a sysadmin. Other team members see only their own row.
+func canViewFullPlayingTime[T database.Validatable](req api.Request[T], team *model.Team, season *model.Season, userId database.UserId) (bool, error) {
+	for _, uid := range team.EditableBy(req.Context, req.DatabaseProvider) {
+		if uid == userId {
+			return true, nil
+		}
+	}
+	if season.IsUserIdACommissionerViaDB(req.Context, req.DatabaseProvider, userId) {
+		return true, nil
+	}
+	return database.SysAdminCheck(req.Context, req.DatabaseProvider, userId)
+}
+
+// GetPlayingTime returns the playing-time fairness report for a team in a
+// season (GET /team/:id/playing_time?season_id=): for every member, how many
+// weeks they were available / maybe / unavailable, how many weeks they
+// appeared in an official lineup and which lines they played, flagging members
+// whose play rate falls well below their availability rate.
+type GetPlayingTime struct{}
+
+func (c GetPlayingTime) Path() (api.HttpMethod, string) {
+	return api.HttpMethodGet, api.AppendPathId(BaseRoute) + "/playing_time"
+}
+
+func (c GetPlayingTime) RequestBody() (*EmptyBody, bool) {
+	return &EmptyBody{}, false
+}
+
+func (c GetPlayingTime) Handler(req api.Request[*EmptyBody]) (any, int, error) {
+	team, status, err := loadAccessibleTeam(req)
+	if err != nil {
+		return nil, status, err
+	}
+
+	seasonStr := req.HTTPRequest().URL.Query().Get("season_id")
+	if seasonStr == "" {
+		return nil, http.StatusBadRequest, errors.New("season_id query parameter is required")
+	}
+	seasonId, err := database.RecordIdFromString(seasonStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	season, err := database.GetExistingRecordById(req.Context, req.DatabaseProvider, &model.Season{}, seasonId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	report, err := model.GetPlayingTimeReport(req.Context, req.DatabaseProvider, season, team)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	full, err := canViewFullPlayingTime(req, team, season, req.Token.UserId)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if !full {
+		own := make([]*model.PlayingTimeEntry, 0, 1)
+		for _, entry := range report.Entries {
+			if entry.UserId == req.Token.UserId {
+				own = append(own, entry)
+			}
+		}
+		report.Entries = own
+	}
+	return gin.H{api.ResourceKey: report}, http.StatusOK, nil
+}
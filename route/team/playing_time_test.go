@@ -0,0 +1,61 @@
+package team
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"intraclub/database"
+	"intraclub/model"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayingTimeVisibility(t *testing.T) {
+	db := database.NewUnitTestDBProvider()
+	router := newTestRouter(t, db)
+	captain := newStoredUser(t, db)
+	member := newStoredUser(t, db)
+	commissioner := newStoredUser(t, db)
+	outsider := newStoredUser(t, db)
+
+	team := newStoredTeam(t, db, captain)
+	addAssignment(t, db, team, member, model.TeamRoleMember)
+
+	season := model.NewSeason()
+	season.Name = "Test Season"
+	season.StartTime = model.NewStartTime(8, 30)
+	season, err := database.CreateOne(context.Background(), db, season)
+	require.NoError(t, err)
+	_, err = database.CreateOne(context.Background(), db, &model.SeasonTeam{SeasonId: season.ID, TeamId: team.ID})
+	require.NoError(t, err)
+	_, err = database.CreateOne(context.Background(), db, &model.SeasonCommissioner{SeasonId: season.ID, UserId: commissioner.ID})
+	require.NoError(t, err)
+
+	path := "/api/team/" + team.ID.String() + "/playing_time?season_id=" + season.ID.String()
+	rowsFor := func(userId database.UserId) []*model.PlayingTimeEntry {
+		w := doJSON(t, router, http.MethodGet, path, nil, newToken(t, userId))
+		require.Equal(t, http.StatusOK, w.Code, "playing time: %s", w.Body.String())
+		var resp struct {
+			Resource *model.PlayingTimeReport `json:"resource"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Resource.Entries
+	}
+
+	// The captain and the commissioner see every member's row ...
+	require.Len(t, rowsFor(captain.ID), 2)
+	require.Len(t, rowsFor(commissioner.ID), 2)
+
+	// ... while an ordinary member sees only their own.
+	rows := rowsFor(member.ID)
+	require.Len(t, rows, 1)
+	require.Equal(t, member.ID, rows[0].UserId)
+
+	w := doJSON(t, router, http.MethodGet, path, nil, newToken(t, outsider.ID))
+	require.Equal(t, http.StatusNotFound, w.Code, "outsider: %s", w.Body.String())
+
+	w = doJSON(t, router, http.MethodGet, "/api/team/"+team.ID.String()+"/playing_time", nil, newToken(t, captain.ID))
+	require.Equal(t, http.StatusBadRequest, w.Code, "missing season: %s", w.Body.String())
+}
@@ -152,7 +152,8 @@ type CloseWeekBody struct{}
 func (b *CloseWeekBody) StaticallyValid() error { return nil }
 
 // weekHasIncompleteMatch reports whether any team match in the week still has an
-// individual match that has not been decided (won or lost).
+// individual match that has not been decided (won or lost), or whose score has
+// not yet been confirmed or resolved.
 func weekHasIncompleteMatch(ctx context.Context, db database.Provider, weekId model.WeekId) (bool, error) {
 	teamMatches, err := database.GetAllWhere[*model.TeamMatch](ctx, db, func(_ context.Context, tm *model.TeamMatch) bool {
 		return tm.WeekId == weekId
@@ -178,6 +179,13 @@ func weekHasIncompleteMatch(ctx context.Context, db database.Provider, weekId mo
 			if im.Status != model.MatchWon && im.Status != model.MatchLost {
 				return true, nil
 			}
+			final, err := im.IsScoreFinal(ctx, db)
+			if err != nil {
+				return false, err
+			}
+			if !final {
+				return true, nil
+			}
 		}
 	}
 	return false, nil